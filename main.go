@@ -1,19 +1,170 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	// Correctly import your two local packages
 	"order-matching-engine/src/api"
 	"order-matching-engine/src/engine"
+	"order-matching-engine/src/fix"
+	"order-matching-engine/src/grpcapi"
 )
 
 func main() {
-	log.Println("Initializing the matching engine...")
+	addr := flag.String("addr", ":8080", "HTTP listen address")
+	grpcAddr := flag.String("grpc-addr", ":9090", "gRPC listen address; empty disables gRPC")
+	fixAddr := flag.String("fix-addr", ":9878", "FIX 4.4 listen address; empty disables FIX")
+	fixCompID := flag.String("fix-comp-id", "OME", "SenderCompID of the FIX acceptor")
+	snapshotPath := flag.String("snapshot", "", "snapshot file to restore on startup and write on POST /admin/snapshot")
+	walPath := flag.String("wal", "", "write-ahead log to replay on startup and append every mutation to")
+	logLevel := flag.String("log-level", "info", "log level: debug, info, warn or error")
+	orderRate := flag.Float64("order-rate", 0, "order submissions per second per account or IP; 0 = unlimited")
+	orderBurst := flag.Int("order-burst", 20, "order submission burst per account or IP")
+	readRate := flag.Float64("read-rate", 0, "cancels and reads per second per account or IP; 0 = unlimited")
+	readBurst := flag.Int("read-burst", 100, "cancel and read burst per account or IP")
+	rounding := flag.String("rounding", "CONSERVATIVE", "rounding of fees, notional spend and pro-rata shares: CONSERVATIVE, TRUNCATE, HALF_UP or HALF_EVEN")
+	imbalanceWebhook := flag.String("imbalance-webhook", "", "URL to POST book imbalance alerts to; empty disables them")
+	imbalanceThreshold := flag.Float64("imbalance-threshold", 0.9, "absolute book imbalance, in (0, 1], that triggers an alert")
+	imbalanceCooldown := flag.Duration("imbalance-cooldown", time.Minute, "minimum time between imbalance alerts for one symbol")
+	maxDepth := flag.Int("max-depth", api.DefaultMaxDepth, "most price levels per side an order book request returns; 0 = uncapped")
+	depthPolicy := flag.String("depth-policy", "clamp", "order book requests deeper than -max-depth: clamp (serve the cap) or reject (400)")
+	maxOrderAge := flag.Duration("max-order-age", 0, "warn once about each order resting longer than this; 0 disables stale-order detection")
+	drain := flag.Duration("drain", 5*time.Second, "on SIGTERM, how long to fail readiness before closing the listener, so load balancers stop routing first")
+	flag.Parse()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		fatal("Invalid -log-level", "error", err)
+	}
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	slog.SetDefault(logger)
+
+	logger.Info("Initializing the matching engine")
 	eng := engine.NewMatchingEngine()
-	srv := api.NewServer(eng)
-	log.Println("Starting API server on :8080")
-	if err := srv.Start(":8080"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	eng.SetLogger(logger)
+	mode, err := engine.ParseRoundingMode(*rounding)
+	if err != nil {
+		fatal("Invalid -rounding", "error", err)
+	}
+	_ = eng.SetRoundingMode(mode)
+	if err := eng.SetImbalanceAlerts(engine.ImbalanceAlertConfig{WebhookURL: *imbalanceWebhook, Threshold: *imbalanceThreshold, Cooldown: *imbalanceCooldown}); err != nil {
+		fatal("Invalid imbalance alert settings", "error", err)
+	}
+
+	if err := eng.SetMaxOrderAge(*maxOrderAge); err != nil {
+		fatal("Invalid -max-order-age", "error", err)
+	}
+
+	policy, err := api.ParseDepthPolicy(*depthPolicy)
+	if err != nil {
+		fatal("Invalid -depth-policy", "error", err)
+	}
+
+	opts := []api.Option{
+		api.WithLogger(logger),
+		api.WithRateLimit(api.RateLimit{Rate: *orderRate, Burst: *orderBurst}, api.RateLimit{Rate: *readRate, Burst: *readBurst}),
+		api.WithMaxDepth(*maxDepth, policy),
+	}
+	if *snapshotPath != "" {
+		opts = append(opts, api.WithSnapshotPath(*snapshotPath))
+		if f, err := os.Open(*snapshotPath); err == nil {
+			err = eng.LoadSnapshot(f)
+			f.Close()
+			if err != nil {
+				fatal("Failed to load snapshot", "path", *snapshotPath, "error", err)
+			}
+			logger.Info("Restored engine state", "path", *snapshotPath)
+		} else if !os.IsNotExist(err) {
+			fatal("Failed to open snapshot", "path", *snapshotPath, "error", err)
+		}
 	}
-}
\ No newline at end of file
+
+	if *walPath != "" {
+		if f, err := os.Open(*walPath); err == nil {
+			err = eng.Recover(f)
+			f.Close()
+			if err != nil {
+				fatal("Failed to replay WAL", "path", *walPath, "error", err)
+			}
+			logger.Info("Replayed write-ahead log", "path", *walPath)
+		} else if !os.IsNotExist(err) {
+			fatal("Failed to open WAL", "path", *walPath, "error", err)
+		}
+		f, err := os.OpenFile(*walPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			fatal("Failed to open WAL for append", "path", *walPath, "error", err)
+		}
+		defer f.Close()
+		eng.SetWAL(engine.NewJSONWAL(f))
+	}
+
+	stopReaper := eng.StartExpiryReaper(time.Second)
+	defer stopReaper()
+
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fatal("Failed to listen for gRPC", "addr", *grpcAddr, "error", err)
+		}
+		grpcSrv := grpcapi.NewServer(eng)
+		go func() {
+			logger.Info("Starting gRPC server", "addr", *grpcAddr)
+			if err := grpcSrv.Serve(lis); err != nil {
+				fatal("gRPC server failed", "error", err)
+			}
+		}()
+	}
+
+	if *fixAddr != "" {
+		lis, err := net.Listen("tcp", *fixAddr)
+		if err != nil {
+			fatal("Failed to listen for FIX", "addr", *fixAddr, "error", err)
+		}
+		acceptor := fix.NewAcceptor(eng, *fixCompID)
+		acceptor.SetLogger(logger)
+		go func() {
+			logger.Info("Starting FIX acceptor", "addr", *fixAddr, "comp_id", *fixCompID)
+			if err := acceptor.Serve(lis); err != nil {
+				fatal("FIX acceptor failed", "error", err)
+			}
+		}()
+	}
+
+	srv := api.NewServer(eng, opts...)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		logger.Info("Shutting down; failing readiness", "drain", *drain)
+		eng.Shutdown()
+		time.Sleep(*drain)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("API server shutdown", "error", err)
+		}
+	}()
+
+	logger.Info("Starting API server", "addr", *addr)
+	if err := srv.Start(*addr); !errors.Is(err, http.ErrServerClosed) {
+		fatal("Failed to start server", "error", err)
+	}
+	<-stopped
+}
+
+// fatal logs at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
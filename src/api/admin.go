@@ -0,0 +1,307 @@
+package api
+
+import (
+    "encoding/json"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "order-matching-engine/src/engine"
+)
+
+// handleSnapshot serves POST /admin/snapshot. With a configured snapshot path
+// the file is replaced atomically; otherwise the snapshot is the response body.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    if s.snapshotPath == "" {
+        w.Header().Set("Content-Type", "application/json")
+        if err := s.eng.Snapshot(w); err != nil {
+            s.writeErrorPlain(w, http.StatusInternalServerError, err.Error())
+        }
+        return
+    }
+    if err := writeSnapshotFile(s.eng.Snapshot, s.snapshotPath); err != nil {
+        s.writeErrorPlain(w, http.StatusInternalServerError, "snapshot failed: "+err.Error())
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "status": "ok",
+        "path":   s.snapshotPath,
+    })
+}
+
+// handleAuctionStart serves POST /admin/auction/start?symbol=AAPL, switching the
+// symbol to auction collection.
+func (s *Server) handleAuctionStart(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    if err := s.eng.StartAuction(symbol); err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol": symbol,
+        "phase":  string(engine.AuctionCollecting),
+    })
+}
+
+// handleAuctionRun serves POST /admin/auction/run?symbol=AAPL, uncrossing the
+// book and resuming continuous trading.
+func (s *Server) handleAuctionRun(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    if s.eng.GetTradingPhase(symbol) != engine.AuctionCollecting {
+        s.writeError(w, http.StatusConflict, engine.CodeInvalidState, "no auction in progress for "+symbol)
+        return
+    }
+    price, trades := s.eng.RunAuction(symbol)
+    sf := s.symbolFormat(r, symbol)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol":         symbol,
+        "clearing_price": sf.price(price),
+        "trades":         sf.trades(trades),
+        "phase":          string(s.eng.GetTradingPhase(symbol)),
+    })
+}
+
+// handleHalt serves POST /admin/halt?symbol=AAPL&mode=reject|queue. The
+// default mode rejects new orders; queue books them for the reopening uncross.
+func (s *Server) handleHalt(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    var mode engine.HaltMode
+    switch r.URL.Query().Get("mode") {
+    case "", "reject", "REJECT":
+        mode = engine.HaltReject
+    case "queue", "QUEUE":
+        mode = engine.HaltQueue
+    default:
+        s.writeErrorPlain(w, http.StatusBadRequest, "invalid mode: must be reject or queue")
+        return
+    }
+    if err := s.eng.Halt(symbol, mode); err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol": symbol,
+        "phase":  string(engine.Halted),
+        "mode":   string(mode),
+    })
+}
+
+// handleResume serves POST /admin/resume?symbol=AAPL. Trades from uncrossing
+// a queueing halt are returned.
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    trades, err := s.eng.Resume(symbol)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol": symbol,
+        "phase":  string(engine.Continuous),
+        "trades": s.symbolFormat(r, symbol).trades(trades),
+    })
+}
+
+// handleKillSwitch serves POST /admin/killswitch, halting every symbol and
+// cancelling every open order until POST /admin/reset.
+func (s *Server) handleKillSwitch(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    cancelled, err := s.eng.KillSwitch()
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "killed": true,
+        "count":  cancelled,
+    })
+}
+
+// handleReset serves POST /admin/reset, lifting the kill switch.
+func (s *Server) handleReset(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    if err := s.eng.Reset(); err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "killed": false,
+    })
+}
+
+// writeSnapshotFile writes to a temp file in the same directory and renames
+// it over path, so a crash mid-write never leaves a truncated snapshot.
+func writeSnapshotFile(snapshot func(w io.Writer) error, path string) error {
+    tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    if err := snapshot(tmp); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}
+
+// handleRollSession serves POST /admin/roll-session?symbol=AAPL, starting a
+// new trading day: DAY orders expire and the ticker and candles reset. The
+// expired orders are returned.
+func (s *Server) handleRollSession(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    expired, err := s.eng.RollSession(symbol)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    sf := s.symbolFormat(r, symbol)
+    out := make([]map[string]interface{}, len(expired))
+    for i, o := range expired {
+        out[i] = orderJSON(o, sf)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol":  symbol,
+        "expired": out,
+        "count":   len(expired),
+    })
+}
+
+// handleReference serves POST /admin/reference?symbol=AAPL&bid=10000&ask=10010,
+// the symbol's external best bid and ask that no_trade_through orders must
+// not trade through. Prices take the same forms as order prices; an omitted
+// or zero side is unquoted.
+func (s *Server) handleReference(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    q := r.URL.Query()
+    symbol := q.Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    sf := s.symbolFormat(r, symbol)
+    bid, err := sf.parsePrice(priceInput{text: q.Get("bid"), decimal: sf.decimalPrices})
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "bid: "+err.Error())
+        return
+    }
+    ask, err := sf.parsePrice(priceInput{text: q.Get("ask"), decimal: sf.decimalPrices})
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "ask: "+err.Error())
+        return
+    }
+    if err := s.eng.SetReference(symbol, bid, ask); err != nil {
+        if engine.Code(err) != "" {
+            s.writeEngineError(w, err)
+        } else {
+            s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
+        }
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol": symbol,
+        "bid":    sf.price(bid),
+        "ask":    sf.price(ask),
+    })
+}
+
+// handleTradeBust serves POST /admin/trades/{trade_id}/bust, busting an
+// executed trade: its orders' filled quantities and positions are restored
+// and it is marked busted in the trade history (see engine bust.go).
+func (s *Server) handleTradeBust(w http.ResponseWriter, r *http.Request) {
+    rest := strings.TrimPrefix(r.URL.Path, "/admin/trades/")
+    tradeID, ok := strings.CutSuffix(rest, "/bust")
+    if !ok || tradeID == "" || strings.Contains(tradeID, "/") {
+        s.writeErrorPlain(w, http.StatusNotFound, "not found")
+        return
+    }
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    if err := s.eng.BustTrade(tradeID); err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "trade_id": tradeID,
+        "busted":   true,
+    })
+}
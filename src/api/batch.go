@@ -0,0 +1,91 @@
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+
+    "order-matching-engine/src/engine"
+)
+
+// MaxBatchSize caps the number of orders accepted by one batch request.
+const MaxBatchSize = 1000
+
+// handleBatch handles POST /api/v1/orders/batch. Each element is validated and
+// submitted in order; the response carries one result per element, either the
+// usual create-order body or {"status":"REJECTED","error":...,"code":...},
+// with the details of an invalid order (see validate.go).
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    var items []json.RawMessage
+    if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid json: expected an array of orders")
+        return
+    }
+    if len(items) == 0 || len(items) > MaxBatchSize {
+        s.writeErrorPlain(w, http.StatusBadRequest, fmt.Sprintf("Invalid batch: must contain between 1 and %d orders", MaxBatchSize))
+        return
+    }
+
+    results := make([]map[string]interface{}, len(items))
+    formats := make([]symbolFormat, len(items))
+    var orders []*engine.Order
+    var positions []int
+    for i, raw := range items {
+        var req createOrderRequest
+        decoder := json.NewDecoder(bytes.NewReader(raw))
+        decoder.DisallowUnknownFields()
+        if err := decoder.Decode(&req); err != nil {
+            results[i] = rejected(engine.CodeInvalidOrder, "Invalid json")
+            continue
+        }
+        order, sf, err := s.newOrder(r, req)
+        if err != nil {
+            s.recordInvalid(req, err)
+            results[i] = invalidOrderBody(err)
+            results[i]["status"] = "REJECTED"
+            continue
+        }
+        formats[i] = sf
+        orders = append(orders, order)
+        positions = append(positions, i)
+    }
+
+    sess, err := s.orderSession(r)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    batch := s.eng.SubmitBatch(orders)
+    var accepted []string
+    for _, res := range batch {
+        if res.Err == nil && !res.Response.Resubmitted {
+            accepted = append(accepted, res.Order.ID)
+        }
+    }
+    s.settleSession(sess, accepted...)
+    for j, res := range batch {
+        i := positions[j]
+        if res.Err != nil {
+            code := engine.Code(res.Err)
+            if code == "" {
+                code = engine.CodeInvalidOrder
+            }
+            results[i] = rejected(code, res.Err.Error())
+            continue
+        }
+        _, results[i] = orderResult(res.Order, res.Response, formats[i])
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
+
+func rejected(code engine.ReasonCode, msg string) map[string]interface{} {
+    return map[string]interface{}{"status": "REJECTED", "error": msg, "code": string(code)}
+}
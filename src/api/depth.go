@@ -0,0 +1,65 @@
+package api
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+)
+
+// DefaultMaxDepth is the most price levels per side the order book
+// endpoints return unless WithMaxDepth sets another cap.
+const DefaultMaxDepth = 500
+
+// DepthPolicy is what the order book endpoints do with a depth above the
+// cap. Either way an absent or zero depth, which asks for the whole book,
+// returns the book down to the cap, and the total_*_levels fields still
+// count the whole book so clients can tell it was cut.
+type DepthPolicy string
+
+const (
+    DepthClamp  DepthPolicy = "clamp"  // Serve the capped depth instead
+    DepthReject DepthPolicy = "reject" // Answer 400
+)
+
+// ParseDepthPolicy parses a DepthPolicy, ignoring case.
+func ParseDepthPolicy(s string) (DepthPolicy, error) {
+    switch policy := DepthPolicy(strings.ToLower(s)); policy {
+    case DepthClamp, DepthReject:
+        return policy, nil
+    }
+    return "", fmt.Errorf("invalid depth policy %q; must be clamp or reject", s)
+}
+
+// WithMaxDepth caps the price levels per side GET /api/v1/orderbook returns,
+// for one symbol or several, to protect memory and response size on deep
+// books. A max of 0 or less removes the cap.
+func WithMaxDepth(max int, policy DepthPolicy) Option {
+    return func(s *Server) {
+        s.maxDepth = max
+        s.depthPolicy = policy
+    }
+}
+
+// bookDepth reads an order book request's depth parameter and applies the
+// cap.
+func (s *Server) bookDepth(r *http.Request) (int, error) {
+    depth := 0
+    if v := r.URL.Query().Get("depth"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            return 0, errors.New("invalid depth")
+        }
+        depth = n
+    }
+    switch {
+    case s.maxDepth <= 0:
+        return depth, nil
+    case depth == 0:
+        return s.maxDepth, nil
+    case depth > s.maxDepth && s.depthPolicy == DepthReject:
+        return 0, fmt.Errorf("depth %d exceeds the maximum of %d", depth, s.maxDepth)
+    }
+    return min(depth, s.maxDepth), nil
+}
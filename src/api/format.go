@@ -0,0 +1,277 @@
+package api
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "mime"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "order-matching-engine/src/engine"
+)
+
+// decimalInput accepts either a JSON number or a decimal string and keeps the
+// exact text, so fractional quantities never pass through a float.
+type decimalInput string
+
+func (d *decimalInput) UnmarshalJSON(b []byte) error {
+    if bytes.Equal(b, []byte("null")) {
+        return nil
+    }
+    if len(b) > 0 && b[0] == '"' {
+        var s string
+        if err := json.Unmarshal(b, &s); err != nil {
+            return err
+        }
+        *d = decimalInput(s)
+        return nil
+    }
+    var n json.Number
+    if err := json.Unmarshal(b, &n); err != nil {
+        return errors.New("expected a number or decimal string")
+    }
+    *d = decimalInput(n)
+    return nil
+}
+
+// priceInput is a price in a request. A JSON integer is raw price units
+// (the original API); a decimal string such as "150.50", or a number with a
+// fraction, is read with the symbol's PriceScale. Prices in decimal
+// responses can therefore be sent straight back.
+type priceInput struct {
+    text    string
+    decimal bool
+}
+
+func (p *priceInput) UnmarshalJSON(b []byte) error {
+    var d decimalInput
+    if err := d.UnmarshalJSON(b); err != nil {
+        return err
+    }
+    p.text = string(d)
+    p.decimal = b[0] == '"' || strings.ContainsAny(p.text, ".eE")
+    return nil
+}
+
+// symbolFormat renders a symbol's fixed-point numbers for one request.
+// Quantities are plain integers for whole-unit symbols (the original API)
+// and exact decimal strings for symbols configured with QuantityDecimals > 0.
+// Prices are raw integers unless the client asked for decimal prices (see
+// wantsDecimalPrices), which are strings with PriceScale decimal places.
+type symbolFormat struct {
+    quantityDecimals int
+    priceScale       int
+    decimalPrices    bool
+}
+
+func (s *Server) symbolFormat(r *http.Request, symbol string) symbolFormat {
+    cfg := s.eng.GetSymbolConfig(symbol)
+    return symbolFormat{
+        quantityDecimals: cfg.QuantityDecimals,
+        priceScale:       cfg.PriceScale,
+        decimalPrices:    wantsDecimalPrices(r),
+    }
+}
+
+// wantsDecimalPrices reports whether the request asks for decimal prices,
+// with ?format=decimal or a format=decimal parameter on an Accept media
+// type (Accept: application/json; format=decimal).
+func wantsDecimalPrices(r *http.Request) bool {
+    if r.URL.Query().Get("format") == "decimal" {
+        return true
+    }
+    for _, accept := range r.Header.Values("Accept") {
+        for _, part := range strings.Split(accept, ",") {
+            if _, params, err := mime.ParseMediaType(part); err == nil && params["format"] == "decimal" {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// wantsCompactBook reports whether the request accepts the compact binary
+// book encoding (Accept: application/octet-stream; see engine compact.go).
+func wantsCompactBook(r *http.Request) bool {
+    for _, accept := range r.Header.Values("Accept") {
+        for _, part := range strings.Split(accept, ",") {
+            if mediaType, _, err := mime.ParseMediaType(part); err == nil && mediaType == "application/octet-stream" {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// parse converts a client quantity into fixed-point units; empty input is 0.
+func (f symbolFormat) parse(in decimalInput) (int64, error) {
+    if in == "" {
+        return 0, nil
+    }
+    return engine.ParseQuantity(string(in), f.quantityDecimals)
+}
+
+// parsePrice converts a client price into price units; empty input is 0.
+func (f symbolFormat) parsePrice(in priceInput) (int64, error) {
+    if in.text == "" {
+        return 0, nil
+    }
+    if in.decimal {
+        return engine.ParsePrice(in.text, f.priceScale)
+    }
+    p, err := strconv.ParseInt(in.text, 10, 64)
+    if err != nil {
+        return 0, fmt.Errorf("invalid price %q", in.text)
+    }
+    return p, nil
+}
+
+func (f symbolFormat) quantity(q int64) interface{} {
+    if f.quantityDecimals == 0 {
+        return q
+    }
+    return engine.FormatQuantity(q, f.quantityDecimals)
+}
+
+func (f symbolFormat) price(p int64) interface{} {
+    if !f.decimalPrices {
+        return p
+    }
+    return engine.FormatPrice(p, f.priceScale)
+}
+
+// halfPrice renders sum/2, such as a mid, which may fall half a unit
+// between two prices: as a float in integer mode, as before, and with one
+// extra decimal place when it does in decimal mode.
+func (f symbolFormat) halfPrice(sum int64) interface{} {
+    if !f.decimalPrices {
+        return float64(sum) / 2
+    }
+    if sum%2 == 0 {
+        return f.price(sum / 2)
+    }
+    return engine.FormatPrice(sum*5, f.priceScale+1)
+}
+
+type tradeJSON struct {
+    engine.Trade
+    Price        interface{} `json:"price"`
+    Quantity     interface{} `json:"quantity"`
+    AggressorFee interface{} `json:"aggressor_fee"`
+    RestingFee   interface{} `json:"resting_fee"`
+}
+
+func (f symbolFormat) trades(trades []engine.Trade) []tradeJSON {
+    if trades == nil {
+        return nil
+    }
+    out := make([]tradeJSON, len(trades))
+    for i, t := range trades {
+        out[i] = f.trade(t)
+    }
+    return out
+}
+
+func (f symbolFormat) trade(t engine.Trade) tradeJSON {
+    return tradeJSON{
+        Trade:        t,
+        Price:        f.price(t.Price),
+        Quantity:     f.quantity(t.Quantity),
+        AggressorFee: f.quantity(t.AggressorFee),
+        RestingFee:   f.quantity(t.RestingFee),
+    }
+}
+
+type executionJSON struct {
+    engine.Execution
+    FilledQuantity interface{} `json:"filled_quantity"`
+    AveragePrice   interface{} `json:"average_price"`
+    Fees           interface{} `json:"fees"`
+}
+
+func (f symbolFormat) execution(e engine.Execution) executionJSON {
+    return executionJSON{
+        Execution:      e,
+        FilledQuantity: f.quantity(e.FilledQuantity),
+        AveragePrice:   f.price(e.AveragePrice),
+        Fees:           f.quantity(e.Fees),
+    }
+}
+
+type levelJSON struct {
+    engine.AggregatedPriceLevel
+    Price    interface{} `json:"price"`
+    Quantity interface{} `json:"quantity"`
+}
+
+func (f symbolFormat) levels(levels []engine.AggregatedPriceLevel) []levelJSON {
+    if levels == nil {
+        return nil
+    }
+    out := make([]levelJSON, len(levels))
+    for i, l := range levels {
+        out[i] = f.level(l)
+    }
+    return out
+}
+
+func (f symbolFormat) level(l engine.AggregatedPriceLevel) levelJSON {
+    return levelJSON{AggregatedPriceLevel: l, Price: f.price(l.Price), Quantity: f.quantity(l.Quantity)}
+}
+
+type candleJSON struct {
+    engine.Candle
+    Open   interface{} `json:"open"`
+    High   interface{} `json:"high"`
+    Low    interface{} `json:"low"`
+    Close  interface{} `json:"close"`
+    Volume interface{} `json:"volume"`
+}
+
+func (f symbolFormat) candles(candles []engine.Candle) []candleJSON {
+    out := make([]candleJSON, len(candles))
+    for i, c := range candles {
+        out[i] = candleJSON{
+            Candle: c,
+            Open:   f.price(c.Open),
+            High:   f.price(c.High),
+            Low:    f.price(c.Low),
+            Close:  f.price(c.Close),
+            Volume: f.quantity(c.Volume),
+        }
+    }
+    return out
+}
+
+type positionJSON struct {
+    engine.Position
+    NetQuantity  interface{} `json:"net_quantity"`
+    AveragePrice interface{} `json:"average_price"`
+    CostBasis    interface{} `json:"cost_basis"`
+    RealizedPnL  interface{} `json:"realized_pnl"`
+    Fees         interface{} `json:"fees"`
+    Volume       interface{} `json:"volume,omitempty"` // Left nil: served by /api/v1/accounts/{id}/fees
+}
+
+func (f symbolFormat) position(p engine.Position) positionJSON {
+    return positionJSON{
+        Position:     p,
+        NetQuantity:  f.quantity(p.NetQuantity),
+        AveragePrice: f.price(p.AveragePrice),
+        CostBasis:    f.quantity(p.CostBasis),
+        RealizedPnL:  f.quantity(p.RealizedPnL),
+        Fees:         f.quantity(p.Fees),
+    }
+}
+
+type accountFeesJSON struct {
+    engine.AccountFees
+    Volume interface{} `json:"volume"`
+}
+
+func (f symbolFormat) accountFees(a engine.AccountFees) accountFeesJSON {
+    return accountFeesJSON{AccountFees: a, Volume: f.quantity(a.Volume)}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+)
+
+// Probe endpoints. /api/v1/live answers 200 whenever the process is serving
+// HTTP, so an orchestrator only restarts a server that has stopped
+// responding. /api/v1/ready reports the engine's Health and answers 503
+// while it is not ready (recovering, killed or shutting down), so load
+// balancers route around it without restarting it. /api/v1/health is the
+// readiness report under its original path.
+var probePaths = map[string]bool{
+    "/api/v1/health": true,
+    "/api/v1/ready":  true,
+    "/api/v1/live":   true,
+}
+
+// handleLive serves GET /api/v1/live.
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "alive"})
+}
+
+// handleReady serves GET /api/v1/ready and /api/v1/health.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+    health := s.eng.Health()
+    status := http.StatusOK
+    if !health.Ready {
+        status = http.StatusServiceUnavailable
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    _ = json.NewEncoder(w).Encode(health)
+}
+
+// Shutdown marks the engine as shutting down, so readiness fails at once,
+// then stops the server started by Start, waiting for requests in flight
+// until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+    s.eng.Shutdown()
+    hs := s.httpServer.Load()
+    if hs == nil {
+        return nil
+    }
+    return hs.Shutdown(ctx)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+    "bufio"
+    "errors"
+    "log/slog"
+    "net"
+    "net/http"
+    "time"
+
+    "github.com/google/uuid"
+    "order-matching-engine/src/engine"
+)
+
+// RequestIDHeader carries the request ID. An incoming value is reused so
+// callers can correlate across services; otherwise one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+// WithLogger sets the structured logger used for access logs.
+func WithLogger(logger *slog.Logger) Option {
+    return func(s *Server) { s.logger = logger }
+}
+
+// withRequestLogging tags the request context with a request ID, echoes it in
+// the response, and logs the request once it completes.
+func (s *Server) withRequestLogging(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        id := r.Header.Get(RequestIDHeader)
+        if id == "" {
+            id = uuid.New().String()
+        }
+        w.Header().Set(RequestIDHeader, id)
+        r = r.WithContext(engine.WithRequestID(r.Context(), id))
+
+        if !s.logger.Enabled(r.Context(), slog.LevelInfo) {
+            next.ServeHTTP(w, r)
+            return
+        }
+        start := time.Now()
+        rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+        next.ServeHTTP(rec, r)
+        s.logger.LogAttrs(r.Context(), slog.LevelInfo, "http request",
+            slog.String("request_id", id),
+            slog.String("method", r.Method),
+            slog.String("path", r.URL.Path),
+            slog.Int("status", rec.status),
+            slog.Duration("latency", time.Since(start)),
+        )
+    })
+}
+
+// statusRecorder captures the response status for access logs.
+type statusRecorder struct {
+    http.ResponseWriter
+    status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+    r.status = status
+    r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack lets WebSocket upgrades through the recorder.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+    h, ok := r.ResponseWriter.(http.Hijacker)
+    if !ok {
+        return nil, nil, errors.New("response writer does not support hijacking")
+    }
+    r.status = http.StatusSwitchingProtocols
+    return h.Hijack()
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+    return r.ResponseWriter
+}
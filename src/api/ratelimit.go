@@ -0,0 +1,146 @@
+package api
+
+import (
+    "math"
+    "net"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "order-matching-engine/src/engine"
+)
+
+// AccountIDHeader identifies the calling account for rate limiting. Without
+// it the account query parameter is used, then the client IP.
+const AccountIDHeader = "X-Account-ID"
+
+// codeRateLimited is the error code of a 429 response.
+const codeRateLimited engine.ReasonCode = "RATE_LIMITED"
+
+// maxRateBuckets bounds the number of tracked clients; beyond it, clients
+// whose buckets have refilled are forgotten.
+const maxRateBuckets = 10_000
+
+// RateLimit is a token bucket: Rate requests per second on average, with
+// bursts of up to Burst requests.
+type RateLimit struct {
+    Rate  float64
+    Burst int
+}
+
+// WithRateLimit caps requests per client. Order submissions (creating,
+// amending, replacing and batching orders) draw on orders; cancels and reads
+// draw on the separate, normally higher, other limit. Each batch request
+// counts once. A zero Rate leaves that class unlimited.
+func WithRateLimit(orders, other RateLimit) Option {
+    return func(s *Server) {
+        if orders.Rate > 0 {
+            s.orderLimiter = newRateLimiter(orders)
+        }
+        if other.Rate > 0 {
+            s.otherLimiter = newRateLimiter(other)
+        }
+    }
+}
+
+// withRateLimit rejects over-limit /api/v1 requests with 429 and a
+// Retry-After header. Health checks are never limited.
+func (s *Server) withRateLimit(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if !strings.HasPrefix(r.URL.Path, "/api/v1/") || probePaths[r.URL.Path] {
+            next.ServeHTTP(w, r)
+            return
+        }
+        limiter := s.otherLimiter
+        if isOrderSubmission(r) {
+            limiter = s.orderLimiter
+        }
+        if limiter != nil {
+            if ok, wait := limiter.allow(rateLimitKey(r), time.Now()); !ok {
+                w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+                s.writeError(w, http.StatusTooManyRequests, codeRateLimited, "rate limit exceeded")
+                return
+            }
+        }
+        next.ServeHTTP(w, r)
+    })
+}
+
+// isOrderSubmission reports whether r creates or changes an order: POST or
+// PATCH under /api/v1/orders. Cancels are DELETEs.
+func isOrderSubmission(r *http.Request) bool {
+    if r.Method != http.MethodPost && r.Method != http.MethodPatch {
+        return false
+    }
+    return r.URL.Path == "/api/v1/orders" || strings.HasPrefix(r.URL.Path, "/api/v1/orders/")
+}
+
+// rateLimitKey identifies the client: the account header, then the account
+// query parameter, then the remote IP.
+func rateLimitKey(r *http.Request) string {
+    if account := r.Header.Get(AccountIDHeader); account != "" {
+        return "account:" + account
+    }
+    if account := r.URL.Query().Get("account"); account != "" {
+        return "account:" + account
+    }
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        host = r.RemoteAddr
+    }
+    return "ip:" + host
+}
+
+type rateLimiter struct {
+    limit   RateLimit
+    mu      sync.Mutex
+    buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+    tokens float64
+    last   time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+    if limit.Burst < 1 {
+        limit.Burst = 1
+    }
+    return &rateLimiter{limit: limit, buckets: make(map[string]*tokenBucket)}
+}
+
+// allow takes a token from key's bucket. If none is available it returns
+// false and how long until one will be.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    burst := float64(l.limit.Burst)
+    b, ok := l.buckets[key]
+    if !ok {
+        if len(l.buckets) >= maxRateBuckets {
+            l.prune(now)
+        }
+        b = &tokenBucket{tokens: burst, last: now}
+        l.buckets[key] = b
+    }
+    b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+    b.last = now
+    if b.tokens < 1 {
+        return false, time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+    }
+    b.tokens--
+    return true, 0
+}
+
+// prune forgets clients whose buckets are full again; they would start with
+// a full bucket anyway. The caller holds mu.
+func (l *rateLimiter) prune(now time.Time) {
+    for key, b := range l.buckets {
+        if b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate >= float64(l.limit.Burst) {
+            delete(l.buckets, key)
+        }
+    }
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+    "bufio"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// MaxReplayEvents caps the number of events one replay request may carry.
+const MaxReplayEvents = 1_000_000
+
+// maxReplayErrors caps how many rejected events a replay reports in detail;
+// the rest are only counted.
+const maxReplayErrors = 100
+
+// replayEvent is one timestamped order event from a replay file: a NEW
+// order, described as for POST /api/v1/orders, or a CANCEL of an earlier
+// order by ID.
+type replayEvent struct {
+    line      int
+    timestamp int64 // Unix milliseconds
+    action    string
+    req       createOrderRequest
+}
+
+// replayLine is one NDJSON replay event.
+type replayLine struct {
+    Timestamp int64  `json:"timestamp"`
+    Action    string `json:"action"`
+    createOrderRequest
+}
+
+// replayColumns sets a CSV column's value on an event. A CSV replay file
+// starts with a header naming its columns in any order; timestamp is
+// required and empty cells are omitted fields.
+var replayColumns = map[string]func(ev *replayEvent, value string) error{
+    "timestamp": func(ev *replayEvent, v string) (err error) {
+        ev.timestamp, err = strconv.ParseInt(v, 10, 64)
+        return err
+    },
+    "action":           func(ev *replayEvent, v string) error { ev.action = v; return nil },
+    "id":               func(ev *replayEvent, v string) error { ev.req.ID = v; return nil },
+    "symbol":           func(ev *replayEvent, v string) error { ev.req.Symbol = v; return nil },
+    "account_id":       func(ev *replayEvent, v string) error { ev.req.AccountID = v; return nil },
+    "side":             func(ev *replayEvent, v string) error { ev.req.Side = v; return nil },
+    "type":             func(ev *replayEvent, v string) error { ev.req.Type = v; return nil },
+    "price":            func(ev *replayEvent, v string) error { ev.req.Price = csvPrice(v); return nil },
+    "stop_price":       func(ev *replayEvent, v string) error { ev.req.StopPrice = csvPrice(v); return nil },
+    "quantity":         func(ev *replayEvent, v string) error { ev.req.Quantity = decimalInput(v); return nil },
+    "display_quantity": func(ev *replayEvent, v string) error { ev.req.DisplayQuantity = decimalInput(v); return nil },
+    "time_in_force":    func(ev *replayEvent, v string) error { ev.req.TimeInForce = v; return nil },
+    "expires_at": func(ev *replayEvent, v string) (err error) {
+        ev.req.ExpiresAt, err = strconv.ParseInt(v, 10, 64)
+        return err
+    },
+    "post_only":   func(ev *replayEvent, v string) (err error) { ev.req.PostOnly, err = strconv.ParseBool(v); return err },
+    "all_or_none": func(ev *replayEvent, v string) (err error) { ev.req.AllOrNone, err = strconv.ParseBool(v); return err },
+    "hidden":      func(ev *replayEvent, v string) (err error) { ev.req.Hidden, err = strconv.ParseBool(v); return err },
+}
+
+// csvPrice reads a CSV price cell like a JSON number: decimal only if it
+// has a fraction or exponent.
+func csvPrice(v string) priceInput {
+    return priceInput{text: v, decimal: strings.ContainsAny(v, ".eE")}
+}
+
+// replayError is a replay event the engine or validation refused.
+type replayError struct {
+    Line  int    `json:"line"`
+    ID    string `json:"id,omitempty"`
+    Error string `json:"error"`
+}
+
+// handleReplay serves POST /api/v1/replay: a backtest. The body is a CSV
+// (the default, or Content-Type text/csv) or NDJSON (application/x-ndjson,
+// or ?format=ndjson) file of order events, read as a stream. The events are
+// replayed in timestamp order, file order breaking ties, into a sandbox
+// engine with the live engine's symbol configuration, so the live books are
+// never touched. With preserve_timestamps=true each order carries its
+// event's timestamp and the sandbox clock follows the events, so expiry and
+// trading hours play out as they did; otherwise orders are stamped as they
+// are replayed. The response aggregates the run: events accepted and
+// rejected, trades, and each symbol's final book.
+func (s *Server) handleReplay(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    q := r.URL.Query()
+    format := strings.ToLower(q.Get("format"))
+    if format == "" {
+        format = "csv"
+        if ct := r.Header.Get("Content-Type"); strings.Contains(ct, "json") {
+            format = "ndjson"
+        }
+    }
+    preserve := q.Get("preserve_timestamps") == "true"
+
+    var events []replayEvent
+    var err error
+    switch format {
+    case "csv":
+        events, err = readReplayCSV(r.Body)
+    case "ndjson":
+        events, err = readReplayNDJSON(r.Body)
+    default:
+        s.writeErrorPlain(w, http.StatusBadRequest, "invalid format; must be csv or ndjson")
+        return
+    }
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid replay file: "+err.Error())
+        return
+    }
+    sort.SliceStable(events, func(i, j int) bool { return events[i].timestamp < events[j].timestamp })
+
+    sandbox := s.eng.NewSandbox()
+    var now time.Time
+    if preserve {
+        sandbox.SetClock(func() time.Time { return now })
+    }
+    var accepted, trades int
+    var rejectedEvents []replayError
+    rejectedCount := 0
+    reject := func(ev replayEvent, err error) {
+        rejectedCount++
+        if len(rejectedEvents) < maxReplayErrors {
+            rejectedEvents = append(rejectedEvents, replayError{Line: ev.line, ID: ev.req.ID, Error: err.Error()})
+        }
+    }
+    symbols := map[string]bool{}
+    for _, ev := range events {
+        now = time.UnixMilli(ev.timestamp)
+        switch strings.ToUpper(ev.action) {
+        case "", "NEW":
+            order, _, err := s.newOrder(r, ev.req)
+            if err != nil {
+                reject(ev, err)
+                continue
+            }
+            if preserve {
+                order.Timestamp = ev.timestamp
+            }
+            resp, err := sandbox.SubmitOrder(order)
+            if err != nil {
+                reject(ev, err)
+                continue
+            }
+            accepted++
+            trades += len(resp.Trades)
+            symbols[order.Symbol] = true
+        case "CANCEL":
+            if _, err := sandbox.CancelOrder(ev.req.ID); err != nil {
+                reject(ev, err)
+                continue
+            }
+            accepted++
+        default:
+            reject(ev, fmt.Errorf("invalid action %q; must be NEW or CANCEL", ev.action))
+        }
+    }
+
+    books := make(map[string]interface{}, len(symbols))
+    for symbol := range symbols {
+        bids, asks := sandbox.GetOrderBookSnapshot(symbol, 0)
+        sf := s.symbolFormat(r, symbol)
+        books[symbol] = map[string]interface{}{"bids": sf.levels(bids), "asks": sf.levels(asks)}
+    }
+    body := map[string]interface{}{
+        "events":   len(events),
+        "accepted": accepted,
+        "rejected": rejectedCount,
+        "trades":   trades,
+        "books":    books,
+    }
+    if len(rejectedEvents) > 0 {
+        body["errors"] = rejectedEvents
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(body)
+}
+
+// readReplayCSV reads CSV replay events; see replayColumns.
+func readReplayCSV(body io.Reader) ([]replayEvent, error) {
+    reader := csv.NewReader(body)
+    reader.TrimLeadingSpace = true
+    reader.ReuseRecord = true
+    header, err := reader.Read()
+    if err != nil {
+        return nil, fmt.Errorf("reading the header: %w", err)
+    }
+    setters := make([]func(*replayEvent, string) error, len(header))
+    hasTimestamp := false
+    for i, name := range header {
+        name = strings.ToLower(strings.TrimSpace(name))
+        if setters[i] = replayColumns[name]; setters[i] == nil {
+            return nil, fmt.Errorf("unknown column %q", name)
+        }
+        hasTimestamp = hasTimestamp || name == "timestamp"
+    }
+    if !hasTimestamp {
+        return nil, errors.New("a timestamp column is required")
+    }
+
+    var events []replayEvent
+    for {
+        record, err := reader.Read()
+        if err == io.EOF {
+            return events, nil
+        }
+        if err != nil {
+            return nil, err
+        }
+        line, _ := reader.FieldPos(0)
+        if len(events) == MaxReplayEvents {
+            return nil, fmt.Errorf("more than %d events", MaxReplayEvents)
+        }
+        ev := replayEvent{line: line}
+        for i, value := range record {
+            if value == "" {
+                continue
+            }
+            if err := setters[i](&ev, value); err != nil {
+                return nil, fmt.Errorf("line %d: %s: %w", line, header[i], err)
+            }
+        }
+        events = append(events, ev)
+    }
+}
+
+// readReplayNDJSON reads one JSON replay event per line; blank lines are
+// skipped.
+func readReplayNDJSON(body io.Reader) ([]replayEvent, error) {
+    scanner := bufio.NewScanner(body)
+    scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+    var events []replayEvent
+    for line := 1; scanner.Scan(); line++ {
+        text := strings.TrimSpace(scanner.Text())
+        if text == "" {
+            continue
+        }
+        if len(events) == MaxReplayEvents {
+            return nil, fmt.Errorf("more than %d events", MaxReplayEvents)
+        }
+        var rl replayLine
+        decoder := json.NewDecoder(strings.NewReader(text))
+        decoder.DisallowUnknownFields()
+        if err := decoder.Decode(&rl); err != nil {
+            return nil, fmt.Errorf("line %d: %w", line, err)
+        }
+        events = append(events, replayEvent{line: line, timestamp: rl.Timestamp, action: rl.Action, req: rl.createOrderRequest})
+    }
+    return events, scanner.Err()
+}
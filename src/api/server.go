@@ -3,62 +3,143 @@ package api
 import (
     "encoding/json"
     "errors"
+    "fmt"
+    "log/slog"
     "net/http"
+    "net/url"
     "strconv"
     "strings"
+    "sync/atomic"
     "time"
 
-    "github.com/google/uuid"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
     "order-matching-engine/src/engine"
 )
 
 type Server struct {
-    eng *engine.MatchingEngine
-    mux *http.ServeMux
+    eng     *engine.MatchingEngine
+    mux     *http.ServeMux
+    handler http.Handler
+
+    snapshotPath string
+    logger       *slog.Logger
+
+    orderLimiter *rateLimiter // See WithRateLimit; nil = unlimited
+    otherLimiter *rateLimiter
+
+    maxDepth    int // See WithMaxDepth; 0 = uncapped
+    depthPolicy DepthPolicy
+
+    sessions sessionRegistry // Open /ws/session connections
+
+    httpServer atomic.Pointer[http.Server] // Set by Start; see Shutdown
+}
+
+// Option configures optional Server behaviour.
+type Option func(*Server)
+
+// WithSnapshotPath makes POST /admin/snapshot write to path instead of
+// returning the snapshot in the response body.
+func WithSnapshotPath(path string) Option {
+    return func(s *Server) { s.snapshotPath = path }
 }
 
-func NewServer(eng *engine.MatchingEngine) *Server {
-    s := &Server{eng: eng, mux: http.NewServeMux()}
+func NewServer(eng *engine.MatchingEngine, opts ...Option) *Server {
+    s := &Server{eng: eng, mux: http.NewServeMux(), logger: slog.New(slog.DiscardHandler), maxDepth: DefaultMaxDepth, depthPolicy: DepthClamp}
+    s.sessions.eng = eng
+    for _, opt := range opts {
+        opt(s)
+    }
     s.registerRoutes()
+    s.handler = s.withRequestLogging(s.withRateLimit(s.mux))
     return s
 }
 
+// Start serves HTTP on addr until Shutdown, when it returns
+// http.ErrServerClosed.
 func (s *Server) Start(addr string) error {
-    return http.ListenAndServe(addr, s.mux)
+    hs := &http.Server{Addr: addr, Handler: s}
+    s.httpServer.Store(hs)
+    return hs.ListenAndServe()
 }
 
 // ServeHTTP allows Server to satisfy http.Handler, delegating to its mux.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-    s.mux.ServeHTTP(w, r)
+    s.handler.ServeHTTP(w, r)
 }
 
 func (s *Server) registerRoutes() {
     // API v1 aliases
     s.mux.HandleFunc("/api/v1/orders", s.handleOrders)
     s.mux.HandleFunc("/api/v1/orders/", s.handleOrderByID)
+    s.mux.HandleFunc("/api/v1/orders/batch", s.handleBatch)
+    s.mux.HandleFunc("/api/v1/replay", s.handleReplay)
     s.mux.HandleFunc("/api/v1/orderbook", s.handleOrderBookGeneral)
     s.mux.HandleFunc("/api/v1/orderbook/", s.handleOrderBookGeneral)
-    // simple health check
-    s.mux.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
-        w.Header().Set("Content-Type", "application/json")
-        w.WriteHeader(http.StatusOK)
-        _ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "healthy"})
-    })
+    s.mux.HandleFunc("/api/v1/orderbook/stats", s.handleBookStats)
+    s.mux.HandleFunc("/api/v1/orderbook/diff", s.handleBookDiff)
+    s.mux.HandleFunc("/api/v1/trades", s.handleTrades)
+    s.mux.HandleFunc("/api/v1/bbo", s.handleBBO)
+    s.mux.HandleFunc("/api/v1/ticker", s.handleTicker)
+    s.mux.HandleFunc("/api/v1/candles", s.handleCandles)
+    s.mux.HandleFunc("/api/v1/positions", s.handlePositions)
+    s.mux.HandleFunc("/api/v1/accounts/", s.handleAccountFees)
+    s.mux.HandleFunc("/api/v1/stats", s.handleStats)
+    s.mux.HandleFunc("/api/v1/rejections", s.handleRejections)
+    s.mux.HandleFunc("/api/v1/symbols", s.handleSymbols)
+    s.mux.HandleFunc("/api/v1/symbols/", s.handleSymbolSession)
+    s.mux.HandleFunc("/ws/orderbook", s.handleOrderBookWS)
+    s.mux.HandleFunc("/ws/trades", s.handleTradesWS)
+    s.mux.HandleFunc("/ws/session", s.handleSessionWS)
+    // admin
+    s.mux.HandleFunc("/admin/snapshot", s.handleSnapshot)
+    s.mux.HandleFunc("/admin/auction/start", s.handleAuctionStart)
+    s.mux.HandleFunc("/admin/auction/run", s.handleAuctionRun)
+    s.mux.HandleFunc("/admin/halt", s.handleHalt)
+    s.mux.HandleFunc("/admin/resume", s.handleResume)
+    s.mux.HandleFunc("/admin/killswitch", s.handleKillSwitch)
+    s.mux.HandleFunc("/admin/reset", s.handleReset)
+    s.mux.HandleFunc("/admin/roll-session", s.handleRollSession)
+    s.mux.HandleFunc("/admin/reference", s.handleReference)
+    s.mux.HandleFunc("/admin/trades/", s.handleTradeBust)
+    s.mux.Handle("/metrics", promhttp.HandlerFor(s.eng.Metrics(), promhttp.HandlerOpts{}))
+    // Probes; see health.go
+    s.mux.HandleFunc("/api/v1/health", s.handleReady)
+    s.mux.HandleFunc("/api/v1/ready", s.handleReady)
+    s.mux.HandleFunc("/api/v1/live", s.handleLive)
 }
 
 type createOrderRequest struct {
     ID       string `json:"id"`
     Symbol   string `json:"symbol"`
+    AccountID string `json:"account_id"`
     Side     string `json:"side"`
     Type     string `json:"type"`
-    Price    int64  `json:"price"`
-    Quantity int64  `json:"quantity"`
+    Price    priceInput `json:"price"` // Units, or a decimal string; see priceInput
+    Quantity decimalInput `json:"quantity"` // Number or decimal string
+    StopPrice priceInput `json:"stop_price"`
+    TimeInForce string `json:"time_in_force"`
+    ExpiresAt int64 `json:"expires_at"` // Unix milliseconds
+    DisplayQuantity decimalInput `json:"display_quantity"` // Iceberg slice size
+    PostOnly bool `json:"post_only"`
+    AllOrNone bool `json:"all_or_none"` // Rest until the whole quantity can trade at once
+    NoTradeThrough bool `json:"no_trade_through"` // Never trade worse than the reference quote (POST /admin/reference)
+    Hidden   bool `json:"hidden"` // Rest without appearing in market data
+    ProtectionPrice priceInput `json:"protection_price"` // Worst acceptable price for MARKET/STOP/MARKET_IF_TOUCHED
+    Notional int64 `json:"notional"` // Cash to spend on a MARKET order instead of a quantity
+    PegReference string `json:"peg_reference"` // BID, ASK or MID for a PEGGED order
+    PegOffset priceInput `json:"peg_offset"` // Added to the pegged quote
+    ClientMetadata map[string]string `json:"client_metadata"` // Echoed back, never interpreted
 }
 
 func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
     switch r.Method {
+    case http.MethodGet:
+        s.listOrders(w, r)
     case http.MethodPost:
         s.createOrder(w, r)
+    case http.MethodDelete:
+        s.cancelAll(w, r)
     default:
         s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
     }
@@ -72,74 +153,332 @@ func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
         s.writeErrorPlain(w, http.StatusBadRequest, "Invalid json")
         return
     }
-    if req.Symbol == "" {
-        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid order: symbol is required")
+    order, sf, err := s.newOrder(r, req)
+    if err != nil {
+        s.recordInvalid(req, err)
+        s.writeInvalidOrder(w, err)
         return
     }
-    if req.Quantity <= 0 {
-        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid order: quantity must be positive")
+    sess, err := s.orderSession(r)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
         return
     }
-    otype, err := parseOrderType(req.Type)
+    resp, err := s.eng.SubmitOrderContext(r.Context(), order)
     if err != nil {
-        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid order: "+err.Error())
+        s.writeEngineError(w, err)
         return
     }
+    if !resp.Resubmitted {
+        s.settleSession(sess, order.ID)
+    }
+    status, body := orderResult(order, resp, sf)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    _ = json.NewEncoder(w).Encode(body)
+}
+
+// engineErrorStatus maps an engine refusal to its HTTP status: 404 for an
+// unknown order or trade, 409 when the symbol is halted, the order ID is
+// reused with different terms or the book is in the wrong phase, 500 when
+// the WAL fails, otherwise 400.
+func engineErrorStatus(err error) int {
+    switch {
+    case errors.Is(err, engine.ErrOrderNotFound), errors.Is(err, engine.ErrTradeNotFound):
+        return http.StatusNotFound
+    case errors.Is(err, engine.ErrSymbolHalted), errors.Is(err, engine.ErrMarketClosed), errors.Is(err, engine.ErrDuplicateOrderID), errors.Is(err, engine.ErrInvalidState):
+        return http.StatusConflict
+    case errors.Is(err, engine.ErrWALFailure):
+        return http.StatusInternalServerError
+    }
+    return http.StatusBadRequest
+}
+
+// writeEngineError writes an engine refusal with its status and reason code.
+func (s *Server) writeEngineError(w http.ResponseWriter, err error) {
+    code := engine.Code(err)
+    if code == "" {
+        code = engine.CodeInvalidOrder
+    }
+    message := err.Error()
+    if code == engine.CodeOrderNotFound {
+        message = "Order not found"
+    }
+    s.writeError(w, engineErrorStatus(err), code, message)
+}
+
+// newOrder validates a create request and builds the engine order for it.
+// Every invalid field is reported, as an orderErrors, not just the first.
+func (s *Server) newOrder(r *http.Request, req createOrderRequest) (*engine.Order, symbolFormat, error) {
+    var errs orderErrors
+    if req.Symbol == "" {
+        errs.add("symbol", "symbol is required")
+    }
+    sf := s.symbolFormat(r, req.Symbol)
+    quantity, err := sf.parse(req.Quantity)
+    quantityOK := err == nil
+    if err != nil {
+        errs.add("quantity", err.Error())
+    }
+    if req.Notional != 0 && req.Quantity != "" {
+        errs.add("notional", "quantity and notional are mutually exclusive")
+    } else if quantityOK && quantity <= 0 && req.Notional == 0 {
+        errs.add("quantity", "quantity must be positive")
+        quantityOK = false // Nothing to hold display_quantity against
+    }
+    price, err := sf.parsePrice(req.Price)
+    if err != nil {
+        errs.add("price", err.Error())
+    }
+    stopPrice, err := sf.parsePrice(req.StopPrice)
+    if err != nil {
+        errs.add("stop_price", "stop_price: "+err.Error())
+    }
+    protectionPrice, err := sf.parsePrice(req.ProtectionPrice)
+    if err != nil {
+        errs.add("protection_price", "protection_price: "+err.Error())
+    }
+    pegOffset, err := sf.parsePrice(req.PegOffset)
+    if err != nil {
+        errs.add("peg_offset", "peg_offset: "+err.Error())
+    }
+    displayQuantity, err := sf.parse(req.DisplayQuantity)
+    if err != nil {
+        errs.add("display_quantity", "display_quantity: "+err.Error())
+    } else if quantityOK && (displayQuantity < 0 || displayQuantity > quantity) {
+        errs.add("display_quantity", "display_quantity must be between 0 and quantity")
+    }
     side, err := parseSide(req.Side)
     if err != nil {
-        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid order: "+err.Error())
-        return
+        errs.add("side", err.Error())
     }
-    if otype == engine.Limit && req.Price <= 0 {
-        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid order: price must be > 0 for limit orders")
-        return
+    expireDate, gtd, err := parseGTD(req.TimeInForce)
+    tif := engine.GTC
+    if err != nil {
+        errs.add("time_in_force", err.Error())
+    } else if !gtd {
+        if tif, err = parseTimeInForce(req.TimeInForce); err != nil {
+            errs.add("time_in_force", err.Error())
+        }
+    }
+    if gtd && expireDate == "" && req.ExpiresAt == 0 {
+        errs.add("expires_at", "GTD orders need expires_at or a date (GTD:YYYY-MM-DD)")
+    }
+    if expireDate != "" && req.ExpiresAt != 0 {
+        errs.add("expires_at", "give expires_at or a GTD date, not both")
+    }
+    if req.ExpiresAt < 0 || (req.ExpiresAt > 0 && req.ExpiresAt <= time.Now().UnixNano()/1_000_000) {
+        errs.add("expires_at", "expires_at must be in the future")
+    }
+    if req.ExpiresAt > 0 && tif == engine.Day {
+        errs.add("expires_at", "DAY orders expire at the session close; omit expires_at")
+    }
+    if req.Notional < 0 {
+        errs.add("notional", "notional must be positive")
+    }
+    if req.Hidden && req.DisplayQuantity != "" {
+        errs.add("hidden", "hidden and display_quantity are mutually exclusive")
     }
-    // Always generate a new ID server side
-    id := uuid.New().String()
-    order := engine.NewOrder(id, req.Symbol, side, otype, req.Price, req.Quantity)
-    resp, err := s.eng.SubmitOrder(order)
+    if protectionPrice < 0 {
+        errs.add("protection_price", "protection_price must not be negative")
+    }
+    pegReference := engine.PegReference(strings.ToUpper(req.PegReference))
+    if pegReference != "" && pegReference != engine.PegBid && pegReference != engine.PegAsk && pegReference != engine.PegMid {
+        errs.add("peg_reference", "invalid peg_reference; must be BID, ASK or MID")
+    }
+
+    // The rest depend on the order type, so are only checked once it is known
+    otype, err := parseOrderType(req.Type)
     if err != nil {
-        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
-        return
+        errs.add("type", err.Error())
+        return nil, symbolFormat{}, errs
     }
-    w.Header().Set("Content-Type", "application/json")
+    // Spread symbols may trade at zero or below; there an omitted price is 0
+    if !s.eng.GetSymbolConfig(req.Symbol).AllowNegativePrice {
+        if (otype == engine.Limit || otype == engine.StopLimit || otype == engine.LimitIfTouched) && price <= 0 {
+            errs.add("price", "price must be > 0 for limit orders")
+        }
+        if (otype == engine.Stop || otype == engine.StopLimit || otype == engine.MarketIfTouched || otype == engine.LimitIfTouched) && stopPrice <= 0 {
+            errs.add("stop_price", "stop_price must be > 0 for stop and if-touched orders")
+        }
+    }
+    if req.Notional > 0 && otype != engine.Market {
+        errs.add("notional", "notional requires a MARKET order")
+    }
+    if req.PostOnly && otype != engine.Limit {
+        errs.add("post_only", "post_only requires a LIMIT order")
+    }
+    if req.Hidden && otype != engine.Limit && otype != engine.StopLimit && otype != engine.LimitIfTouched {
+        errs.add("hidden", "hidden requires a LIMIT, STOP_LIMIT or LIMIT_IF_TOUCHED order")
+    }
+    if otype == engine.Pegged && req.PegReference == "" {
+        errs.add("peg_reference", "PEGGED orders need a peg_reference (BID, ASK or MID)")
+    }
+    if protectionPrice > 0 && otype != engine.Market && otype != engine.Stop && otype != engine.MarketIfTouched {
+        errs.add("protection_price", "protection_price requires a MARKET, STOP or MARKET_IF_TOUCHED order")
+    }
+    if len(errs) > 0 {
+        return nil, symbolFormat{}, errs
+    }
+    // A client-supplied ID is an idempotency key (see engine idempotency.go)
+    id := req.ID
+    if id == "" {
+        id = s.eng.NewOrderID()
+    }
+    order := engine.NewOrder(id, req.Symbol, side, otype, price, quantity)
+    order.TimeInForce = tif
+    order.StopPrice = stopPrice
+    order.ExpiresAt = req.ExpiresAt
+    order.ExpireDate = expireDate
+    order.PostOnly = req.PostOnly
+    order.AllOrNone = req.AllOrNone
+    order.NoTradeThrough = req.NoTradeThrough
+    order.Hidden = req.Hidden
+    order.ProtectionPrice = protectionPrice
+    order.AccountID = req.AccountID
+    order.Notional = req.Notional
+    order.PegReference = pegReference
+    order.PegOffset = pegOffset
+    order.ClientMetadata = req.ClientMetadata
+    if displayQuantity < quantity {
+        order.DisplayQuantity = displayQuantity
+    }
+    return order, sf, nil
+}
+
+// orderResult maps a processed order to its HTTP status and response body.
+func orderResult(order *engine.Order, resp engine.ProcessOrderResponse, sf symbolFormat) (int, map[string]interface{}) {
+    var status int
+    var body map[string]interface{}
     switch order.Status {
     case engine.StatusAccepted:
-        w.WriteHeader(http.StatusCreated)
-        _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        status = http.StatusCreated
+        body = map[string]interface{}{
             "order_id": order.ID,
             "status":   string(order.Status),
             "message":  "Order added to book",
-        })
-        return
+        }
+        if order.IsStop() {
+            body["message"] = "Stop order armed"
+        } else if order.IsIfTouched() {
+            body["message"] = "If-touched order armed"
+        } else if order.IsPegged() {
+            body["price"] = sf.price(order.Price)
+        }
     case engine.StatusPartialFill:
-        w.WriteHeader(http.StatusAccepted)
-        _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        status = http.StatusAccepted
+        body = map[string]interface{}{
             "order_id":           order.ID,
             "status":             string(order.Status),
-            "filled_quantity":    order.FilledQuantity,
-            "remaining_quantity": order.RemainingQuantity(),
-            "trades":             resp.Trades,
-        })
-        return
+            "filled_quantity":    sf.quantity(order.FilledQuantity),
+            "remaining_quantity": sf.quantity(order.RemainingQuantity()),
+            "trades":             sf.trades(resp.Trades),
+        }
+        if !resp.OrderInBook {
+            // IOC remainder was cancelled rather than rested
+            body["cancelled_quantity"] = sf.quantity(order.RemainingQuantity())
+        }
     case engine.StatusFilled:
-        w.WriteHeader(http.StatusOK)
-        _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        status = http.StatusOK
+        body = map[string]interface{}{
             "order_id":        order.ID,
             "status":          string(order.Status),
-            "filled_quantity": order.FilledQuantity,
-            "trades":          resp.Trades,
-        })
-        return
+            "filled_quantity": sf.quantity(order.FilledQuantity),
+            "trades":          sf.trades(resp.Trades),
+        }
+    case engine.StatusCancelled:
+        status = http.StatusOK
+        body = map[string]interface{}{
+            "order_id":           order.ID,
+            "status":             string(order.Status),
+            "filled_quantity":    sf.quantity(order.FilledQuantity),
+            "cancelled_quantity": sf.quantity(order.RemainingQuantity()),
+            "trades":             sf.trades(resp.Trades),
+            "message":            "Order cancelled: no immediate liquidity",
+        }
     default:
-        w.WriteHeader(http.StatusCreated)
-        _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        status = http.StatusCreated
+        body = map[string]interface{}{
             "order_id": order.ID,
             "status":   string(order.Status),
             "message":  "Order added to book",
-        })
+        }
+    }
+    if order.CancelReason != "" {
+        body["cancel_reason"] = order.CancelReason
+    }
+    if len(order.ClientMetadata) > 0 {
+        body["client_metadata"] = order.ClientMetadata
+    }
+    if order.IsNotional() {
+        body["notional"] = order.Notional
+        body["spent_notional"] = order.SpentNotional
+        body["leftover_notional"] = order.LeftoverNotional()
+    }
+    if len(resp.Trades) > 0 {
+        // VWAP of this order's fills, rounded half up (see engine VWAP)
+        body["average_price"] = sf.price(resp.VWAP())
+        body["total_value"] = sf.quantity(resp.TotalValue())
+    }
+    if len(resp.TriggeredTrades) > 0 {
+        body["triggered_trades"] = sf.trades(resp.TriggeredTrades)
+    }
+    return status, body
+}
+
+// metadataTag reads the tag query parameter that GET and DELETE
+// /api/v1/orders select orders by: "key:value" for orders whose
+// client_metadata sets key to value, or a bare value for orders carrying it
+// under any key. A tag selects on its own, so symbol and account filters
+// are refused alongside it. It writes the error and returns false if the
+// query is invalid.
+func (s *Server) metadataTag(w http.ResponseWriter, q url.Values) (engine.MetadataTag, bool) {
+    if q.Has("symbol") || q.Has("account") {
+        s.writeErrorPlain(w, http.StatusBadRequest, "tag cannot be combined with symbol or account")
+        return engine.MetadataTag{}, false
+    }
+    raw := q.Get("tag")
+    key, value, ok := strings.Cut(raw, ":")
+    if !ok {
+        key, value = "", raw
+    }
+    if value == "" || (ok && key == "") {
+        s.writeErrorPlain(w, http.StatusBadRequest, "invalid tag; must be key:value or a value")
+        return engine.MetadataTag{}, false
+    }
+    return engine.MetadataTag{Key: key, Value: value}, true
+}
+
+// cancelAll handles DELETE /api/v1/orders?symbol=&account=, cancelling every
+// open order that matches the (optional) filters, or with ?tag= instead,
+// every open order carrying a metadata tag.
+func (s *Server) cancelAll(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+    var cancelled []*engine.Order
+    var err error
+    if q.Has("tag") {
+        tag, ok := s.metadataTag(w, q)
+        if !ok {
+            return
+        }
+        cancelled, err = s.eng.CancelByTag(tag)
+    } else {
+        cancelled, err = s.eng.CancelAll(q.Get("symbol"), q.Get("account"))
+    }
+    if err != nil {
+        s.writeEngineError(w, err)
         return
     }
+    ids := make([]string, 0, len(cancelled))
+    for _, o := range cancelled {
+        ids = append(ids, o.ID)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "cancelled_order_ids": ids,
+        "count":               len(ids),
+    })
 }
 
 func (s *Server) handleOrderByID(w http.ResponseWriter, r *http.Request) {
@@ -149,17 +488,51 @@ func (s *Server) handleOrderByID(w http.ResponseWriter, r *http.Request) {
         s.writeErrorPlain(w, http.StatusBadRequest, "Invalid order: order id required")
         return
     }
+    if oldID, ok := strings.CutSuffix(id, "/replace"); ok {
+        if r.Method != http.MethodPost {
+            s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+            return
+        }
+        s.replaceOrder(w, r, oldID)
+        return
+    }
+    if orderID, ok := strings.CutSuffix(id, "/queue"); ok {
+        if r.Method != http.MethodGet {
+            s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+            return
+        }
+        s.queuePosition(w, r, orderID)
+        return
+    }
+    if orderID, ok := strings.CutSuffix(id, "/trades"); ok {
+        if r.Method != http.MethodGet {
+            s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+            return
+        }
+        s.orderTrades(w, r, orderID)
+        return
+    }
+    if orderID, ok := strings.CutSuffix(id, "/history"); ok {
+        if r.Method != http.MethodGet {
+            s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+            return
+        }
+        s.orderHistory(w, r, orderID)
+        return
+    }
     switch r.Method {
     case http.MethodGet:
         s.getOrder(w, r, id)
     case http.MethodDelete:
         s.cancelOrder(w, r, id)
+    case http.MethodPatch:
+        s.amendOrder(w, r, id)
     default:
         s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
     }
 }
 
-func (s *Server) getOrder(w http.ResponseWriter, _ *http.Request, id string) {
+func (s *Server) getOrder(w http.ResponseWriter, r *http.Request, id string) {
     o, err := s.eng.GetOrderStatus(id)
     if err != nil {
         w.Header().Set("Content-Type", "application/json")
@@ -169,78 +542,871 @@ func (s *Server) getOrder(w http.ResponseWriter, _ *http.Request, id string) {
     }
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
-    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+    _ = json.NewEncoder(w).Encode(orderJSON(o, s.symbolFormat(r, o.Symbol)))
+}
+
+// orderJSON renders an order's full state.
+func orderJSON(o *engine.Order, sf symbolFormat) map[string]interface{} {
+    return map[string]interface{}{
         "order_id":        o.ID,
         "symbol":          o.Symbol,
+        "account_id":      o.AccountID,
         "side":            string(o.Side),
         "type":            string(o.Type),
-        "price":           o.Price,
-        "quantity":        o.Quantity,
-        "filled_quantity": o.FilledQuantity,
+        "price":           sf.price(o.Price),
+        "stop_price":      sf.price(o.StopPrice),
+        "protection_price": sf.price(o.ProtectionPrice),
+        "quantity":        sf.quantity(o.Quantity),
+        "filled_quantity": sf.quantity(o.FilledQuantity),
+        "display_quantity": sf.quantity(o.DisplayQuantity),
         "status":          string(o.Status),
+        "time_in_force":   string(o.TimeInForce),
+        "post_only":       o.PostOnly,
+        "all_or_none":     o.AllOrNone,
+        "no_trade_through": o.NoTradeThrough,
+        "peg_reference":   o.PegReference,
+        "peg_offset":      sf.price(o.PegOffset),
+        "hidden":          o.Hidden,
+        "expires_at":      o.ExpiresAt,
+        "cancel_reason":   o.CancelReason,
+        "client_metadata": o.ClientMetadata,
         "timestamp":       o.Timestamp,
+        "sequence":        o.Sequence,
+    }
+}
+
+// defaultOrderListLimit is the page size when listOrders is not given a limit.
+const defaultOrderListLimit = 100
+
+// listOrders handles GET /api/v1/orders?account=&symbol=&limit=&offset=,
+// listing an account's live orders a page at a time, or with ?tag= instead
+// of account and symbol, the live orders carrying a metadata tag.
+func (s *Server) listOrders(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+    var tag engine.MetadataTag
+    if q.Has("tag") {
+        var ok bool
+        if tag, ok = s.metadataTag(w, q); !ok {
+            return
+        }
+    }
+    account := q.Get("account")
+    if account == "" && !q.Has("tag") {
+        s.writeErrorPlain(w, http.StatusBadRequest, "account or tag is required")
+        return
+    }
+    limit := defaultOrderListLimit
+    if v := q.Get("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n <= 0 {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid limit")
+            return
+        }
+        limit = n
+    }
+    offset := 0
+    if v := q.Get("offset"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid offset")
+            return
+        }
+        offset = n
+    }
+    var orders []*engine.Order
+    if q.Has("tag") {
+        orders = s.eng.GetOrdersByTag(tag)
+    } else {
+        orders = s.eng.GetOpenOrders(account, q.Get("symbol"))
+    }
+    total := len(orders)
+    start := min(offset, total)
+    page := orders[start : start+min(limit, total-start)]
+    out := make([]map[string]interface{}, len(page))
+    for i, o := range page {
+        out[i] = orderJSON(o, s.symbolFormat(r, o.Symbol))
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "orders": out,
+        "total":  total,
+        "limit":  limit,
+        "offset": offset,
     })
 }
 
-func (s *Server) cancelOrder(w http.ResponseWriter, _ *http.Request, id string) {
+// cancelOrder handles DELETE /api/v1/orders/{id}. The response reports how
+// much of the order executed before the cancel, so a partially filled order
+// can be reconciled from it alone.
+func (s *Server) cancelOrder(w http.ResponseWriter, r *http.Request, id string) {
     o, err := s.eng.CancelOrder(id)
     if err != nil {
-        w.Header().Set("Content-Type", "application/json")
-        if strings.Contains(err.Error(), "order not found") {
-            w.WriteHeader(http.StatusNotFound)
-            _ = json.NewEncoder(w).Encode(map[string]string{"error": "Order not found"})
+        if errors.Is(err, engine.ErrOrderNotOpen) {
+            s.writeError(w, http.StatusBadRequest, engine.CodeOrderNotOpen, "Cannot cancel: order already filled")
             return
         }
-        if strings.Contains(err.Error(), "cannot cancel order") {
-            w.WriteHeader(http.StatusBadRequest)
-            _ = json.NewEncoder(w).Encode(map[string]string{"error": "Cannot cancel: order already filled"})
-            return
+        s.writeEngineError(w, err)
+        return
+    }
+    sf := s.symbolFormat(r, o.Symbol)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "order_id":           o.ID,
+        "status":             string(o.Status),
+        "cancel_reason":      o.CancelReason,
+        "filled_quantity":    sf.quantity(o.FilledQuantity),
+        "remaining_quantity": sf.quantity(o.RemainingQuantity()),
+    })
+}
+
+// queuePosition handles GET /api/v1/orders/{id}/queue: the quantity queued
+// ahead of a resting order at its price level.
+func (s *Server) queuePosition(w http.ResponseWriter, r *http.Request, id string) {
+    ahead, total, err := s.eng.GetQueuePosition(id)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    o, err := s.eng.GetOrderStatus(id)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    sf := s.symbolFormat(r, o.Symbol)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "order_id":       o.ID,
+        "symbol":         o.Symbol,
+        "side":           string(o.Side),
+        "price":          sf.price(o.Price),
+        "quantity_ahead": sf.quantity(ahead),
+        "level_quantity": sf.quantity(total),
+    })
+}
+
+// orderHistory handles GET /api/v1/orders/{id}/history: every event that
+// touched the order, oldest first, followed by its current state.
+func (s *Server) orderHistory(w http.ResponseWriter, r *http.Request, id string) {
+    events, err := s.eng.GetOrderHistory(id)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    o, err := s.eng.GetOrderStatus(id)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    sf := s.symbolFormat(r, o.Symbol)
+    history := make([]map[string]interface{}, len(events))
+    for i, ev := range events {
+        entry := map[string]interface{}{
+            "seq":  ev.Sequence,
+            "type": string(ev.Type),
         }
-        w.WriteHeader(http.StatusBadRequest)
-        _ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+        if ev.Order != nil {
+            entry["order"] = orderJSON(ev.Order, sf)
+        }
+        if ev.Trade != nil {
+            entry["trade"] = sf.trades([]engine.Trade{*ev.Trade})[0]
+        }
+        history[i] = entry
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "order_id": o.ID,
+        "history":  history,
+        "order":    orderJSON(o, sf),
+    })
+}
+
+// orderTrades serves GET /api/v1/orders/{id}/trades: every trade the order
+// took part in, as aggressor or resting order, oldest first.
+func (s *Server) orderTrades(w http.ResponseWriter, r *http.Request, id string) {
+    o, err := s.eng.GetOrderStatus(id)
+    if err != nil {
+        s.writeEngineError(w, err)
         return
     }
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
     _ = json.NewEncoder(w).Encode(map[string]interface{}{
         "order_id": o.ID,
-        "status":   string(o.Status),
+        "trades":   s.symbolFormat(r, o.Symbol).trades(s.eng.GetTradesForOrder(id)),
+    })
+}
+
+type amendOrderRequest struct {
+    Price    priceInput   `json:"price"`
+    Quantity decimalInput `json:"quantity"` // Number or decimal string
+}
+
+func (s *Server) amendOrder(w http.ResponseWriter, r *http.Request, id string) {
+    var req amendOrderRequest
+    decoder := json.NewDecoder(r.Body)
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&req); err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid json")
+        return
+    }
+    existing, err := s.eng.GetOrderStatus(id)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusNotFound, "Order not found")
+        return
+    }
+    sf := s.symbolFormat(r, existing.Symbol)
+    quantity, err := sf.parse(req.Quantity)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid amendment: "+err.Error())
+        return
+    }
+    if quantity < 0 {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid amendment: quantity must not be negative")
+        return
+    }
+    price, err := sf.parsePrice(req.Price)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid amendment: "+err.Error())
+        return
+    }
+    if price < 0 && !s.eng.GetSymbolConfig(existing.Symbol).AllowNegativePrice {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid amendment: price must not be negative")
+        return
+    }
+    if price == 0 && quantity == 0 {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid amendment: price or quantity required")
+        return
+    }
+    o, resp, err := s.eng.AmendOrder(id, price, quantity)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "order_id":           o.ID,
+        "status":             string(o.Status),
+        "price":              sf.price(o.Price),
+        "quantity":           sf.quantity(o.Quantity),
+        "filled_quantity":    sf.quantity(o.FilledQuantity),
+        "remaining_quantity": sf.quantity(o.RemainingQuantity()),
+        "trades":             sf.trades(resp.Trades),
     })
 }
 
-// Unified handler for both /api/v1/orderbook and /api/v1/orderbook/{symbol}
+// replaceOrder handles POST /api/v1/orders/{id}/replace: the body is a new
+// order (symbol optional) that atomically takes the old order's place.
+func (s *Server) replaceOrder(w http.ResponseWriter, r *http.Request, id string) {
+    var req createOrderRequest
+    decoder := json.NewDecoder(r.Body)
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(&req); err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "Invalid json")
+        return
+    }
+    existing, err := s.eng.GetOrderStatus(id)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusNotFound, "Order not found")
+        return
+    }
+    if req.Symbol == "" {
+        req.Symbol = existing.Symbol
+    }
+    order, sf, err := s.newOrder(r, req)
+    if err != nil {
+        s.writeInvalidOrder(w, err)
+        return
+    }
+    sess, err := s.orderSession(r)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    cancelled, resp, err := s.eng.CancelReplace(id, order)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    s.settleSession(sess, order.ID)
+    status, body := orderResult(order, resp, sf)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "cancelled_order": map[string]interface{}{
+            "order_id":        cancelled.ID,
+            "status":          string(cancelled.Status),
+            "filled_quantity": sf.quantity(cancelled.FilledQuantity),
+        },
+        "new_order": body,
+    })
+}
+
+// Unified handler for both /api/v1/orderbook and /api/v1/orderbook/{symbol}.
+// A single symbol's book is sent in the compact binary encoding instead of
+// JSON when the client accepts application/octet-stream.
 func (s *Server) handleOrderBookGeneral(w http.ResponseWriter, r *http.Request) {
     var symbol string
     base := "/api/v1/orderbook/"
     if strings.HasPrefix(r.URL.Path, base) {
         symbol = strings.TrimPrefix(r.URL.Path, base)
+        if levelSymbol, ok := strings.CutSuffix(symbol, "/level"); ok && levelSymbol != "" {
+            switch r.Method {
+            case http.MethodGet:
+                s.levelOrders(w, r, levelSymbol)
+            case http.MethodDelete:
+                s.cancelLevel(w, r, levelSymbol)
+            default:
+                s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+            }
+            return
+        }
         if i := strings.Index(symbol, "/"); i != -1 {
             symbol = symbol[:i] // Defensive
         }
     } else {
         symbol = r.URL.Query().Get("symbol")
     }
-    if symbol == "" {
+    symbols := r.URL.Query().Get("symbols")
+    if symbol == "" && symbols == "" {
         s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
         return
     }
-    depth := 0
-    if v := r.URL.Query().Get("depth"); v != "" {
-        if n, err := strconv.Atoi(v); err == nil && n >= 0 {
-            depth = n
+    depth, err := s.bookDepth(r)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    var group int64
+    if v := r.URL.Query().Get("group"); v != "" {
+        if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+            group = n
         } else {
-            s.writeErrorPlain(w, http.StatusBadRequest, "invalid depth")
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid group")
+            return
+        }
+    }
+    if symbol == "" {
+        if group != 0 {
+            s.writeErrorPlain(w, http.StatusBadRequest, "group is only supported for a single symbol")
             return
         }
+        s.writeOrderBooks(w, r, symbols, depth)
+        return
+    }
+    var snap engine.OrderBookSnapshot
+    if group > 1 {
+        snap = s.eng.GetAggregatedSnapshot(symbol, depth, group)
+    } else {
+        // Dashboards poll this, so plain depth comes from the snapshot cache
+        snap = s.eng.GetCachedSnapshot(symbol, depth)
+    }
+    sf := s.symbolFormat(r, symbol)
+    timestamp := time.Now().UnixNano() / 1_000_000
+    if wantsCompactBook(r) {
+        w.Header().Set("Content-Type", "application/octet-stream")
+        w.WriteHeader(http.StatusOK)
+        _, _ = w.Write(engine.AppendCompactBook(nil, engine.CompactBook{
+            Symbol:           symbol,
+            PriceScale:       sf.priceScale,
+            QuantityDecimals: sf.quantityDecimals,
+            Timestamp:        timestamp,
+            Sequence:         snap.Sequence,
+            Checksum:         snap.Checksum,
+            Bids:             snap.Bids,
+            Asks:             snap.Asks,
+        }))
+        return
     }
-    bids, asks := s.eng.GetOrderBookSnapshot(symbol, depth)
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
     _ = json.NewEncoder(w).Encode(map[string]interface{}{
         "symbol":    symbol,
+        "timestamp": timestamp,
+        "bids":      sf.levels(snap.Bids),
+        "asks":      sf.levels(snap.Asks),
+        "sequence":  snap.Sequence,
+        "checksum":  snap.Checksum,
+
+        "total_bid_levels": snap.TotalBidLevels,
+        "total_ask_levels": snap.TotalAskLevels,
+        "total_bid_orders": snap.TotalBidOrders,
+        "total_ask_orders": snap.TotalAskOrders,
+    })
+}
+
+// levelQuery reads the side and price of a /api/v1/orderbook/{symbol}/level
+// request.
+func (s *Server) levelQuery(r *http.Request, sf symbolFormat) (engine.Side, int64, error) {
+    q := r.URL.Query()
+    side, err := parseSide(q.Get("side"))
+    if err != nil {
+        return "", 0, err
+    }
+    if q.Get("price") == "" {
+        return "", 0, errors.New("price is required")
+    }
+    price, err := sf.parsePrice(priceInput{text: q.Get("price"), decimal: sf.decimalPrices})
+    if err != nil {
+        return "", 0, err
+    }
+    return side, price, nil
+}
+
+// levelOrders handles GET /api/v1/orderbook/{symbol}/level?side=BUY&price=15050,
+// the orders resting at one price in queue order, each with its ID, quantity
+// and timestamp. Quantities are what depth shows (an iceberg's slice);
+// hidden orders are left out unless include_hidden=true, which also reports
+// every order's full remaining quantity. A price with no orders gives an
+// empty list.
+func (s *Server) levelOrders(w http.ResponseWriter, r *http.Request, symbol string) {
+    sf := s.symbolFormat(r, symbol)
+    side, price, err := s.levelQuery(r, sf)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    includeHidden := r.URL.Query().Get("include_hidden") == "true"
+    orders := s.eng.GetLevelOrders(symbol, side, price, includeHidden)
+    out := make([]map[string]interface{}, len(orders))
+    for i, o := range orders {
+        entry := map[string]interface{}{
+            "order_id":  o.ID,
+            "quantity":  sf.quantity(o.Displayed()),
+            "timestamp": o.Timestamp,
+            "sequence":  o.Sequence,
+        }
+        if includeHidden {
+            entry["quantity"] = sf.quantity(o.RemainingQuantity())
+            entry["displayed_quantity"] = sf.quantity(o.Displayed())
+            entry["hidden"] = o.Hidden
+        }
+        out[i] = entry
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol": symbol,
+        "side":   side,
+        "price":  sf.price(price),
+        "orders": out,
+        "count":  len(out),
+    })
+}
+
+// cancelLevel handles DELETE /api/v1/orderbook/{symbol}/level?side=SELL&price=15050,
+// cancelling every order resting at that price. An empty level cancels
+// nothing and still answers 200.
+func (s *Server) cancelLevel(w http.ResponseWriter, r *http.Request, symbol string) {
+    sf := s.symbolFormat(r, symbol)
+    side, price, err := s.levelQuery(r, sf)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    cancelled, err := s.eng.CancelLevel(symbol, side, price)
+    if err != nil {
+        s.writeEngineError(w, err)
+        return
+    }
+    ids := make([]string, 0, len(cancelled))
+    for _, o := range cancelled {
+        ids = append(ids, o.ID)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol":              symbol,
+        "side":                side,
+        "price":               sf.price(price),
+        "cancelled_order_ids": ids,
+        "count":               len(ids),
+    })
+}
+
+// writeOrderBooks answers /api/v1/orderbook?symbols=AAPL,MSFT with a map of
+// symbol to bids/asks. Blank and repeated entries in the list are ignored.
+func (s *Server) writeOrderBooks(w http.ResponseWriter, r *http.Request, list string, depth int) {
+    var symbols []string
+    for _, symbol := range strings.Split(list, ",") {
+        if symbol = strings.TrimSpace(symbol); symbol != "" {
+            symbols = append(symbols, symbol)
+        }
+    }
+    if len(symbols) == 0 {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    books := make(map[string]interface{}, len(symbols))
+    for symbol, snap := range s.eng.GetOrderBookSnapshots(symbols, depth) {
+        sf := s.symbolFormat(r, symbol)
+        books[symbol] = map[string]interface{}{
+            "bids":     sf.levels(snap.Bids),
+            "asks":     sf.levels(snap.Asks),
+            "checksum": snap.Checksum,
+        }
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
         "timestamp": time.Now().UnixNano() / 1_000_000,
-        "bids":      bids,
-        "asks":      asks,
+        "books":     books,
+    })
+}
+
+// handleBBO serves GET /api/v1/bbo?symbol=AAPL. Missing sides, and the spread
+// and mid that depend on them, are returned as null.
+func (s *Server) handleBBO(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    bid, ask, _ := s.eng.GetBBO(symbol)
+    sf := s.symbolFormat(r, symbol)
+    body := map[string]interface{}{
+        "symbol":    symbol,
+        "timestamp": time.Now().UnixNano() / 1_000_000,
+        "bid":       nil,
+        "ask":       nil,
+        "spread":    nil,
+        "mid":       nil,
+    }
+    if bid.Quantity > 0 {
+        body["bid"] = sf.level(bid)
+    }
+    if ask.Quantity > 0 {
+        body["ask"] = sf.level(ask)
+    }
+    if bid.Quantity > 0 && ask.Quantity > 0 {
+        body["spread"] = sf.price(ask.Price - bid.Price)
+        body["mid"] = sf.halfPrice(bid.Price + ask.Price)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(body)
+}
+
+// handleTicker serves GET /api/v1/ticker?symbol=AAPL with the last trade.
+func (s *Server) handleTicker(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    last, ok := s.eng.GetLastTrade(symbol)
+    if !ok {
+        s.writeErrorPlain(w, http.StatusNotFound, "no trades for symbol")
+        return
+    }
+    sf := s.symbolFormat(r, symbol)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol":        last.Symbol,
+        "last_price":    sf.price(last.Price),
+        "last_quantity": sf.quantity(last.Quantity),
+        "timestamp":     last.Timestamp,
+    })
+}
+
+// handleStats serves GET /api/v1/stats, the engine-wide totals. Volume is in
+// raw quantity units, since symbols may differ in QuantityDecimals.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    stats := s.eng.GlobalStats()
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbols":                  stats.Symbols,
+        "resting_orders":           stats.RestingOrders,
+        "trades_executed":          stats.TradesExecuted,
+        "volume":                   stats.Volume,
+        "uptime_seconds":           int64(stats.Uptime.Seconds()),
+        "oldest_order_age_seconds": stats.OldestOrderAge.Seconds(),
+    })
+}
+
+// defaultRejectionLimit is how many rejections handleRejections returns
+// when not given a limit.
+const defaultRejectionLimit = 50
+
+// handleRejections serves GET /api/v1/rejections?limit=50, the most recent
+// refused orders, newest first. Prices and quantities are raw units.
+func (s *Server) handleRejections(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    limit := defaultRejectionLimit
+    if v := r.URL.Query().Get("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n <= 0 {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid limit")
+            return
+        }
+        limit = n
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "rejections": s.eng.GetRejections(limit),
+    })
+}
+
+// recordInvalid logs a request refused before it became an engine order
+// in the engine's rejection log, with the terms as sent.
+func (s *Server) recordInvalid(req createOrderRequest, err error) {
+    s.eng.RecordRejection(&engine.Order{
+        ID:          req.ID,
+        Symbol:      req.Symbol,
+        AccountID:   req.AccountID,
+        Side:        engine.Side(strings.ToUpper(req.Side)),
+        Type:        engine.OrderType(strings.ToUpper(req.Type)),
+        TimeInForce: engine.TimeInForce(strings.ToUpper(req.TimeInForce)),
+    }, err)
+}
+
+// handleSymbols serves GET /api/v1/symbols, every listed symbol with its
+// configuration.
+func (s *Server) handleSymbols(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbols": s.eng.ListedSymbols(),
+    })
+}
+
+// handleSymbolSession serves GET /api/v1/symbols/{symbol}/session, the
+// symbol's trading hours, session state and book phase.
+func (s *Server) handleSymbolSession(w http.ResponseWriter, r *http.Request) {
+    rest := strings.TrimPrefix(r.URL.Path, "/api/v1/symbols/")
+    symbol, ok := strings.CutSuffix(rest, "/session")
+    if !ok || symbol == "" || strings.Contains(symbol, "/") {
+        s.writeErrorPlain(w, http.StatusNotFound, "not found")
+        return
+    }
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(s.eng.GetSessionStatus(symbol))
+}
+
+// handlePositions serves GET /api/v1/positions?account=X
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    account := r.URL.Query().Get("account")
+    if account == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "account is required")
+        return
+    }
+    positions := s.eng.GetPositions(account)
+    out := make([]positionJSON, len(positions))
+    for i, p := range positions {
+        out[i] = s.symbolFormat(r, p.Symbol).position(p)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "account_id": account,
+        "positions":  out,
+    })
+}
+
+// handleAccountFees serves GET /api/v1/accounts/{id}/fees: the account's
+// rolling volume and fee tier in each symbol it has traded.
+func (s *Server) handleAccountFees(w http.ResponseWriter, r *http.Request) {
+    rest := strings.TrimPrefix(r.URL.Path, "/api/v1/accounts/")
+    account, ok := strings.CutSuffix(rest, "/fees")
+    if !ok || account == "" || strings.Contains(account, "/") {
+        s.writeErrorPlain(w, http.StatusNotFound, "not found")
+        return
+    }
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    fees := s.eng.GetAccountFees(account)
+    out := make([]accountFeesJSON, len(fees))
+    for i, f := range fees {
+        out[i] = s.symbolFormat(r, f.Symbol).accountFees(f)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "account_id":  account,
+        "window_days": engine.VolumeWindowDays,
+        "fees":        out,
+    })
+}
+
+// handleCandles serves GET /api/v1/candles?symbol=AAPL&interval=1m&limit=60
+func (s *Server) handleCandles(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    q := r.URL.Query()
+    symbol := q.Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    interval := q.Get("interval")
+    if interval == "" {
+        interval = "1m"
+    }
+    limit := 0
+    if v := q.Get("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n <= 0 {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid limit")
+            return
+        }
+        limit = n
+    }
+    candles, err := s.eng.GetCandles(symbol, interval, limit)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol":   symbol,
+        "interval": interval,
+        "candles":  s.symbolFormat(r, symbol).candles(candles),
+    })
+}
+
+// handleBookStats serves GET /api/v1/orderbook/stats?symbol=AAPL
+func (s *Server) handleBookStats(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    stats := s.eng.GetBookStats(symbol)
+    sf := s.symbolFormat(r, symbol)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol":           stats.Symbol,
+        "best_bid":         sf.price(stats.BestBid),
+        "best_ask":         sf.price(stats.BestAsk),
+        "spread":           sf.price(stats.Spread),
+        "spread_bps":       stats.SpreadBps,
+        "total_bid_volume": sf.quantity(stats.TotalBidVolume),
+        "total_ask_volume": sf.quantity(stats.TotalAskVolume),
+        "imbalance":        stats.Imbalance,
+    })
+}
+
+// handleBookDiff serves GET /api/v1/orderbook/diff?symbol=AAPL&since_seq=N:
+// the levels changed since book sequence N (type "diff", quantity 0 for a
+// removed level), or the book itself (type "snapshot", capped like
+// /api/v1/orderbook) when N is too old to diff.
+func (s *Server) handleBookDiff(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    since, err := strconv.ParseUint(r.URL.Query().Get("since_seq"), 10, 64)
+    if err != nil {
+        s.writeErrorPlain(w, http.StatusBadRequest, "invalid since_seq")
+        return
+    }
+    sf := s.symbolFormat(r, symbol)
+    body := map[string]interface{}{"symbol": symbol, "since_seq": since}
+    if diff, ok := s.eng.GetDepthDiff(symbol, since); ok {
+        body["type"] = "diff"
+        body["sequence"] = diff.Sequence
+        body["bids"] = sf.levels(diff.Bids)
+        body["asks"] = sf.levels(diff.Asks)
+        body["checksum"] = diff.Checksum
+    } else {
+        snap := s.eng.GetBookSnapshot(symbol, s.maxDepth)
+        body["type"] = "snapshot"
+        body["sequence"] = snap.Sequence
+        body["bids"] = sf.levels(snap.Bids)
+        body["asks"] = sf.levels(snap.Asks)
+        body["checksum"] = snap.Checksum
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(body)
+}
+
+// handleTrades serves GET /api/v1/trades?symbol=AAPL&limit=100&since=<ts>
+func (s *Server) handleTrades(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        s.writeErrorPlain(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    q := r.URL.Query()
+    symbol := q.Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    limit := engine.DefaultTradeLimit
+    if v := q.Get("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n <= 0 {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid limit")
+            return
+        }
+        limit = n
+    }
+    var since int64
+    if v := q.Get("since"); v != "" {
+        n, err := strconv.ParseInt(v, 10, 64)
+        if err != nil || n < 0 {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid since")
+            return
+        }
+        since = n
+    }
+    trades := s.eng.GetTrades(symbol, since, limit)
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusOK)
+    _ = json.NewEncoder(w).Encode(map[string]interface{}{
+        "symbol": symbol,
+        "trades": s.symbolFormat(r, symbol).trades(trades),
     })
 }
 
@@ -261,13 +1427,69 @@ func parseOrderType(s string) (engine.OrderType, error) {
         return engine.Limit, nil
     case string(engine.Market):
         return engine.Market, nil
+    case string(engine.Stop):
+        return engine.Stop, nil
+    case string(engine.StopLimit):
+        return engine.StopLimit, nil
+    case string(engine.MarketIfTouched):
+        return engine.MarketIfTouched, nil
+    case string(engine.LimitIfTouched):
+        return engine.LimitIfTouched, nil
+    case string(engine.Pegged):
+        return engine.Pegged, nil
+    case string(engine.MarketToLimit):
+        return engine.MarketToLimit, nil
     default:
-        return "", errors.New("invalid type; must be LIMIT or MARKET")
+        return "", errors.New("invalid type; must be LIMIT, MARKET, STOP, STOP_LIMIT, MARKET_IF_TOUCHED, LIMIT_IF_TOUCHED, PEGGED or MARKET_TO_LIMIT")
     }
 }
+func parseTimeInForce(s string) (engine.TimeInForce, error) {
+    switch strings.ToUpper(strings.TrimSpace(s)) {
+    case "", string(engine.GTC):
+        return engine.GTC, nil
+    case string(engine.IOC):
+        return engine.IOC, nil
+    case string(engine.FOK):
+        return engine.FOK, nil
+    case string(engine.Day):
+        return engine.Day, nil
+    default:
+        return "", errors.New("invalid time_in_force; must be GTC, IOC, FOK, DAY, GTD or GTD:YYYY-MM-DD")
+    }
+}
+
+// parseGTD recognises a GTD time in force: "GTD", which needs expires_at, or
+// "GTD:YYYY-MM-DD", which expires at the symbol's session close on that date
+// (the engine converts it in the symbol's time zone). GTD orders are GTC
+// orders with an expiry.
+func parseGTD(s string) (date string, gtd bool, err error) {
+    s = strings.TrimSpace(s)
+    if len(s) < 3 || !strings.EqualFold(s[:3], "GTD") {
+        return "", false, nil
+    }
+    if len(s) == 3 {
+        return "", true, nil
+    }
+    date, ok := strings.CutPrefix(s[3:], ":")
+    if !ok {
+        return "", false, errors.New("invalid time_in_force; must be GTC, IOC, FOK, DAY, GTD or GTD:YYYY-MM-DD")
+    }
+    if _, err := time.Parse(time.DateOnly, date); err != nil {
+        return "", false, fmt.Errorf("invalid GTD date %q; must be an ISO-8601 date (YYYY-MM-DD)", date)
+    }
+    return date, true, nil
+}
+
 // helper for spec-style simple error bodies
 func (s *Server) writeErrorPlain(w http.ResponseWriter, status int, message string) {
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(status)
     _ = json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
+
+// writeError is writeErrorPlain plus a stable, machine-readable code.
+func (s *Server) writeError(w http.ResponseWriter, status int, code engine.ReasonCode, message string) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    _ = json.NewEncoder(w).Encode(map[string]string{"error": message, "code": string(code)})
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+    "errors"
+    "log/slog"
+    "net/http"
+    "strconv"
+    "sync"
+
+    "github.com/google/uuid"
+    "order-matching-engine/src/engine"
+)
+
+// SessionHeader tags an order request with a session opened on /ws/session.
+const SessionHeader = "X-Session-ID"
+
+var errUnknownSession = errors.New("unknown or closed session")
+
+// wsSession is one open /ws/session connection and the orders submitted
+// under it.
+type wsSession struct {
+    cancelOnDisconnect bool
+    closed             bool // Guarded by sessionRegistry.mu
+    orders             []string
+    pruneAt            int // Drop finished orders once len(orders) reaches this
+}
+
+const minSessionPrune = 1024
+
+// sessionRegistry tracks open sessions by ID.
+type sessionRegistry struct {
+    eng      *engine.MatchingEngine
+    mu       sync.Mutex
+    sessions map[string]*wsSession
+}
+
+func (sr *sessionRegistry) open(cancelOnDisconnect bool) string {
+    id := uuid.New().String()
+    sess := &wsSession{cancelOnDisconnect: cancelOnDisconnect, pruneAt: minSessionPrune}
+    sr.mu.Lock()
+    if sr.sessions == nil {
+        sr.sessions = make(map[string]*wsSession)
+    }
+    sr.sessions[id] = sess
+    sr.mu.Unlock()
+    return id
+}
+
+// close removes the session and returns the orders to cancel, if any.
+func (sr *sessionRegistry) close(id string) []string {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    sess := sr.sessions[id]
+    delete(sr.sessions, id)
+    sess.closed = true
+    if !sess.cancelOnDisconnect {
+        return nil
+    }
+    return sess.orders
+}
+
+// lookup returns the open session id.
+func (sr *sessionRegistry) lookup(id string) (*wsSession, error) {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    sess, ok := sr.sessions[id]
+    if !ok {
+        return nil, errUnknownSession
+    }
+    return sess, nil
+}
+
+// track records orderIDs, which the engine has just accepted, under sess.
+// It returns false if sess has closed since they were submitted: its
+// cleanup has already run, so the caller must cancel them itself.
+func (sr *sessionRegistry) track(sess *wsSession, orderIDs ...string) bool {
+    sr.mu.Lock()
+    defer sr.mu.Unlock()
+    if !sess.cancelOnDisconnect {
+        return true
+    }
+    if sess.closed {
+        return false
+    }
+    if len(sess.orders) >= sess.pruneAt {
+        sess.orders = sr.pruneFinished(sess.orders)
+        sess.pruneAt = max(2*len(sess.orders), minSessionPrune)
+    }
+    sess.orders = append(sess.orders, orderIDs...)
+    return true
+}
+
+// pruneFinished drops filled and cancelled orders, which a disconnect has
+// nothing left to cancel for.
+func (sr *sessionRegistry) pruneFinished(orderIDs []string) []string {
+    kept := orderIDs[:0]
+    for _, id := range orderIDs {
+        order, err := sr.eng.GetOrderStatus(id)
+        if err == nil && (order.Status == engine.StatusFilled || order.Status == engine.StatusCancelled) {
+            continue
+        }
+        kept = append(kept, id)
+    }
+    return kept
+}
+
+// orderSession returns the open session the request names, or nil when it
+// has no session header.
+func (s *Server) orderSession(r *http.Request) (*wsSession, error) {
+    id := r.Header.Get(SessionHeader)
+    if id == "" {
+        return nil, nil
+    }
+    return s.sessions.lookup(id)
+}
+
+// settleSession puts orders submitted under sess in it once they are known
+// to be the session's own: pass only the IDs the engine accepted as new
+// orders, never a rejection or an idempotent retry, whose ID may belong to
+// another client's order. If the session disconnected while they were being
+// submitted, they are cancelled now.
+func (s *Server) settleSession(sess *wsSession, orderIDs ...string) {
+    if sess == nil || len(orderIDs) == 0 || s.sessions.track(sess, orderIDs...) {
+        return
+    }
+    for _, id := range orderIDs {
+        _, _ = s.eng.CancelOrder(id)
+    }
+}
+
+type sessionMessage struct {
+    Type               string `json:"type"` // "session"
+    SessionID          string `json:"session_id"`
+    CancelOnDisconnect bool   `json:"cancel_on_disconnect"`
+}
+
+// handleSessionWS serves /ws/session?cancel_on_disconnect=true. The first
+// message carries a session ID; orders submitted with it in the
+// X-Session-ID header belong to the session. With cancel_on_disconnect set,
+// every one of them still open is cancelled when the connection closes.
+func (s *Server) handleSessionWS(w http.ResponseWriter, r *http.Request) {
+    cancelOnDisconnect := false
+    if v := r.URL.Query().Get("cancel_on_disconnect"); v != "" {
+        b, err := strconv.ParseBool(v)
+        if err != nil {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid cancel_on_disconnect")
+            return
+        }
+        cancelOnDisconnect = b
+    }
+    conn, err := upgrader.Upgrade(w, r, nil)
+    if err != nil {
+        return
+    }
+    defer conn.Close()
+
+    id := s.sessions.open(cancelOnDisconnect)
+    defer s.disconnectSession(id)
+    if err := writeWS(conn, sessionMessage{Type: "session", SessionID: id, CancelOnDisconnect: cancelOnDisconnect}); err != nil {
+        return
+    }
+    <-watchClose(conn)
+}
+
+func (s *Server) disconnectSession(id string) {
+    cancelled := 0
+    for _, orderID := range s.sessions.close(id) {
+        if _, err := s.eng.CancelOrder(orderID); err == nil {
+            cancelled++
+        }
+    }
+    if cancelled > 0 {
+        s.logger.Info("session disconnected", slog.String("session_id", id), slog.Int("cancelled", cancelled))
+    }
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strings"
+
+    "order-matching-engine/src/engine"
+)
+
+// fieldError is one problem with one field of an order request.
+type fieldError struct {
+    Field   string `json:"field"`
+    Message string `json:"message"`
+}
+
+// orderErrors is every problem newOrder found with an order request, so a
+// client can fix them all in one round trip. Its Error joins the messages
+// after "Invalid order: ", which for a single problem is the message the
+// API has always returned.
+type orderErrors []fieldError
+
+func (e *orderErrors) add(field, message string) {
+    *e = append(*e, fieldError{Field: field, Message: message})
+}
+
+func (e orderErrors) Error() string {
+    messages := make([]string, len(e))
+    for i, fe := range e {
+        messages[i] = fe.Message
+    }
+    return "Invalid order: " + strings.Join(messages, "; ")
+}
+
+// invalidOrderBody is the error body for an order request newOrder refused:
+// the usual error and code, plus a details entry per problem.
+func invalidOrderBody(err error) map[string]interface{} {
+    body := map[string]interface{}{"error": err.Error(), "code": string(engine.CodeInvalidOrder)}
+    var errs orderErrors
+    if errors.As(err, &errs) {
+        body["details"] = errs
+    }
+    return body
+}
+
+// writeInvalidOrder answers 400 with invalidOrderBody.
+func (s *Server) writeInvalidOrder(w http.ResponseWriter, err error) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusBadRequest)
+    _ = json.NewEncoder(w).Encode(invalidOrderBody(err))
+}
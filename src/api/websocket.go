@@ -0,0 +1,158 @@
+package api
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gorilla/websocket"
+    "order-matching-engine/src/engine"
+)
+
+const wsWriteTimeout = 5 * time.Second
+
+var upgrader = websocket.Upgrader{
+    ReadBufferSize:  1024,
+    WriteBufferSize: 4096,
+    CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+type depthMessage struct {
+    Type     string                        `json:"type"` // "snapshot" or "update"
+    Symbol   string                        `json:"symbol"`
+    Sequence uint64                        `json:"sequence"`
+    Bids     []levelJSON `json:"bids"`
+    Asks     []levelJSON `json:"asks"`
+    Checksum uint32      `json:"checksum"` // BookChecksum after this message
+    EventSeq uint64      `json:"event_seq,omitempty"` // Last engine event an update reflects
+}
+
+// handleOrderBookWS serves /ws/orderbook?symbol=AAPL&depth=10: an initial
+// snapshot followed by incremental level updates. Clients that fall behind
+// are disconnected and should reconnect for a fresh snapshot.
+func (s *Server) handleOrderBookWS(w http.ResponseWriter, r *http.Request) {
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    depth := 0
+    if v := r.URL.Query().Get("depth"); v != "" {
+        if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+            depth = n
+        } else {
+            s.writeErrorPlain(w, http.StatusBadRequest, "invalid depth")
+            return
+        }
+    }
+    conn, err := upgrader.Upgrade(w, r, nil)
+    if err != nil {
+        return // Upgrade already wrote the error response
+    }
+    defer conn.Close()
+
+    // Subscribe before taking the snapshot so no update can fall in between.
+    sub := s.eng.SubscribeDepth(symbol)
+    defer sub.Close()
+    snap := s.eng.GetBookSnapshot(symbol, depth)
+    seq := snap.Sequence
+    sf := s.symbolFormat(r, symbol)
+    if err := writeWS(conn, depthMessage{Type: "snapshot", Symbol: symbol, Sequence: seq, Bids: sf.levels(snap.Bids), Asks: sf.levels(snap.Asks), Checksum: snap.Checksum}); err != nil {
+        return
+    }
+
+    closed := watchClose(conn)
+    for {
+        select {
+        case update, ok := <-sub.C:
+            if !ok {
+                return // Dropped as a slow consumer
+            }
+            if update.Sequence <= seq {
+                continue // Already reflected in the snapshot
+            }
+            msg := depthMessage{Type: "update", Symbol: symbol, Sequence: update.Sequence, Bids: sf.levels(update.Bids), Asks: sf.levels(update.Asks), Checksum: update.Checksum, EventSeq: update.EventSeq}
+            if err := writeWS(conn, msg); err != nil {
+                return
+            }
+        case <-closed:
+            return
+        }
+    }
+}
+
+// handleTradesWS serves /ws/trades?symbol=AAPL: one JSON message per print,
+// or with fill_granularity=per_order one rolled-up execution per order event
+// (see engine executions.go).
+func (s *Server) handleTradesWS(w http.ResponseWriter, r *http.Request) {
+    symbol := r.URL.Query().Get("symbol")
+    if symbol == "" {
+        s.writeErrorPlain(w, http.StatusBadRequest, "symbol is required")
+        return
+    }
+    granularity := engine.FillGranularity(r.URL.Query().Get("fill_granularity"))
+    if granularity == "" {
+        granularity = engine.FillsPerTrade
+    }
+    if granularity != engine.FillsPerTrade && granularity != engine.FillsPerOrder {
+        s.writeErrorPlain(w, http.StatusBadRequest, "invalid fill_granularity; must be per_trade or per_order")
+        return
+    }
+    sf := s.symbolFormat(r, symbol)
+    // Subscribe before the handshake completes so a client that trades right
+    // after connecting never misses its own print.
+    if granularity == engine.FillsPerOrder {
+        sub := s.eng.SubscribeExecutions(symbol)
+        defer sub.Close()
+        streamWS(w, r, sub.C, sf.execution)
+        return
+    }
+    sub := s.eng.SubscribeTrades(symbol)
+    defer sub.Close()
+    streamWS(w, r, sub.C, sf.trade)
+}
+
+// streamWS upgrades the request and writes each message from c, formatted,
+// until c closes (the subscriber was dropped as a slow consumer) or the
+// client goes away.
+func streamWS[T, J any](w http.ResponseWriter, r *http.Request, c <-chan T, format func(T) J) {
+    conn, err := upgrader.Upgrade(w, r, nil)
+    if err != nil {
+        return
+    }
+    defer conn.Close()
+
+    closed := watchClose(conn)
+    for {
+        select {
+        case msg, ok := <-c:
+            if !ok {
+                return // Dropped as a slow consumer
+            }
+            if err := writeWS(conn, format(msg)); err != nil {
+                return
+            }
+        case <-closed:
+            return
+        }
+    }
+}
+
+func writeWS(conn *websocket.Conn, v interface{}) error {
+    _ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+    return conn.WriteJSON(v)
+}
+
+// watchClose drains client frames and signals once the connection goes away.
+func watchClose(conn *websocket.Conn) <-chan struct{} {
+    closed := make(chan struct{})
+    go func() {
+        defer close(closed)
+        for {
+            if _, _, err := conn.ReadMessage(); err != nil {
+                return
+            }
+        }
+    }()
+    return closed
+}
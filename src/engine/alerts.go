@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultImbalanceCooldown is how long a symbol stays quiet after an alert
+// when ImbalanceAlertConfig.Cooldown is zero.
+const defaultImbalanceCooldown = time.Minute
+
+// ImbalanceAlertConfig configures the book imbalance webhook. See
+// SetImbalanceAlerts.
+type ImbalanceAlertConfig struct {
+	WebhookURL string        // http or https URL that receives each alert as a JSON POST
+	Threshold  float64       // Alert when |BookStats.Imbalance| reaches this; in (0, 1]
+	Cooldown   time.Duration // Minimum time between alerts for one symbol; zero means one minute
+	Client     *http.Client  // Nil uses a client with a 5 second timeout
+}
+
+// ImbalanceAlert is the body POSTed to the webhook: the book's stats when
+// the threshold was crossed, the threshold itself and the engine clock's
+// time, in Unix milliseconds.
+type ImbalanceAlert struct {
+	BookStats
+	Threshold float64 `json:"threshold"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// imbalanceAlerts is the live alert configuration and, per symbol, when the
+// last alert went out.
+type imbalanceAlerts struct {
+	cfg  ImbalanceAlertConfig
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// SetImbalanceAlerts POSTs an ImbalanceAlert to cfg.WebhookURL whenever a
+// change to a book leaves its displayed volume one-sided past cfg.Threshold,
+// measured as BookStats.Imbalance, at most once per cfg.Cooldown per symbol.
+// Delivery happens on a goroutine of its own and is never retried; failures
+// are logged. Nothing is sent during WAL replay. An empty WebhookURL turns
+// alerts off.
+func (me *MatchingEngine) SetImbalanceAlerts(cfg ImbalanceAlertConfig) error {
+	if cfg.WebhookURL == "" {
+		me.alerts.Store(nil)
+		return nil
+	}
+	if u, err := url.Parse(cfg.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid imbalance webhook URL %q", cfg.WebhookURL)
+	}
+	if !(cfg.Threshold > 0 && cfg.Threshold <= 1) {
+		return fmt.Errorf("invalid imbalance threshold %v; must be in (0, 1]", cfg.Threshold)
+	}
+	if cfg.Cooldown < 0 {
+		return fmt.Errorf("invalid imbalance cooldown %v", cfg.Cooldown)
+	}
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = defaultImbalanceCooldown
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	me.alerts.Store(&imbalanceAlerts{cfg: cfg, last: make(map[string]time.Time)})
+	return nil
+}
+
+// checkImbalance sends an alert if book has become too one-sided and its
+// symbol is not cooling down. The cooldown is checked first so a quiet
+// symbol costs no more than a map lookup. The book lock is held.
+func (me *MatchingEngine) checkImbalance(book *OrderBook) {
+	a := me.alerts.Load()
+	if a == nil || me.replaying.Load() {
+		return
+	}
+	now := me.now()
+	a.mu.Lock()
+	last, alerted := a.last[book.symbol]
+	a.mu.Unlock()
+	if alerted && now.Sub(last) < a.cfg.Cooldown {
+		return
+	}
+
+	stats := book.stats()
+	if math.Abs(stats.Imbalance) < a.cfg.Threshold {
+		return
+	}
+	a.mu.Lock()
+	a.last[book.symbol] = now
+	a.mu.Unlock()
+
+	alert := ImbalanceAlert{BookStats: stats, Threshold: a.cfg.Threshold, Timestamp: now.UnixMilli()}
+	go a.send(alert, me.logger)
+}
+
+// send POSTs alert to the webhook, logging anything but a 2xx reply.
+func (a *imbalanceAlerts) send(alert ImbalanceAlert, logger *slog.Logger) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		logger.Warn("imbalance alert not sent", "symbol", alert.Symbol, "error", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, a.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		logger.Warn("imbalance alert not sent", "symbol", alert.Symbol, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.cfg.Client.Do(req)
+	if err != nil {
+		logger.Warn("imbalance alert not sent", "symbol", alert.Symbol, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		logger.Warn("imbalance alert refused", "symbol", alert.Symbol, "status", resp.StatusCode)
+	}
+}
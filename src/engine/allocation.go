@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"iter"
+	"slices"
+)
+
+// --- Allocation Strategies ---
+//
+// How an aggressor's quantity is shared among the orders resting at one
+// price level is pluggable. Each MatchingAlgorithm names an allocator, and
+// matchLevel and the fillable scan ask the symbol's allocator for every
+// level they reach; the walk across levels, price limits, protection and
+// bands stay the same for all of them. A new algorithm needs an allocator
+// and an entry in allocators.
+
+// allocator shares an aggressor among the resting orders of a price level.
+type allocator interface {
+	// match fills order against level until either side is exhausted or
+	// matching must stop, appending to trades and filledOrders.
+	match(ob *OrderBook, order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order)
+
+	// fillable counts how much of want units of order the level could fill,
+	// in the order match would reach its resting orders, and reports whether
+	// matching would stop at the level (it reaches the order's own desk).
+	fillable(ob *OrderBook, order *Order, level *PriceLevel, want int64) (int64, bool)
+}
+
+// allocators maps each supported MatchingAlgorithm to its allocator.
+var allocators = map[MatchingAlgorithm]allocator{
+	FIFO:         fifoAllocator{},
+	ProRata:      proRataAllocator{},
+	SizePriority: sizePriorityAllocator{},
+}
+
+// allocator returns the symbol's allocator; FIFO unless configured.
+func (c SymbolConfig) allocator() allocator {
+	if a, ok := allocators[c.MatchingAlgorithm]; ok {
+		return a
+	}
+	return fifoAllocator{}
+}
+
+// fifoAllocator fills the oldest order first.
+type fifoAllocator struct{}
+
+func (fifoAllocator) match(ob *OrderBook, order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	return ob.matchLevelFIFO(order, level, trades, filledOrders)
+}
+
+func (fifoAllocator) fillable(ob *OrderBook, order *Order, level *PriceLevel, want int64) (int64, bool) {
+	return ob.countFillable(order, level.queue(), want)
+}
+
+// proRataAllocator splits the aggressor by displayed size; see prorata.go.
+type proRataAllocator struct{}
+
+func (proRataAllocator) match(ob *OrderBook, order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	return ob.matchLevelProRata(order, level, trades, filledOrders)
+}
+
+func (proRataAllocator) fillable(ob *OrderBook, order *Order, level *PriceLevel, want int64) (int64, bool) {
+	if ob.levelHasSelfMatch(order, level) {
+		return 0, true // See selfmatch.go
+	}
+	return ob.countFillable(order, level.queue(), want)
+}
+
+// sizePriorityAllocator fills the largest order first; see sizepriority.go.
+type sizePriorityAllocator struct{}
+
+func (sizePriorityAllocator) match(ob *OrderBook, order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	return ob.matchLevelSizePriority(order, level, trades, filledOrders)
+}
+
+func (sizePriorityAllocator) fillable(ob *OrderBook, order *Order, level *PriceLevel, want int64) (int64, bool) {
+	orders := slices.Collect(level.queue())
+	slices.SortStableFunc(orders, bySize)
+	return ob.countFillable(order, slices.Values(orders), want)
+}
+
+// queue yields the level's orders in time priority.
+func (pl *PriceLevel) queue() iter.Seq[*Order] {
+	return func(yield func(*Order) bool) {
+		for e := pl.Orders.Front(); e != nil; e = e.Next() {
+			if !yield(e.Value.(*Order)) {
+				return
+			}
+		}
+	}
+}
+
+// countFillable adds up the remaining quantity of resting orders, in the
+// given order, until it reaches want. Resting AON orders the aggressor
+// would pass over do not count, and counting stops, reporting true, at an
+// order of the aggressor's own desk.
+func (ob *OrderBook) countFillable(order *Order, resting iter.Seq[*Order], want int64) (int64, bool) {
+	var total int64
+	for r := range resting {
+		if passedOver(r, want-total) {
+			continue // See aon.go
+		}
+		if ob.selfMatch(order, r) {
+			return total, true // Matching would stop here
+		}
+		total += r.RemainingQuantity() // Check remaining
+		if total >= want {
+			break
+		}
+	}
+	return total, false
+}
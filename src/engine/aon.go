@@ -0,0 +1,86 @@
+package engine
+
+import "github.com/google/btree"
+
+// --- All-Or-None Orders ---
+//
+// An all-or-none (AON) limit order rests like any other but only ever trades
+// its whole remaining quantity in one aggressing event. Unlike FOK it is not
+// cancelled when it cannot fill on arrival; it waits in the book.
+//
+// Resting AON orders give up strict price-time priority. An incoming order
+// whose remaining quantity is smaller than a resting AON order's remaining
+// quantity passes over it and trades with the orders behind it, at that
+// price and at worse prices, so an AON order can watch later and worse
+// priced orders fill first. It keeps its place in the queue for the next
+// aggressor large enough to take it all. Under pro-rata matching AON orders
+// are left out of the allocation unless the aggressor takes the level's
+// whole quantity, in which case the level is swept in time priority.
+//
+// An incoming AON order only matches when the book, at or better than its
+// limit, can fill all of it (resting AON orders it could not fill completely
+// do not count). Otherwise it rests untouched, even if it crosses the book,
+// and the book stays crossed until an aggressor on the other side fills it
+// or it is cancelled. AON orders sit out call auctions.
+//
+// AON orders must be LIMIT orders that can rest (not IOC or FOK; FOK is the
+// immediate form) and cannot be icebergs.
+
+// checkAllOrNone rejects AON flags on orders that cannot honour them.
+func checkAllOrNone(order *Order) error {
+	if !order.AllOrNone {
+		return nil
+	}
+	switch {
+	case order.Type != Limit:
+		return rejectf(ErrInvalidOrder, "invalid order: all_or_none requires a LIMIT order")
+	case order.TimeInForce == IOC || order.TimeInForce == FOK:
+		return rejectf(ErrInvalidOrder, "invalid order: all_or_none orders rest; use FOK to fill completely or cancel")
+	case order.IsIceberg():
+		return rejectf(ErrInvalidOrder, "invalid order: all_or_none and display_quantity are mutually exclusive")
+	}
+	return nil
+}
+
+// fillsCompletely reports whether an incoming order could fill all of its
+// quantity against the book right now.
+func (ob *OrderBook) fillsCompletely(order *Order) bool {
+	_, ok := ob.checkFillable(order)
+	return ok
+}
+
+// passedOver reports whether an aggressor with incoming quantity left must
+// skip a resting order because it is AON and would not be filled completely.
+func passedOver(resting *Order, incoming int64) bool {
+	return resting.AllOrNone && resting.RemainingQuantity() > incoming
+}
+
+// firstFillable returns the first order in the level, in time priority,
+// that an aggressor with incoming quantity left may trade with, or nil if
+// every order there is passed over.
+func firstFillable(level *PriceLevel, incoming int64) *Order {
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		if resting := e.Value.(*Order); !passedOver(resting, incoming) {
+			return resting
+		}
+	}
+	return nil
+}
+
+// nextLevel returns the best level in tree, or with passed set, the next
+// level after it. A level is passed when only AON orders the aggressor
+// cannot fill are left in it.
+func nextLevel(tree *btree.BTreeG[*PriceLevel], passed *PriceLevel) (*PriceLevel, bool) {
+	if passed == nil {
+		return tree.Min()
+	}
+	var next *PriceLevel
+	tree.AscendGreaterOrEqual(passed, func(pl *PriceLevel) bool {
+		if pl.Price == passed.Price {
+			return true
+		}
+		next = pl
+		return false
+	})
+	return next, next != nil
+}
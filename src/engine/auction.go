@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/google/btree"
+)
+
+// --- Call Auction ---
+//
+// While a book is AuctionCollecting, limit orders rest without matching, so
+// the book may become crossed. RunAuction then picks one clearing price and
+// executes every crossing order at it:
+//
+//  1. The clearing price maximises executable volume, min(buy volume at or
+//     above the price, sell volume at or below it).
+//  2. Ties go to the price with the smallest imbalance |buy - sell|.
+//  3. Remaining ties go to the price closest to the last trade price (if the
+//     symbol has traded), then to the lower price.
+//
+// Orders execute in price-time priority on each side. Auction volume counts
+// each order's full remaining quantity, including iceberg reserve. AON
+// orders take no part (see aon.go) and keep resting.
+
+// TradingPhase is the matching mode of a symbol's book.
+type TradingPhase string
+
+const (
+	Continuous        TradingPhase = "CONTINUOUS"
+	AuctionCollecting TradingPhase = "AUCTION_COLLECTING"
+	Halted            TradingPhase = "HALTED"   // See halt.go
+	PreOpen           TradingPhase = "PRE_OPEN" // Collecting before the session open; see session.go
+)
+
+// StartAuction switches a symbol into AuctionCollecting. New limit orders rest
+// without matching until RunAuction is called.
+func (me *MatchingEngine) StartAuction(symbol string) error {
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if book.phase != Continuous {
+		return rejectf(ErrInvalidState, "cannot start auction for %s: book is %s", symbol, book.phase)
+	}
+	if err := me.logWAL(WALEntry{Op: WALAuctionStart, Symbol: symbol}); err != nil {
+		return err
+	}
+	book.phase = AuctionCollecting
+	return nil
+}
+
+// RunAuction uncrosses a collecting book at a single clearing price and
+// returns it to continuous trading. It returns the clearing price and the
+// auction trades; the price is 0 if nothing crossed. Stops triggered by the
+// auction fire afterwards as usual and are published with the trades.
+func (me *MatchingEngine) RunAuction(symbol string) (clearingPrice int64, trades []Trade) {
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if book.phase != AuctionCollecting {
+		return 0, nil
+	}
+	if err := me.logWAL(WALEntry{Op: WALAuctionRun, Symbol: symbol}); err != nil {
+		return 0, nil // Stay collecting; the caller can retry
+	}
+
+	clearingPrice, trades = me.reopen(book)
+	return clearingPrice, trades
+}
+
+// reopen uncrosses a book that has been collecting orders, returns it to
+// continuous trading and fires any stops the uncross triggered. The caller
+// must hold the book's lock.
+func (me *MatchingEngine) reopen(book *OrderBook) (int64, []Trade) {
+	book.config = me.GetSymbolConfig(book.symbol) // Fees and matching rules may have changed
+	clearingPrice, trades := book.uncross()
+	book.phase = Continuous
+	response := ProcessOrderResponse{Trades: trades}
+	response.TriggeredOrders, response.TriggeredTrades = book.triggerStops()
+	me.publishDepth(book)
+	me.publishExecutions(book.symbol, response)
+	return clearingPrice, trades
+}
+
+// GetTradingPhase returns the symbol's current trading phase.
+func (me *MatchingEngine) GetTradingPhase(symbol string) TradingPhase {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.phase
+}
+
+// collectAccepts rejects orders that make no sense without continuous
+// matching: market orders have no price to cross at, pegged orders no
+// quote to peg to, and IOC/FOK would be cancelled before the uncross.
+func collectAccepts(order *Order, phase TradingPhase) error {
+	if order.Type == Market || order.Type == MarketToLimit {
+		return rejectf(ErrPhaseRejected, "market orders are not accepted while the book is %s", phase)
+	}
+	if order.IsPegged() {
+		return rejectf(ErrPhaseRejected, "pegged orders are not accepted while the book is %s", phase)
+	}
+	if order.TimeInForce == IOC || order.TimeInForce == FOK {
+		return rejectf(ErrPhaseRejected, "%s orders are not accepted while the book is %s", order.TimeInForce, phase)
+	}
+	return nil
+}
+
+// collecting reports whether new orders are booked without matching, as
+// during an auction, a pre-open or a halt that queues orders.
+func (ob *OrderBook) collecting() bool {
+	return ob.phase == AuctionCollecting || ob.phase == PreOpen || (ob.phase == Halted && ob.haltMode == HaltQueue)
+}
+
+// collect books an order without matching it.
+func (ob *OrderBook) collect(order *Order) ProcessOrderResponse {
+	if order.IsConditional() {
+		ob.armStop(order)
+		return ProcessOrderResponse{}
+	}
+	ob.addOrder(order)
+	return ProcessOrderResponse{OrderInBook: true}
+}
+
+// clearingPrice finds the auction price using the rules above. ok is false if
+// no volume can execute.
+func (ob *OrderBook) clearingPrice() (price int64, ok bool) {
+	var bestVolume, bestImbalance, bestDistance int64
+	for _, candidate := range ob.limitPrices() {
+		var buyVolume, sellVolume int64
+		ob.bids.Ascend(func(pl *PriceLevel) bool {
+			if pl.Price < candidate {
+				return false
+			}
+			buyVolume += remainingAtLevel(pl)
+			return true
+		})
+		ob.asks.Ascend(func(pl *PriceLevel) bool {
+			if pl.Price > candidate {
+				return false
+			}
+			sellVolume += remainingAtLevel(pl)
+			return true
+		})
+		volume := min(buyVolume, sellVolume)
+		if volume == 0 {
+			continue
+		}
+		imbalance := buyVolume - sellVolume
+		if imbalance < 0 {
+			imbalance = -imbalance
+		}
+		var distance int64
+		if ob.hasTraded {
+			distance = candidate - ob.lastTradePrice
+			if distance < 0 {
+				distance = -distance
+			}
+		}
+		// Candidates ascend, so keeping the first of equals prefers lower prices
+		better := !ok ||
+			volume > bestVolume ||
+			(volume == bestVolume && imbalance < bestImbalance) ||
+			(volume == bestVolume && imbalance == bestImbalance && distance < bestDistance)
+		if better {
+			price, ok = candidate, true
+			bestVolume, bestImbalance, bestDistance = volume, imbalance, distance
+		}
+	}
+	return price, ok
+}
+
+// remainingAtLevel sums the full remaining quantity at a level, hidden iceberg
+// reserve included. AON orders sit out auctions and do not count.
+func remainingAtLevel(pl *PriceLevel) int64 {
+	var total int64
+	for e := pl.Orders.Front(); e != nil; e = e.Next() {
+		if order := e.Value.(*Order); !order.AllOrNone {
+			total += order.RemainingQuantity()
+		}
+	}
+	return total
+}
+
+// limitPrices returns every distinct resting price on both sides, ascending.
+func (ob *OrderBook) limitPrices() []int64 {
+	seen := make(map[int64]struct{}, len(ob.bidPriceMap)+len(ob.askPriceMap))
+	for price := range ob.bidPriceMap {
+		seen[price] = struct{}{}
+	}
+	for price := range ob.askPriceMap {
+		seen[price] = struct{}{}
+	}
+	prices := make([]int64, 0, len(seen))
+	for price := range seen {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+	return prices
+}
+
+// uncross executes all crossing orders at the clearing price.
+func (ob *OrderBook) uncross() (int64, []Trade) {
+	price, ok := ob.clearingPrice()
+	if !ok {
+		return 0, nil
+	}
+
+	crossing := func(tree *btree.BTreeG[*PriceLevel], crosses func(int64) bool) []*Order {
+		var orders []*Order
+		tree.Ascend(func(pl *PriceLevel) bool {
+			if !crosses(pl.Price) {
+				return false
+			}
+			for e := pl.Orders.Front(); e != nil; e = e.Next() {
+				if order := e.Value.(*Order); !order.AllOrNone {
+					orders = append(orders, order)
+				}
+			}
+			return true
+		})
+		return orders
+	}
+	buys := crossing(ob.bids, func(p int64) bool { return p >= price })
+	sells := crossing(ob.asks, func(p int64) bool { return p <= price })
+
+	var trades []Trade
+	for len(buys) > 0 && len(sells) > 0 {
+		buy, sell := buys[0], sells[0]
+		quantity := min(buy.RemainingQuantity(), sell.RemainingQuantity())
+		// The later of the two orders is the one that crossed the book
+		aggressor, resting := buy, sell
+		if sell.Sequence > buy.Sequence {
+			aggressor, resting = sell, buy
+		}
+		trades = append(trades, ob.createTrade(aggressor, resting, price, quantity))
+		ob.fillAtAuction(buy, quantity)
+		ob.fillAtAuction(sell, quantity)
+		if buy.RemainingQuantity() == 0 {
+			buys = buys[1:]
+		}
+		if sell.RemainingQuantity() == 0 {
+			sells = sells[1:]
+		}
+	}
+	return price, trades
+}
+
+// fillAtAuction applies an auction fill to a resting order in place.
+func (ob *OrderBook) fillAtAuction(order *Order, quantity int64) {
+	order.FilledQuantity += quantity
+	ob.touch(order)
+	if order.RemainingQuantity() == 0 {
+		order.Status = StatusFilled
+		ob.removeOrder(order.element)
+		return
+	}
+	order.Status = StatusPartialFill
+	if order.IsIceberg() {
+		order.VisibleQuantity -= min(quantity, order.VisibleQuantity)
+		if order.VisibleQuantity == 0 {
+			order.replenish()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+// --- Price Bands ---
+//
+// A symbol with PriceBandBps set only trades within that many basis points of
+// its reference price: the last trade price once the symbol has traded,
+// otherwise the configured ReferencePrice. With no reference yet, orders are
+// not banded. Limit orders outside the band are rejected; market orders stop
+// walking the book at the band edge and the remainder is cancelled. On
+// symbols with AllowNegativePrice the band is measured from |reference|, and
+// a reference of exactly zero leaves the book unbanded.
+
+// band returns the allowed price range, or (0, 0) if the book is unbanded.
+// A real band never has high == 0: it lies strictly on one side of zero.
+func (ob *OrderBook) band() (low, high int64) {
+	if ob.config.PriceBandBps <= 0 {
+		return 0, 0
+	}
+	reference := ob.config.ReferencePrice
+	if ob.hasTraded {
+		reference = ob.lastTradePrice
+	}
+	if reference == 0 {
+		return 0, 0
+	}
+	delta := abs(reference) * ob.config.PriceBandBps / 10_000
+	return reference - delta, reference + delta
+}
+
+// checkBand rejects a limit price outside the current band.
+func (ob *OrderBook) checkBand(price int64) error {
+	low, high := ob.band()
+	if high != 0 && (price < low || price > high) {
+		return rejectf(ErrOutsideBand, "invalid order: price %d outside price band [%d, %d]", price, low, high)
+	}
+	return nil
+}
+
+// withinBand reports whether a market order may trade at price given the band
+// fixed when it started matching. Limit orders were checked on entry.
+func withinBand(order *Order, price, low, high int64) bool {
+	if order.Type != Market || high == 0 {
+		return true
+	}
+	return price >= low && price <= high
+}
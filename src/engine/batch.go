@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// BatchResult is the outcome of one order in a batch submission. Err is set
+// if the order was rejected; otherwise Response holds its matching result.
+// Order (nil if rejected) is a copy taken right after the order was
+// processed, so later orders in the batch trading against it do not change
+// its result.
+type BatchResult struct {
+	Order    *Order
+	Response ProcessOrderResponse
+	Err      error
+}
+
+// SubmitBatch submits orders one after another through the normal matching
+// path, so later orders can trade against earlier ones in the same batch. A
+// rejected order does not stop the rest. Results are in input order.
+func (me *MatchingEngine) SubmitBatch(orders []*Order) []BatchResult {
+	results := make([]BatchResult, len(orders))
+	for i, order := range orders {
+		results[i] = me.submitForBatch(order)
+	}
+	return results
+}
+
+func (me *MatchingEngine) submitForBatch(order *Order) BatchResult {
+	var orderCopy Order
+	response, err := me.submit(context.Background(), order, func() { orderCopy = *order })
+	if err != nil {
+		return BatchResult{Err: err}
+	}
+	return BatchResult{Order: &orderCopy, Response: response}
+}
+
+// SubmitMany is SubmitBatch for throughput: it groups orders by symbol and
+// takes each symbol's lock once for its whole group instead of once per
+// order. Every order still goes through the normal submission path, and
+// orders for the same symbol are processed in input order; groups run in
+// order of each symbol's first appearance, so orders for different symbols
+// may be sequenced differently than in SubmitBatch. Other callers wait for
+// the whole group. Results are in input order, as BatchResults rather than
+// bare ProcessOrderResponses: a response has no way to say an order was
+// rejected, and a batch caller needs each rejection's error.
+func (me *MatchingEngine) SubmitMany(orders []*Order) []BatchResult {
+	results := make([]BatchResult, len(orders))
+	groups := make(map[string][]int)
+	var symbols []string
+	for i, order := range orders {
+		if _, ok := groups[order.Symbol]; !ok {
+			symbols = append(symbols, order.Symbol)
+		}
+		groups[order.Symbol] = append(groups[order.Symbol], i)
+	}
+	for _, symbol := range symbols {
+		me.submitGroup(symbol, orders, groups[symbol], results)
+	}
+	return results
+}
+
+// submitGroup submits orders[i] for each i in indexes, all for symbol, under
+// a single hold of the symbol's lock.
+func (me *MatchingEngine) submitGroup(symbol string, orders []*Order, indexes []int, results []BatchResult) {
+	// Validation needs no lock; do it first so that a group of invalid
+	// orders never creates a book
+	cfgs := make([]SymbolConfig, len(indexes))
+	var valid int
+	for j, i := range indexes {
+		order := orders[i]
+		cfg, err := me.validateOrder(order)
+		if err != nil {
+			me.recordRejection(order, rejectInvalid, err)
+			me.logSubmit(context.Background(), order, order.Type, ProcessOrderResponse{}, err, time.Now())
+			me.metrics.observeSubmit(order.Type, order.Side, time.Now())
+			results[i] = BatchResult{Err: err}
+			continue
+		}
+		cfgs[j] = cfg
+		valid++
+	}
+	if valid == 0 {
+		return
+	}
+
+	copies := make([]Order, len(indexes)) // One allocation for the group's result copies
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+	for j, i := range indexes {
+		if results[i].Err != nil {
+			continue
+		}
+		order := orders[i]
+		start := time.Now()
+		orderType := order.Type
+		orderCopy := &copies[j]
+		response, err := me.submitLocked(book, cfgs[j], order, func() { *orderCopy = *order })
+		me.logSubmit(context.Background(), order, orderType, response, err, start)
+		me.metrics.observeSubmit(order.Type, order.Side, start)
+		if err != nil {
+			results[i] = BatchResult{Err: err}
+			continue
+		}
+		results[i] = BatchResult{Order: orderCopy, Response: response}
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+// --- Trade Busts ---
+//
+// BustTrade cancels an executed trade after the fact, as an exchange does
+// with an erroneous print. The trade stays in the symbol's trade log marked
+// Busted, and both orders' FilledQuantity give back the busted quantity.
+// Busted orders do not re-enter the book: an order still resting keeps its
+// place and can trade the quantity again, one that had filled completely
+// is cancelled with cancel_reason TRADE_BUSTED, and one already cancelled
+// or expired stays that way. Both accounts' positions book the opposite
+// fill at the trade price and get the trade's fees back, so net quantity,
+// rolling volume and total P&L are restored, though P&L the trade realized
+// may now show in the cost basis instead. Each order gets a BUST event
+// carrying its new state and the trade, which its history records. What
+// the trade set off stands: stops it triggered, the ticker, candles and
+// trade counters are not revised. Only trades still in the trade log (see
+// tradeLogCapacity) can be busted. Busts are logged to the WAL with the
+// whole trade. Replay finds it again by its orders, price, quantity and
+// event sequence, which repeat even where trade IDs (UUIDs by default) do
+// not. A bust that finds no such trade fails Recover.
+
+// BustTrade busts the trade with the given ID. It returns ErrTradeNotFound
+// if no symbol's trade log holds it and ErrInvalidState if it is already
+// busted.
+func (me *MatchingEngine) BustTrade(tradeID string) error {
+	for _, sb := range me.allBooks() {
+		sb.lock.Lock()
+		if i := sb.book.tradeIndex(tradeID); i >= 0 {
+			err := me.bustTrade(sb.book, i)
+			sb.lock.Unlock()
+			return err
+		}
+		sb.lock.Unlock()
+	}
+	return rejectf(ErrTradeNotFound, "trade %s not found", tradeID)
+}
+
+// tradeIndex returns the index of a trade in the book's log, searching the
+// most recent first, or -1.
+func (ob *OrderBook) tradeIndex(tradeID string) int {
+	for i := len(ob.trades) - 1; i >= 0; i-- {
+		if ob.trades[i].TradeID == tradeID {
+			return i
+		}
+	}
+	return -1
+}
+
+// replayBust busts a trade logged by bustTrade, matched on everything but
+// its ID.
+func (me *MatchingEngine) replayBust(logged Trade) error {
+	book, lock := me.getBookAndLock(logged.Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+	for i := len(book.trades) - 1; i >= 0; i-- {
+		trade := book.trades[i]
+		if trade.EventSeq == logged.EventSeq && trade.AggressorOrderID == logged.AggressorOrderID &&
+			trade.RestingOrderID == logged.RestingOrderID && trade.Price == logged.Price && trade.Quantity == logged.Quantity {
+			return me.bustTrade(book, i)
+		}
+	}
+	return rejectf(ErrTradeNotFound, "trade %s not found", logged.TradeID)
+}
+
+// bustTrade busts the book's i'th logged trade. The book lock is held.
+func (me *MatchingEngine) bustTrade(book *OrderBook, i int) error {
+	trade := &book.trades[i]
+	if trade.Busted {
+		return rejectf(ErrInvalidState, "trade %s is already busted", trade.TradeID)
+	}
+	logged := *trade
+	if err := me.logWAL(WALEntry{Op: WALBust, Symbol: book.symbol, Trade: &logged}); err != nil {
+		return err
+	}
+	trade.Busted = true
+	fees := []int64{trade.AggressorFee, trade.RestingFee}
+	for j, id := range []string{trade.AggressorOrderID, trade.RestingOrderID} {
+		order, ok := me.storedOrder(id)
+		if !ok {
+			continue // Not kept, e.g. before a snapshot; nothing to restore
+		}
+		book.bustFill(order, *trade, fees[j])
+	}
+	me.publishDepth(book)
+	me.logger.Info("trade busted", "symbol", book.symbol, "trade_id", trade.TradeID,
+		"price", trade.Price, "quantity", trade.Quantity)
+	return nil
+}
+
+// bustFill takes a busted trade back out of one of its orders and the
+// order's position, then emits the order's BUST event.
+func (ob *OrderBook) bustFill(order *Order, trade Trade, fee int64) {
+	order.FilledQuantity -= trade.Quantity
+	switch {
+	case order.element != nil:
+		order.Status = StatusPartialFill
+		if order.FilledQuantity == 0 {
+			order.Status = StatusAccepted
+		}
+		ob.touch(order)
+	case order.Status == StatusFilled:
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonBusted
+	}
+
+	if position, ok := ob.positions[order.AccountID]; ok {
+		side := Buy
+		if order.Side == Buy {
+			side = Sell
+		}
+		position.apply(side, trade.Price, trade.Quantity)
+		position.charge(-fee)
+		position.removeVolume(trade.Timestamp, trade.Quantity)
+	}
+
+	if ob.events == nil {
+		return
+	}
+	orderCopy := *order
+	orderCopy.element = nil
+	ob.events.emit(OrderEvent{Type: EventBust, Symbol: ob.symbol, Order: &orderCopy, Trade: &trade})
+}
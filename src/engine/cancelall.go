@@ -0,0 +1,106 @@
+package engine
+
+// CancelAll cancels every open order for the given symbol and account. An
+// empty symbol matches every book and an empty accountID matches every
+// account. Each book is cancelled atomically under its own lock; books are
+// visited in symbol order, one at a time, so it cannot deadlock with other
+// multi-book operations. Filled or already cancelled orders are skipped.
+// It returns copies of the cancelled orders.
+func (me *MatchingEngine) CancelAll(symbol, accountID string) ([]*Order, error) {
+	var cancelled []*Order
+	for _, sb := range me.allBooks() {
+		if symbol != "" && sb.symbol != symbol {
+			continue
+		}
+		orders, err := me.cancelAllInBook(sb, accountID)
+		cancelled = append(cancelled, orders...)
+		if err != nil {
+			return cancelled, err
+		}
+	}
+	return cancelled, nil
+}
+
+func (me *MatchingEngine) cancelAllInBook(sb symbolBook, accountID string) ([]*Order, error) {
+	sb.lock.Lock()
+	defer sb.lock.Unlock()
+
+	book := sb.book
+	var cancelled []*Order
+	for _, order := range book.openOrders(accountID) {
+		if err := me.logWAL(WALEntry{Op: WALCancel, OrderID: order.ID, Reason: ReasonCancelAll}); err != nil {
+			me.publishDepth(book)
+			return cancelled, err
+		}
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonCancelAll
+		book.CancelOrder(order.ID)
+		book.emitOrder(EventCancelled, order)
+		orderCopy := *order
+		cancelled = append(cancelled, &orderCopy)
+	}
+	me.publishDepth(book)
+	return cancelled, nil
+}
+
+// openOrders lists the book's resting orders (bids, then asks, best price and
+// oldest first) followed by armed stops, optionally filtered by account.
+func (ob *OrderBook) openOrders(accountID string) []*Order {
+	var orders []*Order
+	collect := func(pl *PriceLevel) bool {
+		for e := pl.Orders.Front(); e != nil; e = e.Next() {
+			order := e.Value.(*Order)
+			if accountID == "" || order.AccountID == accountID {
+				orders = append(orders, order)
+			}
+		}
+		return true
+	}
+	ob.bids.Ascend(collect)
+	ob.asks.Ascend(collect)
+	for _, stop := range ob.stops {
+		if accountID == "" || stop.AccountID == accountID {
+			orders = append(orders, stop)
+		}
+	}
+	return orders
+}
+
+// CancelLevel cancels every order resting at one price on one side of a
+// symbol's book, hidden and pegged orders included, and so removes the level.
+// A level with no orders is not an error: it returns an empty list. Orders
+// are cancelled oldest first and returned as copies.
+func (me *MatchingEngine) CancelLevel(symbol string, side Side, price int64) ([]*Order, error) {
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	priceMap := book.askPriceMap
+	if side == Buy {
+		priceMap = book.bidPriceMap
+	}
+	level, ok := priceMap[price]
+	if !ok {
+		return nil, nil
+	}
+	var orders []*Order
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		orders = append(orders, e.Value.(*Order))
+	}
+
+	var cancelled []*Order
+	for _, order := range orders {
+		if err := me.logWAL(WALEntry{Op: WALCancel, OrderID: order.ID, Reason: ReasonCancelLevel}); err != nil {
+			me.publishDepth(book)
+			return cancelled, err
+		}
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonCancelLevel
+		book.CancelOrder(order.ID)
+		book.emitOrder(EventCancelled, order)
+		orderCopy := *order
+		cancelled = append(cancelled, &orderCopy)
+	}
+	me.publishDepth(book)
+	return cancelled, nil
+}
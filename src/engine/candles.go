@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// candleCapacity is how many candles each book keeps per interval.
+const candleCapacity = 1000
+
+// candleIntervals maps the supported interval names to their length in
+// milliseconds.
+var candleIntervals = map[string]int64{
+	"1s":  1_000,
+	"1m":  60_000,
+	"5m":  300_000,
+	"15m": 900_000,
+	"1h":  3_600_000,
+}
+
+// CandleIntervals returns the supported interval names, shortest first.
+func CandleIntervals() []string {
+	names := make([]string, 0, len(candleIntervals))
+	for name := range candleIntervals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return candleIntervals[names[i]] < candleIntervals[names[j]] })
+	return names
+}
+
+// Candle is an OHLCV bar for one interval. Start is the bucket's opening time
+// in Unix milliseconds.
+type Candle struct {
+	Start  int64 `json:"start"`
+	Open   int64 `json:"open"`
+	High   int64 `json:"high"`
+	Low    int64 `json:"low"`
+	Close  int64 `json:"close"`
+	Volume int64 `json:"volume"`
+	Trades int   `json:"trades"`
+}
+
+// candleSeries is a fixed-size ring of candles for one interval, oldest
+// candles overwritten first.
+type candleSeries struct {
+	interval int64
+	ring     []Candle
+	next     int // Slot the next new candle goes into
+	count    int
+}
+
+func newCandleSeries(interval int64) *candleSeries {
+	return &candleSeries{interval: interval, ring: make([]Candle, candleCapacity)}
+}
+
+// add folds a trade into the current candle, opening a new one when the trade
+// falls into a later bucket.
+func (cs *candleSeries) add(trade Trade) {
+	start := trade.Timestamp - trade.Timestamp%cs.interval
+	if cs.count > 0 {
+		last := &cs.ring[(cs.next+len(cs.ring)-1)%len(cs.ring)]
+		if last.Start == start {
+			if trade.Price > last.High {
+				last.High = trade.Price
+			}
+			if trade.Price < last.Low {
+				last.Low = trade.Price
+			}
+			last.Close = trade.Price
+			last.Volume += trade.Quantity
+			last.Trades++
+			return
+		}
+	}
+	cs.ring[cs.next] = Candle{
+		Start:  start,
+		Open:   trade.Price,
+		High:   trade.Price,
+		Low:    trade.Price,
+		Close:  trade.Price,
+		Volume: trade.Quantity,
+		Trades: 1,
+	}
+	cs.next = (cs.next + 1) % len(cs.ring)
+	if cs.count < len(cs.ring) {
+		cs.count++
+	}
+}
+
+// latest returns up to limit of the most recent candles, oldest first.
+func (cs *candleSeries) latest(limit int) []Candle {
+	if limit > cs.count {
+		limit = cs.count
+	}
+	out := make([]Candle, limit)
+	for i := 0; i < limit; i++ {
+		out[i] = cs.ring[(cs.next-limit+i+len(cs.ring))%len(cs.ring)]
+	}
+	return out
+}
+
+// updateCandles folds a trade into every interval. It is O(1) per interval,
+// so it runs inline with matching rather than on a separate consumer.
+func (ob *OrderBook) updateCandles(trade Trade) {
+	if ob.candles == nil {
+		ob.candles = make(map[string]*candleSeries, len(candleIntervals))
+		for name, interval := range candleIntervals {
+			ob.candles[name] = newCandleSeries(interval)
+		}
+	}
+	for _, series := range ob.candles {
+		series.add(trade)
+	}
+}
+
+// GetCandles returns up to limit of the most recent candles for a symbol and
+// interval (e.g. "1m"), oldest first. Buckets without trades are omitted.
+// limit <= 0 uses DefaultTradeLimit and is capped at candleCapacity.
+func (me *MatchingEngine) GetCandles(symbol, interval string, limit int) ([]Candle, error) {
+	if _, ok := candleIntervals[interval]; !ok {
+		return nil, fmt.Errorf("unsupported interval %q: use one of %s", interval, strings.Join(CandleIntervals(), ", "))
+	}
+	if limit <= 0 {
+		limit = DefaultTradeLimit
+	}
+	if limit > candleCapacity {
+		limit = candleCapacity
+	}
+
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	series, ok := book.candles[interval]
+	if !ok {
+		return []Candle{}, nil
+	}
+	return series.latest(limit), nil
+}
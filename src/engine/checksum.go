@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"hash/crc32"
+	"strconv"
+)
+
+// ChecksumDepth is how many levels per side BookChecksum covers.
+const ChecksumDepth = 25
+
+// BookChecksum returns a CRC32 (IEEE) of the symbol's top ChecksumDepth
+// displayed levels, so depth feed consumers can verify the book they have
+// rebuilt. The checksummed string interleaves the sides level by level,
+// best first:
+//
+//	bid1Price:bid1Qty:ask1Price:ask1Qty:bid2Price:bid2Qty:...
+//
+// Prices and quantities are the raw integers (quantities in units of
+// 10^-QuantityDecimals). Once one side runs out, the other side's levels
+// continue alone; an empty book checksums the empty string.
+func (me *MatchingEngine) BookChecksum(symbol string) uint32 {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.checksum()
+}
+
+// checksum implements BookChecksum. Caller holds the lock.
+func (ob *OrderBook) checksum() uint32 {
+	bids, asks := ob.snapshot(ChecksumDepth)
+	buf := make([]byte, 0, 64*(len(bids)+len(asks)))
+	appendLevel := func(level AggregatedPriceLevel) {
+		if len(buf) > 0 {
+			buf = append(buf, ':')
+		}
+		buf = strconv.AppendInt(buf, level.Price, 10)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, level.Quantity, 10)
+	}
+	for i := 0; i < max(len(bids), len(asks)); i++ {
+		if i < len(bids) {
+			appendLevel(bids[i])
+		}
+		if i < len(asks) {
+			appendLevel(asks[i])
+		}
+	}
+	return crc32.ChecksumIEEE(buf)
+}
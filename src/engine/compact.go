@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// --- Compact Book Snapshots ---
+//
+// A binary encoding of a depth snapshot for consumers that poll deep books
+// often; GET /api/v1/orderbook serves it for Accept:
+// application/octet-stream. Integers are big-endian:
+//
+//	magic             4 bytes "OMEB"
+//	version           uint8, 1
+//	price_scale       uint8
+//	quantity_decimals uint8
+//	timestamp         int64, Unix milliseconds
+//	sequence          uint64
+//	checksum          uint32
+//	symbol            uint16 length, then that many bytes
+//	bids              uint32 count, then count (price int64, quantity int64) pairs, best first
+//	asks              the same
+//
+// Prices and quantities are raw units, scaled by price_scale and
+// quantity_decimals as in SymbolConfig, so a level takes 16 bytes however
+// it would print as JSON. The snapshot's totals are not carried.
+// DecodeCompactBook reads it back.
+
+// compactBookMagic starts every compact book, and compactBookVersion follows
+// it.
+const (
+	compactBookMagic   = "OMEB"
+	compactBookVersion = 1
+)
+
+// CompactBook is one symbol's depth as carried by the compact encoding.
+type CompactBook struct {
+	Symbol           string
+	PriceScale       int
+	QuantityDecimals int
+	Timestamp        int64 // Unix milliseconds
+	Sequence         uint64
+	Checksum         uint32
+	Bids             []AggregatedPriceLevel // Best first
+	Asks             []AggregatedPriceLevel
+}
+
+// AppendCompactBook appends book's compact encoding to buf.
+func AppendCompactBook(buf []byte, book CompactBook) []byte {
+	buf = append(buf, compactBookMagic...)
+	buf = append(buf, compactBookVersion, byte(book.PriceScale), byte(book.QuantityDecimals))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(book.Timestamp))
+	buf = binary.BigEndian.AppendUint64(buf, book.Sequence)
+	buf = binary.BigEndian.AppendUint32(buf, book.Checksum)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(book.Symbol)))
+	buf = append(buf, book.Symbol...)
+	for _, levels := range [][]AggregatedPriceLevel{book.Bids, book.Asks} {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(levels)))
+		for _, level := range levels {
+			buf = binary.BigEndian.AppendUint64(buf, uint64(level.Price))
+			buf = binary.BigEndian.AppendUint64(buf, uint64(level.Quantity))
+		}
+	}
+	return buf
+}
+
+// DecodeCompactBook decodes a book written by AppendCompactBook. Trailing
+// bytes are an error.
+func DecodeCompactBook(data []byte) (CompactBook, error) {
+	d := compactDecoder{data: data}
+	if magic := d.next(len(compactBookMagic)); string(magic) != compactBookMagic {
+		return CompactBook{}, fmt.Errorf("invalid compact book: bad magic %q", magic)
+	}
+	header := d.next(3)
+	if d.err == nil && header[0] != compactBookVersion {
+		return CompactBook{}, fmt.Errorf("invalid compact book: unsupported version %d", header[0])
+	}
+	var book CompactBook
+	if d.err == nil {
+		book.PriceScale, book.QuantityDecimals = int(header[1]), int(header[2])
+	}
+	book.Timestamp = int64(d.uint64())
+	book.Sequence = d.uint64()
+	book.Checksum = d.uint32()
+	book.Symbol = string(d.next(int(d.uint16())))
+	book.Bids = d.levels()
+	book.Asks = d.levels()
+	if d.err == nil && len(d.data) > 0 {
+		d.err = fmt.Errorf("%d bytes after the asks", len(d.data))
+	}
+	if d.err != nil {
+		return CompactBook{}, fmt.Errorf("invalid compact book: %w", d.err)
+	}
+	return book, nil
+}
+
+// compactDecoder consumes a compact book; after the first short read every
+// read returns zero values and err says what ran out.
+type compactDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *compactDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = fmt.Errorf("truncated: %d bytes left, %d needed", len(d.data), n)
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *compactDecoder) uint16() uint16 {
+	if b := d.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *compactDecoder) uint32() uint32 {
+	if b := d.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *compactDecoder) uint64() uint64 {
+	if b := d.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// levels reads a count-prefixed side. The count is checked against the
+// bytes left before anything is allocated.
+func (d *compactDecoder) levels() []AggregatedPriceLevel {
+	n := int(d.uint32())
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	if len(d.data)/16 < n {
+		d.err = fmt.Errorf("truncated: %d levels declared, room for %d", n, len(d.data)/16)
+		return nil
+	}
+	levels := make([]AggregatedPriceLevel, n)
+	for i := range levels {
+		levels[i] = AggregatedPriceLevel{Price: int64(d.uint64()), Quantity: int64(d.uint64())}
+	}
+	return levels
+}
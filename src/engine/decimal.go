@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Quantities are fixed-point: an int64 count of the smallest tradable unit.
+// A symbol with QuantityDecimals = 2 stores 1.5 shares as 150, so every
+// matching and fill computation stays exact integer arithmetic.
+
+// MaxQuantityDecimals bounds the scale so quantities cannot overflow int64.
+const MaxQuantityDecimals = 8
+
+// Prices are fixed-point the same way, in units of 10^-PriceScale; see
+// SymbolConfig.PriceScale. MaxPriceScale bounds it likewise.
+const MaxPriceScale = 8
+
+var pow10 = [...]int64{1, 10, 100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000}
+
+// ParseQuantity converts a decimal string such as "1.5" into fixed-point
+// units with the given number of decimals. More fractional digits than the
+// scale allows is an error rather than a silent rounding.
+func ParseQuantity(s string, decimals int) (int64, error) {
+	if decimals < 0 || decimals > MaxQuantityDecimals {
+		return 0, fmt.Errorf("unsupported quantity decimals %d", decimals)
+	}
+	return parseFixed(s, decimals, "quantity")
+}
+
+// parseFixed is ParseQuantity and ParsePrice; what names the value in errors.
+func parseFixed(s string, decimals int, what string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty %s", what)
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && frac == "" || hasPoint && frac == "" {
+		return 0, fmt.Errorf("invalid %s %q", what, s)
+	}
+	if len(frac) > decimals {
+		return 0, fmt.Errorf("%s %q has more than %d decimal places", what, s, decimals)
+	}
+	frac += strings.Repeat("0", decimals-len(frac))
+
+	var units int64
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid %s %q", what, s)
+		}
+		if units > (1<<63-1-int64(c-'0'))/10 {
+			return 0, fmt.Errorf("%s %q is too large", what, s)
+		}
+		units = units*10 + int64(c-'0')
+	}
+	if negative {
+		units = -units
+	}
+	return units, nil
+}
+
+// FormatQuantity renders fixed-point units as a decimal string without
+// trailing zeros, e.g. 150 with 2 decimals is "1.5".
+func FormatQuantity(units int64, decimals int) string {
+	if decimals <= 0 {
+		return fmt.Sprintf("%d", units)
+	}
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	scale := pow10[decimals]
+	frac := strings.TrimRight(fmt.Sprintf("%0*d", decimals, units%scale), "0")
+	if frac == "" {
+		return fmt.Sprintf("%s%d", sign, units/scale)
+	}
+	return fmt.Sprintf("%s%d.%s", sign, units/scale, frac)
+}
+
+// ParsePrice converts a decimal price such as "150.50" or "-0.25" into
+// fixed-point units with scale decimal places (15050 and -25 at scale 2).
+// Like ParseQuantity it refuses to round.
+func ParsePrice(s string, scale int) (int64, error) {
+	if scale < 0 || scale > MaxPriceScale {
+		return 0, fmt.Errorf("unsupported price scale %d", scale)
+	}
+	return parseFixed(s, scale, "price")
+}
+
+// FormatPrice renders fixed-point price units with exactly scale decimal
+// places, as prices are quoted: 15050 at scale 2 is "150.50".
+func FormatPrice(units int64, scale int) string {
+	if scale <= 0 {
+		return fmt.Sprintf("%d", units)
+	}
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, units/pow10[scale], scale, units%pow10[scale])
+}
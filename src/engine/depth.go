@@ -0,0 +1,99 @@
+package engine
+
+// DepthUpdate carries the new aggregated quantity of every price level that
+// changed in one engine operation. A Quantity of 0 means the level is gone.
+// Sequence increases by one per update, so a gap means a message was missed
+// and the client should re-sync from a snapshot. Checksum is BookChecksum
+// once the update is applied; a mismatch also calls for a re-sync. EventSeq
+// is the last engine event (see OrderEvent) the update reflects.
+type DepthUpdate struct {
+	Symbol   string                 `json:"symbol"`
+	Sequence uint64                 `json:"sequence"`
+	EventSeq uint64                 `json:"event_seq"`
+	Bids     []AggregatedPriceLevel `json:"bids"`
+	Asks     []AggregatedPriceLevel `json:"asks"`
+	Checksum uint32                 `json:"checksum"`
+}
+
+// touch marks the order's price level as changed since the last depth
+// update. Hidden orders never change what depth shows, so they are ignored.
+func (ob *OrderBook) touch(order *Order) {
+	if order.Hidden {
+		return
+	}
+	if order.Side == Buy {
+		ob.dirtyBids[order.Price] = struct{}{}
+	} else {
+		ob.dirtyAsks[order.Price] = struct{}{}
+	}
+}
+
+// levelQuantity returns the aggregated quantity resting at a price, or 0.
+func (ob *OrderBook) levelQuantity(side Side, price int64) int64 {
+	priceMap := ob.askPriceMap
+	if side == Buy {
+		priceMap = ob.bidPriceMap
+	}
+	if level, ok := priceMap[price]; ok {
+		return level.TotalQuantity()
+	}
+	return 0
+}
+
+// flushDepth consumes the changed levels and returns the next depth update.
+// build is false when nobody is listening; the sequence still advances so
+// snapshots stay comparable with later updates, and the changed levels are
+// still kept for GetDepthDiff.
+func (ob *OrderBook) flushDepth(build bool) (DepthUpdate, bool) {
+	if len(ob.dirtyBids) == 0 && len(ob.dirtyAsks) == 0 {
+		return DepthUpdate{}, false
+	}
+	ob.seq++
+	update := DepthUpdate{Symbol: ob.symbol, Sequence: ob.seq}
+	for price := range ob.dirtyBids {
+		update.Bids = append(update.Bids, AggregatedPriceLevel{Price: price, Quantity: ob.levelQuantity(Buy, price)})
+	}
+	for price := range ob.dirtyAsks {
+		update.Asks = append(update.Asks, AggregatedPriceLevel{Price: price, Quantity: ob.levelQuantity(Sell, price)})
+	}
+	ob.recordDepth(update)
+	if build {
+		update.EventSeq = ob.events.last()
+		update.Checksum = ob.checksum()
+	}
+	clear(ob.dirtyBids)
+	clear(ob.dirtyAsks)
+	return update, build
+}
+
+// publishDepth re-pegs pegged orders after a change to the book (and sweeps
+// it, if SetBookSweep is on), then sends pending level changes to depth
+// subscribers and checks for an imbalance alert. Must be called with the
+// symbol lock held so updates go out in order.
+func (me *MatchingEngine) publishDepth(book *OrderBook) {
+	me.repeg(book)
+	if me.sweepBooks.Load() {
+		book.sweep() // See verify.go
+	}
+	update, ok := book.flushDepth(me.depthFeed.hasSubscribers(book.symbol))
+	if ok {
+		me.depthFeed.publish(book.symbol, update)
+	}
+	me.checkImbalance(book)
+}
+
+// SubscribeDepth streams DepthUpdates for a symbol. Pair it with
+// GetDepthSnapshot or GetBookSnapshot taken after subscribing and discard updates whose
+// Sequence is not greater than the snapshot's.
+func (me *MatchingEngine) SubscribeDepth(symbol string) *Subscription[DepthUpdate] {
+	return me.depthFeed.subscribe(symbol)
+}
+
+// GetDepthSnapshot is GetOrderBookSnapshot plus the book sequence it reflects.
+func (me *MatchingEngine) GetDepthSnapshot(symbol string, depth int) (bids, asks []AggregatedPriceLevel, seq uint64) {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	bids, asks = book.snapshot(depth)
+	return bids, asks, book.seq
+}
@@ -0,0 +1,81 @@
+package engine
+
+import "sort"
+
+// --- Depth Diffs ---
+//
+// Clients that poll the book rather than stream it can ask for only what
+// changed since the book sequence of their last response. Each book keeps
+// its most recent depth updates, and GetDepthDiff folds the ones after the
+// client's sequence into a single update holding each changed level's
+// current quantity (0 when the level is gone). A sequence older than the
+// retained history, or one the book has not reached, cannot be diffed and
+// the client should re-sync from a full snapshot.
+
+// depthHistoryCapacity is how many depth updates each book retains for
+// diffs. Like the trade log it is trimmed back once it doubles.
+const depthHistoryCapacity = 1000
+
+// recordDepth keeps an update's levels for GetDepthDiff.
+func (ob *OrderBook) recordDepth(update DepthUpdate) {
+	ob.history = append(ob.history, DepthUpdate{Sequence: update.Sequence, Bids: update.Bids, Asks: update.Asks})
+	if len(ob.history) >= 2*depthHistoryCapacity {
+		ob.history = append([]DepthUpdate(nil), ob.history[len(ob.history)-depthHistoryCapacity:]...)
+	}
+}
+
+// GetDepthDiff returns the levels of symbol's book that changed after book
+// sequence sinceSeq, with their current quantities, bids best first and
+// asks best first. The result's Sequence and Checksum describe the book
+// now; a sinceSeq equal to it gives an empty diff. ok is false when
+// sinceSeq is older than the retained history or newer than the book, and
+// the caller should fall back to GetBookSnapshot.
+func (me *MatchingEngine) GetDepthDiff(symbol string, sinceSeq uint64) (diff DepthUpdate, ok bool) {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if sinceSeq > book.seq {
+		return DepthUpdate{}, false
+	}
+	// Index of the first update after sinceSeq; sequences are consecutive
+	start := len(book.history) - int(book.seq-sinceSeq)
+	if start < 0 {
+		return DepthUpdate{}, false
+	}
+
+	bids := make(map[int64]int64)
+	asks := make(map[int64]int64)
+	for _, update := range book.history[start:] {
+		for _, level := range update.Bids {
+			bids[level.Price] = level.Quantity
+		}
+		for _, level := range update.Asks {
+			asks[level.Price] = level.Quantity
+		}
+	}
+	diff = DepthUpdate{
+		Symbol:   symbol,
+		Sequence: book.seq,
+		EventSeq: book.events.last(),
+		Bids:     diffLevels(bids, true),
+		Asks:     diffLevels(asks, false),
+		Checksum: book.checksum(),
+	}
+	return diff, true
+}
+
+// diffLevels turns price -> quantity into levels, best price first.
+func diffLevels(changed map[int64]int64, descending bool) []AggregatedPriceLevel {
+	levels := make([]AggregatedPriceLevel, 0, len(changed))
+	for price, quantity := range changed {
+		levels = append(levels, AggregatedPriceLevel{Price: price, Quantity: quantity})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	return levels
+}
@@ -1,111 +1,459 @@
 package engine
 
 import (
-	"fmt"
+	"context"
+	"sort"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // MatchingEngine is the top-level, thread-safe component for all symbols.
 type MatchingEngine struct {
-	Books map[string]*OrderBook
-	Locks map[string]*sync.RWMutex
-	globalMutex sync.RWMutex
+	// Books are found without locking; each symbol's book is guarded by the
+	// stripe its symbol hashes to. See getBookAndLock.
+	books       sync.Map // symbol -> *OrderBook
+	stripes     [lockStripes]sync.RWMutex
+	createMutex sync.Mutex // Serialises book creation
 
 	// Global, thread-safe store for ALL orders
 	orderStore      map[string]*Order
+	claimedIDs      map[string]struct{} // Submissions in progress; see claimOrderID
+	tags            tagIndex            // Open orders by client metadata; see metadata.go
 	orderStoreMutex sync.RWMutex
+	orderSeq        atomic.Int64 // Last Order.Sequence handed out
+
+	depthFeed     *feed[DepthUpdate]
+	tradeFeed     *feed[Trade]
+	executionFeed *feed[Execution] // See SubscribeExecutions
+
+	wal      WAL
+	walSeq   uint64
+	walMutex sync.Mutex
+
+	symbolConfigs map[string]SymbolConfig
+	strictSymbols bool
+	rounding      RoundingMode // See SetRoundingMode
+	configMutex   sync.RWMutex
+
+	metrics *engineMetrics
+	logger  *slog.Logger
+	clock   func() time.Time // See SetClock
+
+	events *eventLog // See SetEventHook
+	alerts atomic.Pointer[imbalanceAlerts] // See SetImbalanceAlerts
+	ids    *idSource                        // See SetIDGenerator
+
+	rejections *rejectionLog // See GetRejections
+
+	started     time.Time    // See GlobalStats
+	tradeCount  atomic.Int64 // Trades executed since start
+	tradeVolume atomic.Int64 // Quantity traded since start
+
+	replaying    atomic.Bool  // Set by Recover; the clock is not consulted
+	killed       atomic.Bool  // See KillSwitch
+	sweepBooks   atomic.Bool  // See SetBookSweep
+	shuttingDown atomic.Bool  // See Shutdown
+	maxOrderAge  atomic.Int64 // Nanoseconds; see SetMaxOrderAge
+	killMutex    sync.Mutex   // Serialises KillSwitch and Reset
 }
 
 // NewMatchingEngine creates a new, thread-safe engine.
 func NewMatchingEngine() *MatchingEngine {
-	return &MatchingEngine{
-		Books:       make(map[string]*OrderBook),
-		Locks:       make(map[string]*sync.RWMutex),
+	me := &MatchingEngine{
 		orderStore:  make(map[string]*Order),
+		claimedIDs:  make(map[string]struct{}),
+		tags:        tagIndex{},
+		depthFeed:   newFeed[DepthUpdate](),
+		tradeFeed:   newFeed[Trade](),
+		executionFeed: newFeed[Execution](),
+		symbolConfigs: make(map[string]SymbolConfig),
+		events:      &eventLog{},
+		ids:         &idSource{},
+		rejections:  &rejectionLog{},
+		started:     time.Now(),
 	}
+	me.metrics = newEngineMetrics(me)
+	me.events.tap = me.observeEvent
+	me.logger = slog.New(slog.DiscardHandler)
+	me.clock = time.Now
+	return me
 }
 
-// getBookAndLock is a thread-safe way to get/create the book and lock.
+// lockStripes is how many book locks there are. Symbols hash onto a
+// stripe, so a lock may guard several books: it must never be taken twice
+// at once, which is why operations spanning books lock them one at a time.
+const lockStripes = 256
+
+// getBookAndLock is a thread-safe way to get/create the book and lock. An
+// existing book is found without taking any engine-wide lock; only creating
+// one does.
 func (me *MatchingEngine) getBookAndLock(symbol string) (*OrderBook, *sync.RWMutex) {
-	me.globalMutex.RLock()
-	lock, ok := me.Locks[symbol]
-	book, okBook := me.Books[symbol]
-	me.globalMutex.RUnlock()
+	lock := me.stripe(symbol)
+	if book, ok := me.books.Load(symbol); ok {
+		return book.(*OrderBook), lock
+	}
 
-	if ok && okBook {
-		return book, lock
+	me.createMutex.Lock()
+	defer me.createMutex.Unlock()
+	if book, ok := me.books.Load(symbol); ok {
+		return book.(*OrderBook), lock
 	}
 
-	me.globalMutex.Lock()
-	defer me.globalMutex.Unlock()
-	if lock, ok = me.Locks[symbol]; ok {
-		return me.Books[symbol], lock
+	newBook := NewOrderBook(symbol)
+	newBook.events = me.events
+	newBook.ids = me.ids
+	if me.killed.Load() {
+		newBook.phase = Halted
+		newBook.haltMode = HaltReject
 	}
+	me.books.Store(symbol, newBook)
+	return newBook, lock
+}
+
+// stripe returns the lock for symbol, by FNV-1a hash.
+func (me *MatchingEngine) stripe(symbol string) *sync.RWMutex {
+	h := uint32(2166136261)
+	for i := 0; i < len(symbol); i++ {
+		h ^= uint32(symbol[i])
+		h *= 16777619
+	}
+	return &me.stripes[h%lockStripes]
+}
 
-	newLock := &sync.RWMutex{}
-	newBook := NewOrderBook()
-	me.Locks[symbol] = newLock
-	me.Books[symbol] = newBook
-	return newBook, newLock
+type symbolBook struct {
+	symbol string
+	book   *OrderBook
+	lock   *sync.RWMutex
+}
+
+// allBooks returns every book with its lock, sorted by symbol. Operations that
+// touch many books lock them one at a time in this order.
+func (me *MatchingEngine) allBooks() []symbolBook {
+	var books []symbolBook
+	me.books.Range(func(key, value any) bool {
+		symbol := key.(string)
+		books = append(books, symbolBook{symbol: symbol, book: value.(*OrderBook), lock: me.stripe(symbol)})
+		return true
+	})
+	sort.Slice(books, func(i, j int) bool { return books[i].symbol < books[j].symbol })
+	return books
 }
 
 // SubmitOrder is the thread-safe entry point for all new orders.
 func (me *MatchingEngine) SubmitOrder(order *Order) (ProcessOrderResponse, error) {
-	book, lock := me.getBookAndLock(order.Symbol)
+	return me.submit(context.Background(), order, nil)
+}
 
-	// Add order to global store first
-	me.orderStoreMutex.Lock()
-	me.orderStore[order.ID] = order
-	me.orderStoreMutex.Unlock()
+// SubmitOrderContext is SubmitOrder with a context carrying request-scoped
+// values, such as the request ID, that are attached to the engine's logs.
+func (me *MatchingEngine) SubmitOrderContext(ctx context.Context, order *Order) (ProcessOrderResponse, error) {
+	return me.submit(ctx, order, nil)
+}
+
+// submit validates and processes an order under its book lock. If inspect is
+// non-nil it is called, with the lock still held, once the order is processed.
+func (me *MatchingEngine) submit(ctx context.Context, order *Order, inspect func()) (response ProcessOrderResponse, err error) {
+	start := time.Now()
+	orderType := order.Type
+	defer me.metrics.observeSubmit(order.Type, order.Side, start)
+
+	cfg, err := me.validateOrder(order)
+	if err != nil {
+		me.recordRejection(order, rejectInvalid, err)
+		me.logSubmit(ctx, order, orderType, response, err, start)
+		return ProcessOrderResponse{}, err
+	}
+
+	book, lock := me.getBookAndLock(order.Symbol)
 
 	lock.Lock()
 	defer lock.Unlock()
+	// Log before unlocking so the order's fields are read under the lock
+	defer func() { me.logSubmit(ctx, order, orderType, response, err, start) }()
+	return me.submitLocked(book, cfg, order, inspect)
+}
 
-	if order.Type == Market {
-		totalQty, ok := book.checkMarketOrderLiquidity(order)
-		if !ok {
-			// Reject the order.
-			// We must also remove it from the global store.
-			me.orderStoreMutex.Lock()
-			delete(me.orderStore, order.ID)
-			me.orderStoreMutex.Unlock()
-			return ProcessOrderResponse{}, fmt.Errorf("insufficient liquidity: only %d shares available, requested %d", totalQty, order.Quantity)
+// submitLocked is submit after validation, with the book lock held.
+func (me *MatchingEngine) submitLocked(book *OrderBook, cfg SymbolConfig, order *Order, inspect func()) (response ProcessOrderResponse, err error) {
+	// Symbol config can change at any time; apply it under the lock. A
+	// symbol delisted since validation is re-checked here, where DelistSymbol
+	// cannot interleave.
+	if _, err := me.lookupSymbolConfig(order.Symbol); err != nil {
+		me.recordRejection(order, rejectInvalid, err)
+		return ProcessOrderResponse{}, err
+	}
+	book.config = cfg
+
+	// A known ID is a retry; answer it without executing or logging anything
+	existing, claimed := me.claimOrderID(order.ID)
+	if !claimed {
+		if response, err = resubmit(existing, order); err != nil {
+			me.recordRejection(order, rejectDuplicate, err)
+			return response, err
 		}
+		if inspect != nil {
+			inspect()
+		}
+		return response, nil
 	}
+	defer me.releaseOrderID(order.ID) // Stored by execute, or rejected
 
-	response := book.ProcessOrder(order)
+	// Trading hours come from the clock, so they are skipped on replay,
+	// where the logged session transitions stand in for it
+	if !me.replaying.Load() && me.advanceSession(book, me.now()) == SessionClosed {
+		err := rejectf(ErrMarketClosed, "market closed for %s", order.Symbol)
+		me.recordRejection(order, rejectClosed, err)
+		return ProcessOrderResponse{}, err
+	}
+
+	order.Sequence = me.orderSeq.Add(1)
+	received := *order
+	if err := me.logWAL(WALEntry{Op: WALSubmit, Order: &received}); err != nil {
+		me.recordRejection(order, rejectWAL, err)
+		return ProcessOrderResponse{}, err
+	}
+
+	if reason, err := admit(book, order); err != nil {
+		me.recordRejection(order, reason, err)
+		return ProcessOrderResponse{}, err
+	}
+	// Market and FOK orders must fill completely; see fillcheck.go
+	if reason, err := stageFill(book, order); err != nil {
+		me.recordRejection(order, reason, err)
+		return ProcessOrderResponse{}, err
+	}
+	response = me.execute(book, order)
 
+	if inspect != nil {
+		inspect()
+	}
 	return response, nil
 }
 
+// admit runs the checks that depend on the book's current state, returning
+// the metrics rejection reason with the error. It changes nothing but the
+// order's own price (pegs, self-cross repricing), so a rejected order leaves
+// the book exactly as it was. The book lock is held.
+func admit(book *OrderBook, order *Order) (string, error) {
+	// First, so the checks below see a repriced order's new price
+	if err := book.guardSelfCross(order); err != nil {
+		return rejectSelfCross, err
+	}
+	// Post-only orders must add liquidity.
+	if err := book.checkPostOnly(order); err != nil {
+		return rejectPostOnly, err
+	}
+	if book.phase == Halted && book.haltMode == HaltReject {
+		return rejectHalted, rejectf(ErrSymbolHalted, "trading halted for %s", order.Symbol)
+	}
+	if order.IsPegged() && !book.collecting() {
+		if err := book.priceNewPeg(order); err != nil {
+			return rejectInsufficientLiquidity, err
+		}
+	}
+	if order.tradesAsLimit() {
+		if err := book.checkBand(order.Price); err != nil {
+			return rejectPriceBand, err
+		}
+		if err := book.checkTradeThrough(order); err != nil {
+			return rejectTradeThrough, err
+		}
+	}
+	if book.collecting() {
+		if err := collectAccepts(order, book.phase); err != nil {
+			return rejectCollecting, err
+		}
+	} else if order.Type == MarketToLimit {
+		if reason, err := book.admitMarketToLimit(order); err != nil {
+			return reason, err
+		}
+	}
+	if order.Type == Market && !order.IsNotional() && book.config.MaxNotional > 0 {
+		if book.config.exceedsNotional(book.marketValue(order)) {
+			return rejectInvalid, rejectf(ErrInvalidOrder, "invalid order: quantity %d would exceed maximum notional %d at current prices", order.Quantity, book.config.MaxNotional)
+		}
+	}
+	if book.full() && book.wouldRest(order) {
+		return rejectBookFull, rejectf(ErrBookFull, "order book for %s is full: %d orders resting", order.Symbol, book.resting)
+	}
+	return "", nil
+}
+
+// checkPostOnly rejects a post-only order priced to take liquidity.
+func (ob *OrderBook) checkPostOnly(order *Order) error {
+	if !order.PostOnly {
+		return nil
+	}
+	if best, ok := ob.bestOpposite(order.Side); ok && crosses(order, best) {
+		return rejectf(ErrPostOnlyCross, "post-only order would take liquidity: price %d crosses best opposite price %d", order.Price, best)
+	}
+	return nil
+}
+
+// admitAmend runs admit's price checks on a limit order's new price: the
+// self-cross guard, post-only, the price band and trade-through. It checks
+// a copy, so a rejected amendment leaves the order as it was, and returns
+// the price to amend to, which the self-cross guard may have moved. The
+// book lock is held.
+func (ob *OrderBook) admitAmend(order *Order, newPrice, newQuantity int64) (int64, error) {
+	candidate := *order
+	candidate.Price = newPrice
+	candidate.Quantity = newQuantity
+	if err := ob.guardSelfCross(&candidate); err != nil {
+		return 0, err
+	}
+	if err := ob.checkPostOnly(&candidate); err != nil {
+		return 0, err
+	}
+	if err := ob.checkBand(candidate.Price); err != nil {
+		return 0, err
+	}
+	if err := ob.checkTradeThrough(&candidate); err != nil {
+		return 0, err
+	}
+	return candidate.Price, nil
+}
+
+// execute stores an admitted order, matches it and publishes the result.
+// The book lock is held.
+func (me *MatchingEngine) execute(book *OrderBook, order *Order) ProcessOrderResponse {
+	me.storeOrder(order)
+
+	book.emitOrder(EventAccepted, order)
+	response := book.ProcessOrder(order)
+	me.publishDepth(book)
+	me.publishExecutions(book.symbol, response)
+	return response
+}
+
 // CancelOrder is the thread-safe entry point for cancelling an order.
 func (me *MatchingEngine) CancelOrder(orderID string) (*Order, error) {
+	return me.cancelOrder(orderID, ReasonRequested)
+}
+
+// cancelOrder cancels an order with the given cancel reason, REQUESTED if
+// empty. Bulk cancels log each order's cancel with their own reason, and WAL
+// replay passes it back here so recovered orders say why they were cancelled.
+func (me *MatchingEngine) cancelOrder(orderID, reason string) (*Order, error) {
+	if reason == "" {
+		reason = ReasonRequested
+	}
 	// Find the order in the global store
-	me.orderStoreMutex.Lock()
+	me.orderStoreMutex.RLock()
 	order, ok := me.orderStore[orderID]
+	me.orderStoreMutex.RUnlock()
 	if !ok {
-		me.orderStoreMutex.Unlock()
-		return nil, fmt.Errorf("order not found") // 404
+		return nil, ErrOrderNotFound // 404
 	}
 
+	// Status only changes under the symbol lock, so check it there
+	book, lock := me.getBookAndLock(order.Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Check if it's already filled or cancelled
 	if order.Status == StatusFilled || order.Status == StatusCancelled {
-		me.orderStoreMutex.Unlock()
-		return nil, fmt.Errorf("cannot cancel order already filled or cancelled") // 400
+		return nil, rejectf(ErrOrderNotOpen, "cannot cancel order already filled or cancelled") // 400
+	}
+	entry := WALEntry{Op: WALCancel, OrderID: orderID}
+	if reason != ReasonRequested {
+		entry.Reason = reason
+	}
+	if err := me.logWAL(entry); err != nil {
+		return nil, err
 	}
 
-	// Mark as cancelled
+	// Mark as cancelled, then remove it from the active book
 	order.Status = StatusCancelled
-	me.orderStoreMutex.Unlock()
+	order.CancelReason = reason
+	book.CancelOrder(order.ID) // This just removes it from the book
+	book.emitOrder(EventCancelled, order)
+	me.publishDepth(book)
+	me.untagOrders([]*Order{order})
+
+	return order, nil
+}
+
+// AmendOrder is the thread-safe entry point for changing a resting order's
+// price and/or total quantity. A zero value keeps the current field (a zero
+// quantity or a negative one is ignored; negative prices are real prices on
+// symbols with AllowNegativePrice and rejected elsewhere). A quantity equal
+// to the filled quantity cancels the rest of the order. A new price passes
+// the checks a submitted order's would: tick size, price band, post-only,
+// trade-through and the self-cross guard, which may reprice it. It returns
+// a copy of the amended order plus any trades caused by the amendment.
+func (me *MatchingEngine) AmendOrder(orderID string, newPrice, newQuantity int64) (*Order, ProcessOrderResponse, error) {
+	me.orderStoreMutex.RLock()
+	order, ok := me.orderStore[orderID]
+	me.orderStoreMutex.RUnlock()
+	if !ok {
+		return nil, ProcessOrderResponse{}, ErrOrderNotFound // 404
+	}
 
-	// Now remove it from the active book
 	book, lock := me.getBookAndLock(order.Symbol)
 	lock.Lock()
 	defer lock.Unlock()
-	
-	book.CancelOrder(order.ID) // This just removes it from the book
 
-	return order, nil
+	if order.Status == StatusFilled || order.Status == StatusCancelled {
+		return nil, ProcessOrderResponse{}, rejectf(ErrOrderNotOpen, "cannot amend order already filled or cancelled") // 400
+	}
+	if book.phase == Halted && book.haltMode == HaltReject {
+		return nil, ProcessOrderResponse{}, rejectf(ErrSymbolHalted, "trading halted for %s", order.Symbol) // 409
+	}
+	if order.IsPegged() && newPrice != 0 && newPrice != order.Price {
+		return nil, ProcessOrderResponse{}, rejectf(ErrInvalidOrder, "invalid amendment: a pegged order is priced by its peg")
+	}
+	if newPrice == 0 {
+		newPrice = order.Price
+	}
+	if newQuantity <= 0 {
+		newQuantity = order.Quantity
+	}
+	if newQuantity < order.FilledQuantity {
+		return nil, ProcessOrderResponse{}, rejectf(ErrInvalidOrder, "invalid amendment: quantity %d is below filled quantity %d", newQuantity, order.FilledQuantity)
+	}
+	// Nothing is left to reprice or size-check on a cancelling amendment
+	cancel := newQuantity == order.FilledQuantity
+	if cancel {
+		newPrice = order.Price
+	}
+	cfg := me.GetSymbolConfig(order.Symbol)
+	if !cancel {
+		if order.HasLimitPrice() {
+			if err := cfg.checkPrice("price", newPrice); err != nil {
+				return nil, ProcessOrderResponse{}, err
+			}
+			if err := cfg.checkMaxNotional(newPrice, newQuantity); err != nil {
+				return nil, ProcessOrderResponse{}, err
+			}
+		}
+		if err := cfg.checkQuantity(newQuantity); err != nil {
+			return nil, ProcessOrderResponse{}, err
+		}
+	}
+	book.config = cfg
+	if !cancel && order.Type == Limit && newPrice != order.Price {
+		repriced, err := book.admitAmend(order, newPrice, newQuantity)
+		if err != nil {
+			return nil, ProcessOrderResponse{}, err
+		}
+		newPrice = repriced
+	}
+	if err := me.logWAL(WALEntry{Op: WALAmend, OrderID: orderID, Price: newPrice, Quantity: newQuantity}); err != nil {
+		return nil, ProcessOrderResponse{}, err
+	}
+
+	response, ok := book.AmendOrder(order, newPrice, newQuantity, me.orderSeq.Add(1))
+	if !ok {
+		return nil, ProcessOrderResponse{}, rejectf(ErrOrderNotOpen, "cannot amend order that is not resting in the book")
+	}
+	me.publishDepth(book)
+	me.publishExecutions(book.symbol, response)
+
+	orderCopy := *order
+	return &orderCopy, response, nil
 }
 
 // GetOrderStatus retrieves an order by its ID from the global store.
@@ -115,7 +463,7 @@ func (me *MatchingEngine) GetOrderStatus(orderID string) (*Order, error) {
 	
 	order, ok := me.orderStore[orderID]
 	if !ok {
-		return nil, fmt.Errorf("order not found") // 404
+		return nil, ErrOrderNotFound // 404
 	}
 	
 	// Return a copy to avoid data races
@@ -139,42 +487,79 @@ func (me *MatchingEngine) GetOrderBookSnapshot(symbol string, depth int) (bids [
 	if book == nil {
 		return
 	}
+	return book.snapshot(depth)
+}
 
-	// --- Get Asks (Lowest price first) ---
-	askCount := 0
-	book.asks.Ascend(func(l *PriceLevel) bool {
-		if depth > 0 && askCount >= depth {
-			return false
-		}
-		var totalQuantity int64
-		for e := l.Orders.Front(); e != nil; e = e.Next() {
-			totalQuantity += e.Value.(*Order).RemainingQuantity()
-		}
-		// Quantities at each price level are aggregated
-		if totalQuantity > 0 {
-			asks = append(asks, AggregatedPriceLevel{Price: l.Price, Quantity: totalQuantity})
-			askCount++
-		}
-		return true
-	})
+// OrderBookSnapshot is one symbol's aggregated depth, with the depth feed
+// sequence and BookChecksum it was taken at. Empty sides are empty slices,
+// never nil. The totals count the whole displayed book on each side, however
+// far depth truncated Bids and Asks; hidden orders are not counted.
+type OrderBookSnapshot struct {
+	Bids           []AggregatedPriceLevel `json:"bids"`
+	Asks           []AggregatedPriceLevel `json:"asks"`
+	Sequence       uint64                 `json:"sequence"`
+	Checksum       uint32                 `json:"checksum"`
+	TotalBidLevels int                    `json:"total_bid_levels"`
+	TotalAskLevels int                    `json:"total_ask_levels"`
+	TotalBidOrders int                    `json:"total_bid_orders"`
+	TotalAskOrders int                    `json:"total_ask_orders"`
+}
 
-	// --- Get Bids (Highest price first) ---
-	bidCount := 0
-	book.bids.Ascend(func(l *PriceLevel) bool {
-		if depth > 0 && bidCount >= depth {
-			return false
-		}
-		var totalQuantity int64
-		for e := l.Orders.Front(); e != nil; e = e.Next() {
-			totalQuantity += e.Value.(*Order).RemainingQuantity()
-		}
-		// Quantities at each price level are aggregated
-		if totalQuantity > 0 {
-			bids = append(bids, AggregatedPriceLevel{Price: l.Price, Quantity: totalQuantity})
-			bidCount++
-		}
-		return true
-	})
+// GetBookSnapshot returns a symbol's depth, sequence and checksum, all read
+// under one hold of the book's read lock.
+func (me *MatchingEngine) GetBookSnapshot(symbol string, depth int) OrderBookSnapshot {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return book.bookSnapshot(depth)
+}
 
-	return bids, asks
-}
\ No newline at end of file
+// GetAggregatedSnapshot is GetBookSnapshot with the levels grouped into price
+// buckets of width group: bids round down and asks round up, toward worse
+// prices, so grouped bids and asks never cross. Depth counts buckets. The
+// sequence, checksum and totals describe the ungrouped book. A group of 0 or
+// 1 leaves the levels as they are.
+func (me *MatchingEngine) GetAggregatedSnapshot(symbol string, depth int, group int64) OrderBookSnapshot {
+	if group <= 1 {
+		return me.GetBookSnapshot(symbol, depth)
+	}
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	snap := book.bookSnapshot(0)
+	snap.Bids = groupLevels(snap.Bids, group, false, depth)
+	snap.Asks = groupLevels(snap.Asks, group, true, depth)
+	return snap
+}
+
+// bookSnapshot builds an OrderBookSnapshot. The book lock must be held.
+func (ob *OrderBook) bookSnapshot(depth int) OrderBookSnapshot {
+	bids, asks := ob.snapshot(depth)
+	if bids == nil {
+		bids = []AggregatedPriceLevel{}
+	}
+	if asks == nil {
+		asks = []AggregatedPriceLevel{}
+	}
+	snap := OrderBookSnapshot{Bids: bids, Asks: asks, Sequence: ob.seq, Checksum: ob.checksum()}
+	snap.TotalBidLevels, snap.TotalBidOrders = displayedCounts(ob.bids)
+	snap.TotalAskLevels, snap.TotalAskOrders = displayedCounts(ob.asks)
+	return snap
+}
+
+// GetOrderBookSnapshots returns the depth of several symbols at once, keyed
+// by symbol. Each book is read under its own lock, one after another, so the
+// snapshots are individually consistent but not taken at a single instant.
+// Duplicate symbols are returned once.
+func (me *MatchingEngine) GetOrderBookSnapshots(symbols []string, depth int) map[string]OrderBookSnapshot {
+	snapshots := make(map[string]OrderBookSnapshot, len(symbols))
+	for _, symbol := range symbols {
+		if _, done := snapshots[symbol]; done {
+			continue
+		}
+		snapshots[symbol] = me.GetBookSnapshot(symbol, depth)
+	}
+	return snapshots
+}
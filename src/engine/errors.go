@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReasonCode is a stable, machine-readable code for why the engine refused a
+// request. Messages may change; codes do not.
+type ReasonCode string
+
+const (
+	CodeInvalidOrder          ReasonCode = "INVALID_ORDER"
+	CodeUnknownSymbol         ReasonCode = "UNKNOWN_SYMBOL"
+	CodeInsufficientLiquidity ReasonCode = "INSUFFICIENT_LIQUIDITY"
+	CodePostOnlyCross         ReasonCode = "POST_ONLY_CROSS"
+	CodeOutsideBand           ReasonCode = "OUTSIDE_PRICE_BAND"
+	CodeSymbolHalted          ReasonCode = "SYMBOL_HALTED"
+	CodePhaseRejected         ReasonCode = "NOT_ACCEPTED_IN_PHASE" // e.g. market orders during an auction
+	CodeDuplicateOrderID      ReasonCode = "DUPLICATE_ORDER_ID"
+	CodeOrderNotFound         ReasonCode = "ORDER_NOT_FOUND"
+	CodeOrderNotOpen          ReasonCode = "ORDER_NOT_OPEN" // Already filled or cancelled
+	CodeInvalidState          ReasonCode = "INVALID_STATE"  // Auction, halt or kill switch in the wrong phase
+	CodeWALFailure            ReasonCode = "WAL_FAILURE"
+	CodeBookFull              ReasonCode = "BOOK_FULL"     // Symbol at its MaxOrders cap
+	CodeMarketClosed          ReasonCode = "MARKET_CLOSED" // Outside the symbol's trading hours
+	CodeTradeThrough          ReasonCode = "TRADE_THROUGH" // Priced through the symbol's reference quote
+	CodeSelfCross             ReasonCode = "SELF_CROSS"    // Would cross its account's own resting order
+	CodeTradeNotFound         ReasonCode = "TRADE_NOT_FOUND"
+)
+
+// Error is a refusal from the engine. errors.Is matches it against the
+// sentinel with the same code, whatever the message, so callers can test
+// e.g. errors.Is(err, ErrSymbolHalted).
+type Error struct {
+	Code    ReasonCode
+	Message string
+	err     error // Underlying cause, if any
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.err }
+
+// Is reports whether target is an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Sentinels for errors.Is, one per ReasonCode.
+var (
+	ErrInvalidOrder          = &Error{Code: CodeInvalidOrder, Message: "invalid order"}
+	ErrUnknownSymbol         = &Error{Code: CodeUnknownSymbol, Message: "unknown symbol"}
+	ErrInsufficientLiquidity = &Error{Code: CodeInsufficientLiquidity, Message: "insufficient liquidity"}
+	ErrPostOnlyCross         = &Error{Code: CodePostOnlyCross, Message: "post-only order would take liquidity"}
+	ErrOutsideBand           = &Error{Code: CodeOutsideBand, Message: "price outside price band"}
+	ErrSymbolHalted          = &Error{Code: CodeSymbolHalted, Message: "trading halted"}
+	ErrPhaseRejected         = &Error{Code: CodePhaseRejected, Message: "order not accepted in the current trading phase"}
+	ErrDuplicateOrderID      = &Error{Code: CodeDuplicateOrderID, Message: "duplicate order id"}
+	ErrOrderNotFound         = &Error{Code: CodeOrderNotFound, Message: "order not found"}
+	ErrOrderNotOpen          = &Error{Code: CodeOrderNotOpen, Message: "order is not open"}
+	ErrInvalidState          = &Error{Code: CodeInvalidState, Message: "invalid state"}
+	ErrWALFailure            = &Error{Code: CodeWALFailure, Message: "write-ahead log append failed"}
+	ErrBookFull              = &Error{Code: CodeBookFull, Message: "order book is full"}
+	ErrMarketClosed          = &Error{Code: CodeMarketClosed, Message: "market closed"}
+	ErrTradeThrough          = &Error{Code: CodeTradeThrough, Message: "order would trade through the reference quote"}
+	ErrSelfCross             = &Error{Code: CodeSelfCross, Message: "order would cross its account's own resting order"}
+	ErrTradeNotFound         = &Error{Code: CodeTradeNotFound, Message: "trade not found"}
+)
+
+// rejectf returns an error with the sentinel's code and a formatted message.
+func rejectf(sentinel *Error, format string, args ...any) error {
+	return &Error{Code: sentinel.Code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Code returns the ReasonCode carried by err, or "" if it has none.
+func Code(err error) ReasonCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
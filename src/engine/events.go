@@ -0,0 +1,212 @@
+package engine
+
+import "sync"
+
+// OrderEventType is what happened in an OrderEvent.
+type OrderEventType string
+
+const (
+	EventAccepted  OrderEventType = "ACCEPTED"  // Admitted to the book, before any matching
+	EventTrade     OrderEventType = "TRADE"     // Trade carries the execution
+	EventCancelled OrderEventType = "CANCELLED" // Order.CancelReason says why
+	EventAmended   OrderEventType = "AMENDED"   // Order carries the new price and quantity
+	EventExpired   OrderEventType = "EXPIRED"   // GTD or DAY expiry
+	EventBust      OrderEventType = "BUST"      // Order and Trade carry the busted trade and the order after it
+)
+
+// OrderEvent is one state change, stamped with the engine-wide event
+// sequence. Sequence starts at 1 and increases by exactly one per event
+// across every symbol, so a consumer that sees a gap has missed an event.
+// Order events carry a copy of the order after the change; trade events
+// carry the trade, whose EventSeq equals Sequence.
+type OrderEvent struct {
+	Sequence uint64         `json:"seq"`
+	Type     OrderEventType `json:"type"`
+	Symbol   string         `json:"symbol"`
+	Order    *Order         `json:"order,omitempty"`
+	Trade    *Trade         `json:"trade,omitempty"`
+}
+
+// eventLog hands out event sequence numbers and delivers events to the
+// engine's own consumers, the hook and subscribers. Numbering and delivery
+// happen under one mutex, so all of them see events in sequence order even
+// when symbols are processed concurrently.
+type eventLog struct {
+	mu   sync.Mutex
+	seq  uint64
+	tap  func(OrderEvent) // The engine's own consumers; see observeEvent
+	hook func(OrderEvent)
+	subs map[*eventSubscriber]struct{}
+
+	history map[string][]OrderEvent // By order ID; see GetOrderHistory
+}
+
+// SetEventHook calls hook synchronously for every event, in sequence order,
+// while the engine holds the symbol's lock. hook must be fast and must not
+// call back into the engine. Pass nil to remove it.
+func (me *MatchingEngine) SetEventHook(hook func(OrderEvent)) {
+	me.events.mu.Lock()
+	me.events.hook = hook
+	me.events.mu.Unlock()
+}
+
+// Subscribe calls handler for every event from now on, on a goroutine of its
+// own, so a slow handler never holds up matching. Each subscriber sees events
+// in sequence order, and so in order within every symbol. Up to
+// subscriptionBuffer events wait while the handler is busy; a subscriber that
+// falls further behind is dropped, as the depth and trade feeds drop slow
+// consumers, and gets no more events (the gap between the last Sequence it
+// saw and EventSequence is what it missed). The returned func unsubscribes:
+// no further events are delivered, though a call already running finishes.
+// Unlike SetEventHook, handler may call back into the engine.
+func (me *MatchingEngine) Subscribe(handler func(OrderEvent)) (unsubscribe func()) {
+	sub := &eventSubscriber{handler: handler, ch: make(chan OrderEvent, subscriptionBuffer), done: make(chan struct{})}
+	me.events.mu.Lock()
+	if me.events.subs == nil {
+		me.events.subs = make(map[*eventSubscriber]struct{})
+	}
+	me.events.subs[sub] = struct{}{}
+	me.events.mu.Unlock()
+	go sub.run()
+
+	return func() {
+		me.events.mu.Lock()
+		delete(me.events.subs, sub)
+		me.events.mu.Unlock()
+		sub.stop()
+	}
+}
+
+// eventSubscriber queues events for one Subscribe handler and delivers them
+// from its own goroutine.
+type eventSubscriber struct {
+	handler func(OrderEvent)
+
+	ch      chan OrderEvent
+	done    chan struct{} // Closed on unsubscribe or when dropped
+	stopped sync.Once
+}
+
+// push queues ev without blocking and reports whether there was room.
+func (s *eventSubscriber) push(ev OrderEvent) bool {
+	select {
+	case s.ch <- ev:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *eventSubscriber) stop() {
+	s.stopped.Do(func() { close(s.done) })
+}
+
+func (s *eventSubscriber) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case ev := <-s.ch:
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			s.handler(ev)
+		}
+	}
+}
+
+// EventSequence returns the sequence of the last event emitted.
+func (me *MatchingEngine) EventSequence() uint64 {
+	return me.events.last()
+}
+
+func (l *eventLog) last() uint64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq
+}
+
+// restore continues numbering after seq, dropping order histories that
+// predate the snapshot; used when loading one.
+func (l *eventLog) restore(seq uint64) {
+	l.mu.Lock()
+	l.seq = seq
+	l.history = nil
+	l.mu.Unlock()
+}
+
+// emit stamps the next sequence on ev (and on its trade) and delivers it.
+// A book without an event log, e.g. one built outside an engine, emits
+// nothing.
+func (l *eventLog) emit(ev OrderEvent) uint64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	ev.Sequence = l.seq
+	if ev.Trade != nil {
+		ev.Trade.EventSeq = l.seq
+	}
+	l.record(ev)
+	if l.tap != nil {
+		l.tap(ev)
+	}
+	if l.hook != nil {
+		l.hook(ev)
+	}
+	for sub := range l.subs {
+		if !sub.push(ev) {
+			delete(l.subs, sub) // Slow consumer
+			sub.stop()
+		}
+	}
+	return l.seq
+}
+
+// cancelMetricReasons labels ome_orders_cancelled_total by cancel reason.
+// Cancels the engine makes on its own while matching (unfilled remainders,
+// self-match prevention, busts) are not counted.
+var cancelMetricReasons = map[string]string{
+	ReasonRequested:   cancelRequested,
+	ReasonReplaced:    cancelRequested,
+	ReasonCancelAll:   cancelAll,
+	ReasonCancelLevel: cancelLevel,
+	ReasonCancelTag:   cancelTag,
+	ReasonExpired:     cancelExpired,
+	ReasonKillSwitch:  cancelKillSwitch,
+	ReasonDelisted:    cancelDelisted,
+}
+
+// observeEvent feeds the engine's own consumers from the event stream: the
+// trade feed, the trade and cancellation metrics and the GlobalStats totals.
+// It runs under the event log's lock, so it must not block.
+func (me *MatchingEngine) observeEvent(ev OrderEvent) {
+	switch ev.Type {
+	case EventTrade:
+		me.tradeFeed.publish(ev.Symbol, *ev.Trade)
+		me.metrics.tradesExecuted.WithLabelValues(ev.Symbol).Inc()
+		me.tradeCount.Add(1)
+		me.tradeVolume.Add(ev.Trade.Quantity)
+	case EventCancelled, EventExpired:
+		if reason, ok := cancelMetricReasons[ev.Order.CancelReason]; ok {
+			me.metrics.cancelled(reason, 1)
+		}
+	}
+}
+
+// emitOrder emits an event carrying a copy of order. The book lock is held.
+func (ob *OrderBook) emitOrder(typ OrderEventType, order *Order) {
+	if ob.events == nil {
+		return
+	}
+	orderCopy := *order
+	orderCopy.element = nil
+	ob.events.emit(OrderEvent{Type: typ, Symbol: ob.symbol, Order: &orderCopy})
+}
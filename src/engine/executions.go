@@ -0,0 +1,65 @@
+package engine
+
+// --- Executions ---
+//
+// The trade feed carries one message per trade. Clients that only want to
+// know how each order event filled can subscribe to executions instead: one
+// rolled-up report per aggressor order per event, however many levels or
+// resting orders it traded against. An order that sweeps three levels is
+// three trades but one Execution; a stop it triggers is an order event of its
+// own and gets its own report.
+
+// FillGranularity selects the messages a fill subscriber receives.
+type FillGranularity string
+
+const (
+	FillsPerTrade FillGranularity = "per_trade" // Every Trade (SubscribeTrades)
+	FillsPerOrder FillGranularity = "per_order" // One Execution per order event (SubscribeExecutions)
+)
+
+// Execution is the rolled-up fill of one aggressor order in one order event.
+type Execution struct {
+	Symbol         string   `json:"symbol"`
+	OrderID        string   `json:"order_id"` // The aggressor
+	Side           Side     `json:"side"`
+	FilledQuantity int64    `json:"filled_quantity"`
+	AveragePrice   int64    `json:"average_price"` // Quantity-weighted, truncated
+	Fees           int64    `json:"fees"`          // The aggressor's fees on these trades
+	TradeIDs       []string `json:"trade_ids"`     // In execution order
+	EventSeq       uint64   `json:"event_seq"`     // The last trade's
+	Timestamp      int64    `json:"timestamp"`     // The last trade's
+}
+
+// rollUp groups consecutive trades by aggressor into executions.
+func rollUp(symbol string, trades []Trade) []Execution {
+	var executions []Execution
+	var notional int64
+	for _, trade := range trades {
+		n := len(executions)
+		if n == 0 || executions[n-1].OrderID != trade.AggressorOrderID {
+			if n > 0 {
+				executions[n-1].AveragePrice = notional / executions[n-1].FilledQuantity
+			}
+			executions = append(executions, Execution{Symbol: symbol, OrderID: trade.AggressorOrderID, Side: trade.AggressorSide})
+			notional = 0
+			n++
+		}
+		exec := &executions[n-1]
+		exec.FilledQuantity += trade.Quantity
+		exec.Fees += trade.AggressorFee
+		exec.TradeIDs = append(exec.TradeIDs, trade.TradeID)
+		exec.EventSeq = trade.EventSeq
+		exec.Timestamp = trade.Timestamp
+		notional += trade.Price * trade.Quantity
+	}
+	if n := len(executions); n > 0 {
+		executions[n-1].AveragePrice = notional / executions[n-1].FilledQuantity
+	}
+	return executions
+}
+
+// SubscribeExecutions streams one Execution per aggressor order per order
+// event in a symbol, in execution order.
+func (me *MatchingEngine) SubscribeExecutions(symbol string) *Subscription[Execution] {
+	return me.executionFeed.subscribe(symbol)
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+// ExpireOrders cancels every resting or armed order whose ExpiresAt is at or
+// before now, marking it CANCELLED with reason EXPIRED. Books are visited one
+// at a time under their own lock, so expiry never races with matching.
+// It returns copies of the expired orders.
+func (me *MatchingEngine) ExpireOrders(now time.Time) []*Order {
+	nowMs := now.UnixNano() / 1_000_000
+	var expired []*Order
+
+	for _, sb := range me.allBooks() {
+		sb.lock.Lock()
+		var due []*Order
+		for _, order := range sb.book.expiring {
+			if order.ExpiresAt <= nowMs {
+				due = append(due, order)
+			}
+		}
+		// Map iteration is random; expire oldest deadline first for a stable log
+		sort.Slice(due, func(i, j int) bool {
+			if due[i].ExpiresAt != due[j].ExpiresAt {
+				return due[i].ExpiresAt < due[j].ExpiresAt
+			}
+			return due[i].ID < due[j].ID
+		})
+		for _, order := range due {
+			if err := me.logWAL(WALEntry{Op: WALExpire, OrderID: order.ID}); err != nil {
+				break // Retry on the next sweep rather than expire unlogged
+			}
+			sb.book.expire(order)
+			orderCopy := *order
+			expired = append(expired, &orderCopy)
+		}
+		me.publishDepth(sb.book)
+		sb.lock.Unlock()
+	}
+	return expired
+}
+
+// expireOrder expires a single order regardless of the clock; used by WAL replay.
+func (me *MatchingEngine) expireOrder(orderID string) bool {
+	me.orderStoreMutex.RLock()
+	order, ok := me.orderStore[orderID]
+	me.orderStoreMutex.RUnlock()
+	if !ok {
+		return false
+	}
+	book, lock := me.getBookAndLock(order.Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+	if order.Status == StatusFilled || order.Status == StatusCancelled {
+		return false
+	}
+	book.expire(order)
+	me.publishDepth(book)
+	return true
+}
+
+// expire pulls an order out of the book (or the armed stops) as expired.
+func (ob *OrderBook) expire(order *Order) {
+	ob.CancelOrder(order.ID)
+	delete(ob.expiring, order.ID)
+	order.Status = StatusCancelled
+	order.CancelReason = ReasonExpired
+	ob.emitOrder(EventExpired, order)
+}
+
+// StartExpiryReaper runs ExpireOrders, SyncSessions and CheckStaleOrders
+// every interval, at the engine clock's current time, in the background
+// until the returned stop function is called.
+func (me *MatchingEngine) StartExpiryReaper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				me.ExpireOrders(me.now())
+				me.SyncSessions(me.now())
+				me.CheckStaleOrders(me.now())
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
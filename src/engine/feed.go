@@ -0,0 +1,89 @@
+package engine
+
+import "sync"
+
+// subscriptionBuffer is how many undelivered messages a subscriber may queue
+// before it is considered too slow and dropped.
+const subscriptionBuffer = 256
+
+// Subscription receives messages for one symbol on C. C is closed when the
+// subscription is closed or dropped for falling behind.
+type Subscription[T any] struct {
+	C <-chan T
+
+	ch     chan T
+	symbol string
+	feed   *feed[T]
+}
+
+// Close unsubscribes and closes C. It is safe to call more than once.
+func (s *Subscription[T]) Close() {
+	s.feed.remove(s)
+}
+
+// feed is a non-blocking fan-out of messages to per-symbol subscribers.
+// Publishing never waits on a subscriber: a full buffer drops the subscriber
+// instead, so a slow consumer can never stall the matching path.
+type feed[T any] struct {
+	mu     sync.Mutex
+	subs   map[*Subscription[T]]struct{}
+	counts map[string]int
+}
+
+func newFeed[T any]() *feed[T] {
+	return &feed[T]{
+		subs:   make(map[*Subscription[T]]struct{}),
+		counts: make(map[string]int),
+	}
+}
+
+func (f *feed[T]) subscribe(symbol string) *Subscription[T] {
+	ch := make(chan T, subscriptionBuffer)
+	sub := &Subscription[T]{C: ch, ch: ch, symbol: symbol, feed: f}
+
+	f.mu.Lock()
+	f.subs[sub] = struct{}{}
+	f.counts[symbol]++
+	f.mu.Unlock()
+	return sub
+}
+
+func (f *feed[T]) remove(sub *Subscription[T]) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removeLocked(sub)
+}
+
+func (f *feed[T]) removeLocked(sub *Subscription[T]) {
+	if _, ok := f.subs[sub]; !ok {
+		return
+	}
+	delete(f.subs, sub)
+	f.counts[sub.symbol]--
+	if f.counts[sub.symbol] == 0 {
+		delete(f.counts, sub.symbol)
+	}
+	close(sub.ch)
+}
+
+// hasSubscribers lets publishers skip building messages nobody will read.
+func (f *feed[T]) hasSubscribers(symbol string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[symbol] > 0
+}
+
+func (f *feed[T]) publish(symbol string, msg T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subs {
+		if sub.symbol != symbol {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			f.removeLocked(sub) // Slow consumer
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import "fmt"
+
+// --- Fees ---
+//
+// Every trade is charged from the symbol's fee schedule: the aggressor is
+// always the taker and pays TakerFeeBps of the trade's notional (price x
+// quantity); the resting order is the maker and receives MakerRebateBps back.
+// Fees are signed from the account's point of view, so a charge is positive
+// and a rebate negative. By default rounding favours the exchange: the taker
+// fee is rounded up and the maker rebate down (see rounding.go).
+//
+// A schedule may add volume tiers. Each trade prices each side at the tier
+// its account's rolling volume in the symbol has reached before the trade
+// (see positions.go), so crossing a threshold takes effect from the next
+// trade. Anonymous orders always pay the base rates; a schedule of zeros is
+// commission-free.
+
+// FeeSchedule is a symbol's maker/taker pricing in basis points: the base
+// rates, and optional volume tiers that replace them for busier accounts.
+type FeeSchedule struct {
+	TakerFeeBps    int64     `json:"taker_fee_bps,omitempty"`
+	MakerRebateBps int64     `json:"maker_rebate_bps,omitempty"`
+	Tiers          []FeeTier `json:"tiers,omitempty"` // Ascending MinVolume
+}
+
+// FeeTier is the pricing for an account whose volume in the symbol over the
+// last VolumeWindowDays days is at least MinVolume.
+type FeeTier struct {
+	MinVolume      int64 `json:"min_volume"`
+	TakerFeeBps    int64 `json:"taker_fee_bps"`
+	MakerRebateBps int64 `json:"maker_rebate_bps"`
+}
+
+func (f FeeSchedule) validate() error {
+	if !validBps(f.TakerFeeBps) || !validBps(f.MakerRebateBps) {
+		return fmt.Errorf("invalid symbol config: taker_fee_bps and maker_rebate_bps must be between 0 and 9999")
+	}
+	var prev int64
+	for i, tier := range f.Tiers {
+		if !validBps(tier.TakerFeeBps) || !validBps(tier.MakerRebateBps) {
+			return fmt.Errorf("invalid symbol config: fee tier %d: taker_fee_bps and maker_rebate_bps must be between 0 and 9999", i)
+		}
+		if tier.MinVolume <= prev {
+			return fmt.Errorf("invalid symbol config: fee tier %d: min_volume must be positive and above the previous tier's", i)
+		}
+		prev = tier.MinVolume
+	}
+	return nil
+}
+
+func validBps(bps int64) bool {
+	return bps >= 0 && bps < 10_000
+}
+
+// Tier resolves the pricing for an account with the given rolling volume:
+// the highest tier it has reached, or the base rates as a tier with
+// MinVolume 0.
+func (f FeeSchedule) Tier(volume int64) FeeTier {
+	tier := FeeTier{TakerFeeBps: f.TakerFeeBps, MakerRebateBps: f.MakerRebateBps}
+	for _, t := range f.Tiers {
+		if volume < t.MinVolume {
+			break
+		}
+		tier = t
+	}
+	return tier
+}
+
+// fees returns the aggressor's and the resting order's fee for a trade, each
+// at the tier for its account's rolling volume, rounded by mode.
+func (f FeeSchedule) fees(price, quantity, aggressorVolume, restingVolume int64, mode RoundingMode) (aggressorFee, restingFee int64) {
+	notional := abs(price) * quantity
+	taker, maker := f.Tier(aggressorVolume), f.Tier(restingVolume)
+	return mode.mulDiv(notional, taker.TakerFeeBps, 10_000, true), -mode.mulDiv(notional, maker.MakerRebateBps, 10_000, false)
+}
+
+// AccountFees is an account's fee tier in one symbol.
+type AccountFees struct {
+	Symbol string  `json:"symbol"`
+	Volume int64   `json:"volume"` // Over the last VolumeWindowDays days
+	Tier   FeeTier `json:"tier"`
+}
+
+// GetAccountFees returns the account's current fee tier in every symbol it
+// has traded, sorted by symbol, with volume counted up to the engine clock.
+func (me *MatchingEngine) GetAccountFees(accountID string) []AccountFees {
+	now := me.now().UnixMilli()
+	fees := []AccountFees{}
+	for _, sb := range me.allBooks() {
+		sb.lock.RLock()
+		if position, ok := sb.book.positions[accountID]; ok {
+			volume := position.RollingVolume(now)
+			fees = append(fees, AccountFees{Symbol: sb.symbol, Volume: volume, Tier: sb.book.config.Fees.Tier(volume)})
+		}
+		sb.lock.RUnlock()
+	}
+	return fees
+}
@@ -0,0 +1,178 @@
+package engine
+
+import "maps"
+
+// --- Fill-Or-Reject Matching ---
+//
+// Market and FOK orders are rejected unless they can fill completely on
+// arrival. Rather than walk the book once to count the liquidity and again
+// to take it, the engine matches such an order straight away and decides as
+// it goes: stageFill runs the normal match with the book's trade effects
+// held back, then either keeps it or rolls it back.
+//
+// While a match is staged the book itself changes as usual (resting orders
+// are filled, removed and replenished), but each trade is only priced: its
+// ID, its event, the ticker, the trade log and both positions wait until the
+// order is accepted and ProcessOrder commits the match. Before the first
+// fill at a price level the level's queue and every order's fill state are
+// saved, so a rollback puts back exactly what was there, queue priority and
+// iceberg slices included, and a rejected order leaves no trace.
+//
+// A match that stops at a market order's protection price or the price
+// band, with quantity left, counts the rest of the side as the old pre-scan
+// did: if the book holds enough the order is accepted and its remainder is
+// cancelled as UNFILLED. A match that runs out of book, or reaches the
+// order's own desk (see selfmatch.go), is rolled back.
+
+// stagedFill is a market or FOK order's match, made before the order is
+// accepted and kept only if it can fill completely.
+type stagedFill struct {
+	order   *Order
+	trades  []Trade
+	filled  []*Order
+	resting []*Order // The resting order of each trade, to book it on commit
+	levels  []levelSnapshot
+
+	// The aggressor and depth tracking as they were before the match
+	filledQuantity int64
+	cancelReason   string
+	dirtyBids      map[int64]struct{}
+	dirtyAsks      map[int64]struct{}
+}
+
+// levelSnapshot is a price level's queue and fill state before a staged
+// match first traded there.
+type levelSnapshot struct {
+	level  *PriceLevel
+	side   Side
+	orders []*Order
+	states []fillState
+}
+
+// fillState is the part of a resting order that matching changes.
+type fillState struct {
+	filledQuantity  int64
+	visibleQuantity int64
+	status          OrderStatus
+}
+
+// mustFill reports whether an order is rejected unless it fills completely
+// on arrival. Notional market orders spend what they can instead and report
+// the leftover cash.
+func mustFill(order *Order) bool {
+	return (order.Type == Market && !order.IsNotional()) || (order.TimeInForce == FOK && !order.IsConditional())
+}
+
+// stageFill matches a market or FOK order that admit accepted, returning the
+// metrics rejection reason with the error if it cannot fill. A rejected
+// match is rolled back; an accepted one is committed by ProcessOrder. The
+// book lock is held.
+func stageFill(book *OrderBook, order *Order) (string, error) {
+	if !mustFill(order) {
+		return "", nil
+	}
+	if available, ok := book.stage(order); !ok {
+		return rejectInsufficientLiquidity, rejectf(ErrInsufficientLiquidity, "insufficient liquidity: only %d shares available, requested %d", available, order.Quantity)
+	}
+	return "", nil
+}
+
+// stage matches order with its trades held back and reports how much of it
+// the book could fill and whether that is all of it. An order that cannot
+// fill is rolled back before stage returns.
+func (ob *OrderBook) stage(order *Order) (int64, bool) {
+	s := &stagedFill{
+		order:          order,
+		filledQuantity: order.FilledQuantity,
+		cancelReason:   order.CancelReason,
+		dirtyBids:      maps.Clone(ob.dirtyBids),
+		dirtyAsks:      maps.Clone(ob.dirtyAsks),
+	}
+	ob.staged = s
+	if order.Side == Buy {
+		s.trades, s.filled = ob.matchBuyOrder(order)
+	} else {
+		s.trades, s.filled = ob.matchSellOrder(order)
+	}
+
+	available := order.FilledQuantity
+	if remaining := order.RemainingQuantity(); remaining > 0 && !selfMatchStopped(order) {
+		// Stopped by a limit, protection price or band: count what is left
+		available += ob.fillable(order, remaining)
+	}
+	if available >= order.Quantity {
+		return available, true
+	}
+	ob.rollback(s)
+	return available, false
+}
+
+// save records a level before the staged match first trades at it.
+func (s *stagedFill) save(level *PriceLevel) {
+	for _, saved := range s.levels {
+		if saved.level == level {
+			return
+		}
+	}
+	snap := levelSnapshot{level: level}
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		order := e.Value.(*Order)
+		snap.side = order.Side
+		snap.orders = append(snap.orders, order)
+		snap.states = append(snap.states, fillState{order.FilledQuantity, order.VisibleQuantity, order.Status})
+	}
+	s.levels = append(s.levels, snap)
+}
+
+// commitStaged books the staged match's trades, in the order they were
+// made, and returns them with the resting orders they filled.
+func (ob *OrderBook) commitStaged() ([]Trade, []*Order) {
+	s := ob.staged
+	ob.staged = nil
+	for i := range s.trades {
+		ob.bookTrade(s.order, s.resting[i], &s.trades[i])
+	}
+	return s.trades, s.filled
+}
+
+// rollback undoes a staged match: every level it traded at is rebuilt in its
+// original order and state, and the aggressor is reset.
+func (ob *OrderBook) rollback(s *stagedFill) {
+	ob.staged = nil
+	for i := len(s.levels) - 1; i >= 0; i-- {
+		ob.restoreLevel(s.levels[i])
+	}
+	s.order.FilledQuantity = s.filledQuantity
+	s.order.CancelReason = s.cancelReason
+	ob.dirtyBids, ob.dirtyAsks = s.dirtyBids, s.dirtyAsks
+}
+
+func (ob *OrderBook) restoreLevel(snap levelSnapshot) {
+	priceMap, tree := ob.askPriceMap, ob.asks
+	if snap.side == Buy {
+		priceMap, tree = ob.bidPriceMap, ob.bids
+	}
+	level := snap.level
+	level.Orders.Init()
+	level.hidden = nil
+	for i, order := range snap.orders {
+		state := snap.states[i]
+		order.FilledQuantity, order.VisibleQuantity, order.Status = state.filledQuantity, state.visibleQuantity, state.status
+		if _, ok := ob.orderMap[order.ID]; !ok {
+			// Filled and removed by the match; re-index it as addOrder would
+			if order.ExpiresAt > 0 {
+				ob.expiring[order.ID] = order
+			}
+			if order.IsPegged() {
+				ob.pegged[order.ID] = order
+			}
+			ob.resting++
+		}
+		level.AddOrder(order)
+		ob.orderMap[order.ID] = order.element
+	}
+	if _, ok := priceMap[level.Price]; !ok {
+		priceMap[level.Price] = level
+		tree.ReplaceOrInsert(level)
+	}
+}
@@ -0,0 +1,61 @@
+package engine
+
+import "fmt"
+
+// HaltMode controls what a halted book does with new orders.
+type HaltMode string
+
+const (
+	// HaltReject rejects new orders and amendments until the book resumes.
+	HaltReject HaltMode = "REJECT"
+	// HaltQueue books new limit orders without matching; Resume then
+	// uncrosses the book like an auction before continuous trading restarts.
+	HaltQueue HaltMode = "QUEUE"
+)
+
+// Halt stops matching in a symbol. Cancels are always allowed while halted.
+func (me *MatchingEngine) Halt(symbol string, mode HaltMode) error {
+	if mode != HaltReject && mode != HaltQueue {
+		return fmt.Errorf("invalid halt mode %q", mode)
+	}
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if book.phase != Continuous {
+		return rejectf(ErrInvalidState, "cannot halt %s: book is %s", symbol, book.phase)
+	}
+	if err := me.logWAL(WALEntry{Op: WALHalt, Symbol: symbol, Mode: string(mode)}); err != nil {
+		return err
+	}
+	book.phase = Halted
+	book.haltMode = mode
+	return nil
+}
+
+// Resume restarts continuous trading in a halted symbol. Orders queued during
+// a HaltQueue halt are uncrossed first; the resulting trades are returned.
+// Books halted by the kill switch only resume through Reset.
+func (me *MatchingEngine) Resume(symbol string) ([]Trade, error) {
+	if me.killed.Load() {
+		return nil, rejectf(ErrInvalidState, "cannot resume %s: kill switch is engaged", symbol)
+	}
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if book.phase != Halted {
+		return nil, rejectf(ErrInvalidState, "cannot resume %s: book is %s", symbol, book.phase)
+	}
+	if err := me.logWAL(WALEntry{Op: WALResume, Symbol: symbol}); err != nil {
+		return nil, err
+	}
+	mode := book.haltMode
+	book.haltMode = ""
+	if mode == HaltQueue {
+		_, trades := me.reopen(book)
+		return trades, nil
+	}
+	book.phase = Continuous
+	return nil, nil
+}
@@ -0,0 +1,51 @@
+package engine
+
+// orderHistoryCapacity bounds the events kept per order. An order that
+// outgrows it, with thousands of partial fills, keeps its first event (its
+// acceptance) and the most recent ones; the sequence gap shows the cut.
+const orderHistoryCapacity = 1000
+
+// record files ev under every order it touches: the order of an order
+// event, and both sides of a trade. l.mu is held.
+func (l *eventLog) record(ev OrderEvent) {
+	if l.history == nil {
+		l.history = make(map[string][]OrderEvent)
+	}
+	switch {
+	case ev.Order != nil:
+		l.appendHistory(ev.Order.ID, ev)
+	case ev.Trade != nil:
+		l.appendHistory(ev.Trade.AggressorOrderID, ev)
+		l.appendHistory(ev.Trade.RestingOrderID, ev)
+	}
+}
+
+// appendHistory trims an order's history back to capacity once it doubles,
+// keeping appends amortized O(1) like the trade log.
+func (l *eventLog) appendHistory(orderID string, ev OrderEvent) {
+	h := append(l.history[orderID], ev)
+	if len(h) >= 2*orderHistoryCapacity {
+		h = append(h[:1], h[len(h)-orderHistoryCapacity+1:]...)
+	}
+	l.history[orderID] = h
+}
+
+// GetOrderHistory returns every event that touched an order, oldest first:
+// its acceptance, each trade it took part in, amendments and the cancel or
+// expiry that ended it. A filled order's history ends with its last trade.
+// The history outlives the order's time in the book, but is kept in memory
+// only: it is not part of snapshots and starts empty after a restore.
+func (me *MatchingEngine) GetOrderHistory(orderID string) ([]OrderEvent, error) {
+	if _, ok := me.storedOrder(orderID); !ok {
+		return nil, ErrOrderNotFound
+	}
+	me.events.mu.Lock()
+	defer me.events.mu.Unlock()
+	h := me.events.history[orderID]
+	if len(h) > orderHistoryCapacity {
+		h = append(h[:1:1], h[len(h)-orderHistoryCapacity+1:]...)
+	} else {
+		h = append([]OrderEvent(nil), h...)
+	}
+	return h, nil
+}
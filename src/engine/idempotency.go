@@ -0,0 +1,106 @@
+package engine
+
+import "maps"
+
+// --- Idempotent Submission ---
+//
+// Order IDs double as idempotency keys. Resubmitting an ID that is already in
+// the order store executes nothing: if the terms match, the caller's order is
+// overwritten with the stored order's current state (a clean retry);
+// otherwise the submission is rejected as a conflict. Terms are compared with
+// the stored order as it is now, so retrying after an amendment conflicts.
+// An ID is claimed before its order is processed (see claimOrderID), so
+// the same new ID sent to two symbols at once is accepted only once.
+
+// storedOrder looks an order up in the global store.
+func (me *MatchingEngine) storedOrder(id string) (*Order, bool) {
+	me.orderStoreMutex.RLock()
+	defer me.orderStoreMutex.RUnlock()
+	order, ok := me.orderStore[id]
+	return order, ok
+}
+
+// claimOrderID reserves id for an order about to be processed under its
+// book lock. Book locks alone cannot keep two books from admitting the same
+// new ID at once, each then overwriting the other in the order store, so
+// the ID is taken in the store's own lock. It returns false, with the
+// stored order if there is one, when the ID is stored or claimed by a
+// submission still in progress. A successful claim must be released with
+// releaseOrderID once the order is stored or rejected.
+func (me *MatchingEngine) claimOrderID(id string) (*Order, bool) {
+	me.orderStoreMutex.Lock()
+	defer me.orderStoreMutex.Unlock()
+	if order, ok := me.orderStore[id]; ok {
+		return order, false
+	}
+	if _, ok := me.claimedIDs[id]; ok {
+		return nil, false
+	}
+	me.claimedIDs[id] = struct{}{}
+	return nil, true
+}
+
+// releaseOrderID drops a claim taken by claimOrderID.
+func (me *MatchingEngine) releaseOrderID(id string) {
+	me.orderStoreMutex.Lock()
+	delete(me.claimedIDs, id)
+	me.orderStoreMutex.Unlock()
+}
+
+// resubmit answers a submission whose ID is already taken; existing is nil
+// while the ID is still claimed by a submission on another book. The caller
+// holds the book lock for order.Symbol, which also guards existing whenever
+// the symbols match.
+func resubmit(existing, order *Order) (ProcessOrderResponse, error) {
+	if existing == nil || !sameTerms(existing, order) {
+		return ProcessOrderResponse{}, rejectf(ErrDuplicateOrderID, "duplicate order id %s: conflicts with an existing order", order.ID)
+	}
+	*order = *existing
+	order.element = nil
+	return ProcessOrderResponse{
+		OrderInBook:   existing.element != nil,
+		IsMarketOrder: existing.Type == Market,
+		Resubmitted:   true,
+	}, nil
+}
+
+// sameTerms reports whether order asks for the same thing as existing.
+// Symbol is compared first: it never changes, and existing's other fields
+// are only safe to read under its own book lock.
+func sameTerms(existing, order *Order) bool {
+	if existing.Symbol != order.Symbol {
+		return false
+	}
+	if !existing.IsNotional() && existing.Quantity != order.Quantity {
+		return false
+	}
+	return sameType(existing, order) &&
+		existing.AccountID == order.AccountID &&
+		existing.Side == order.Side &&
+		(existing.Price == order.Price || existing.IsPegged() || order.Type == MarketToLimit) && // A peg's or a converted order's price is the engine's
+		existing.PegReference == order.PegReference &&
+		existing.PegOffset == order.PegOffset &&
+		existing.StopPrice == order.StopPrice &&
+		existing.ProtectionPrice == order.ProtectionPrice &&
+		existing.Notional == order.Notional &&
+		existing.DisplayQuantity == order.DisplayQuantity &&
+		existing.TimeInForce == order.TimeInForce &&
+		existing.PostOnly == order.PostOnly &&
+		existing.Hidden == order.Hidden &&
+		existing.AllOrNone == order.AllOrNone &&
+		existing.NoTradeThrough == order.NoTradeThrough &&
+		maps.Equal(existing.ClientMetadata, order.ClientMetadata) &&
+		existing.ExpiresAt == order.ExpiresAt
+}
+
+// sameType allows for a conditional order that has since triggered into a
+// market or limit order.
+func sameType(existing, order *Order) bool {
+	if existing.Type == order.Type {
+		return true
+	}
+	if order.Type == MarketToLimit {
+		return existing.Type == Limit // Converted on arrival
+	}
+	return order.IsConditional() && existing.Type == order.triggeredType()
+}
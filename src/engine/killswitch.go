@@ -0,0 +1,87 @@
+package engine
+
+// KillSwitch halts every symbol and cancels every open order, resting and
+// armed stops alike, returning how many were cancelled. Until Reset, every
+// book (including ones created afterwards) rejects new orders, amendments
+// and replacements, and Resume is refused. The engine is marked killed
+// before any book is touched, then books are halted and flattened one at a
+// time in symbol order, so it cannot deadlock with other multi-book
+// operations and no order can slip into a book after it was flattened.
+func (me *MatchingEngine) KillSwitch() (int, error) {
+	me.killMutex.Lock()
+	defer me.killMutex.Unlock()
+
+	if err := me.logWAL(WALEntry{Op: WALKill}); err != nil {
+		return 0, err
+	}
+	// Under createMutex so a book created concurrently either sees the flag
+	// or is already listed by allBooks below
+	me.createMutex.Lock()
+	me.killed.Store(true)
+	me.createMutex.Unlock()
+
+	var cancelled int
+	for _, sb := range me.allBooks() {
+		cancelled += me.flatten(sb)
+	}
+	return cancelled, nil
+}
+
+// flatten halts one book and cancels all of its open orders.
+func (me *MatchingEngine) flatten(sb symbolBook) int {
+	sb.lock.Lock()
+	defer sb.lock.Unlock()
+
+	book := sb.book
+	if book.phase == Halted {
+		book.operatorHalt = book.haltMode
+	}
+	book.phase = Halted
+	book.haltMode = HaltReject
+	orders := book.openOrders("")
+	for _, order := range orders {
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonKillSwitch
+		book.CancelOrder(order.ID)
+		book.emitOrder(EventCancelled, order)
+	}
+	me.publishDepth(book)
+	return len(orders)
+}
+
+// Reset lifts the kill switch and returns every book it halted to continuous
+// trading. Books an operator had halted before the kill switch stay halted,
+// in the mode they were halted in, until Resume.
+func (me *MatchingEngine) Reset() error {
+	me.killMutex.Lock()
+	defer me.killMutex.Unlock()
+
+	if !me.killed.Load() {
+		return rejectf(ErrInvalidState, "cannot reset: kill switch is not engaged")
+	}
+	if err := me.logWAL(WALEntry{Op: WALReset}); err != nil {
+		return err
+	}
+	me.createMutex.Lock()
+	me.killed.Store(false)
+	me.createMutex.Unlock()
+
+	for _, sb := range me.allBooks() {
+		sb.lock.Lock()
+		switch {
+		case sb.book.operatorHalt != "":
+			sb.book.haltMode = sb.book.operatorHalt
+			sb.book.operatorHalt = ""
+		case sb.book.phase == Halted:
+			sb.book.phase = Continuous
+			sb.book.haltMode = ""
+		}
+		sb.lock.Unlock()
+	}
+	return nil
+}
+
+// Killed reports whether the kill switch is engaged.
+func (me *MatchingEngine) Killed() bool {
+	return me.killed.Load()
+}
@@ -0,0 +1,69 @@
+package engine
+
+import "sort"
+
+// --- Symbol Listing ---
+//
+// A listed symbol is one with a registered SymbolConfig. ListSymbol and
+// DelistSymbol manage the set explicitly; ConfigureSymbol lists a symbol
+// too, or changes an existing listing. With strict symbols on, orders for
+// unlisted symbols are refused before any book is created for them.
+// Without it, unlisted symbols, delisted ones included, trade under default
+// rules.
+
+// ListSymbol lists a new symbol with the given configuration. Listing a
+// symbol twice is an error; use ConfigureSymbol to change a listing.
+func (me *MatchingEngine) ListSymbol(cfg SymbolConfig) error {
+	return me.registerSymbol(cfg, false)
+}
+
+// DelistSymbol removes a symbol's listing and cancels every open order in
+// its book, resting and armed stops alike, with cancel reason DELISTED. The
+// book keeps its trade history. Orders submitted concurrently either land
+// before the delisting, and are cancelled with the rest, or are checked
+// against the listing after it. It returns copies of the cancelled orders.
+func (me *MatchingEngine) DelistSymbol(symbol string) ([]*Order, error) {
+	me.configMutex.RLock()
+	_, listed := me.symbolConfigs[symbol]
+	me.configMutex.RUnlock()
+	if !listed {
+		return nil, rejectf(ErrUnknownSymbol, "unknown symbol: %s", symbol)
+	}
+
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	me.configMutex.Lock()
+	delete(me.symbolConfigs, symbol)
+	me.configMutex.Unlock()
+
+	var cancelled []*Order
+	for _, order := range book.openOrders("") {
+		if err := me.logWAL(WALEntry{Op: WALCancel, OrderID: order.ID, Reason: ReasonDelisted}); err != nil {
+			me.publishDepth(book)
+			return cancelled, err
+		}
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonDelisted
+		book.CancelOrder(order.ID)
+		book.emitOrder(EventCancelled, order)
+		orderCopy := *order
+		cancelled = append(cancelled, &orderCopy)
+	}
+	me.publishDepth(book)
+	return cancelled, nil
+}
+
+// ListedSymbols returns the configuration of every listed symbol, sorted by
+// symbol.
+func (me *MatchingEngine) ListedSymbols() []SymbolConfig {
+	me.configMutex.RLock()
+	configs := make([]SymbolConfig, 0, len(me.symbolConfigs))
+	for _, cfg := range me.symbolConfigs {
+		configs = append(configs, cfg)
+	}
+	me.configMutex.RUnlock()
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Symbol < configs[j].Symbol })
+	return configs
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// WithRequestID returns a context carrying id, which the engine attaches to
+// the log lines of work done under that context.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID stored in ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// SetLogger sets the structured logger for order and trade events. The
+// default discards everything. Call it before the engine starts serving.
+func (me *MatchingEngine) SetLogger(logger *slog.Logger) {
+	me.logger = logger
+}
+
+// logSubmit logs the outcome of a submission and one line per resulting
+// trade, tagged with the request ID from ctx. Nothing is built unless info
+// logging is enabled.
+func (me *MatchingEngine) logSubmit(ctx context.Context, order *Order, orderType OrderType, response ProcessOrderResponse, err error, start time.Time) {
+	if !me.logger.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+	requestID := RequestID(ctx)
+	attrs := []slog.Attr{
+		slog.String("request_id", requestID),
+		slog.String("order_id", order.ID),
+		slog.String("symbol", order.Symbol),
+		slog.String("side", string(order.Side)),
+		slog.String("type", string(orderType)),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		me.logger.LogAttrs(ctx, slog.LevelInfo, "order rejected", attrs...)
+		return
+	}
+	attrs = append(attrs,
+		slog.String("status", string(order.Status)),
+		slog.Int64("filled_quantity", order.FilledQuantity),
+		slog.Int("trades", len(response.Trades)+len(response.TriggeredTrades)),
+	)
+	me.logger.LogAttrs(ctx, slog.LevelInfo, "order submitted", attrs...)
+
+	for _, trades := range [][]Trade{response.Trades, response.TriggeredTrades} {
+		for _, trade := range trades {
+			me.logger.LogAttrs(ctx, slog.LevelInfo, "trade executed",
+				slog.String("request_id", requestID),
+				slog.String("trade_id", trade.TradeID),
+				slog.String("symbol", trade.Symbol),
+				slog.Int64("price", trade.Price),
+				slog.Int64("quantity", trade.Quantity),
+				slog.String("aggressor_order_id", trade.AggressorOrderID),
+				slog.String("resting_order_id", trade.RestingOrderID),
+			)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package engine
+
+// --- Market-To-Limit Orders ---
+//
+// A MarketToLimit order takes the liquidity at the best opposite price like
+// a market order, then rests whatever is left as a limit order at that
+// price instead of cancelling it. ProcessOrder converts it on arrival: it
+// becomes a Limit order priced at the best opposite level, hidden orders
+// included, so it trades at that one price and rests the remainder where
+// it last traded. With nothing on the opposite side there is no price to
+// take, and it is rejected with INSUFFICIENT_LIQUIDITY like a market order.
+// Once converted it is an ordinary limit order: it reports type LIMIT, and
+// amends, expiry and snapshots treat it as one. It cannot be FOK, which
+// would fill at the best price or not at all, nor enter a collecting book,
+// where there is nothing to take.
+
+// marketToLimitPrice returns the price a market-to-limit order would convert
+// at, and false if the opposite side is empty.
+func (ob *OrderBook) marketToLimitPrice(order *Order) (int64, bool) {
+	return ob.bestOpposite(order.Side)
+}
+
+// admitMarketToLimit rejects a market-to-limit order with nothing to take,
+// or whose conversion price is outside the band, through the reference or
+// over its notional limit.
+func (ob *OrderBook) admitMarketToLimit(order *Order) (string, error) {
+	price, ok := ob.marketToLimitPrice(order)
+	if !ok {
+		return rejectInsufficientLiquidity, rejectf(ErrInsufficientLiquidity, "insufficient liquidity: no opposite orders for a market-to-limit order to take")
+	}
+	if err := ob.checkBand(price); err != nil {
+		return rejectPriceBand, err
+	}
+	if !ob.withinReference(order, price) {
+		return rejectTradeThrough, rejectf(ErrTradeThrough, "order would trade through the reference at best opposite price %d", price)
+	}
+	if err := ob.config.checkMaxNotional(price, order.Quantity); err != nil {
+		return rejectInvalid, err
+	}
+	return "", nil
+}
+
+// convertToLimit makes a market-to-limit order a limit order at the best
+// opposite price. It reports false, leaving the order unchanged, if the
+// opposite side is empty.
+func (ob *OrderBook) convertToLimit(order *Order) bool {
+	price, ok := ob.marketToLimitPrice(order)
+	if !ok {
+		return false
+	}
+	order.Type = Limit
+	order.Price = price
+	return true
+}
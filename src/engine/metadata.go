@@ -0,0 +1,192 @@
+package engine
+
+import "sort"
+
+// --- Client Metadata ---
+//
+// Orders may carry ClientMetadata, string pairs such as a strategy name or
+// a parent order ID, that the engine stores and echoes but never matches
+// on. It travels with the order into its events, order history, the WAL and
+// snapshots. A retry of an order ID must carry the same metadata.
+//
+// Each pair is also a tag that GetOrdersByTag and CancelByTag select open
+// orders by. An index beside the order store maps every tag to the orders
+// carrying it: orders are added as they are stored and dropped when
+// cancelled by ID or by tag; orders that close any other way (filled,
+// expired, cancelled in bulk) are pruned the next time their tag is looked
+// up. Loading a snapshot rebuilds it.
+
+const (
+	MaxMetadataEntries = 16   // Pairs per order
+	MaxMetadataBytes   = 1024 // Keys and values together
+)
+
+// checkMetadata rejects metadata over the size bounds or with an empty key.
+func checkMetadata(order *Order) error {
+	if len(order.ClientMetadata) > MaxMetadataEntries {
+		return rejectf(ErrInvalidOrder, "invalid order: client_metadata has %d entries, at most %d allowed", len(order.ClientMetadata), MaxMetadataEntries)
+	}
+	size := 0
+	for key, value := range order.ClientMetadata {
+		if key == "" {
+			return rejectf(ErrInvalidOrder, "invalid order: client_metadata keys must not be empty")
+		}
+		size += len(key) + len(value)
+	}
+	if size > MaxMetadataBytes {
+		return rejectf(ErrInvalidOrder, "invalid order: client_metadata is %d bytes, at most %d allowed", size, MaxMetadataBytes)
+	}
+	return nil
+}
+
+// MetadataTag selects orders by client metadata: those with Key set to
+// Value, or with Value under any key when Key is empty.
+type MetadataTag struct {
+	Key   string
+	Value string
+}
+
+// tags returns the index entries for an order's metadata: each pair, and
+// each value under any key.
+func (o *Order) tags() []MetadataTag {
+	tags := make([]MetadataTag, 0, 2*len(o.ClientMetadata))
+	for key, value := range o.ClientMetadata {
+		tags = append(tags, MetadataTag{Key: key, Value: value}, MetadataTag{Value: value})
+	}
+	return tags
+}
+
+// tagIndex maps each tag to the IDs of the orders carrying it. The engine's
+// is guarded by orderStoreMutex.
+type tagIndex map[MetadataTag]map[string]struct{}
+
+func (ix tagIndex) add(order *Order) {
+	for _, tag := range order.tags() {
+		ids, ok := ix[tag]
+		if !ok {
+			ids = make(map[string]struct{})
+			ix[tag] = ids
+		}
+		ids[order.ID] = struct{}{}
+	}
+}
+
+func (ix tagIndex) remove(order *Order) {
+	for _, tag := range order.tags() {
+		delete(ix[tag], order.ID)
+		if len(ix[tag]) == 0 {
+			delete(ix, tag)
+		}
+	}
+}
+
+// storeOrder adds an order to the global store and the tag index.
+func (me *MatchingEngine) storeOrder(order *Order) {
+	me.orderStoreMutex.Lock()
+	me.orderStore[order.ID] = order
+	me.tags.add(order)
+	me.orderStoreMutex.Unlock()
+}
+
+// untagOrders drops closed orders from the tag index.
+func (me *MatchingEngine) untagOrders(orders []*Order) {
+	if len(orders) == 0 {
+		return
+	}
+	me.orderStoreMutex.Lock()
+	for _, order := range orders {
+		me.tags.remove(order)
+	}
+	me.orderStoreMutex.Unlock()
+}
+
+// taggedOrders returns the indexed orders carrying tag, grouped by symbol
+// in symbol order, each group oldest first. They may have closed since.
+func (me *MatchingEngine) taggedOrders(tag MetadataTag) [][]*Order {
+	me.orderStoreMutex.RLock()
+	bySymbol := make(map[string][]*Order)
+	for id := range me.tags[tag] {
+		if order, ok := me.orderStore[id]; ok {
+			bySymbol[order.Symbol] = append(bySymbol[order.Symbol], order)
+		}
+	}
+	me.orderStoreMutex.RUnlock()
+
+	groups := make([][]*Order, 0, len(bySymbol))
+	for _, orders := range bySymbol {
+		sort.Slice(orders, func(i, j int) bool { return orders[i].Sequence < orders[j].Sequence })
+		groups = append(groups, orders)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0].Symbol < groups[j][0].Symbol })
+	return groups
+}
+
+// GetOrdersByTag returns copies of every open (ACCEPTED or PARTIAL_FILL)
+// order carrying tag, armed stops included, by symbol and then oldest
+// first. Each book is read under its own lock.
+func (me *MatchingEngine) GetOrdersByTag(tag MetadataTag) []*Order {
+	orders := []*Order{}
+	var closed []*Order
+	for _, group := range me.taggedOrders(tag) {
+		_, lock := me.getBookAndLock(group[0].Symbol)
+		lock.RLock()
+		for _, order := range group {
+			if order.Status == StatusFilled || order.Status == StatusCancelled {
+				closed = append(closed, order)
+				continue
+			}
+			orderCopy := *order
+			orderCopy.element = nil
+			orders = append(orders, &orderCopy)
+		}
+		lock.RUnlock()
+	}
+	me.untagOrders(closed)
+	return orders
+}
+
+// CancelByTag cancels every open order carrying tag, armed stops included,
+// with cancel_reason CANCEL_TAG. Like CancelAll, each book is cancelled
+// atomically under its own lock, one book at a time in symbol order. It
+// returns copies of the cancelled orders.
+func (me *MatchingEngine) CancelByTag(tag MetadataTag) ([]*Order, error) {
+	var cancelled []*Order
+	for _, group := range me.taggedOrders(tag) {
+		orders, err := me.cancelTaggedInBook(group)
+		cancelled = append(cancelled, orders...)
+		if err != nil {
+			return cancelled, err
+		}
+	}
+	return cancelled, nil
+}
+
+func (me *MatchingEngine) cancelTaggedInBook(group []*Order) ([]*Order, error) {
+	book, lock := me.getBookAndLock(group[0].Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var cancelled, closed []*Order
+	defer func() {
+		me.publishDepth(book)
+		me.untagOrders(closed)
+	}()
+	for _, order := range group {
+		if order.Status == StatusFilled || order.Status == StatusCancelled {
+			closed = append(closed, order)
+			continue
+		}
+		if err := me.logWAL(WALEntry{Op: WALCancel, OrderID: order.ID, Reason: ReasonCancelTag}); err != nil {
+			return cancelled, err
+		}
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonCancelTag
+		book.CancelOrder(order.ID)
+		book.emitOrder(EventCancelled, order)
+		closed = append(closed, order)
+		orderCopy := *order
+		orderCopy.element = nil
+		cancelled = append(cancelled, &orderCopy)
+	}
+	return cancelled, nil
+}
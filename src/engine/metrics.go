@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Rejection reasons reported on ome_orders_rejected_total.
+const (
+	rejectInvalid               = "invalid"
+	rejectPostOnly              = "post_only"
+	rejectInsufficientLiquidity = "insufficient_liquidity"
+	rejectWAL                   = "wal"
+	rejectCollecting            = "collecting"
+	rejectHalted                = "halted"
+	rejectPriceBand             = "price_band"
+	rejectDuplicate             = "duplicate"
+	rejectBookFull              = "book_full"
+	rejectClosed                = "market_closed"
+	rejectTradeThrough          = "trade_through"
+	rejectSelfCross             = "self_cross"
+)
+
+// Cancellation reasons reported on ome_orders_cancelled_total.
+const (
+	cancelRequested  = "cancel"
+	cancelAll        = "cancel_all"
+	cancelLevel      = "cancel_level"
+	cancelTag        = "cancel_tag"
+	cancelExpired    = "expired"
+	cancelKillSwitch = "kill_switch"
+	cancelDelisted   = "delisted"
+)
+
+// engineMetrics holds the engine's Prometheus collectors. Each engine has its
+// own registry so several engines (e.g. in tests) can coexist.
+type engineMetrics struct {
+	registry        *prometheus.Registry
+	ordersSubmitted *prometheus.CounterVec
+	ordersRejected  *prometheus.CounterVec
+	ordersCancelled *prometheus.CounterVec
+	tradesExecuted  *prometheus.CounterVec
+	staleOrders     *prometheus.CounterVec
+	submitLatency   prometheus.Histogram
+}
+
+func newEngineMetrics(me *MatchingEngine) *engineMetrics {
+	m := &engineMetrics{
+		registry: prometheus.NewRegistry(),
+		ordersSubmitted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ome_orders_submitted_total",
+			Help: "Orders submitted, by order type and side.",
+		}, []string{"type", "side"}),
+		ordersRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ome_orders_rejected_total",
+			Help: "Orders rejected at submission, by reason.",
+		}, []string{"reason"}),
+		ordersCancelled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ome_orders_cancelled_total",
+			Help: "Open orders cancelled, by reason.",
+		}, []string{"reason"}),
+		tradesExecuted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ome_trades_executed_total",
+			Help: "Trades executed, by symbol.",
+		}, []string{"symbol"}),
+		staleOrders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ome_stale_orders_total",
+			Help: "Resting orders reported older than the max order age, by symbol.",
+		}, []string{"symbol"}),
+		submitLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ome_order_submit_duration_seconds",
+			Help:    "Time to submit an order, including waiting for the book lock.",
+			Buckets: prometheus.ExponentialBuckets(0.000_01, 4, 10), // 10µs .. ~2.6s
+		}),
+	}
+	m.registry.MustRegister(
+		m.ordersSubmitted,
+		m.ordersRejected,
+		m.ordersCancelled,
+		m.tradesExecuted,
+		m.staleOrders,
+		m.submitLatency,
+		restingCollector{me: me},
+		collectors.NewGoCollector(),
+	)
+	return m
+}
+
+func (m *engineMetrics) observeSubmit(orderType OrderType, side Side, start time.Time) {
+	m.ordersSubmitted.WithLabelValues(string(orderType), string(side)).Inc()
+	m.submitLatency.Observe(time.Since(start).Seconds())
+}
+
+func (m *engineMetrics) reject(reason string) {
+	m.ordersRejected.WithLabelValues(reason).Inc()
+}
+
+func (m *engineMetrics) cancelled(reason string, n int) {
+	if n > 0 {
+		m.ordersCancelled.WithLabelValues(reason).Add(float64(n))
+	}
+}
+
+var restingOrdersDesc = prometheus.NewDesc(
+	"ome_resting_orders",
+	"Orders currently resting in the book, by symbol.",
+	[]string{"symbol"}, nil,
+)
+
+var oldestOrderAgeDesc = prometheus.NewDesc(
+	"ome_oldest_order_age_seconds",
+	"Age of the oldest order resting in the book, by symbol; 0 when empty.",
+	[]string{"symbol"}, nil,
+)
+
+// restingCollector reports live resting order counts and oldest order ages,
+// reading each book under its read lock at scrape time.
+type restingCollector struct {
+	me *MatchingEngine
+}
+
+func (c restingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- restingOrdersDesc
+	ch <- oldestOrderAgeDesc
+}
+
+func (c restingCollector) Collect(ch chan<- prometheus.Metric) {
+	now := c.me.now()
+	for _, sb := range c.me.allBooks() {
+		sb.lock.RLock()
+		n := len(sb.book.orderMap)
+		age := sb.book.oldestOrderAge(now)
+		sb.lock.RUnlock()
+		ch <- prometheus.MustNewConstMetric(restingOrdersDesc, prometheus.GaugeValue, float64(n), sb.symbol)
+		ch <- prometheus.MustNewConstMetric(oldestOrderAgeDesc, prometheus.GaugeValue, age.Seconds(), sb.symbol)
+	}
+}
+
+// Metrics returns the engine's Prometheus registry for scraping.
+func (me *MatchingEngine) Metrics() prometheus.Gatherer {
+	return me.metrics.registry
+}
@@ -0,0 +1,46 @@
+package engine
+
+// NextFill reports, without changing anything, the very next fill an order
+// on side with the given limit would get: the best opposite level it
+// crosses and the quantity available there. Pass math.MaxInt64 (buy) or
+// math.MinInt64 (sell) as the limit to ask for a market order. The quantity
+// includes hidden orders and iceberg reserve, which would trade all the
+// same, but not AON orders, which only trade with an order large enough to
+// take them whole; a level holding only AON orders is skipped. The price is
+// the resting price. ok is false if nothing crosses, or if the book is
+// collecting orders without matching. Like the other OrderBook methods it
+// is not thread-safe; see MatchingEngine.NextFill.
+func (ob *OrderBook) NextFill(side Side, limitPrice int64) (price, quantity int64, ok bool) {
+	if ob.collecting() {
+		return 0, 0, false
+	}
+	tree := ob.asks
+	if side == Sell {
+		tree = ob.bids
+	}
+	probe := &Order{Side: side, Price: limitPrice}
+	tree.Ascend(func(pl *PriceLevel) bool {
+		if !crosses(probe, pl.Price) {
+			return false
+		}
+		for e := pl.Orders.Front(); e != nil; e = e.Next() {
+			if order := e.Value.(*Order); !order.AllOrNone {
+				quantity += order.RemainingQuantity()
+			}
+		}
+		if quantity == 0 {
+			return true // Only AON orders here
+		}
+		price, ok = pl.Price, true
+		return false
+	})
+	return price, quantity, ok
+}
+
+// NextFill is OrderBook.NextFill for symbol's book, under its read lock.
+func (me *MatchingEngine) NextFill(symbol string, side Side, limitPrice int64) (price, quantity int64, ok bool) {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.NextFill(side, limitPrice)
+}
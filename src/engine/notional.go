@@ -0,0 +1,105 @@
+package engine
+
+import "math"
+
+// --- Notional Orders ---
+//
+// A notional market order ("buy $1000 of AAPL") carries a cash amount instead
+// of a quantity. It walks the opposite side like any market order, but each
+// level is capped by what the remaining cash can buy there, rounded down to
+// the lot size. Quantity starts at zero and grows as the order fills, so once
+// matched Quantity == FilledQuantity and the unspent cash is reported as
+// LeftoverNotional.
+//
+// Cash is in price units per whole unit of quantity. For symbols with
+// QuantityDecimals > 0 the cost of a fill is price*qty/10^decimals; the walk
+// keeps that product exact and only SpentNotional is rounded (up by
+// default, so the leftover never overstates what is left; see rounding.go).
+
+// checkNotional validates the fields that differ for a notional order.
+func (cfg SymbolConfig) checkNotional(order *Order) error {
+	if order.Notional < 0 {
+		return rejectf(ErrInvalidOrder, "invalid order: notional must be positive")
+	}
+	if order.Type != Market {
+		return rejectf(ErrInvalidOrder, "invalid order: notional requires a MARKET order")
+	}
+	if cfg.AllowNegativePrice {
+		// Cash cannot be converted to quantity at a price of zero or below
+		return rejectf(ErrInvalidOrder, "invalid order: notional orders are not supported for %s, which allows negative prices", cfg.Symbol)
+	}
+	if order.Quantity != 0 || order.DisplayQuantity != 0 {
+		return rejectf(ErrInvalidOrder, "invalid order: notional and quantity are mutually exclusive")
+	}
+	if order.Notional > math.MaxInt64/pow10[cfg.QuantityDecimals] {
+		return rejectf(ErrInvalidOrder, "invalid order: notional %d is too large", order.Notional)
+	}
+	return nil
+}
+
+// processNotional matches a notional market order until its cash runs out,
+// the opposite side is exhausted, or a protection price or price band stops
+// it. Whatever cash is left is not booked.
+func (ob *OrderBook) processNotional(order *Order) ProcessOrderResponse {
+	scale := pow10[ob.config.QuantityDecimals]
+	budget := order.Notional * scale // In price x quantity units, like spent
+	var spent int64
+
+	tree := ob.asks
+	if order.Side == Sell {
+		tree = ob.bids
+	}
+	low, high := ob.band()
+	trades := []Trade{}
+	filledOrders := []*Order{}
+	exhausted := false
+
+	for tree.Len() > 0 && !selfMatchStopped(order) {
+		level, _ := tree.Min()
+		if !withinProtection(order, level.Price) || !withinBand(order, level.Price, low, high) {
+			break
+		}
+		affordable := (budget - spent) / level.Price
+		if ob.config.LotSize > 0 {
+			affordable -= affordable % ob.config.LotSize
+		}
+		if affordable == 0 {
+			exhausted = true
+			break
+		}
+		// Let the ordinary level matcher fill up to what the cash buys here
+		before := order.FilledQuantity
+		order.Quantity += affordable
+		trades, filledOrders = ob.matchLevel(order, level, trades, filledOrders)
+		spent += (order.FilledQuantity - before) * level.Price
+		order.Quantity = order.FilledQuantity
+	}
+	if spent == budget {
+		exhausted = true
+	}
+	order.SpentNotional = ob.config.rounding.div(spent, scale, true)
+
+	reason := ReasonUnfilled
+	if selfMatchStopped(order) {
+		reason = ReasonSelfMatch
+	}
+	switch {
+	case order.FilledQuantity == 0:
+		order.Status = StatusCancelled
+		order.CancelReason = reason
+	case exhausted:
+		order.Status = StatusFilled
+		order.CancelReason = ""
+	default:
+		order.Status = StatusPartialFill // Ran out of liquidity with cash to spare
+		order.CancelReason = reason
+	}
+	if order.Status != StatusFilled {
+		ob.emitOrder(EventCancelled, order)
+	}
+	return ProcessOrderResponse{
+		Trades:              trades,
+		FilledRestingOrders: filledOrders,
+		IsMarketOrder:       true,
+	}
+}
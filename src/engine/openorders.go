@@ -0,0 +1,23 @@
+package engine
+
+// GetOpenOrders returns copies of every live (ACCEPTED or PARTIAL_FILL) order
+// for the given account and symbol; empty filters match everything. Orders
+// come back by symbol, then in book order (bids best price first, asks, then
+// armed stops), so the listing is stable for pagination. Each book is read
+// under its own lock.
+func (me *MatchingEngine) GetOpenOrders(accountID, symbol string) []*Order {
+	orders := []*Order{}
+	for _, sb := range me.allBooks() {
+		if symbol != "" && sb.symbol != symbol {
+			continue
+		}
+		sb.lock.RLock()
+		for _, order := range sb.book.openOrders(accountID) {
+			orderCopy := *order
+			orderCopy.element = nil
+			orders = append(orders, &orderCopy)
+		}
+		sb.lock.RUnlock()
+	}
+	return orders
+}
@@ -2,10 +2,10 @@ package engine
 
 import (
 	"container/list"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/btree"
-	"github.com/google/uuid"
 )
 
 // --- B-Tree Comparators ---
@@ -22,10 +22,15 @@ func BidsSort(a, b *PriceLevel) bool {
 
 // --- PriceLevel ---
 
-// PriceLevel is a FIFO queue of Orders at a specific price.
+// PriceLevel is a FIFO queue of Orders at a specific price. Displayed orders
+// queue ahead of hidden ones: the queue is every displayed order in time
+// priority followed by every hidden order in time priority, so FIFO matching
+// reaches hidden liquidity only once the displayed orders are exhausted.
 type PriceLevel struct {
 	Price  int64
 	Orders *list.List // Queue of *Order
+
+	hidden *list.Element // First hidden order in Orders, or nil
 }
 
 // NewPriceLevel creates a new PriceLevel queue
@@ -36,93 +41,287 @@ func NewPriceLevel(price int64) *PriceLevel {
 	}
 }
 
-// AddOrder adds an order to the back of the queue (FIFO).
+// AddOrder adds an order to the back of its part of the queue (FIFO): a
+// displayed order goes behind the other displayed orders but ahead of any
+// hidden one.
 func (pl *PriceLevel) AddOrder(order *Order) {
+	if !order.Hidden && pl.hidden != nil {
+		order.element = pl.Orders.InsertBefore(order, pl.hidden)
+		return
+	}
 	order.element = pl.Orders.PushBack(order)
+	if order.Hidden && pl.hidden == nil {
+		pl.hidden = order.element
+	}
+}
+
+// ReduceOrderQuantity lowers an order's total quantity in place, keeping its
+// position in the queue. It refuses increases, which must lose priority via
+// RemoveOrder and AddOrder, and any reduction that leaves nothing to rest:
+// the new quantity must exceed what is already filled.
+func (pl *PriceLevel) ReduceOrderQuantity(order *Order, newQty int64) error {
+	if newQty > order.Quantity {
+		return rejectf(ErrInvalidOrder, "invalid reduction: quantity %d exceeds current quantity %d", newQty, order.Quantity)
+	}
+	if newQty <= order.FilledQuantity {
+		return rejectf(ErrInvalidOrder, "invalid reduction: quantity %d must exceed filled quantity %d", newQty, order.FilledQuantity)
+	}
+	order.Quantity = newQty
+	if order.IsIceberg() && order.VisibleQuantity > order.RemainingQuantity() {
+		order.VisibleQuantity = order.RemainingQuantity()
+	}
+	return nil
 }
 
 // RemoveOrder removes a specific order from the queue.
 func (pl *PriceLevel) RemoveOrder(order *Order) {
 	if order.element != nil {
+		if order.element == pl.hidden {
+			pl.hidden = order.element.Next() // Hidden orders are contiguous
+		}
 		pl.Orders.Remove(order.element)
 		order.element = nil
 	}
 }
 
+// TotalQuantity sums the displayed quantity of every order at this price.
+// Iceberg reserve and hidden orders are not included.
+func (pl *PriceLevel) TotalQuantity() int64 {
+	var totalQuantity int64
+	for e := pl.Orders.Front(); e != nil; e = e.Next() {
+		totalQuantity += e.Value.(*Order).Displayed()
+	}
+	return totalQuantity
+}
+
 // --- OrderBook (Not Thread-Safe) ---
 
 // OrderBook manages the buy and sell orders for a single symbol.
 // It is NOT thread-safe and must be protected by a mutex.
 type OrderBook struct {
+	symbol string
+
 	bids *btree.BTreeG[*PriceLevel] // Max-heap (highest price first)
 	asks *btree.BTreeG[*PriceLevel] // Min-heap (lowest price first)
 
 	bidPriceMap map[int64]*PriceLevel
 	askPriceMap map[int64]*PriceLevel
 	orderMap    map[string]*list.Element
+	resting     int // Orders in the book, kept by addOrder and removeOrder
+
+	stops          []*Order // Armed stop orders, in arrival order
+	expiring       map[string]*Order // Resting or armed orders with an ExpiresAt
+	pegged         map[string]*Order // Resting pegged orders; see peg.go
+	lastTradePrice int64
+	lastTradeQty   int64
+	lastTradeTime  int64
+	lastTradeID    string
+	hasTraded      bool
+	sessionRolled  bool // No trade since RollSession; the ticker is empty
+
+	config       SymbolConfig   // Applied by the engine under the lock before matching
+	phase        TradingPhase   // Continuous matching, auction collection or halted
+	haltMode     HaltMode       // How a halted book treats new orders
+	operatorHalt HaltMode       // An operator halt the kill switch overrode; see Reset
+	reference    referenceQuote // External best bid and ask; see reference.go
+
+	trades  []Trade                  // Executed trades, oldest first (see recordTrade)
+	candles map[string]*candleSeries // OHLCV per interval, built lazily on first trade
+
+	positions map[string]*Position // Per account; see positions.go
+
+	// Levels changed since the last depth update, and the update sequence.
+	dirtyBids map[int64]struct{}
+	dirtyAsks map[int64]struct{}
+	seq       uint64
+	history   []DepthUpdate // Recent depth updates, oldest first; see diff.go
+
+	cached atomic.Pointer[OrderBookSnapshot] // Full-depth snapshot; see GetCachedSnapshot
+
+	events *eventLog // The engine's event sequence; nil outside an engine
+	ids    *idSource // The engine's ID generator; nil outside an engine
+
+	staged *stagedFill // Market or FOK match awaiting acceptance; see fillcheck.go
 }
 
-// NewOrderBook creates and initializes a new OrderBook.
-func NewOrderBook() *OrderBook {
+// NewOrderBook creates and initializes a new OrderBook for a symbol.
+func NewOrderBook(symbol string) *OrderBook {
 	return &OrderBook{
+		symbol:      symbol,
+		phase:       Continuous,
 		bids:        btree.NewG(2, BidsSort),
 		asks:        btree.NewG(2, AsksSort),
 		bidPriceMap: make(map[int64]*PriceLevel),
 		askPriceMap: make(map[int64]*PriceLevel),
 		orderMap:    make(map[string]*list.Element),
+		expiring:    make(map[string]*Order),
+		pegged:      make(map[string]*Order),
+		positions:   make(map[string]*Position),
+		dirtyBids:   make(map[int64]struct{}),
+		dirtyAsks:   make(map[int64]struct{}),
 	}
 }
 
-// checkMarketOrderLiquidity scans the book to find the total available quantity.
+// checkFillable scans the opposite side of the book to find how much of the
+// order could execute right now. Limit orders only count levels at or better
+// than their limit price; market orders count the whole side. Resting AON
+// orders the order would pass over do not count.
 // It returns (totalQuantity, isSufficient).
-func (ob *OrderBook) checkMarketOrderLiquidity(order *Order) (int64, bool) {
+func (ob *OrderBook) checkFillable(order *Order) (int64, bool) {
+	totalQuantity := ob.fillable(order, order.Quantity)
+	return totalQuantity, totalQuantity >= order.Quantity
+}
+
+// fillable is checkFillable for want units of the order, counting no
+// further once it has found them.
+func (ob *OrderBook) fillable(order *Order, want int64) int64 {
 	var totalQuantity int64 = 0
+	tree := ob.asks // Need to buy, so we check the asks (sellers)
+	if order.Side == Sell {
+		tree = ob.bids // Need to sell, so we check the bids (buyers)
+	}
+	alloc := ob.config.allocator()
+	tree.Ascend(func(pl *PriceLevel) bool {
+		if order.tradesAsLimit() && !crosses(order, pl.Price) {
+			return false
+		}
+		quantity, stop := alloc.fillable(ob, order, pl, want-totalQuantity)
+		totalQuantity += quantity
+		return !stop && totalQuantity < want
+	})
+	return totalQuantity
+}
+
+// full reports whether the book holds its configured maximum of orders.
+func (ob *OrderBook) full() bool {
+	return ob.config.MaxOrders > 0 && ob.resting >= ob.config.MaxOrders
+}
+
+// wouldRest reports whether an order, if admitted now, would leave quantity
+// in the book: anything booked by an auction, or a GTC or DAY limit order
+// the opposite side cannot fill completely.
+func (ob *OrderBook) wouldRest(order *Order) bool {
+	if ob.collecting() {
+		return !order.IsConditional()
+	}
+	if order.Type == MarketToLimit {
+		price, ok := ob.marketToLimitPrice(order)
+		if !ok {
+			return false
+		}
+		limit := *order
+		limit.Type, limit.Price = Limit, price
+		order = &limit
+	}
+	if !order.tradesAsLimit() || order.TimeInForce == IOC || order.TimeInForce == FOK {
+		return false
+	}
+	_, fillable := ob.checkFillable(order)
+	return !fillable
+}
+
+// bestOpposite returns the best price on the side a new order would trade
+// against, hidden orders included.
+func (ob *OrderBook) bestOpposite(side Side) (int64, bool) {
+	tree := ob.asks
+	if side == Sell {
+		tree = ob.bids
+	}
+	level, ok := tree.Min()
+	if !ok {
+		return 0, false
+	}
+	return level.Price, true
+}
+
+// crosses reports whether a limit order is willing to trade at the given price.
+func crosses(order *Order, price int64) bool {
 	if order.Side == Buy {
-		// Need to buy, so we check the asks (sellers)
-		ob.asks.Ascend(func(pl *PriceLevel) bool {
-			for e := pl.Orders.Front(); e != nil; e = e.Next() {
-				totalQuantity += e.Value.(*Order).RemainingQuantity() // Check remaining
-				if totalQuantity >= order.Quantity {
-					return false
-				}
-			}
-			return true
-		})
-	} else {
-		// Need to sell, so we check the bids (buyers)
-		ob.bids.Ascend(func(pl *PriceLevel) bool {
-			for e := pl.Orders.Front(); e != nil; e = e.Next() {
-				totalQuantity += e.Value.(*Order).RemainingQuantity() // Check remaining
-				if totalQuantity >= order.Quantity {
-					return false
-				}
-			}
-			return true
-		})
+		return order.Price >= price
 	}
-	return totalQuantity, totalQuantity >= order.Quantity
+	return order.Price <= price
+}
+
+// withinProtection reports whether a market order may trade at the given
+// price. Orders without a protection price may walk the whole book.
+func withinProtection(order *Order, price int64) bool {
+	if order.Type != Market || order.ProtectionPrice <= 0 {
+		return true
+	}
+	if order.Side == Buy {
+		return price <= order.ProtectionPrice
+	}
+	return price >= order.ProtectionPrice
 }
 
-// ProcessOrder processes a new order, attempting to match it.
+// ProcessOrder processes a new order, attempting to match it. Stop orders are
+// armed instead, and any stops triggered by the resulting trades are fired.
+// During an auction or a queueing halt the order is only booked (see
+// auction.go).
 func (ob *OrderBook) ProcessOrder(order *Order) ProcessOrderResponse {
+	if ob.collecting() {
+		return ob.collect(order)
+	}
+	var response ProcessOrderResponse
+	if order.IsConditional() {
+		ob.armStop(order)
+	} else if order.IsNotional() {
+		response = ob.processNotional(order)
+	} else if order.Type == MarketToLimit && !ob.convertToLimit(order) {
+		// admit rejects these; cancel rather than rest one without a price
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonUnfilled
+		ob.emitOrder(EventCancelled, order)
+	} else {
+		response = ob.processOrder(order)
+	}
+	response.TriggeredOrders, response.TriggeredTrades = ob.triggerStops()
+	return response
+}
+
+func (ob *OrderBook) processOrder(order *Order) ProcessOrderResponse {
 	var trades []Trade
 	var filledRestingOrders []*Order
 
-	if order.Side == Buy {
+	switch {
+	case order.AllOrNone && !ob.fillsCompletely(order):
+		// Rests untouched until an aggressor can take it all; see aon.go
+		trades, filledRestingOrders = []Trade{}, []*Order{}
+	case ob.staged != nil && ob.staged.order == order:
+		// Already matched by stageFill; see fillcheck.go
+		trades, filledRestingOrders = ob.commitStaged()
+	case order.Side == Buy:
 		trades, filledRestingOrders = ob.matchBuyOrder(order)
-	} else {
+	default:
 		trades, filledRestingOrders = ob.matchSellOrder(order)
 	}
 
 	orderInBook := false
-	if order.Type == Limit && order.RemainingQuantity() > 0 {
+	if order.RemainingQuantity() == 0 {
+		order.Status = StatusFilled
+	} else if selfMatchStopped(order) {
+		// Reached its own desk's order: the remainder is cancelled, never rested
+		if order.FilledQuantity > 0 {
+			order.Status = StatusPartialFill
+		} else {
+			order.Status = StatusCancelled
+		}
+		ob.emitOrder(EventCancelled, order)
+	} else if order.TimeInForce == IOC || order.Type == Market {
+		// IOC and market orders never rest: whatever did not match is cancelled.
+		order.CancelReason = ReasonUnfilled
+		if order.FilledQuantity > 0 {
+			order.Status = StatusPartialFill
+		} else {
+			order.Status = StatusCancelled
+		}
+		ob.emitOrder(EventCancelled, order)
+	} else if order.tradesAsLimit() {
 		ob.addOrder(order)
 		orderInBook = true
 		if order.FilledQuantity > 0 {
 			order.Status = StatusPartialFill
 		}
-	} else if order.RemainingQuantity() == 0 {
-		order.Status = StatusFilled
 	}
 
 	return ProcessOrderResponse{
@@ -136,37 +335,23 @@ func (ob *OrderBook) ProcessOrder(order *Order) ProcessOrderResponse {
 func (ob *OrderBook) matchBuyOrder(order *Order) ([]Trade, []*Order) {
 	trades := []Trade{}
 	filledOrders := []*Order{}
+	low, high := ob.band() // Fixed at entry so a walk cannot drag its own band
 
-	for order.RemainingQuantity() > 0 && ob.asks.Len() > 0 {
-		bestAskLevel, _ := ob.asks.Min()
-		if order.Type == Limit && order.Price < bestAskLevel.Price {
+	var passed *PriceLevel // Last level holding only AON orders too large to fill
+	for order.RemainingQuantity() > 0 && !selfMatchStopped(order) {
+		bestAskLevel, ok := nextLevel(ob.asks, passed)
+		if !ok {
 			break
 		}
-
-		for bestAskLevel.Orders.Len() > 0 {
-			element := bestAskLevel.Orders.Front()
-			askOrder := element.Value.(*Order)
-
-			tradeQuantity := min(order.RemainingQuantity(), askOrder.RemainingQuantity())
-			tradePrice := askOrder.Price
-
-			trades = append(trades, ob.createTrade(order.ID, askOrder.ID, tradePrice, tradeQuantity))
-
-			order.FilledQuantity += tradeQuantity
-			askOrder.FilledQuantity += tradeQuantity
-
-			if askOrder.RemainingQuantity() == 0 {
-				askOrder.Status = StatusFilled
-				filledOrders = append(filledOrders, askOrder)
-				ob.removeOrder(element)
-			} else {
-				// Partial fill of the resting order
-				askOrder.Status = StatusPartialFill
-			}
-
-			if order.RemainingQuantity() == 0 {
-				return trades, filledOrders
-			}
+		if order.tradesAsLimit() && order.Price < bestAskLevel.Price {
+			break
+		}
+		if !withinProtection(order, bestAskLevel.Price) || !withinBand(order, bestAskLevel.Price, low, high) || !ob.withinReference(order, bestAskLevel.Price) {
+			break
+		}
+		trades, filledOrders = ob.matchLevel(order, bestAskLevel, trades, filledOrders)
+		if bestAskLevel.Orders.Len() > 0 {
+			passed = bestAskLevel
 		}
 	}
 	return trades, filledOrders
@@ -175,60 +360,172 @@ func (ob *OrderBook) matchBuyOrder(order *Order) ([]Trade, []*Order) {
 func (ob *OrderBook) matchSellOrder(order *Order) ([]Trade, []*Order) {
 	trades := []Trade{}
 	filledOrders := []*Order{}
+	low, high := ob.band() // Fixed at entry so a walk cannot drag its own band
 
-	for order.RemainingQuantity() > 0 && ob.bids.Len() > 0 {
-		bestBidLevel, _ := ob.bids.Min()
-		if order.Type == Limit && order.Price > bestBidLevel.Price {
+	var passed *PriceLevel // Last level holding only AON orders too large to fill
+	for order.RemainingQuantity() > 0 && !selfMatchStopped(order) {
+		bestBidLevel, ok := nextLevel(ob.bids, passed)
+		if !ok {
 			break
 		}
+		if order.tradesAsLimit() && order.Price > bestBidLevel.Price {
+			break
+		}
+		if !withinProtection(order, bestBidLevel.Price) || !withinBand(order, bestBidLevel.Price, low, high) || !ob.withinReference(order, bestBidLevel.Price) {
+			break
+		}
+		trades, filledOrders = ob.matchLevel(order, bestBidLevel, trades, filledOrders)
+		if bestBidLevel.Orders.Len() > 0 {
+			passed = bestBidLevel
+		}
+	}
+	return trades, filledOrders
+}
 
-		for bestBidLevel.Orders.Len() > 0 {
-			element := bestBidLevel.Orders.Front()
-			bidOrder := element.Value.(*Order)
+// matchLevel fills the incoming order against one price level, in the order
+// the symbol's allocator chooses (FIFO by default; see allocation.go), until
+// either side is exhausted. A resting iceberg only trades its displayed
+// slice; when the slice is used up it replenishes from reserve and moves to
+// the back of the queue, losing time priority. Resting AON orders the order
+// cannot fill completely are passed over (see aon.go).
+func (ob *OrderBook) matchLevel(order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	if ob.staged != nil {
+		ob.staged.save(level)
+	}
+	return ob.config.allocator().match(ob, order, level, trades, filledOrders)
+}
 
-			tradeQuantity := min(order.RemainingQuantity(), bidOrder.RemainingQuantity())
-			tradePrice := bidOrder.Price
+func (ob *OrderBook) matchLevelFIFO(order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	for order.RemainingQuantity() > 0 {
+		restingOrder := firstFillable(level, order.RemainingQuantity())
+		if restingOrder == nil {
+			break
+		}
+		if ob.selfMatch(order, restingOrder) {
+			stopSelfMatch(order)
+			break
+		}
+		tradeQuantity := min(order.RemainingQuantity(), restingOrder.Visible())
+		trades, filledOrders = ob.fillResting(order, level, restingOrder, tradeQuantity, trades, filledOrders)
+	}
+	return trades, filledOrders
+}
 
-			trades = append(trades, ob.createTrade(order.ID, bidOrder.ID, tradePrice, tradeQuantity))
+// fillResting executes quantity between the incoming order and one resting
+// order at the symbol's execution price, removing the resting order once it
+// is filled and rotating an exhausted iceberg slice to the back of the level.
+func (ob *OrderBook) fillResting(order *Order, level *PriceLevel, restingOrder *Order, tradeQuantity int64, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	tradePrice := ob.executionPrice(order, restingOrder.Price)
 
-			order.FilledQuantity += tradeQuantity
-			bidOrder.FilledQuantity += tradeQuantity
+	trades = append(trades, ob.createTrade(order, restingOrder, tradePrice, tradeQuantity))
 
-			if bidOrder.RemainingQuantity() == 0 {
-				bidOrder.Status = StatusFilled
-				filledOrders = append(filledOrders, bidOrder)
-				ob.removeOrder(element)
-			} else {
-				// Partial fill of the resting order
-				bidOrder.Status = StatusPartialFill
-			}
+	order.FilledQuantity += tradeQuantity
+	restingOrder.FilledQuantity += tradeQuantity
+	ob.touch(restingOrder)
 
-			if order.RemainingQuantity() == 0 {
-				return trades, filledOrders
-			}
+	if restingOrder.RemainingQuantity() == 0 {
+		restingOrder.Status = StatusFilled
+		filledOrders = append(filledOrders, restingOrder)
+		ob.removeOrder(restingOrder.element)
+		return trades, filledOrders
+	}
+
+	// Partial fill of the resting order
+	restingOrder.Status = StatusPartialFill
+	if restingOrder.IsIceberg() {
+		restingOrder.VisibleQuantity -= tradeQuantity
+		if restingOrder.VisibleQuantity == 0 {
+			level.RemoveOrder(restingOrder)
+			restingOrder.replenish()
+			level.AddOrder(restingOrder)
+			ob.orderMap[restingOrder.ID] = restingOrder.element
 		}
 	}
 	return trades, filledOrders
 }
 
-func (ob *OrderBook) createTrade(aggressorOrderID, restingOrderID string, price, quantity int64) Trade {
-	return Trade{
-		TradeID:          uuid.New().String(),
-		AggressorOrderID: aggressorOrderID,
-		RestingOrderID:   restingOrderID,
+// executionPrice is the price an aggressor trades at against a resting order
+// at restingPrice. Under MidPoint a limit aggressor trades halfway between
+// its limit and the resting price, splitting the price improvement; an odd
+// gap, or one that is not a multiple of the tick, rounds toward the resting
+// price, so neither side ever trades past its own limit. PriceImprovement
+// splits the gap the same way but moves at most one tick (TickSize, or 1)
+// off the resting price, so it only prints between the two prices when
+// there is room for a whole tick there. Market orders have no limit to
+// split and always trade at the resting price.
+func (ob *OrderBook) executionPrice(aggressor *Order, restingPrice int64) int64 {
+	policy := ob.config.PricePolicy
+	if (policy != MidPoint && policy != PriceImprovement) || aggressor.Type != Limit {
+		return restingPrice
+	}
+	half := (aggressor.Price - restingPrice) / 2 // Truncates toward the resting price
+	if tick := ob.config.TickSize; tick > 0 {
+		half -= half % tick
+	}
+	if policy == PriceImprovement {
+		step := max(ob.config.TickSize, 1)
+		half = max(min(half, step), -step)
+	}
+	return restingPrice + half
+}
+
+// createTrade prices a trade between the aggressor and a resting order and
+// books it, unless the aggressor's match is staged (see fillcheck.go).
+func (ob *OrderBook) createTrade(aggressor, resting *Order, price, quantity int64) Trade {
+	trade := Trade{
+		Symbol:           ob.symbol,
+		AggressorOrderID: aggressor.ID,
+		RestingOrderID:   resting.ID,
+		AggressorSide:    aggressor.Side,
 		Price:            price,
 		Quantity:         quantity,
 		Timestamp:        time.Now().UnixNano() / 1_000_000, // Unix Milliseconds
 	}
+	trade.AggressorFee, trade.RestingFee = ob.config.Fees.fees(price, quantity,
+		ob.accountVolume(aggressor.AccountID, trade.Timestamp), ob.accountVolume(resting.AccountID, trade.Timestamp), ob.config.rounding)
+	if ob.staged != nil {
+		ob.staged.resting = append(ob.staged.resting, resting)
+		return trade
+	}
+	ob.bookTrade(aggressor, resting, &trade)
+	return trade
+}
+
+// bookTrade gives a trade its ID and records it: the trade event, the
+// ticker, the trade log and both accounts' positions.
+func (ob *OrderBook) bookTrade(aggressor, resting *Order, trade *Trade) {
+	trade.TradeID = ob.ids.get().NextTradeID()
+	if ob.events != nil {
+		event := *trade
+		trade.EventSeq = ob.events.emit(OrderEvent{Type: EventTrade, Symbol: ob.symbol, Trade: &event})
+	}
+	ob.lastTradePrice = trade.Price
+	ob.lastTradeQty = trade.Quantity
+	ob.lastTradeTime = trade.Timestamp
+	ob.lastTradeID = trade.TradeID
+	ob.hasTraded = true
+	ob.sessionRolled = false
+	ob.recordTrade(*trade)
+	ob.updatePositions(aggressor, resting, *trade)
 }
 
 // addOrder adds a limit order to the book.
 func (ob *OrderBook) addOrder(order *Order) {
+	if order.ExpiresAt > 0 {
+		ob.expiring[order.ID] = order
+	}
+	if order.IsPegged() {
+		ob.pegged[order.ID] = order
+	}
+	if order.IsIceberg() && (order.VisibleQuantity <= 0 || order.VisibleQuantity > order.RemainingQuantity()) {
+		order.replenish()
+	}
 	if order.Side == Buy {
 		ob.addBid(order)
 	} else {
 		ob.addAsk(order)
 	}
+	ob.resting++
 }
 
 func (ob *OrderBook) addBid(order *Order) {
@@ -243,6 +540,7 @@ func (ob *OrderBook) addBid(order *Order) {
 
 	level.AddOrder(order)
 	ob.orderMap[order.ID] = order.element
+	ob.touch(order)
 }
 
 func (ob *OrderBook) addAsk(order *Order) {
@@ -257,12 +555,24 @@ func (ob *OrderBook) addAsk(order *Order) {
 
 	level.AddOrder(order)
 	ob.orderMap[order.ID] = order.element
+	ob.touch(order)
+}
+
+// level returns the price level a resting order is queued at.
+func (ob *OrderBook) level(order *Order) *PriceLevel {
+	if order.Side == Buy {
+		return ob.bidPriceMap[order.Price]
+	}
+	return ob.askPriceMap[order.Price]
 }
 
 // removeOrder finds an order by its list element and removes it.
 func (ob *OrderBook) removeOrder(element *list.Element) {
 	order := element.Value.(*Order)
 	delete(ob.orderMap, order.ID)
+	delete(ob.expiring, order.ID)
+	delete(ob.pegged, order.ID)
+	ob.resting--
 
 	var priceMap map[int64]*PriceLevel
 	var tree *btree.BTreeG[*PriceLevel]
@@ -275,8 +585,14 @@ func (ob *OrderBook) removeOrder(element *list.Element) {
 		tree = ob.asks
 	}
 
-	level := priceMap[order.Price]
+	ob.touch(order)
+	level, ok := priceMap[order.Price]
+	if !ok {
+		order.element = nil // Its level is already gone; see verify.go
+		return
+	}
 	level.RemoveOrder(order)
+	// An emptied level never lingers in the tree or the price map
 	if level.Orders.Len() == 0 {
 		delete(priceMap, order.Price)
 		tree.Delete(level)
@@ -287,15 +603,164 @@ func (ob *OrderBook) removeOrder(element *list.Element) {
 func (ob *OrderBook) CancelOrder(orderID string) bool {
 	element, exists := ob.orderMap[orderID]
 	if !exists {
-		return false
+		return ob.disarmStop(orderID)
 	}
 	ob.removeOrder(element)
 	return true
 }
 
+// AmendOrder changes the price and/or total quantity of a resting order.
+// A pure quantity decrease is applied in place and keeps queue priority; a
+// price change or quantity increase re-queues the order through the matcher,
+// so a price that now crosses the book trades immediately; it then takes seq
+// as its new arrival sequence. Reducing the quantity to what is already
+// filled cancels the order.
+// It returns false if the order is not resting in this book.
+func (ob *OrderBook) AmendOrder(order *Order, newPrice, newQuantity, seq int64) (ProcessOrderResponse, bool) {
+	element, exists := ob.orderMap[order.ID]
+	if !exists {
+		return ProcessOrderResponse{}, false
+	}
+
+	// Reducing to the filled quantity leaves nothing to rest: cancel it
+	if newQuantity == order.FilledQuantity {
+		ob.removeOrder(element)
+		order.Status = StatusCancelled
+		order.CancelReason = ReasonRequested
+		ob.emitOrder(EventCancelled, order)
+		return ProcessOrderResponse{}, true
+	}
+	// A reduction keeps priority; anything else re-queues at the back
+	if newPrice == order.Price && newQuantity <= order.Quantity {
+		if err := ob.level(order).ReduceOrderQuantity(order, newQuantity); err != nil {
+			return ProcessOrderResponse{}, false
+		}
+		ob.touch(order)
+		ob.emitOrder(EventAmended, order)
+		return ProcessOrderResponse{OrderInBook: true}, true
+	}
+
+	ob.removeOrder(element)
+	order.Price = newPrice
+	order.Quantity = newQuantity
+	order.Timestamp = time.Now().UnixNano() / 1_000_000 // Priority is reset
+	order.Sequence = seq
+	ob.emitOrder(EventAmended, order)
+	return ob.ProcessOrder(order), true
+}
+
+// snapshot aggregates up to depth levels per side (0 = all). Levels holding
+// only hidden orders are skipped. Caller holds the lock.
+func (ob *OrderBook) snapshot(depth int) (bids []AggregatedPriceLevel, asks []AggregatedPriceLevel) {
+	// --- Get Asks (Lowest price first) ---
+	askCount := 0
+	ob.asks.Ascend(func(l *PriceLevel) bool {
+		if depth > 0 && askCount >= depth {
+			return false
+		}
+		totalQuantity := l.TotalQuantity()
+		// Quantities at each price level are aggregated
+		if totalQuantity > 0 {
+			asks = append(asks, AggregatedPriceLevel{Price: l.Price, Quantity: totalQuantity})
+			askCount++
+		}
+		return true
+	})
+
+	// --- Get Bids (Highest price first) ---
+	bidCount := 0
+	ob.bids.Ascend(func(l *PriceLevel) bool {
+		if depth > 0 && bidCount >= depth {
+			return false
+		}
+		totalQuantity := l.TotalQuantity()
+		// Quantities at each price level are aggregated
+		if totalQuantity > 0 {
+			bids = append(bids, AggregatedPriceLevel{Price: l.Price, Quantity: totalQuantity})
+			bidCount++
+		}
+		return true
+	})
+
+	return bids, asks
+}
+
+// groupLevels merges best-first levels into buckets group wide, rounding each
+// price up (asks) or down (bids) to a multiple of group, and keeps at most
+// depth buckets (all when depth is 0). Rounding is monotonic, so levels in
+// one bucket are always adjacent.
+func groupLevels(levels []AggregatedPriceLevel, group int64, up bool, depth int) []AggregatedPriceLevel {
+	grouped := []AggregatedPriceLevel{}
+	for _, level := range levels {
+		price := bucket(level.Price, group, up)
+		if n := len(grouped); n > 0 && grouped[n-1].Price == price {
+			grouped[n-1].Quantity += level.Quantity
+			continue
+		}
+		if depth > 0 && len(grouped) == depth {
+			break
+		}
+		grouped = append(grouped, AggregatedPriceLevel{Price: price, Quantity: level.Quantity})
+	}
+	return grouped
+}
+
+// bucket rounds price to a multiple of group, down (floor, also for negative
+// prices) or up.
+func bucket(price, group int64, up bool) int64 {
+	floor := price / group * group // Truncates toward zero
+	if floor > price {
+		floor -= group
+	}
+	if up && floor < price {
+		return floor + group
+	}
+	return floor
+}
+
+// displayedCounts counts the levels on a side that show any quantity and the
+// orders showing it, i.e. what an untruncated snapshot would aggregate.
+func displayedCounts(tree *btree.BTreeG[*PriceLevel]) (levels, orders int) {
+	tree.Ascend(func(pl *PriceLevel) bool {
+		shown := 0
+		for e := pl.Orders.Front(); e != nil; e = e.Next() {
+			if e.Value.(*Order).Displayed() > 0 {
+				shown++
+			}
+		}
+		if shown > 0 {
+			levels++
+			orders += shown
+		}
+		return true
+	})
+	return levels, orders
+}
+
+// bestDisplayed returns the best level on a side that shows any quantity,
+// skipping levels holding only hidden orders.
+func bestDisplayed(tree *btree.BTreeG[*PriceLevel]) (level *PriceLevel, ok bool) {
+	tree.Ascend(func(pl *PriceLevel) bool {
+		if pl.TotalQuantity() > 0 {
+			level, ok = pl, true
+			return false
+		}
+		return true
+	})
+	return level, ok
+}
+
 func min(a, b int64) int64 {
 	if a < b {
 		return a
 	}
 	return b
+}
+
+// abs is |x|; prices may be negative on symbols with AllowNegativePrice.
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
 }
\ No newline at end of file
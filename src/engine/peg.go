@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// --- Pegged Orders ---
+//
+// A pegged order rests at its reference quote plus PegOffset:
+//
+//   - PegBid: the best bid
+//   - PegAsk: the best ask
+//   - PegMid: halfway between them, in whole ticks, rounded down for a buy
+//     and up for a sell (both quotes are needed)
+//
+// Quotes come from displayed orders that are not themselves pegged, so
+// pegged orders never chase each other or their own price.
+//
+// Pegged orders only add liquidity. A peg that would cross the book is
+// clamped to one tick inside it: a buy rests one tick below the best ask and
+// a sell one tick above the best bid, hidden orders included. Pegged orders
+// therefore never trade on arrival or when they move; they trade as the
+// resting side when an aggressor reaches them.
+//
+// After every change to the book (a submission, cancel, amendment, trade,
+// expiry and so on) the engine re-prices each pegged order whose peg has
+// moved. A re-peg goes to the back of the queue at its new price, with a new
+// Sequence, and is published as an EventAmended. A peg that cannot be priced
+// (its quote is gone, or the price would be outside the price band or not
+// positive on a symbol without AllowNegativePrice) leaves the order where it
+// is until it can. A new pegged order that cannot be priced is rejected.
+//
+// Pegs are frozen while a book collects orders (auction, pre-open, queueing
+// halt): resting pegged orders take part in the uncross at their last price,
+// new ones are refused, and pegs resume once matching does.
+
+// PegReference is the quote a pegged order tracks.
+type PegReference string
+
+const (
+	PegBid PegReference = "BID" // Best bid
+	PegAsk PegReference = "ASK" // Best ask
+	PegMid PegReference = "MID" // Midpoint of the best bid and ask
+)
+
+// checkPeg rejects pegged orders with terms they cannot honour, and peg
+// fields on orders that are not pegged.
+func (cfg SymbolConfig) checkPeg(order *Order) error {
+	if !order.IsPegged() {
+		if order.PegReference != "" || order.PegOffset != 0 {
+			return rejectf(ErrInvalidOrder, "invalid order: peg_reference and peg_offset require a PEGGED order")
+		}
+		return nil
+	}
+	switch order.PegReference {
+	case PegBid, PegAsk, PegMid:
+	default:
+		return rejectf(ErrInvalidOrder, "invalid order: peg_reference %q must be BID, ASK or MID", order.PegReference)
+	}
+	switch {
+	case order.Price != 0:
+		return rejectf(ErrInvalidOrder, "invalid order: a pegged order is priced by its peg; omit price")
+	case order.StopPrice != 0 || order.Notional != 0 || order.ProtectionPrice != 0:
+		return rejectf(ErrInvalidOrder, "invalid order: pegged orders take no stop_price, notional or protection_price")
+	case order.TimeInForce == IOC || order.TimeInForce == FOK:
+		return rejectf(ErrInvalidOrder, "invalid order: pegged orders rest and never take liquidity; IOC and FOK are not supported")
+	case cfg.TickSize > 0 && order.PegOffset%cfg.TickSize != 0:
+		return rejectf(ErrInvalidOrder, "invalid order: peg_offset %d is not a multiple of tick size %d", order.PegOffset, cfg.TickSize)
+	}
+	return nil
+}
+
+// pegQuote returns the best price on side among displayed orders that are
+// not pegged.
+func (ob *OrderBook) pegQuote(side Side) (price int64, ok bool) {
+	tree := ob.bids
+	if side == Sell {
+		tree = ob.asks
+	}
+	tree.Ascend(func(pl *PriceLevel) bool {
+		for e := pl.Orders.Front(); e != nil; e = e.Next() {
+			if order := e.Value.(*Order); order.Displayed() > 0 && !order.IsPegged() {
+				price, ok = pl.Price, true
+				return false
+			}
+		}
+		return true
+	})
+	return price, ok
+}
+
+// pegPrice is where order's peg puts it now. ok is false if it cannot be
+// priced: the quote is missing or the price is not a valid one.
+func (ob *OrderBook) pegPrice(order *Order) (price int64, ok bool) {
+	tick := max(ob.config.TickSize, 1)
+	switch order.PegReference {
+	case PegBid:
+		price, ok = ob.pegQuote(Buy)
+	case PegAsk:
+		price, ok = ob.pegQuote(Sell)
+	case PegMid:
+		bid, hasBid := ob.pegQuote(Buy)
+		ask, hasAsk := ob.pegQuote(Sell)
+		if ok = hasBid && hasAsk; ok {
+			price = midTick(bid, ask, tick, order.Side == Buy)
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	price += order.PegOffset
+	// Clamp one tick inside the opposite side, so the order never takes
+	if best, crossable := ob.bestOpposite(order.Side); crossable {
+		if order.Side == Buy && price >= best {
+			price = best - tick
+		} else if order.Side == Sell && price <= best {
+			price = best + tick
+		}
+	}
+	if price <= 0 && !ob.config.AllowNegativePrice {
+		return 0, false
+	}
+	return price, true
+}
+
+// midTick is the midpoint of bid and ask as a whole number of ticks, rounded
+// down when down is set and up otherwise.
+func midTick(bid, ask, tick int64, down bool) int64 {
+	sum, step := bid+ask, 2*tick
+	q := sum / step
+	if r := sum % step; r != 0 && (r < 0) == down {
+		if down {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q * tick
+}
+
+// priceNewPeg prices a pegged order on arrival. The book lock is held.
+func (ob *OrderBook) priceNewPeg(order *Order) error {
+	price, ok := ob.pegPrice(order)
+	if !ok {
+		return rejectf(ErrInsufficientLiquidity, "no %s quote to peg to for %s", order.PegReference, order.Symbol)
+	}
+	if err := ob.config.checkMaxNotional(price, order.Quantity); err != nil {
+		return err
+	}
+	order.Price = price
+	return nil
+}
+
+// repeg moves every pegged order whose peg has changed, oldest first, so a
+// run of re-pegs is the same every time, on replay too. A peg clamped by
+// another pegged order may be freed when that one moves, so passes repeat
+// until nothing moves (at most one per order). The book lock is held.
+func (me *MatchingEngine) repeg(book *OrderBook) {
+	if len(book.pegged) == 0 || book.collecting() {
+		return
+	}
+	orders := make([]*Order, 0, len(book.pegged))
+	for _, order := range book.pegged {
+		orders = append(orders, order)
+	}
+	slices.SortFunc(orders, func(a, b *Order) int { return cmp.Compare(a.Sequence, b.Sequence) })
+
+	for range orders {
+		moved := false
+		for _, order := range orders {
+			price, ok := book.pegPrice(order)
+			if !ok || price == order.Price || book.checkBand(price) != nil {
+				continue
+			}
+			book.removeOrder(order.element)
+			order.Price = price
+			order.Timestamp = time.Now().UnixNano() / 1_000_000 // Priority is reset
+			order.Sequence = me.orderSeq.Add(1)
+			book.addOrder(order)
+			book.emitOrder(EventAmended, order)
+			moved = true
+		}
+		if !moved {
+			return
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package engine
+
+import "slices"
+
+// --- Positions ---
+//
+// Each book tracks a position per account that has traded in it, updated on
+// every trade for both the aggressor and the resting order (orders without an
+// AccountID are not tracked). A position keeps its net quantity (long
+// positive, short negative) and the cost basis of the open quantity, so the
+// average entry price is CostBasis / |NetQuantity|. Reducing a position
+// realizes P&L against that average; a trade that takes the position through
+// zero closes it completely and opens the remainder at the trade price.
+//
+// Trade fees (see fees.go) accumulate in Fees and are deducted from
+// RealizedPnL, so a maker rebate adds to it.
+//
+// Money amounts (CostBasis, RealizedPnL, Fees) are price x quantity, so for symbols
+// with QuantityDecimals > 0 they carry the same scale as a quantity.
+//
+// A position also counts the quantity the account traded per UTC day, by
+// trade timestamp, for the last VolumeWindowDays days. That rolling volume
+// picks the account's fee tier.
+
+// VolumeWindowDays is how many days, today included, count towards an
+// account's rolling volume.
+const VolumeWindowDays = 30
+
+// DailyVolume is the quantity an account traded in a symbol on one UTC day.
+type DailyVolume struct {
+	Day      int64 `json:"day"` // Days since the Unix epoch
+	Quantity int64 `json:"quantity"`
+}
+
+// Position is an account's holding and realized P&L in one symbol.
+type Position struct {
+	AccountID    string `json:"account_id"`
+	Symbol       string `json:"symbol"`
+	NetQuantity  int64  `json:"net_quantity"`  // Long positive, short negative
+	AveragePrice int64  `json:"average_price"` // Average entry price, truncated; 0 when flat
+	CostBasis    int64  `json:"cost_basis"`    // Entry value of the open quantity
+	RealizedPnL  int64  `json:"realized_pnl"`  // Net of fees
+	Fees         int64  `json:"fees"`          // Fees paid less rebates received
+
+	Volume []DailyVolume `json:"volume,omitempty"` // Oldest first, within the window
+}
+
+// apply updates the position for a fill of quantity at price on side.
+func (p *Position) apply(side Side, price, quantity int64) {
+	signed := quantity
+	if side == Sell {
+		signed = -quantity
+	}
+	if p.NetQuantity == 0 || (p.NetQuantity > 0) == (signed > 0) {
+		p.NetQuantity += signed
+		p.CostBasis += price * quantity
+		p.updateAverage()
+		return
+	}
+
+	open := p.NetQuantity
+	if open < 0 {
+		open = -open
+	}
+	closing := min(quantity, open)
+	released := p.CostBasis
+	if closing < open {
+		// mulDiv takes no negative inputs; a spread's cost basis can be one
+		released = RoundTruncate.mulDiv(abs(p.CostBasis), closing, open, false)
+		if p.CostBasis < 0 {
+			released = -released
+		}
+	}
+	if p.NetQuantity > 0 {
+		p.RealizedPnL += price*closing - released // Selling out of a long
+	} else {
+		p.RealizedPnL += released - price*closing // Buying back a short
+	}
+	p.CostBasis -= released
+	if side == Buy {
+		p.NetQuantity += closing
+	} else {
+		p.NetQuantity -= closing
+	}
+
+	// Flipped through zero: the rest opens a new position at this price
+	if rest := quantity - closing; rest > 0 {
+		p.NetQuantity = rest
+		if side == Sell {
+			p.NetQuantity = -rest
+		}
+		p.CostBasis = price * rest
+	}
+	p.updateAverage()
+}
+
+func (p *Position) updateAverage() {
+	switch {
+	case p.NetQuantity > 0:
+		p.AveragePrice = p.CostBasis / p.NetQuantity
+	case p.NetQuantity < 0:
+		p.AveragePrice = p.CostBasis / -p.NetQuantity
+	default:
+		p.AveragePrice = 0
+	}
+}
+
+// charge books a trade fee against the position.
+func (p *Position) charge(fee int64) {
+	p.Fees += fee
+	p.RealizedPnL -= fee
+}
+
+// windowStart returns the first day inside the volume window ending on the
+// day of timestamp (Unix milliseconds).
+func windowStart(timestamp int64) int64 {
+	return timestamp/86_400_000 - VolumeWindowDays + 1
+}
+
+// addVolume counts quantity traded at timestamp towards the rolling volume
+// and drops days that have left the window.
+func (p *Position) addVolume(timestamp, quantity int64) {
+	day := timestamp / 86_400_000
+	if n := len(p.Volume); n > 0 && p.Volume[n-1].Day >= day {
+		p.Volume[n-1].Quantity += quantity // Same day, or a clock step back
+	} else {
+		p.Volume = append(p.Volume, DailyVolume{Day: day, Quantity: quantity})
+	}
+	start := windowStart(timestamp)
+	for len(p.Volume) > 0 && p.Volume[0].Day < start {
+		p.Volume = p.Volume[1:]
+	}
+}
+
+// removeVolume takes back quantity counted on the day of timestamp, if that
+// day is still in the window.
+func (p *Position) removeVolume(timestamp, quantity int64) {
+	day := timestamp / 86_400_000
+	for i := range p.Volume {
+		if p.Volume[i].Day == day {
+			p.Volume[i].Quantity = max(p.Volume[i].Quantity-quantity, 0)
+			return
+		}
+	}
+}
+
+// RollingVolume returns the quantity traded in the VolumeWindowDays days up
+// to and including the day of timestamp (Unix milliseconds).
+func (p *Position) RollingVolume(timestamp int64) int64 {
+	start := windowStart(timestamp)
+	var volume int64
+	for _, v := range p.Volume {
+		if v.Day >= start {
+			volume += v.Quantity
+		}
+	}
+	return volume
+}
+
+// accountVolume returns an account's rolling volume in the book as of
+// timestamp; anonymous orders have none.
+func (ob *OrderBook) accountVolume(accountID string, timestamp int64) int64 {
+	if position, ok := ob.positions[accountID]; ok {
+		return position.RollingVolume(timestamp)
+	}
+	return 0
+}
+
+// updatePositions applies a trade to both sides' positions.
+func (ob *OrderBook) updatePositions(aggressor, resting *Order, trade Trade) {
+	fees := []int64{trade.AggressorFee, trade.RestingFee}
+	for i, order := range []*Order{aggressor, resting} {
+		if order.AccountID == "" {
+			continue
+		}
+		position, ok := ob.positions[order.AccountID]
+		if !ok {
+			position = &Position{AccountID: order.AccountID, Symbol: ob.symbol}
+			ob.positions[order.AccountID] = position
+		}
+		position.apply(order.Side, trade.Price, trade.Quantity)
+		position.charge(fees[i])
+		position.addVolume(trade.Timestamp, trade.Quantity)
+	}
+}
+
+// GetPositions returns an account's positions in every symbol it has traded,
+// sorted by symbol. A position that has been closed is still returned, with
+// its realized P&L.
+func (me *MatchingEngine) GetPositions(accountID string) []Position {
+	positions := []Position{}
+	for _, sb := range me.allBooks() {
+		sb.lock.RLock()
+		if position, ok := sb.book.positions[accountID]; ok {
+			copied := *position
+			copied.Volume = slices.Clone(position.Volume)
+			positions = append(positions, copied)
+		}
+		sb.lock.RUnlock()
+	}
+	return positions
+}
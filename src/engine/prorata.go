@@ -0,0 +1,79 @@
+package engine
+
+// --- Pro-Rata Matching ---
+//
+// Under pro-rata allocation an aggressor that cannot take a whole price level
+// is split across every resting order there in proportion to its displayed
+// quantity, instead of filling the oldest order first.
+//
+// Each order first gets displayed * incoming / levelTotal, rounded down (or by
+// the engine's RoundingMode; see rounding.go). The units lost to rounding
+// (fewer than the number of orders) are then handed out one at a time in
+// time priority, oldest order first, skipping orders already allocated their
+// full displayed quantity, and cycling until none remain. Rounding to nearest
+// can instead round up more shares than the aggressor has units for; the
+// newest of the orders rounded up then give their extra unit back. The
+// rounded-down shares never exceed the aggressor, so this excess is at most
+// one unit per order. An aggressor large enough to take the whole level
+// fills every order fully, so allocation only matters when the level is
+// oversubscribed. AON orders are left out of the allocation (see aon.go).
+//
+// Hidden orders display nothing, so they take no share: only displayed
+// orders are rationed, and hidden ones trade, in time priority, only from
+// what an aggressor has left once every displayed order is filled.
+
+// matchLevelProRata fills the incoming order against one price level using
+// pro-rata allocation.
+func (ob *OrderBook) matchLevelProRata(order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	incoming := order.RemainingQuantity()
+	// AON orders cannot take a share and hidden orders trade after the
+	// displayed ones, so only the displayed others are rationed
+	resting := make([]*Order, 0, level.Orders.Len())
+	var total int64
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		if restingOrder := e.Value.(*Order); !restingOrder.AllOrNone && !restingOrder.Hidden {
+			resting = append(resting, restingOrder)
+			total += restingOrder.Displayed()
+		}
+	}
+	if incoming >= total {
+		// Nothing to ration: sweep the level as FIFO would, displayed orders
+		// first and hidden ones from what is left
+		return ob.matchLevelFIFO(order, level, trades, filledOrders)
+	}
+	if ob.levelHasSelfMatch(order, level) {
+		stopSelfMatch(order) // A share of the level would trade with the aggressor's own desk
+		return trades, filledOrders
+	}
+
+	allocations := make([]int64, len(resting))
+	var allocated int64
+	for i, restingOrder := range resting {
+		allocations[i] = ob.config.rounding.mulDiv(restingOrder.Displayed(), incoming, total, false)
+		allocated += allocations[i]
+	}
+	for i := len(resting) - 1; i >= 0 && allocated > incoming; i-- {
+		if allocations[i] > RoundTruncate.mulDiv(resting[i].Displayed(), incoming, total, false) {
+			allocations[i]--
+			allocated--
+		}
+	}
+	for residual := incoming - allocated; residual > 0; {
+		for i, restingOrder := range resting {
+			if residual == 0 {
+				break
+			}
+			if allocations[i] < restingOrder.Displayed() {
+				allocations[i]++
+				residual--
+			}
+		}
+	}
+
+	for i, restingOrder := range resting {
+		if allocations[i] > 0 {
+			trades, filledOrders = ob.fillResting(order, level, restingOrder, allocations[i], trades, filledOrders)
+		}
+	}
+	return trades, filledOrders
+}
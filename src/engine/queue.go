@@ -0,0 +1,64 @@
+package engine
+
+// GetQueuePosition reports where a resting order stands in its price level:
+// ahead is the remaining quantity of the orders queued before it, total the
+// remaining quantity of the whole level, its own included. Both count hidden
+// orders and iceberg reserve, which the order's own feed does not show.
+// An order that is not resting (filled, cancelled or an armed stop) is
+// refused with ErrOrderNotOpen.
+func (me *MatchingEngine) GetQueuePosition(orderID string) (ahead int64, total int64, err error) {
+	order, ok := me.storedOrder(orderID)
+	if !ok {
+		return 0, 0, ErrOrderNotFound
+	}
+	book, lock := me.getBookAndLock(order.Symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if order.element == nil {
+		return 0, 0, rejectf(ErrOrderNotOpen, "order %s is not resting in the book", orderID)
+	}
+	level := book.level(order)
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		if e == order.element {
+			ahead = total
+		}
+		total += e.Value.(*Order).RemainingQuantity()
+	}
+	return ahead, total, nil
+}
+
+// GetLevelOrders returns copies of the orders resting at one price on one
+// side of a symbol's book, in queue order (time priority, hidden orders
+// last). Hidden orders are left out unless includeHidden is set. A price
+// with no level gives an empty list.
+func (me *MatchingEngine) GetLevelOrders(symbol string, side Side, price int64, includeHidden bool) []Order {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.GetLevelOrders(side, price, includeHidden)
+}
+
+// GetLevelOrders is MatchingEngine.GetLevelOrders for this book; the caller
+// holds the lock.
+func (ob *OrderBook) GetLevelOrders(side Side, price int64, includeHidden bool) []Order {
+	priceMap := ob.askPriceMap
+	if side == Buy {
+		priceMap = ob.bidPriceMap
+	}
+	orders := []Order{}
+	level, ok := priceMap[price]
+	if !ok {
+		return orders
+	}
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		order := e.Value.(*Order)
+		if order.Hidden && !includeHidden {
+			break // Hidden orders queue behind every displayed one
+		}
+		orderCopy := *order
+		orderCopy.element = nil
+		orders = append(orders, orderCopy)
+	}
+	return orders
+}
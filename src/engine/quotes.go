@@ -0,0 +1,109 @@
+package engine
+
+import "math"
+
+// GetBBO returns the best displayed bid and ask levels for a symbol. A side
+// with nothing displayed is returned as the zero level; ok is false when both
+// sides are.
+func (me *MatchingEngine) GetBBO(symbol string) (bid, ask AggregatedPriceLevel, ok bool) {
+	book, lock := me.getBookAndLock(symbol)
+
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if level, found := bestDisplayed(book.bids); found {
+		bid = AggregatedPriceLevel{Price: level.Price, Quantity: level.TotalQuantity()}
+		ok = true
+	}
+	if level, found := bestDisplayed(book.asks); found {
+		ask = AggregatedPriceLevel{Price: level.Price, Quantity: level.TotalQuantity()}
+		ok = true
+	}
+	return bid, ask, ok
+}
+
+// BookStats summarises the displayed liquidity in a book. Spread, SpreadBps
+// and the best prices are zero when a side is empty. SpreadBps is relative
+// to the mid's magnitude, and zero when the mid is; Imbalance is
+// (bid volume - ask volume) / (bid volume + ask volume), in [-1, 1], and zero
+// for an empty book.
+type BookStats struct {
+	Symbol         string  `json:"symbol"`
+	BestBid        int64   `json:"best_bid"`
+	BestAsk        int64   `json:"best_ask"`
+	Spread         int64   `json:"spread"`
+	SpreadBps      float64 `json:"spread_bps"`
+	TotalBidVolume int64   `json:"total_bid_volume"`
+	TotalAskVolume int64   `json:"total_ask_volume"`
+	Imbalance      float64 `json:"imbalance"`
+}
+
+// GetBookStats computes BookStats for a symbol under the book's read lock.
+func (me *MatchingEngine) GetBookStats(symbol string) BookStats {
+	book, lock := me.getBookAndLock(symbol)
+
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.stats()
+}
+
+// stats is GetBookStats for a book whose lock is held.
+func (ob *OrderBook) stats() BookStats {
+	stats := BookStats{Symbol: ob.symbol}
+	bid, hasBid := bestDisplayed(ob.bids)
+	if hasBid {
+		stats.BestBid = bid.Price
+	}
+	ask, hasAsk := bestDisplayed(ob.asks)
+	if hasAsk {
+		stats.BestAsk = ask.Price
+	}
+	ob.bids.Ascend(func(pl *PriceLevel) bool {
+		stats.TotalBidVolume += pl.TotalQuantity()
+		return true
+	})
+	ob.asks.Ascend(func(pl *PriceLevel) bool {
+		stats.TotalAskVolume += pl.TotalQuantity()
+		return true
+	})
+
+	// Prices can be zero or negative (see AllowNegativePrice), so it is the
+	// sides being there that counts, not their sign
+	if hasBid && hasAsk {
+		stats.Spread = stats.BestAsk - stats.BestBid
+		if mid := math.Abs(float64(stats.BestBid+stats.BestAsk) / 2); mid > 0 {
+			stats.SpreadBps = float64(stats.Spread) / mid * 10_000
+		}
+	}
+	if total := stats.TotalBidVolume + stats.TotalAskVolume; total > 0 {
+		stats.Imbalance = float64(stats.TotalBidVolume-stats.TotalAskVolume) / float64(total)
+	}
+	return stats
+}
+
+// LastTrade is the most recent print for a symbol.
+type LastTrade struct {
+	Symbol    string `json:"symbol"`
+	Price     int64  `json:"price"`
+	Quantity  int64  `json:"quantity"`
+	Timestamp int64  `json:"timestamp"` // Unix milliseconds
+}
+
+// GetLastTrade returns the symbol's most recent trade; ok is false if the
+// symbol has not traded yet, or not since RollSession.
+func (me *MatchingEngine) GetLastTrade(symbol string) (last LastTrade, ok bool) {
+	book, lock := me.getBookAndLock(symbol)
+
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if !book.hasTraded || book.sessionRolled {
+		return LastTrade{}, false
+	}
+	return LastTrade{
+		Symbol:    symbol,
+		Price:     book.lastTradePrice,
+		Quantity:  book.lastTradeQty,
+		Timestamp: book.lastTradeTime,
+	}, true
+}
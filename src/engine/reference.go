@@ -0,0 +1,93 @@
+package engine
+
+import "fmt"
+
+// --- Reference Quotes ---
+//
+// An engine run beside other venues can be told a symbol's consolidated
+// best bid and ask (an NBBO) with SetReference, and orders flagged
+// NoTradeThrough then never trade worse than it. A protected limit order
+// priced through the reference, a buy above the reference ask or a sell
+// below the reference bid, is rejected on entry with TRADE_THROUGH. A
+// protected market order walks the book only as far as the reference, like
+// a protection price, and whatever it cannot fill there is cancelled as
+// UNFILLED. A reference side of 0 is unquoted and protects nothing. The
+// reference is logged to the WAL and kept in snapshots, so recovery checks
+// orders against the quote they originally saw.
+
+// referenceQuote is a symbol's external best bid and ask; 0 is unquoted.
+type referenceQuote struct {
+	bid, ask int64
+}
+
+// SetReference sets symbol's reference best bid and ask. Either may be 0 to
+// leave that side unquoted; a reference with both sides 0 is cleared. With
+// strict symbols on, an unlisted symbol is refused with ErrUnknownSymbol, as
+// it is on submission.
+func (me *MatchingEngine) SetReference(symbol string, bid, ask int64) error {
+	if _, err := me.lookupSymbolConfig(symbol); err != nil {
+		return err
+	}
+	if bid < 0 || ask < 0 {
+		return fmt.Errorf("invalid reference: bid and ask must not be negative")
+	}
+	if bid > 0 && ask > 0 && bid > ask {
+		return fmt.Errorf("invalid reference: bid %d is above ask %d", bid, ask)
+	}
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := me.logWAL(WALEntry{Op: WALReference, Symbol: symbol, Bid: bid, Ask: ask}); err != nil {
+		return err
+	}
+	book.reference = referenceQuote{bid: bid, ask: ask}
+	return nil
+}
+
+// GetReference returns symbol's reference best bid and ask, 0 where unquoted.
+func (me *MatchingEngine) GetReference(symbol string) (bid, ask int64) {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.reference.bid, book.reference.ask
+}
+
+// referenceLimit returns the worst price a protected order may trade at: the
+// reference ask for a buy, the reference bid for a sell. ok is false if the
+// order is unprotected or that side of the reference is unquoted.
+func (ob *OrderBook) referenceLimit(order *Order) (limit int64, ok bool) {
+	if !order.NoTradeThrough {
+		return 0, false
+	}
+	if order.Side == Buy {
+		return ob.reference.ask, ob.reference.ask > 0
+	}
+	return ob.reference.bid, ob.reference.bid > 0
+}
+
+// checkTradeThrough rejects a protected limit order priced through the
+// reference.
+func (ob *OrderBook) checkTradeThrough(order *Order) error {
+	limit, ok := ob.referenceLimit(order)
+	if !ok || ob.withinReference(order, order.Price) {
+		return nil
+	}
+	if order.Side == Buy {
+		return rejectf(ErrTradeThrough, "order would trade through the reference: price %d above reference ask %d", order.Price, limit)
+	}
+	return rejectf(ErrTradeThrough, "order would trade through the reference: price %d below reference bid %d", order.Price, limit)
+}
+
+// withinReference reports whether order may trade at price without trading
+// through the reference.
+func (ob *OrderBook) withinReference(order *Order, price int64) bool {
+	limit, ok := ob.referenceLimit(order)
+	if !ok {
+		return true
+	}
+	if order.Side == Buy {
+		return price <= limit
+	}
+	return price >= limit
+}
@@ -0,0 +1,105 @@
+package engine
+
+import "sync"
+
+// rejectionLogCapacity is how many recent rejections GetRejections can
+// return; older ones are overwritten.
+const rejectionLogCapacity = 1000
+
+// Rejection is one refused submission: when, why, and the order as it was
+// submitted.
+type Rejection struct {
+	Timestamp int64         `json:"timestamp"` // Unix milliseconds, by the engine clock
+	Symbol    string        `json:"symbol"`
+	Code      ReasonCode    `json:"code"`
+	Message   string        `json:"message"`
+	Order     RejectedOrder `json:"order"`
+}
+
+// RejectedOrder summarises the terms of a rejected order.
+type RejectedOrder struct {
+	ID          string      `json:"id"`
+	AccountID   string      `json:"account_id,omitempty"`
+	Side        Side        `json:"side"`
+	Type        OrderType   `json:"type"`
+	TimeInForce TimeInForce `json:"time_in_force,omitempty"`
+	Price       int64       `json:"price,omitempty"`
+	StopPrice   int64       `json:"stop_price,omitempty"`
+	Quantity    int64       `json:"quantity,omitempty"`
+	Notional    int64       `json:"notional,omitempty"`
+}
+
+// rejectionLog is a ring buffer of the most recent rejections.
+type rejectionLog struct {
+	mu      sync.Mutex
+	entries []Rejection // Grows to rejectionLogCapacity, then wraps
+	next    int         // Where the next entry goes once full
+}
+
+func (l *rejectionLog) add(r Rejection) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < rejectionLogCapacity {
+		l.entries = append(l.entries, r)
+		return
+	}
+	l.entries[l.next] = r
+	l.next = (l.next + 1) % rejectionLogCapacity
+}
+
+// recordRejection is where every refused submission ends up: it counts the
+// rejection under reason on ome_orders_rejected_total and, outside WAL
+// replay (where the rejection already happened once), adds it to the log
+// read by GetRejections.
+func (me *MatchingEngine) recordRejection(order *Order, reason string, err error) {
+	me.metrics.reject(reason)
+	if me.replaying.Load() {
+		return
+	}
+	code := Code(err)
+	if code == "" {
+		code = CodeInvalidOrder
+	}
+	me.rejections.add(Rejection{
+		Timestamp: me.now().UnixMilli(),
+		Symbol:    order.Symbol,
+		Code:      code,
+		Message:   err.Error(),
+		Order: RejectedOrder{
+			ID:          order.ID,
+			AccountID:   order.AccountID,
+			Side:        order.Side,
+			Type:        order.Type,
+			TimeInForce: order.TimeInForce,
+			Price:       order.Price,
+			StopPrice:   order.StopPrice,
+			Quantity:    order.Quantity,
+			Notional:    order.Notional,
+		},
+	})
+}
+
+// RecordRejection records an order a front end refused before it reached
+// the engine, such as a malformed request, alongside the engine's own
+// rejections. Errors without a ReasonCode are logged as INVALID_ORDER.
+func (me *MatchingEngine) RecordRejection(order *Order, err error) {
+	me.recordRejection(order, rejectInvalid, err)
+}
+
+// GetRejections returns up to limit of the most recent rejections, newest
+// first; limit <= 0 returns all that are kept.
+func (me *MatchingEngine) GetRejections(limit int) []Rejection {
+	l := me.rejections
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := len(l.entries)
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	out := make([]Rejection, 0, limit)
+	// Newest is just before next once the buffer has wrapped, else at the end
+	for i := 1; i <= limit; i++ {
+		out = append(out, l.entries[(l.next-i+n)%n])
+	}
+	return out
+}
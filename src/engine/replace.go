@@ -0,0 +1,74 @@
+package engine
+
+// CancelReplace atomically cancels a live order and submits newOrder in its
+// place. Both happen under the symbol lock, so no other order can slip in
+// between. newOrder must be for the same symbol and side (an empty symbol
+// defaults to the old order's). Every check the new order must pass is made
+// before the old order is touched: if the replacement is rejected, the
+// original keeps resting with its queue priority. It returns a copy of the
+// cancelled order and the new order's matching result; newOrder itself
+// holds the new order's state.
+func (me *MatchingEngine) CancelReplace(oldID string, newOrder *Order) (*Order, ProcessOrderResponse, error) {
+	old, ok := me.storedOrder(oldID)
+	if !ok {
+		return nil, ProcessOrderResponse{}, ErrOrderNotFound // 404
+	}
+	if newOrder.Symbol == "" {
+		newOrder.Symbol = old.Symbol
+	}
+	if newOrder.Symbol != old.Symbol {
+		return nil, ProcessOrderResponse{}, rejectf(ErrInvalidOrder, "invalid replacement: symbol %s does not match original %s", newOrder.Symbol, old.Symbol)
+	}
+	cfg, err := me.validateOrder(newOrder)
+	if err != nil {
+		me.recordRejection(newOrder, rejectInvalid, err)
+		return nil, ProcessOrderResponse{}, err
+	}
+
+	book, lock := me.getBookAndLock(old.Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+	book.config = cfg
+
+	if old.Status == StatusFilled || old.Status == StatusCancelled {
+		return nil, ProcessOrderResponse{}, rejectf(ErrOrderNotOpen, "cannot replace order already filled or cancelled") // 400
+	}
+	// Same side only: the old order then never sits on the side the new one
+	// trades against, so admitting the new order before cancelling the old
+	// one sees the same opposite book as executing it afterwards.
+	if newOrder.Side != old.Side {
+		return nil, ProcessOrderResponse{}, rejectf(ErrInvalidOrder, "invalid replacement: side %s does not match original %s", newOrder.Side, old.Side)
+	}
+	if _, claimed := me.claimOrderID(newOrder.ID); !claimed {
+		err := rejectf(ErrDuplicateOrderID, "duplicate order id %s: conflicts with an existing order", newOrder.ID)
+		me.recordRejection(newOrder, rejectDuplicate, err)
+		return nil, ProcessOrderResponse{}, err
+	}
+	defer me.releaseOrderID(newOrder.ID)
+	if reason, err := admit(book, newOrder); err != nil {
+		me.recordRejection(newOrder, reason, err)
+		return nil, ProcessOrderResponse{}, err
+	}
+
+	newOrder.Sequence = me.orderSeq.Add(1)
+	received := *newOrder
+	if err := me.logWAL(WALEntry{Op: WALReplace, OrderID: oldID, Order: &received}); err != nil {
+		me.recordRejection(newOrder, rejectWAL, err)
+		return nil, ProcessOrderResponse{}, err
+	}
+	// Staged after logging, as for a new order, so a rejection replays as one
+	if reason, err := stageFill(book, newOrder); err != nil {
+		me.recordRejection(newOrder, reason, err)
+		return nil, ProcessOrderResponse{}, err
+	}
+
+	old.Status = StatusCancelled
+	old.CancelReason = ReasonReplaced
+	book.CancelOrder(old.ID)
+	book.emitOrder(EventCancelled, old)
+	cancelled := *old
+	cancelled.element = nil
+
+	response := me.execute(book, newOrder)
+	return &cancelled, response, nil
+}
@@ -0,0 +1,59 @@
+package engine
+
+import "sort"
+
+// --- Session Roll ---
+//
+// RollSession starts a new trading day for a symbol, for a scheduler to call
+// between sessions. It resets:
+//
+//   - DAY orders, resting or armed, which expire (cancel_reason EXPIRED)
+//     exactly as they would have at the close
+//   - the ticker: GetLastTrade reports no trade until the symbol trades again
+//   - candles, which start empty
+//
+// and preserves everything else: GTC and GTD orders with their queue
+// priority, trade history (GetTrades), positions, the order history, symbol
+// configuration and the book's phase. The last trade price also stays the
+// reference for price bands, stop triggers and auction tie-breaks until the
+// symbol trades again, so the previous close anchors the new day.
+
+// RollSession rolls symbol over to a new session, returning copies of the
+// DAY orders it expired, oldest deadline first. The roll is logged to the
+// WAL and replayed from it. With strict symbols on, an unlisted symbol is
+// refused with ErrUnknownSymbol, as it is on submission.
+func (me *MatchingEngine) RollSession(symbol string) ([]*Order, error) {
+	if _, err := me.lookupSymbolConfig(symbol); err != nil {
+		return nil, err
+	}
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := me.logWAL(WALEntry{Op: WALRollSession, Symbol: symbol}); err != nil {
+		return nil, err
+	}
+	var day []*Order
+	for _, order := range book.expiring {
+		if order.TimeInForce == Day {
+			day = append(day, order)
+		}
+	}
+	sort.Slice(day, func(i, j int) bool {
+		if day[i].ExpiresAt != day[j].ExpiresAt {
+			return day[i].ExpiresAt < day[j].ExpiresAt
+		}
+		return day[i].ID < day[j].ID
+	})
+	expired := make([]*Order, len(day))
+	for i, order := range day {
+		book.expire(order)
+		orderCopy := *order
+		expired[i] = &orderCopy
+	}
+
+	book.sessionRolled = true
+	book.candles = nil
+	me.publishDepth(book)
+	return expired, nil
+}
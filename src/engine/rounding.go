@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// --- Rounding ---
+//
+// Fee calculation, the cash a notional order has spent and pro-rata
+// allocation all divide integers. They round through the helpers here, under
+// one engine-wide RoundingMode (see SetRoundingMode), so the same inputs
+// round the same way everywhere.
+//
+// The default, RoundConservative, rounds each calculation in the direction
+// that protects the exchange and the book: taker fees up and maker rebates
+// down, spent cash up (so the leftover is never overstated), and pro-rata
+// shares down with the residual handed out in time priority. The other modes
+// round every exact result the same way regardless of who it favours.
+//
+// Some divisions are limits rather than roundings and always truncate: a
+// notional order never buys more than its cash affords, and a market price
+// band never widens.
+
+// RoundingMode is how integer divisions are rounded.
+type RoundingMode string
+
+const (
+	RoundConservative RoundingMode = ""          // Per calculation, in the exchange's favour (the default)
+	RoundTruncate     RoundingMode = "TRUNCATE"  // Toward zero
+	RoundHalfUp       RoundingMode = "HALF_UP"   // To nearest, halves away from zero
+	RoundHalfEven     RoundingMode = "HALF_EVEN" // To nearest, halves to even (banker's)
+)
+
+// ParseRoundingMode returns the mode named s, in any case; "CONSERVATIVE" is
+// accepted for the default.
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch mode := RoundingMode(strings.ToUpper(s)); mode {
+	case RoundConservative, RoundTruncate, RoundHalfUp, RoundHalfEven:
+		return mode, nil
+	case "CONSERVATIVE":
+		return RoundConservative, nil
+	}
+	return "", fmt.Errorf("invalid rounding mode %q; must be CONSERVATIVE, TRUNCATE, HALF_UP or HALF_EVEN", s)
+}
+
+// SetRoundingMode sets how fees, notional spend and pro-rata shares are
+// rounded on every book. Like the symbol configuration, a book picks it up
+// the next time it matches.
+func (me *MatchingEngine) SetRoundingMode(mode RoundingMode) error {
+	mode, err := ParseRoundingMode(string(mode))
+	if err != nil {
+		return err
+	}
+	me.configMutex.Lock()
+	me.rounding = mode
+	me.configMutex.Unlock()
+	return nil
+}
+
+// mulDiv returns a * b / d rounded by mode, computing the product in 128
+// bits so it cannot overflow. Under RoundConservative it rounds up when up
+// is set and down otherwise. a and b must be non-negative, d positive, and
+// the result must fit in an int64.
+func (mode RoundingMode) mulDiv(a, b, d int64, up bool) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	q, r := bits.Div64(hi, lo, uint64(d))
+	return int64(q) + mode.roundUp(q, r, uint64(d), up)
+}
+
+// div returns n / d rounded by mode, like mulDiv with b = 1.
+func (mode RoundingMode) div(n, d int64, up bool) int64 {
+	return mode.mulDiv(n, 1, d, up)
+}
+
+// roundUp returns 1 if the quotient q with remainder r of a division by d
+// rounds up, else 0.
+func (mode RoundingMode) roundUp(q, r, d uint64, up bool) int64 {
+	if r == 0 {
+		return 0
+	}
+	switch mode {
+	case RoundTruncate:
+		return 0
+	case RoundHalfUp:
+		if r >= d-r {
+			return 1
+		}
+	case RoundHalfEven:
+		if r > d-r || (r == d-r && q%2 == 1) {
+			return 1
+		}
+	default:
+		if up {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,17 @@
+package engine
+
+// NewSandbox returns a new engine with this engine's symbol configuration,
+// strictness and rounding mode but none of its state: no books, orders,
+// trades, WAL or hooks. Backtests replay orders into one without touching
+// the live engine.
+func (me *MatchingEngine) NewSandbox() *MatchingEngine {
+	sandbox := NewMatchingEngine()
+	me.configMutex.RLock()
+	for symbol, cfg := range me.symbolConfigs {
+		sandbox.symbolConfigs[symbol] = cfg
+	}
+	sandbox.strictSymbols = me.strictSymbols
+	sandbox.rounding = me.rounding
+	me.configMutex.RUnlock()
+	return sandbox
+}
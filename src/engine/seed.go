@@ -0,0 +1,114 @@
+package engine
+
+// --- Book Seeding ---
+//
+// SeedBook is a maintenance and test hook for loading a book in bulk, as for
+// load tests or scenario setup. Unlike SubmitBatch it does not match: every
+// order goes straight into the book, so the orders must not cross each other
+// or the book. Each seeded order is logged to the WAL as a submission; on
+// replay it cannot cross either, so it rests exactly as it was seeded.
+
+// SeedBook inserts resting GTC or DAY limit orders into symbol's book without
+// matching. Orders are checked against the symbol's configuration, for
+// duplicate IDs, against MaxOrders, and for crossing (any bid at or above
+// any ask, seeded or already resting); if any check fails nothing is
+// seeded. Each order's Timestamp is set to the seeding time and its
+// Sequence to the next engine sequence in slice order, so within a price
+// level priority follows the slice. An empty Symbol on an order means
+// symbol. Only a WAL failure stops part way, leaving the orders before it
+// seeded.
+func (me *MatchingEngine) SeedBook(symbol string, orders []*Order) error {
+	ids := make(map[string]bool, len(orders))
+	for _, order := range orders {
+		if order.Symbol == "" {
+			order.Symbol = symbol
+		}
+		if err := checkSeed(symbol, order); err != nil {
+			return err
+		}
+		if ids[order.ID] {
+			return rejectf(ErrDuplicateOrderID, "duplicate order id %s in seed", order.ID)
+		}
+		ids[order.ID] = true
+		if _, err := me.validateOrder(order); err != nil {
+			return err
+		}
+	}
+
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	cfg, err := me.lookupSymbolConfig(symbol)
+	if err != nil {
+		return err
+	}
+	book.config = cfg
+	for _, order := range orders {
+		if _, claimed := me.claimOrderID(order.ID); !claimed {
+			return rejectf(ErrDuplicateOrderID, "duplicate order id %s: already exists", order.ID)
+		}
+		defer me.releaseOrderID(order.ID)
+	}
+	if cfg.MaxOrders > 0 && book.resting+len(orders) > cfg.MaxOrders {
+		return rejectf(ErrBookFull, "order book for %s is full: %d orders resting, %d to seed, limit %d", symbol, book.resting, len(orders), cfg.MaxOrders)
+	}
+	if err := checkSeedCross(book, orders); err != nil {
+		return err
+	}
+
+	now := me.now().UnixNano() / 1_000_000
+	defer me.publishDepth(book)
+	for _, order := range orders {
+		order.Timestamp = now
+		order.Sequence = me.orderSeq.Add(1)
+		order.Status = StatusAccepted
+		order.FilledQuantity = 0
+		received := *order
+		if err := me.logWAL(WALEntry{Op: WALSubmit, Order: &received}); err != nil {
+			return err
+		}
+		me.storeOrder(order)
+		book.emitOrder(EventAccepted, order)
+		book.addOrder(order)
+	}
+	return nil
+}
+
+// checkSeed checks that order is a plain resting limit order for symbol.
+func checkSeed(symbol string, order *Order) error {
+	switch {
+	case order.ID == "":
+		return rejectf(ErrInvalidOrder, "invalid seed order: id is required")
+	case order.Symbol != symbol:
+		return rejectf(ErrInvalidOrder, "invalid seed order %s: symbol %s is not %s", order.ID, order.Symbol, symbol)
+	case order.Type != Limit:
+		return rejectf(ErrInvalidOrder, "invalid seed order %s: only LIMIT orders can be seeded", order.ID)
+	case order.TimeInForce == IOC || order.TimeInForce == FOK:
+		return rejectf(ErrInvalidOrder, "invalid seed order %s: %s orders cannot rest", order.ID, order.TimeInForce)
+	case order.Side != Buy && order.Side != Sell:
+		return rejectf(ErrInvalidOrder, "invalid seed order %s: invalid side %q", order.ID, order.Side)
+	case order.Quantity <= 0 || order.Notional != 0:
+		return rejectf(ErrInvalidOrder, "invalid seed order %s: quantity must be positive", order.ID)
+	}
+	return nil
+}
+
+// checkSeedCross rejects seed orders that would cross each other or the
+// book. The book lock is held.
+func checkSeedCross(book *OrderBook, orders []*Order) error {
+	bid, hasBid := book.bestOpposite(Sell)
+	ask, hasAsk := book.bestOpposite(Buy)
+	for _, order := range orders {
+		if order.Side == Buy && (!hasBid || order.Price > bid) {
+			bid, hasBid = order.Price, true
+		}
+		if order.Side == Sell && (!hasAsk || order.Price < ask) {
+			ask, hasAsk = order.Price, true
+		}
+	}
+	if hasBid && hasAsk && bid >= ask {
+		return rejectf(ErrInvalidOrder, "invalid seed: best bid %d would cross best ask %d", bid, ask)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package engine
+
+import "fmt"
+
+// --- Self-Cross Guard ---
+//
+// Self-match prevention acts while matching. A symbol with SelfCross set
+// also checks at submission: an account's incoming limit order priced to
+// cross one of the same account's resting orders, such as a sell at 149
+// against its own bid at 150, is caught before it reaches the matcher.
+// SelfCrossReject refuses it with SELF_CROSS. SelfCrossReprice instead
+// moves its price one tick (TickSize, or 1) short of the account's best
+// opposite resting price, so it can still trade with other accounts ahead
+// of that price and then rests; if that would take a price to zero or below
+// on a symbol without negative prices, it is rejected. As after an
+// amendment, resubmitting a repriced order's ID with the original price
+// conflicts. A limit order amended to a new price is checked the same way.
+// Orders without an AccountID, market, pegged and conditional orders, and
+// orders entered while the book is collecting for an auction are not
+// checked.
+
+// SelfCrossAction selects what the self-cross guard does with an order that
+// would cross its own account's resting orders.
+type SelfCrossAction string
+
+const (
+	SelfCrossReject  SelfCrossAction = "REJECT"  // Refuse the order with SELF_CROSS
+	SelfCrossReprice SelfCrossAction = "REPRICE" // Move it one tick short of the account's own price
+)
+
+// checkSelfCross rejects an unknown self_cross action.
+func (cfg SymbolConfig) checkSelfCross() error {
+	if cfg.SelfCross != "" && cfg.SelfCross != SelfCrossReject && cfg.SelfCross != SelfCrossReprice {
+		return fmt.Errorf("invalid symbol config: unknown self_cross %q", cfg.SelfCross)
+	}
+	return nil
+}
+
+// guardSelfCross applies the symbol's self-cross guard to an incoming order,
+// repricing it or returning the rejection. The book lock is held.
+func (ob *OrderBook) guardSelfCross(order *Order) error {
+	if ob.config.SelfCross == "" || order.AccountID == "" || order.Type != Limit || ob.collecting() {
+		return nil
+	}
+	own, ok := ob.ownBestOpposite(order)
+	if !ok {
+		return nil
+	}
+	if ob.config.SelfCross == SelfCrossReject {
+		return rejectf(ErrSelfCross, "order would cross account %s's own resting order at %d", order.AccountID, own)
+	}
+	tick := max(ob.config.TickSize, 1)
+	price := own + tick
+	if order.Side == Buy {
+		price = own - tick
+	}
+	if price <= 0 && !ob.config.AllowNegativePrice {
+		return rejectf(ErrSelfCross, "order would cross account %s's own resting order at %d and cannot be repriced clear of it", order.AccountID, own)
+	}
+	order.Price = price
+	return nil
+}
+
+// ownBestOpposite returns the best price at which order's account rests an
+// order the incoming order would cross, hidden orders included. Only the
+// levels the order crosses are walked, and only until the first of its
+// account's orders, so the lookup costs no more than the match would.
+func (ob *OrderBook) ownBestOpposite(order *Order) (int64, bool) {
+	tree := ob.asks
+	if order.Side == Sell {
+		tree = ob.bids
+	}
+	var own int64
+	found := false
+	tree.Ascend(func(level *PriceLevel) bool {
+		if !crosses(order, level.Price) {
+			return false
+		}
+		for e := level.Orders.Front(); e != nil; e = e.Next() {
+			if e.Value.(*Order).AccountID == order.AccountID {
+				own, found = level.Price, true
+				return false
+			}
+		}
+		return true
+	})
+	return own, found
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Self-Match Prevention ---
+//
+// Desks that cannot share an account ID can still keep their orders from
+// trading with each other by namespacing their order IDs. On a symbol with
+// SelfMatchPrefixes, two orders whose IDs start with the same listed prefix
+// belong to one desk. An aggressor that reaches a resting order of its own
+// desk stops matching there and its unfilled remainder is cancelled with
+// cancel_reason SELF_MATCH (cancel newest): trades it made before that
+// point stand and the resting order is left alone. Under pro-rata matching
+// the aggressor stops before any level holding one of its desk's orders,
+// since it could not avoid a share of it.
+//
+// Market, FOK and AON orders only count the liquidity ahead of their desk's
+// first order when checking whether they can fill, so an order that could
+// only fill by trading with itself is rejected (market, FOK) or rests (AON)
+// as if that liquidity were not there. Orders whose IDs match no prefix
+// trade normally. Call auctions do not apply the check.
+
+// checkSelfMatchPrefixes rejects empty prefixes, which would put every
+// order on one desk.
+func (cfg SymbolConfig) checkSelfMatchPrefixes() error {
+	for _, prefix := range cfg.SelfMatchPrefixes {
+		if prefix == "" {
+			return fmt.Errorf("invalid symbol config: self_match_prefixes must not contain an empty prefix")
+		}
+	}
+	return nil
+}
+
+// desk returns the configured prefix an order ID starts with, the longest
+// if several do, or "" if it belongs to no desk.
+func (cfg SymbolConfig) desk(id string) string {
+	var desk string
+	for _, prefix := range cfg.SelfMatchPrefixes {
+		if len(prefix) > len(desk) && strings.HasPrefix(id, prefix) {
+			desk = prefix
+		}
+	}
+	return desk
+}
+
+// selfMatch reports whether aggressor and resting belong to the same desk
+// and so must not trade.
+func (ob *OrderBook) selfMatch(aggressor, resting *Order) bool {
+	if len(ob.config.SelfMatchPrefixes) == 0 {
+		return false
+	}
+	desk := ob.config.desk(aggressor.ID)
+	return desk != "" && ob.config.desk(resting.ID) == desk
+}
+
+// stopSelfMatch ends an aggressor's matching; its remainder is cancelled
+// once matching returns.
+func stopSelfMatch(order *Order) {
+	order.CancelReason = ReasonSelfMatch
+}
+
+// selfMatchStopped reports whether stopSelfMatch ended order's matching.
+func selfMatchStopped(order *Order) bool {
+	return order.CancelReason == ReasonSelfMatch
+}
+
+// levelHasSelfMatch reports whether a level holds an order, other than an
+// AON order left out of pro-rata allocation, of the aggressor's desk.
+func (ob *OrderBook) levelHasSelfMatch(order *Order, level *PriceLevel) bool {
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		if resting := e.Value.(*Order); !resting.AllOrNone && ob.selfMatch(order, resting) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+)
+
+// --- Trading Session ---
+//
+// DAY orders rest like GTC orders but are cancelled at the symbol's session
+// close. On submission a DAY order's ExpiresAt is set to today's close (in
+// the session time zone), so the expiry reaper sweeps it like any GTD order;
+// one that arrives at or after the close is rejected. A GTD order may give
+// an ExpireDate instead of an ExpiresAt; it is stamped the same way with the
+// close on that date, or the end of the date if the symbol has no close. An
+// order that already carries an ExpiresAt, such as one replayed from the
+// WAL, keeps it, which keeps replay independent of the wall clock.
+
+// SetClock replaces the engine's time source, which decides DAY order
+// expiry and drives the expiry reaper. It is meant for tests and must be
+// called before the engine serves traffic.
+func (me *MatchingEngine) SetClock(now func() time.Time) {
+	me.clock = now
+}
+
+func (me *MatchingEngine) now() time.Time {
+	return me.clock()
+}
+
+// sessionClose returns the session close on now's date, or the zero time if
+// the symbol has no close configured.
+func (cfg SymbolConfig) sessionClose(now time.Time) (time.Time, error) {
+	return cfg.sessionTime(now, "session_close", cfg.SessionClose)
+}
+
+// sessionTime returns the time of day hhmm on now's date in the session time
+// zone, or the zero time if hhmm is empty. field names it in errors.
+func (cfg SymbolConfig) sessionTime(now time.Time, field, hhmm string) (time.Time, error) {
+	if hhmm == "" {
+		return time.Time{}, nil
+	}
+	hm, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid symbol config: %s %q must be HH:MM", field, hhmm)
+	}
+	loc, err := cfg.sessionLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), hm.Hour(), hm.Minute(), 0, 0, loc), nil
+}
+
+// sessionLocation returns the session time zone, UTC when none is set.
+func (cfg SymbolConfig) sessionLocation() (*time.Location, error) {
+	if cfg.SessionTimeZone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(cfg.SessionTimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid symbol config: session_time_zone: %w", err)
+	}
+	return loc, nil
+}
+
+// stampDayExpiry sets a DAY order to expire at today's close.
+func (cfg SymbolConfig) stampDayExpiry(order *Order, now time.Time) error {
+	closeAt, err := cfg.sessionClose(now)
+	if err != nil {
+		return err
+	}
+	if closeAt.IsZero() {
+		return rejectf(ErrInvalidOrder, "invalid order: DAY orders need a session close configured for %s", order.Symbol)
+	}
+	if !now.Before(closeAt) {
+		return rejectf(ErrInvalidOrder, "invalid order: session for %s closed at %s", order.Symbol, closeAt.Format("15:04 MST"))
+	}
+	order.ExpiresAt = closeAt.UnixNano() / 1_000_000
+	return nil
+}
+
+// stampDateExpiry sets a GTD order to expire at the session close on its
+// ExpireDate, or at the end of that date without a close. An expiry at or
+// before now is rejected.
+func (cfg SymbolConfig) stampDateExpiry(order *Order, now time.Time) error {
+	loc, err := cfg.sessionLocation()
+	if err != nil {
+		return err
+	}
+	date, err := time.ParseInLocation(time.DateOnly, order.ExpireDate, loc)
+	if err != nil {
+		return rejectf(ErrInvalidOrder, "invalid order: expire_date %q must be an ISO-8601 date (YYYY-MM-DD)", order.ExpireDate)
+	}
+	expiry := date.AddDate(0, 0, 1) // Midnight ending the date
+	closeAt, err := cfg.sessionClose(date)
+	if err != nil {
+		return err
+	}
+	if !closeAt.IsZero() {
+		expiry = closeAt
+	}
+	if !now.Before(expiry) {
+		return rejectf(ErrInvalidOrder, "invalid order: expire_date %s is in the past (expired %s)", order.ExpireDate, expiry.Format(time.RFC3339))
+	}
+	order.ExpiresAt = expiry.UnixNano() / 1_000_000
+	return nil
+}
+
+// --- Trading Hours ---
+//
+// A symbol with SessionOpen configured only trades from SessionOpen until
+// SessionClose each day, in SessionTimeZone; sessions do not span midnight.
+// From SessionPreOpen, if set, until the open the book is PreOpen: orders
+// are collected without matching, as in a call auction, and the book
+// uncrosses at the open. At any other time new orders are rejected with
+// MARKET_CLOSED. Cancels are always allowed.
+//
+// Transitions happen under the book lock when an order arrives or
+// SyncSessions runs (the expiry reaper calls it on every sweep), so the
+// opening uncross does not wait for the first order of the day. They are
+// logged to the WAL, and replay applies the logged transitions instead of
+// consulting the clock.
+
+// SessionState is where a symbol is in its trading day.
+type SessionState string
+
+const (
+	SessionOpen    SessionState = "OPEN"     // Continuous trading, or no hours configured
+	SessionPreOpen SessionState = "PRE_OPEN" // Orders collected for the opening uncross
+	SessionClosed  SessionState = "CLOSED"   // New orders rejected
+)
+
+// validateSession checks the trading hours are well formed and in order.
+func (cfg SymbolConfig) validateSession(now time.Time) error {
+	closeAt, err := cfg.sessionClose(now)
+	if err != nil {
+		return err
+	}
+	openAt, err := cfg.sessionTime(now, "session_open", cfg.SessionOpen)
+	if err != nil {
+		return err
+	}
+	preOpenAt, err := cfg.sessionTime(now, "session_pre_open", cfg.SessionPreOpen)
+	if err != nil {
+		return err
+	}
+	if openAt.IsZero() {
+		if !preOpenAt.IsZero() {
+			return fmt.Errorf("invalid symbol config: session_pre_open needs session_open")
+		}
+		return nil
+	}
+	if closeAt.IsZero() || !openAt.Before(closeAt) {
+		return fmt.Errorf("invalid symbol config: session_open %s must be before session_close", cfg.SessionOpen)
+	}
+	if !preOpenAt.IsZero() && preOpenAt.After(openAt) {
+		return fmt.Errorf("invalid symbol config: session_pre_open %s must not be after session_open", cfg.SessionPreOpen)
+	}
+	return nil
+}
+
+// sessionState returns the symbol's session state at now. The config has
+// been through validateSession.
+func (cfg SymbolConfig) sessionState(now time.Time) SessionState {
+	if cfg.SessionOpen == "" {
+		return SessionOpen
+	}
+	closeAt, _ := cfg.sessionClose(now)
+	openAt, _ := cfg.sessionTime(now, "session_open", cfg.SessionOpen)
+	preOpenAt, _ := cfg.sessionTime(now, "session_pre_open", cfg.SessionPreOpen)
+	if preOpenAt.IsZero() {
+		preOpenAt = openAt
+	}
+	switch {
+	case now.Before(preOpenAt):
+		return SessionClosed
+	case now.Before(openAt):
+		return SessionPreOpen
+	case now.Before(closeAt):
+		return SessionOpen
+	}
+	return SessionClosed
+}
+
+// advanceSession moves the book into PreOpen, or uncrosses it out of
+// PreOpen, as the session state at now requires, and returns that state.
+// A transition that cannot be logged is retried on the next call. Halted
+// books are left alone. The book lock is held.
+func (me *MatchingEngine) advanceSession(book *OrderBook, now time.Time) SessionState {
+	state := book.config.sessionState(now)
+	switch {
+	case state == SessionPreOpen && book.phase == Continuous:
+		if me.logWAL(WALEntry{Op: WALPreOpen, Symbol: book.symbol}) == nil {
+			book.phase = PreOpen
+		}
+	case state == SessionOpen && book.phase == PreOpen:
+		if me.logWAL(WALEntry{Op: WALOpen, Symbol: book.symbol}) == nil {
+			me.reopen(book)
+		}
+	}
+	return state
+}
+
+// SyncSessions applies any session transition due at now to every book.
+func (me *MatchingEngine) SyncSessions(now time.Time) {
+	if me.replaying.Load() {
+		return
+	}
+	for _, sb := range me.allBooks() {
+		sb.lock.Lock()
+		sb.book.config = me.GetSymbolConfig(sb.symbol)
+		me.advanceSession(sb.book, now)
+		sb.lock.Unlock()
+	}
+}
+
+// replaySession applies a logged session transition regardless of the clock.
+func (me *MatchingEngine) replaySession(op WALOp, symbol string) {
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+	switch {
+	case op == WALPreOpen && book.phase == Continuous:
+		book.phase = PreOpen
+	case op == WALOpen && book.phase == PreOpen:
+		me.reopen(book)
+	}
+}
+
+// SessionStatus reports a symbol's trading hours, its session state by the
+// engine clock, and its book's trading phase. The phase can lag the state
+// briefly: a book stays PreOpen past the open until the next order or
+// SyncSessions uncrosses it.
+type SessionStatus struct {
+	Symbol   string       `json:"symbol"`
+	State    SessionState `json:"state"`
+	Phase    TradingPhase `json:"phase"`
+	PreOpen  string       `json:"pre_open,omitempty"`
+	Open     string       `json:"open,omitempty"`
+	Close    string       `json:"close,omitempty"`
+	TimeZone string       `json:"time_zone,omitempty"`
+}
+
+// GetSessionStatus returns the symbol's SessionStatus at the engine clock.
+func (me *MatchingEngine) GetSessionStatus(symbol string) SessionStatus {
+	cfg := me.GetSymbolConfig(symbol)
+	return SessionStatus{
+		Symbol:   symbol,
+		State:    cfg.sessionState(me.now()),
+		Phase:    me.GetTradingPhase(symbol),
+		PreOpen:  cfg.SessionPreOpen,
+		Open:     cfg.SessionOpen,
+		Close:    cfg.SessionClose,
+		TimeZone: cfg.SessionTimeZone,
+	}
+}
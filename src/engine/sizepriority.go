@@ -0,0 +1,64 @@
+package engine
+
+import "cmp"
+
+// --- Size Priority Matching ---
+//
+// Under size priority the aggressor at a price level trades with the
+// largest resting order first, by visible quantity, and time priority only
+// breaks ties between orders of the same size. Displayed orders still come
+// before hidden ones, so a hidden order, however large, trades only once
+// the displayed orders at its price are gone. An iceberg competes with its
+// current slice; once the slice is used up it replenishes and moves to the
+// back of the queue as under FIFO, so it loses any tie. The AON and
+// self-match rules are FIFO's: an AON order the aggressor cannot fill is
+// passed over, and matching stops at the largest order of the aggressor's
+// own desk.
+
+// matchLevelSizePriority fills the incoming order against one price level,
+// largest resting order first.
+func (ob *OrderBook) matchLevelSizePriority(order *Order, level *PriceLevel, trades []Trade, filledOrders []*Order) ([]Trade, []*Order) {
+	for order.RemainingQuantity() > 0 {
+		restingOrder := largestFillable(level, order.RemainingQuantity())
+		if restingOrder == nil {
+			break
+		}
+		if ob.selfMatch(order, restingOrder) {
+			stopSelfMatch(order)
+			break
+		}
+		tradeQuantity := min(order.RemainingQuantity(), restingOrder.Visible())
+		trades, filledOrders = ob.fillResting(order, level, restingOrder, tradeQuantity, trades, filledOrders)
+	}
+	return trades, filledOrders
+}
+
+// largestFillable returns the first order in size priority that an
+// aggressor with incoming quantity left may trade with, or nil if every
+// order in the level is passed over.
+func largestFillable(level *PriceLevel, incoming int64) *Order {
+	var best *Order
+	for e := level.Orders.Front(); e != nil; e = e.Next() {
+		resting := e.Value.(*Order)
+		if passedOver(resting, incoming) {
+			continue
+		}
+		if best == nil || bySize(resting, best) < 0 {
+			best = resting
+		}
+	}
+	return best
+}
+
+// bySize orders resting orders at one level in size priority: displayed
+// before hidden, then larger visible quantity first. A stable sort keeps
+// time priority between equal sizes.
+func bySize(a, b *Order) int {
+	if a.Hidden != b.Hidden {
+		if b.Hidden {
+			return -1
+		}
+		return 1
+	}
+	return cmp.Compare(b.Visible(), a.Visible())
+}
@@ -0,0 +1,207 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes.
+const snapshotVersion = 1
+
+type engineSnapshot struct {
+	Version  int             `json:"version"`
+	Orders   []*Order        `json:"orders"` // Every order in the global store
+	Books    []*bookSnapshot `json:"books"`
+	Killed   bool            `json:"killed,omitempty"` // Kill switch engaged
+	EventSeq uint64          `json:"event_seq"`        // Last event emitted; numbering resumes after it
+}
+
+// bookSnapshot lists resting order IDs best price first and, within a price,
+// in FIFO order, so re-adding them in sequence rebuilds the exact queues.
+type bookSnapshot struct {
+	Symbol         string   `json:"symbol"`
+	Bids           []string `json:"bids"`
+	Asks           []string `json:"asks"`
+	Stops          []string `json:"stops"`
+	LastTradePrice int64    `json:"last_trade_price"`
+	LastTradeQty   int64    `json:"last_trade_quantity,omitempty"`
+	LastTradeTime  int64    `json:"last_trade_time,omitempty"`
+	LastTradeID    string   `json:"last_trade_id,omitempty"`
+	HasTraded      bool     `json:"has_traded"`
+	SessionRolled  bool     `json:"session_rolled,omitempty"`
+	Phase          TradingPhase `json:"phase,omitempty"`
+	HaltMode       HaltMode `json:"halt_mode,omitempty"`
+	OperatorHalt   HaltMode `json:"operator_halt,omitempty"` // See OrderBook.operatorHalt
+	ReferenceBid   int64    `json:"reference_bid,omitempty"`
+	ReferenceAsk   int64    `json:"reference_ask,omitempty"`
+	Positions      []Position `json:"positions,omitempty"`
+	Sequence       uint64   `json:"sequence"`
+}
+
+// Snapshot writes every book, resting order and the global order store to w
+// as versioned JSON. Each book is captured under its own read lock.
+func (me *MatchingEngine) Snapshot(w io.Writer) error {
+	me.orderStoreMutex.RLock()
+	bySymbol := make(map[string][]*Order)
+	for _, order := range me.orderStore {
+		bySymbol[order.Symbol] = append(bySymbol[order.Symbol], order)
+	}
+	me.orderStoreMutex.RUnlock()
+
+	seen := make(map[string]bool, len(bySymbol))
+	for symbol := range bySymbol {
+		seen[symbol] = true
+	}
+	me.books.Range(func(key, _ any) bool {
+		seen[key.(string)] = true
+		return true
+	})
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	snap := engineSnapshot{Version: snapshotVersion, Killed: me.killed.Load()}
+	for _, symbol := range symbols {
+		book, lock := me.getBookAndLock(symbol)
+		lock.RLock()
+		for _, order := range bySymbol[symbol] {
+			orderCopy := *order
+			orderCopy.element = nil
+			snap.Orders = append(snap.Orders, &orderCopy)
+		}
+		snap.Books = append(snap.Books, book.snapshotState())
+		lock.RUnlock()
+	}
+	// Read last, so it is at least the sequence of every event captured above
+	snap.EventSeq = me.events.last()
+
+	return json.NewEncoder(w).Encode(snap)
+}
+
+func (ob *OrderBook) snapshotState() *bookSnapshot {
+	state := &bookSnapshot{
+		Symbol:         ob.symbol,
+		LastTradePrice: ob.lastTradePrice,
+		LastTradeQty:   ob.lastTradeQty,
+		LastTradeTime:  ob.lastTradeTime,
+		LastTradeID:    ob.lastTradeID,
+		HasTraded:      ob.hasTraded,
+		SessionRolled:  ob.sessionRolled,
+		Phase:          ob.phase,
+		HaltMode:       ob.haltMode,
+		OperatorHalt:   ob.operatorHalt,
+		ReferenceBid:   ob.reference.bid,
+		ReferenceAsk:   ob.reference.ask,
+		Sequence:       ob.seq,
+	}
+	collect := func(ids *[]string) func(*PriceLevel) bool {
+		return func(pl *PriceLevel) bool {
+			for e := pl.Orders.Front(); e != nil; e = e.Next() {
+				*ids = append(*ids, e.Value.(*Order).ID)
+			}
+			return true
+		}
+	}
+	ob.bids.Ascend(collect(&state.Bids))
+	ob.asks.Ascend(collect(&state.Asks))
+	for _, stop := range ob.stops {
+		state.Stops = append(state.Stops, stop.ID)
+	}
+	for _, position := range ob.positions {
+		copied := *position
+		copied.Volume = slices.Clone(position.Volume)
+		state.Positions = append(state.Positions, copied)
+	}
+	sort.Slice(state.Positions, func(i, j int) bool { return state.Positions[i].AccountID < state.Positions[j].AccountID })
+	return state
+}
+
+// LoadSnapshot replaces the engine's state with one written by Snapshot.
+// It is meant to run at startup, before the engine serves any traffic.
+func (me *MatchingEngine) LoadSnapshot(r io.Reader) error {
+	var snap engineSnapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	orderStore := make(map[string]*Order, len(snap.Orders))
+	var lastSeq int64
+	for _, order := range snap.Orders {
+		orderStore[order.ID] = order
+		lastSeq = max(lastSeq, order.Sequence)
+	}
+
+	books := make(map[string]*OrderBook, len(snap.Books))
+	for _, state := range snap.Books {
+		book := NewOrderBook(state.Symbol)
+		book.events = me.events
+		book.ids = me.ids
+		book.lastTradePrice = state.LastTradePrice
+		book.lastTradeQty = state.LastTradeQty
+		book.lastTradeTime = state.LastTradeTime
+		book.lastTradeID = state.LastTradeID
+		book.hasTraded = state.HasTraded
+		book.sessionRolled = state.SessionRolled
+		if state.Phase != "" {
+			book.phase = state.Phase
+		}
+		book.haltMode = state.HaltMode
+		book.operatorHalt = state.OperatorHalt
+		book.reference = referenceQuote{bid: state.ReferenceBid, ask: state.ReferenceAsk}
+		for _, position := range state.Positions {
+			book.positions[position.AccountID] = &position
+		}
+		for _, ids := range [][]string{state.Bids, state.Asks} {
+			for _, id := range ids {
+				order, ok := orderStore[id]
+				if !ok {
+					return fmt.Errorf("invalid snapshot: resting order %s missing from store", id)
+				}
+				book.addOrder(order)
+			}
+		}
+		for _, id := range state.Stops {
+			order, ok := orderStore[id]
+			if !ok {
+				return fmt.Errorf("invalid snapshot: stop order %s missing from store", id)
+			}
+			book.armStop(order)
+		}
+		// Rebuilding is not a market data change
+		clear(book.dirtyBids)
+		clear(book.dirtyAsks)
+		book.seq = state.Sequence
+		books[state.Symbol] = book
+	}
+
+	me.createMutex.Lock()
+	me.books.Clear()
+	for symbol, book := range books {
+		me.books.Store(symbol, book)
+	}
+	me.killed.Store(snap.Killed)
+	me.createMutex.Unlock()
+
+	me.orderStoreMutex.Lock()
+	me.orderStore = orderStore
+	me.tags = tagIndex{}
+	for _, order := range orderStore {
+		if order.Status != StatusFilled && order.Status != StatusCancelled {
+			me.tags.add(order)
+		}
+	}
+	me.orderStoreMutex.Unlock()
+	// New orders must sort after every restored one
+	me.orderSeq.Store(lastSeq)
+	me.events.restore(snap.EventSeq)
+	me.seedIDs()
+	return nil
+}
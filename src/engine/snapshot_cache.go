@@ -0,0 +1,42 @@
+package engine
+
+// --- Cached Depth Snapshots ---
+//
+// GetCachedSnapshot serves repeated depth reads, such as dashboards polling
+// the REST API, without walking the book each time. Each book keeps one
+// full-depth snapshot, stamped with the depth sequence it was built at. The
+// sequence advances with every depth change (see flushDepth), so a cached
+// snapshot whose sequence is current, taken while no change is pending, is
+// exactly what a fresh one would be. Anything else rebuilds it.
+
+// GetCachedSnapshot is GetBookSnapshot served from the book's cached
+// full-depth snapshot when the book has not changed since it was built, and
+// rebuilt under the read lock otherwise. Depth slices the cached levels.
+// The returned slices are the caller's own.
+func (me *MatchingEngine) GetCachedSnapshot(symbol string, depth int) OrderBookSnapshot {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	full := book.cached.Load()
+	if full == nil || full.Sequence != book.seq || len(book.dirtyBids) > 0 || len(book.dirtyAsks) > 0 {
+		snap := book.bookSnapshot(0)
+		full = &snap
+		// Readers share the read lock, so several may rebuild at once;
+		// they build the same snapshot and the last store wins
+		book.cached.Store(full)
+	}
+
+	snap := *full
+	snap.Bids = truncateLevels(full.Bids, depth)
+	snap.Asks = truncateLevels(full.Asks, depth)
+	return snap
+}
+
+// truncateLevels copies the first depth levels (all of them if depth is 0).
+func truncateLevels(levels []AggregatedPriceLevel, depth int) []AggregatedPriceLevel {
+	if depth > 0 && depth < len(levels) {
+		levels = levels[:depth]
+	}
+	return append([]AggregatedPriceLevel{}, levels...)
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"container/list"
+	"fmt"
+	"time"
+)
+
+// --- Order Age ---
+//
+// An order's age is the engine clock's time less its Timestamp, which is
+// reset whenever it loses priority. Each level's queue is in time order,
+// its displayed orders first and then its hidden ones, so the oldest order
+// in a book is at the FIFO front of one of those queues, and the stale ones
+// are a run at each front. SetMaxOrderAge sets the age past which a resting
+// order is reported stale: a warning logged once per order and counted on
+// ome_stale_orders_total, never a cancel (expiry is GTD's job). The oldest
+// order's age is in GlobalStats and, per symbol, on ome_oldest_order_age_seconds.
+
+// SetMaxOrderAge sets the age past which a resting order is stale; 0, the
+// default, turns stale-order detection off.
+func (me *MatchingEngine) SetMaxOrderAge(age time.Duration) error {
+	if age < 0 {
+		return fmt.Errorf("invalid max order age %v", age)
+	}
+	me.maxOrderAge.Store(int64(age))
+	return nil
+}
+
+// OldestOrderAge returns how long the oldest order resting in symbol's book
+// has held its priority, by the engine clock, or 0 if nothing rests.
+func (me *MatchingEngine) OldestOrderAge(symbol string) time.Duration {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.oldestOrderAge(me.now())
+}
+
+// CheckStaleOrders reports every resting order older than the max order age
+// at now that has not been reported since it last gained priority, logging
+// a warning for each, and returns copies of them. The expiry reaper calls it
+// on every tick.
+func (me *MatchingEngine) CheckStaleOrders(now time.Time) []Order {
+	maxAge := time.Duration(me.maxOrderAge.Load())
+	if maxAge == 0 {
+		return nil
+	}
+	cutoff := now.Add(-maxAge).UnixMilli()
+	var stale []Order
+	for _, sb := range me.allBooks() {
+		sb.lock.Lock()
+		for _, order := range sb.book.staleOrders(cutoff) {
+			if order.staleReported == order.Timestamp {
+				continue
+			}
+			order.staleReported = order.Timestamp
+			stale = append(stale, *order)
+			me.metrics.staleOrders.WithLabelValues(sb.symbol).Inc()
+			me.logger.Warn("stale order", "symbol", sb.symbol, "order_id", order.ID, "account_id", order.AccountID,
+				"age", now.Sub(time.UnixMilli(order.Timestamp)), "max_age", maxAge)
+		}
+		sb.lock.Unlock()
+	}
+	return stale
+}
+
+// queues returns a level's two time-ordered queues, displayed then hidden,
+// as the element each starts at and the one it stops before.
+func (pl *PriceLevel) queues() [2][2]*list.Element {
+	return [2][2]*list.Element{{pl.Orders.Front(), pl.hidden}, {pl.hidden, nil}}
+}
+
+// oldestOrderAge returns the age at now of the book's oldest resting order,
+// or 0 if nothing rests.
+func (ob *OrderBook) oldestOrderAge(now time.Time) time.Duration {
+	var oldest *Order
+	visit := func(pl *PriceLevel) bool {
+		for _, q := range pl.queues() {
+			if q[0] == nil || q[0] == q[1] {
+				continue
+			}
+			if order := q[0].Value.(*Order); oldest == nil || order.Timestamp < oldest.Timestamp {
+				oldest = order
+			}
+		}
+		return true
+	}
+	ob.bids.Ascend(visit)
+	ob.asks.Ascend(visit)
+	if oldest == nil {
+		return 0
+	}
+	return max(now.Sub(time.UnixMilli(oldest.Timestamp)), 0)
+}
+
+// staleOrders returns the resting orders whose Timestamp is at or before
+// cutoff, reading each queue only as far as its first younger order.
+func (ob *OrderBook) staleOrders(cutoff int64) []*Order {
+	var stale []*Order
+	visit := func(pl *PriceLevel) bool {
+		for _, q := range pl.queues() {
+			for e := q[0]; e != nil && e != q[1]; e = e.Next() {
+				order := e.Value.(*Order)
+				if order.Timestamp > cutoff {
+					break
+				}
+				stale = append(stale, order)
+			}
+		}
+		return true
+	}
+	ob.bids.Ascend(visit)
+	ob.asks.Ascend(visit)
+	return stale
+}
@@ -0,0 +1,35 @@
+package engine
+
+import "time"
+
+// EngineStats is an at-a-glance view of the whole engine. Volume is the sum
+// of traded quantities in each symbol's own units (see QuantityDecimals), so
+// it is only meaningful across symbols that share a scale.
+type EngineStats struct {
+	Symbols        int           `json:"symbols"`
+	RestingOrders  int           `json:"resting_orders"`
+	TradesExecuted int64         `json:"trades_executed"`
+	Volume         int64         `json:"volume"`
+	Uptime         time.Duration `json:"uptime"`
+	OldestOrderAge time.Duration `json:"oldest_order_age"` // Across every book; see staleness.go
+}
+
+// GlobalStats aggregates EngineStats across every book. Trade totals are
+// counters kept from the event stream (see observeEvent); resting orders are read from each
+// book under its read lock, one book at a time.
+func (me *MatchingEngine) GlobalStats() EngineStats {
+	stats := EngineStats{
+		TradesExecuted: me.tradeCount.Load(),
+		Volume:         me.tradeVolume.Load(),
+		Uptime:         time.Since(me.started),
+	}
+	now := me.now()
+	for _, sb := range me.allBooks() {
+		sb.lock.RLock()
+		stats.RestingOrders += sb.book.resting
+		stats.OldestOrderAge = max(stats.OldestOrderAge, sb.book.oldestOrderAge(now))
+		sb.lock.RUnlock()
+		stats.Symbols++
+	}
+	return stats
+}
@@ -0,0 +1,86 @@
+package engine
+
+// --- Stop and If-Touched Orders ---
+//
+// Conditional orders trigger on the last trade price reaching StopPrice. The
+// direction depends on the kind:
+//
+//	           buy triggers on a trade      sell triggers on a trade
+//	stop       at or above StopPrice        at or below StopPrice
+//	if-touched at or below StopPrice        at or above StopPrice
+//
+// A stop fires when the market moves against the position (to cut a loss or
+// enter a breakout); an if-touched order fires when it moves in its favour
+// (to take profit or buy a dip). Both are "stops" below: they share the
+// armed list and firing rules.
+//
+// Armed stops live outside the price levels and are checked against the last
+// trade price after every match. When a single incoming order triggers more
+// than one stop, they fire one at a time in the order they were armed
+// (oldest first). Each fired stop may move the last trade price, so the armed
+// list is re-evaluated after every fire until nothing else triggers.
+
+// armStop parks a stop order until its trigger price is reached.
+func (ob *OrderBook) armStop(order *Order) {
+	ob.stops = append(ob.stops, order)
+	if order.ExpiresAt > 0 {
+		ob.expiring[order.ID] = order
+	}
+}
+
+// disarmStop removes an armed stop order by ID, e.g. on cancel.
+func (ob *OrderBook) disarmStop(orderID string) bool {
+	for i, stop := range ob.stops {
+		if stop.ID == orderID {
+			ob.stops = append(ob.stops[:i], ob.stops[i+1:]...)
+			delete(ob.expiring, orderID)
+			return true
+		}
+	}
+	return false
+}
+
+// stopTriggered reports whether the last trade price has reached the order's
+// trigger, in the direction given in the table above.
+func (ob *OrderBook) stopTriggered(order *Order) bool {
+	if !ob.hasTraded {
+		return false
+	}
+	// Buy stops and sell if-touched orders wait for the price to rise
+	if (order.Side == Buy) == order.IsStop() {
+		return ob.lastTradePrice >= order.StopPrice
+	}
+	return ob.lastTradePrice <= order.StopPrice
+}
+
+// triggerStops fires every armed stop whose trigger price has been crossed,
+// converting each into a market (STOP, MARKET_IF_TOUCHED) or limit
+// (STOP_LIMIT, LIMIT_IF_TOUCHED) order and running it through matching.
+// Triggered market stops never rest; any quantity that cannot execute is
+// cancelled.
+func (ob *OrderBook) triggerStops() ([]*Order, []Trade) {
+	var triggered []*Order
+	var trades []Trade
+
+	for {
+		index := -1
+		for i, stop := range ob.stops {
+			if ob.stopTriggered(stop) {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return triggered, trades
+		}
+
+		stop := ob.stops[index]
+		ob.stops = append(ob.stops[:index], ob.stops[index+1:]...)
+		delete(ob.expiring, stop.ID)
+		stop.Type = stop.triggeredType()
+
+		response := ob.processOrder(stop)
+		triggered = append(triggered, stop)
+		trades = append(trades, response.Trades...)
+	}
+}
@@ -0,0 +1,322 @@
+package engine
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"time"
+)
+
+// MatchingAlgorithm selects how an aggressor's fill is shared among the
+// resting orders at a price level.
+type MatchingAlgorithm string
+
+const (
+	FIFO         MatchingAlgorithm = "FIFO"          // Strict time priority (default)
+	ProRata      MatchingAlgorithm = "PRO_RATA"      // Proportional to displayed size; see prorata.go
+	SizePriority MatchingAlgorithm = "SIZE_PRIORITY" // Largest displayed size first, then time; see sizepriority.go
+)
+
+// PricePolicy selects the price a crossing limit order trades at.
+type PricePolicy string
+
+const (
+	RestingPrice PricePolicy = "RESTING"  // The resting order's price (default)
+	MidPoint     PricePolicy = "MIDPOINT" // Halfway between the aggressor's limit and the resting price
+	// Like MidPoint, but at most one tick off the resting price
+	PriceImprovement PricePolicy = "PRICE_IMPROVEMENT"
+)
+
+// SymbolConfig holds per-instrument trading parameters. Symbols without an
+// explicit config use the zero value.
+type SymbolConfig struct {
+	Symbol string `json:"symbol"`
+	// QuantityDecimals is the number of decimal places quantities carry;
+	// order quantities are stored in units of 10^-QuantityDecimals.
+	QuantityDecimals int `json:"quantity_decimals"`
+	// PriceScale is the number of decimal places in a price: prices are
+	// stored in units of 10^-PriceScale (2 for cents). Matching ignores it;
+	// the API uses it to read and write decimal prices such as "150.50".
+	PriceScale int `json:"price_scale,omitempty"`
+	// TickSize is the price increment limit and stop prices must align to.
+	TickSize int64 `json:"tick_size,omitempty"`
+	// LotSize is the quantity increment orders must align to.
+	LotSize int64 `json:"lot_size,omitempty"`
+	// MinQty and MaxQty bound the order quantity. Zero means no bound.
+	MinQty int64 `json:"min_qty,omitempty"`
+	MaxQty int64 `json:"max_qty,omitempty"`
+	// MaxNotional caps an order's value, price x quantity in price units
+	// (quantity scaled down by QuantityDecimals). Zero means no cap.
+	MaxNotional int64 `json:"max_notional,omitempty"`
+	// MaxOrders caps the live orders resting in the book. Once it is
+	// reached, orders that would rest are rejected; orders that fill
+	// completely on arrival still trade. Zero means no cap.
+	MaxOrders int `json:"max_orders,omitempty"`
+	// PriceBandBps limits trading to within this many basis points of the
+	// reference price (see bands.go). Zero disables the band.
+	PriceBandBps int64 `json:"price_band_bps,omitempty"`
+	// ReferencePrice anchors the band until the symbol first trades.
+	ReferencePrice int64 `json:"reference_price,omitempty"`
+	// AllowNegativePrice lets limit and stop prices be zero or negative, for
+	// instruments such as calendar spreads. Zero is then an ordinary price,
+	// so an omitted price is a price of 0, while a zero amend price still
+	// means "keep". Notional orders are refused on such symbols, and notional
+	// caps and fees apply to |price| x quantity.
+	AllowNegativePrice bool `json:"allow_negative_price,omitempty"`
+	// MatchingAlgorithm is FIFO when empty.
+	MatchingAlgorithm MatchingAlgorithm `json:"matching_algorithm,omitempty"`
+	// PricePolicy is RestingPrice when empty.
+	PricePolicy PricePolicy `json:"price_policy,omitempty"`
+	// Fees is the maker/taker schedule charged on every trade (see fees.go).
+	Fees FeeSchedule `json:"fees"`
+	// SessionClose is the daily close as "HH:MM" in SessionTimeZone (an IANA
+	// name, UTC when empty). DAY orders are only accepted when it is set.
+	SessionClose    string `json:"session_close,omitempty"`
+	SessionTimeZone string `json:"session_time_zone,omitempty"`
+	// SessionOpen, also "HH:MM", restricts trading to the hours from it to
+	// SessionClose; SessionPreOpen, if set, starts collecting orders for the
+	// opening uncross before it (see session.go). Empty means no hours.
+	SessionOpen    string `json:"session_open,omitempty"`
+	SessionPreOpen string `json:"session_pre_open,omitempty"`
+	// SelfMatchPrefixes stops orders whose IDs start with the same listed
+	// prefix (e.g. "deskA-") from trading with each other; see selfmatch.go.
+	// Empty disables the check.
+	SelfMatchPrefixes []string `json:"self_match_prefixes,omitempty"`
+	// SelfCross rejects or reprices an account's incoming limit order that
+	// would cross its own resting orders; see selfcross.go. Empty disables
+	// the guard.
+	SelfCross SelfCrossAction `json:"self_cross,omitempty"`
+
+	rounding RoundingMode // The engine's, filled in on lookup; see SetRoundingMode
+}
+
+// ConfigureSymbol registers or replaces the configuration for a symbol.
+func (me *MatchingEngine) ConfigureSymbol(cfg SymbolConfig) error {
+	return me.registerSymbol(cfg, true)
+}
+
+// registerSymbol validates cfg and registers it. Unless replace is set, a
+// symbol that is already registered is refused, checked under the same hold
+// of configMutex as the registration.
+func (me *MatchingEngine) registerSymbol(cfg SymbolConfig, replace bool) error {
+	if cfg.Symbol == "" {
+		return fmt.Errorf("invalid symbol config: symbol is required")
+	}
+	if cfg.QuantityDecimals < 0 || cfg.QuantityDecimals > MaxQuantityDecimals {
+		return fmt.Errorf("invalid symbol config: quantity_decimals must be between 0 and %d", MaxQuantityDecimals)
+	}
+	if cfg.PriceScale < 0 || cfg.PriceScale > MaxPriceScale {
+		return fmt.Errorf("invalid symbol config: price_scale must be between 0 and %d", MaxPriceScale)
+	}
+	if cfg.PriceBandBps < 0 || cfg.PriceBandBps >= 10_000 || (cfg.ReferencePrice < 0 && !cfg.AllowNegativePrice) {
+		return fmt.Errorf("invalid symbol config: price_band_bps must be between 0 and 9999 and reference_price must not be negative")
+	}
+	if cfg.TickSize < 0 || cfg.LotSize < 0 || cfg.MinQty < 0 || cfg.MaxQty < 0 || cfg.MaxNotional < 0 || cfg.MaxOrders < 0 {
+		return fmt.Errorf("invalid symbol config: tick_size, lot_size, min_qty, max_qty, max_notional and max_orders must not be negative")
+	}
+	if err := cfg.Fees.validate(); err != nil {
+		return err
+	}
+	if err := cfg.validateSession(time.Now()); err != nil {
+		return err
+	}
+	if _, ok := allocators[cfg.MatchingAlgorithm]; cfg.MatchingAlgorithm != "" && !ok {
+		return fmt.Errorf("invalid symbol config: unknown matching_algorithm %q", cfg.MatchingAlgorithm)
+	}
+	if cfg.PricePolicy != "" && cfg.PricePolicy != RestingPrice && cfg.PricePolicy != MidPoint && cfg.PricePolicy != PriceImprovement {
+		return fmt.Errorf("invalid symbol config: unknown price_policy %q", cfg.PricePolicy)
+	}
+	if cfg.MaxQty > 0 && cfg.MaxQty < cfg.MinQty {
+		return fmt.Errorf("invalid symbol config: max_qty %d is below min_qty %d", cfg.MaxQty, cfg.MinQty)
+	}
+	if err := cfg.checkSelfMatchPrefixes(); err != nil {
+		return err
+	}
+	if err := cfg.checkSelfCross(); err != nil {
+		return err
+	}
+	cfg.SelfMatchPrefixes = slices.Clone(cfg.SelfMatchPrefixes)
+	me.configMutex.Lock()
+	defer me.configMutex.Unlock()
+	if _, listed := me.symbolConfigs[cfg.Symbol]; listed && !replace {
+		return rejectf(ErrInvalidState, "symbol %s is already listed", cfg.Symbol)
+	}
+	me.symbolConfigs[cfg.Symbol] = cfg
+	return nil
+}
+
+// SetStrictSymbols controls how orders for unconfigured symbols are handled.
+// When strict, they are rejected; otherwise they trade under default rules.
+func (me *MatchingEngine) SetStrictSymbols(strict bool) {
+	me.configMutex.Lock()
+	me.strictSymbols = strict
+	me.configMutex.Unlock()
+}
+
+// GetSymbolConfig returns the symbol's configuration, or defaults if none
+// has been registered.
+func (me *MatchingEngine) GetSymbolConfig(symbol string) SymbolConfig {
+	me.configMutex.RLock()
+	cfg, ok := me.symbolConfigs[symbol]
+	rounding := me.rounding
+	me.configMutex.RUnlock()
+	if !ok {
+		cfg = SymbolConfig{Symbol: symbol}
+	}
+	cfg.rounding = rounding
+	return cfg
+}
+
+// lookupSymbolConfig returns the symbol's configuration and an error if the
+// symbol is unconfigured while strict mode is on.
+func (me *MatchingEngine) lookupSymbolConfig(symbol string) (SymbolConfig, error) {
+	me.configMutex.RLock()
+	cfg, ok := me.symbolConfigs[symbol]
+	strict := me.strictSymbols
+	rounding := me.rounding
+	me.configMutex.RUnlock()
+	if !ok {
+		if strict {
+			return SymbolConfig{}, rejectf(ErrUnknownSymbol, "unknown symbol: %s", symbol)
+		}
+		cfg = SymbolConfig{Symbol: symbol}
+	}
+	cfg.rounding = rounding
+	return cfg, nil
+}
+
+// validateOrder checks an incoming order against its symbol's tick, lot,
+// size and notional limits, returning the config it was checked against.
+// Market orders have no price yet; admit checks their notional against the
+// book.
+func (me *MatchingEngine) validateOrder(order *Order) (SymbolConfig, error) {
+	cfg, err := me.lookupSymbolConfig(order.Symbol)
+	if err != nil {
+		return cfg, err
+	}
+	if order.HasLimitPrice() {
+		if err := cfg.checkPrice("price", order.Price); err != nil {
+			return cfg, err
+		}
+	}
+	if order.IsConditional() {
+		if err := cfg.checkPrice("stop_price", order.StopPrice); err != nil {
+			return cfg, err
+		}
+	}
+	if err := checkAllOrNone(order); err != nil {
+		return cfg, err
+	}
+	if order.Type == MarketToLimit && order.TimeInForce == FOK {
+		return cfg, rejectf(ErrInvalidOrder, "invalid order: market-to-limit orders rest their remainder; use a FOK market order to fill completely or reject")
+	}
+	if err := checkMetadata(order); err != nil {
+		return cfg, err
+	}
+	if err := cfg.checkPeg(order); err != nil {
+		return cfg, err
+	}
+	if order.TimeInForce == Day && order.ExpiresAt == 0 {
+		if order.ExpireDate != "" {
+			return cfg, rejectf(ErrInvalidOrder, "invalid order: DAY orders expire at the session close; omit expire_date")
+		}
+		if err := cfg.stampDayExpiry(order, me.now()); err != nil {
+			return cfg, err
+		}
+	}
+	if order.ExpireDate != "" && order.ExpiresAt == 0 {
+		if err := cfg.stampDateExpiry(order, me.now()); err != nil {
+			return cfg, err
+		}
+	}
+	if order.Notional != 0 {
+		if err := cfg.checkNotional(order); err != nil {
+			return cfg, err
+		}
+		if cfg.MaxNotional > 0 && order.Notional > cfg.MaxNotional {
+			return cfg, rejectf(ErrInvalidOrder, "invalid order: notional %d exceeds maximum notional %d", order.Notional, cfg.MaxNotional)
+		}
+		return cfg, nil
+	}
+	if err := cfg.checkQuantity(order.Quantity); err != nil {
+		return cfg, err
+	}
+	switch order.Type {
+	case Limit, StopLimit, LimitIfTouched:
+		return cfg, cfg.checkMaxNotional(order.Price, order.Quantity)
+	case Stop, MarketIfTouched:
+		// Valued at the trigger; the fill price is unknown until it fires
+		return cfg, cfg.checkMaxNotional(order.StopPrice, order.Quantity)
+	}
+	return cfg, nil
+}
+
+func (cfg SymbolConfig) checkPrice(field string, price int64) error {
+	if price <= 0 && !cfg.AllowNegativePrice {
+		return rejectf(ErrInvalidOrder, "invalid order: %s must be > 0", field)
+	}
+	if cfg.TickSize > 0 && price%cfg.TickSize != 0 {
+		return rejectf(ErrInvalidOrder, "invalid order: %s %d is not a multiple of tick size %d", field, price, cfg.TickSize)
+	}
+	return nil
+}
+
+func (cfg SymbolConfig) checkQuantity(qty int64) error {
+	if cfg.LotSize > 0 && qty%cfg.LotSize != 0 {
+		return rejectf(ErrInvalidOrder, "invalid order: quantity %d is not a multiple of lot size %d", qty, cfg.LotSize)
+	}
+	if cfg.MinQty > 0 && qty < cfg.MinQty {
+		return rejectf(ErrInvalidOrder, "invalid order: quantity %d is below minimum %d", qty, cfg.MinQty)
+	}
+	if cfg.MaxQty > 0 && qty > cfg.MaxQty {
+		return rejectf(ErrInvalidOrder, "invalid order: quantity %d exceeds maximum %d", qty, cfg.MaxQty)
+	}
+	return nil
+}
+
+// checkMaxNotional rejects an order whose value at price exceeds MaxNotional.
+func (cfg SymbolConfig) checkMaxNotional(price, qty int64) error {
+	if cfg.MaxNotional == 0 {
+		return nil
+	}
+	hi, lo := bits.Mul64(uint64(abs(price)), uint64(qty))
+	if cfg.exceedsNotional(hi, lo) {
+		return rejectf(ErrInvalidOrder, "invalid order: quantity %d at price %d exceeds maximum notional %d", qty, price, cfg.MaxNotional)
+	}
+	return nil
+}
+
+// exceedsNotional reports whether a price x quantity product, as a 128-bit
+// hi:lo pair in raw quantity units, is above MaxNotional.
+func (cfg SymbolConfig) exceedsNotional(hi, lo uint64) bool {
+	capHi, capLo := bits.Mul64(uint64(cfg.MaxNotional), uint64(pow10[cfg.QuantityDecimals]))
+	return hi > capHi || (hi == capHi && lo > capLo)
+}
+
+// marketValue is what a market order would pay (or receive) filling against
+// the book as it stands, as a 128-bit hi:lo price x quantity product. It
+// stops where the order would: at its protection price, the price band, or
+// once it is filled.
+func (ob *OrderBook) marketValue(order *Order) (hi, lo uint64) {
+	tree := ob.asks
+	if order.Side == Sell {
+		tree = ob.bids
+	}
+	bandLow, bandHigh := ob.band()
+	remaining := order.Quantity
+	tree.Ascend(func(pl *PriceLevel) bool {
+		if !withinProtection(order, pl.Price) || !withinBand(order, pl.Price, bandLow, bandHigh) {
+			return false
+		}
+		for e := pl.Orders.Front(); e != nil && remaining > 0; e = e.Next() {
+			qty := min(remaining, e.Value.(*Order).RemainingQuantity())
+			remaining -= qty
+			h, l := bits.Mul64(uint64(abs(pl.Price)), uint64(qty))
+			var carry uint64
+			lo, carry = bits.Add64(lo, l, 0)
+			hi += h + carry
+		}
+		return remaining > 0
+	})
+	return hi, lo
+}
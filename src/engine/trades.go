@@ -0,0 +1,92 @@
+package engine
+
+import "sort"
+
+// tradeLogCapacity is how many trades each book retains for history queries.
+// The log is trimmed back to this size once it grows to twice the capacity,
+// keeping appends amortized O(1).
+const tradeLogCapacity = 100_000
+
+const (
+	DefaultTradeLimit = 100
+	MaxTradeLimit     = 1000
+)
+
+// recordTrade appends an executed trade to the book's history and candles.
+func (ob *OrderBook) recordTrade(trade Trade) {
+	ob.trades = append(ob.trades, trade)
+	if len(ob.trades) >= 2*tradeLogCapacity {
+		ob.trades = append([]Trade(nil), ob.trades[len(ob.trades)-tradeLogCapacity:]...)
+	}
+	ob.updateCandles(trade)
+}
+
+// GetTrades returns up to limit trades for a symbol executed strictly after the
+// since timestamp (Unix milliseconds), oldest first. Pass the timestamp of the
+// last trade received to page forward. limit <= 0 uses DefaultTradeLimit and
+// is capped at MaxTradeLimit.
+func (me *MatchingEngine) GetTrades(symbol string, since int64, limit int) []Trade {
+	if limit <= 0 {
+		limit = DefaultTradeLimit
+	}
+	if limit > MaxTradeLimit {
+		limit = MaxTradeLimit
+	}
+
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	start := sort.Search(len(book.trades), func(i int) bool {
+		return book.trades[i].Timestamp > since
+	})
+	end := start + limit
+	if end > len(book.trades) {
+		end = len(book.trades)
+	}
+
+	trades := make([]Trade, end-start)
+	copy(trades, book.trades[start:end])
+	return trades
+}
+
+// GetTradesForOrder returns every retained trade the order took part in, as
+// aggressor or resting order, oldest first. Trades older than the symbol's
+// log capacity are no longer kept. An unknown order has no trades.
+func (me *MatchingEngine) GetTradesForOrder(orderID string) []Trade {
+	order, ok := me.storedOrder(orderID)
+	if !ok {
+		return nil
+	}
+	book, lock := me.getBookAndLock(order.Symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	trades := []Trade{}
+	for _, trade := range book.trades {
+		if trade.AggressorOrderID == orderID || trade.RestingOrderID == orderID {
+			trades = append(trades, trade)
+		}
+	}
+	return trades
+}
+
+// publishExecutions fans a response's trades, including any produced by
+// triggered stops, out to execution subscribers, rolled up per order. Must
+// be called with the symbol lock held; the feed never blocks, so network
+// writes happen outside the lock. Trades themselves reach the trade feed as
+// they are booked; see observeEvent.
+func (me *MatchingEngine) publishExecutions(symbol string, response ProcessOrderResponse) {
+	if me.executionFeed.hasSubscribers(symbol) {
+		for _, exec := range rollUp(symbol, append(append([]Trade(nil), response.Trades...), response.TriggeredTrades...)) {
+			me.executionFeed.publish(symbol, exec)
+		}
+	}
+}
+
+// SubscribeTrades streams every trade executed in a symbol, in execution
+// order. It is fed from the event stream, so every booked trade is published,
+// whatever operation caused it.
+func (me *MatchingEngine) SubscribeTrades(symbol string) *Subscription[Trade] {
+	return me.tradeFeed.subscribe(symbol)
+}
@@ -10,6 +10,9 @@ type Side string
 type OrderType string
 type OrderStatus string
 
+// TimeInForce controls how long an order stays active once submitted.
+type TimeInForce string
+
 const (
 	Buy  Side = "BUY"
 	Sell Side = "SELL"
@@ -18,6 +21,45 @@ const (
 const (
 	Limit  OrderType = "LIMIT"
 	Market OrderType = "MARKET"
+	// Stop orders stay dormant until the market trades through StopPrice,
+	// then become a Market (Stop) or Limit (StopLimit) order.
+	Stop      OrderType = "STOP"
+	StopLimit OrderType = "STOP_LIMIT"
+	// If-touched orders are the mirror image: they stay dormant until the
+	// market trades at StopPrice or better for them (at or below for a buy,
+	// at or above for a sell), as when taking profit, then become a Market
+	// (MarketIfTouched) or Limit (LimitIfTouched) order. See stops.go.
+	MarketIfTouched OrderType = "MARKET_IF_TOUCHED"
+	LimitIfTouched  OrderType = "LIMIT_IF_TOUCHED"
+	// Pegged orders rest at a price the engine derives from the book's best
+	// bid or offer and moves as that quote moves. See peg.go.
+	Pegged OrderType = "PEGGED"
+	// Market-to-limit orders take the best opposite price, then rest what is
+	// left as a Limit order there. See market_to_limit.go.
+	MarketToLimit OrderType = "MARKET_TO_LIMIT"
+)
+
+const (
+	GTC TimeInForce = "GTC" // Good-Till-Cancel: unfilled quantity rests in the book
+	IOC TimeInForce = "IOC" // Immediate-Or-Cancel: unfilled quantity is cancelled
+	FOK TimeInForce = "FOK" // Fill-Or-Kill: fill completely at once or reject
+	Day TimeInForce = "DAY" // Rests like GTC until the symbol's session close; see session.go
+)
+
+// Cancel reasons recorded on every cancelled order, including orders whose
+// unfilled remainder was cancelled after a partial fill.
+const (
+	ReasonRequested   = "REQUESTED"    // CancelOrder
+	ReasonCancelAll   = "CANCEL_ALL"   // CancelAll
+	ReasonCancelLevel = "CANCEL_LEVEL" // CancelLevel
+	ReasonReplaced    = "REPLACED"     // CancelReplace
+	ReasonUnfilled    = "UNFILLED"     // IOC or market remainder with no more liquidity
+	ReasonExpired     = "EXPIRED"      // GTD or DAY expiry
+	ReasonKillSwitch  = "KILL_SWITCH"  // KillSwitch
+	ReasonDelisted    = "DELISTED"     // DelistSymbol
+	ReasonSelfMatch   = "SELF_MATCH"   // Aggressor reached its own desk's order; see selfmatch.go
+	ReasonBusted      = "TRADE_BUSTED" // Its fill was busted by BustTrade; see bust.go
+	ReasonCancelTag   = "CANCEL_TAG"   // CancelByTag
 )
 
 // NEW CONSTANTS for order status
@@ -32,16 +74,80 @@ const (
 type Order struct {
 	ID        string      `json:"id"`
 	Symbol    string      `json:"symbol"`
+	AccountID string      `json:"account_id,omitempty"` // Owning account; empty if not supplied
     Side      Side        `json:"side"`
 	Type      OrderType   `json:"type"`
 	Price     int64       `json:"price"`     // Stored as integer (cents)
+	StopPrice int64       `json:"stop_price,omitempty"` // Trigger price for STOP/STOP_LIMIT
+	ProtectionPrice int64 `json:"protection_price,omitempty"` // Worst price a market order may trade at; 0 = unbounded
 	Quantity  int64       `json:"quantity"`  // Original quantity
+	Notional  int64       `json:"notional,omitempty"` // Cash to spend (price units) on a notional MARKET order instead of a quantity
+	SpentNotional int64   `json:"spent_notional,omitempty"` // Cash a notional order spent, rounded up to a whole price unit
 	FilledQuantity int64  `json:"filled_quantity"`
+	DisplayQuantity int64 `json:"display_quantity,omitempty"` // Iceberg slice size; 0 = fully displayed
+	VisibleQuantity int64 `json:"visible_quantity,omitempty"` // Iceberg slice currently shown
+	Hidden    bool        `json:"hidden,omitempty"` // Never shown in depth; trades after displayed orders at its price
 	Status    OrderStatus `json:"status"`
-	Timestamp int64       `json:"timestamp"` // Unix milliseconds
+	TimeInForce TimeInForce `json:"time_in_force"` // Empty is treated as GTC
+	PostOnly  bool        `json:"post_only,omitempty"` // Reject rather than take liquidity
+	AllOrNone bool        `json:"all_or_none,omitempty"` // Only trade the whole remaining quantity at once; see aon.go
+	NoTradeThrough bool   `json:"no_trade_through,omitempty"` // Never trade worse than the symbol's reference quote; see reference.go
+	ExpiresAt int64       `json:"expires_at,omitempty"` // Unix milliseconds; 0 = never (GTD when set)
+	ExpireDate string     `json:"expire_date,omitempty"` // GTD until the session close on this YYYY-MM-DD; see session.go
+	PegReference PegReference `json:"peg_reference,omitempty"` // Quote a PEGGED order tracks
+	PegOffset int64       `json:"peg_offset,omitempty"` // Added to the reference price; a multiple of the tick size
+	CancelReason string   `json:"cancel_reason,omitempty"`
+	ClientMetadata map[string]string `json:"client_metadata,omitempty"` // Opaque to the engine, echoed back; see metadata.go
+	Timestamp int64       `json:"timestamp"` // Unix milliseconds, for display
+	Sequence  int64       `json:"sequence"`  // Engine-assigned arrival order; breaks time-priority ties
 
 	// Internal field to store its place in the PriceLevel queue.
 	element *list.Element
+	staleReported int64 // The Timestamp it was last reported stale at; see staleness.go
+}
+
+// IsStop reports whether the order is a dormant stop order.
+func (o *Order) IsStop() bool {
+	return o.Type == Stop || o.Type == StopLimit
+}
+
+// IsIfTouched reports whether the order is a dormant if-touched order.
+func (o *Order) IsIfTouched() bool {
+	return o.Type == MarketIfTouched || o.Type == LimitIfTouched
+}
+
+// IsConditional reports whether the order waits for a trigger at StopPrice:
+// a stop or an if-touched order.
+func (o *Order) IsConditional() bool {
+	return o.IsStop() || o.IsIfTouched()
+}
+
+// HasLimitPrice reports whether Price is the order's limit, now or once it
+// triggers.
+func (o *Order) HasLimitPrice() bool {
+	return o.Type == Limit || o.Type == StopLimit || o.Type == LimitIfTouched
+}
+
+// IsPegged reports whether the order's price tracks the BBO.
+func (o *Order) IsPegged() bool {
+	return o.Type == Pegged
+}
+
+// tradesAsLimit reports whether the order matches and rests at Price: a
+// limit order, or a pegged order at its current peg.
+func (o *Order) tradesAsLimit() bool {
+	return o.Type == Limit || o.Type == Pegged
+}
+
+// triggeredType is the type a conditional order becomes when it fires.
+func (o *Order) triggeredType() OrderType {
+	switch o.Type {
+	case Stop, MarketIfTouched:
+		return Market
+	case StopLimit, LimitIfTouched:
+		return Limit
+	}
+	return o.Type
 }
 
 // RemainingQuantity calculates the unfilled quantity.
@@ -49,14 +155,58 @@ func (o *Order) RemainingQuantity() int64 {
 	return o.Quantity - o.FilledQuantity
 }
 
+// IsNotional reports whether the order is sized by cash rather than quantity.
+func (o *Order) IsNotional() bool {
+	return o.Notional > 0
+}
+
+// LeftoverNotional is the cash a notional order did not spend.
+func (o *Order) LeftoverNotional() int64 {
+	return o.Notional - o.SpentNotional
+}
+
+// IsIceberg reports whether only a slice of the order is shown at a time.
+func (o *Order) IsIceberg() bool {
+	return o.DisplayQuantity > 0
+}
+
+// Visible is how much of a resting order is displayed and can match before
+// it has to replenish.
+func (o *Order) Visible() int64 {
+	if o.IsIceberg() {
+		return o.VisibleQuantity
+	}
+	return o.RemainingQuantity()
+}
+
+// Displayed is how much of a resting order market data shows: nothing for a
+// hidden order, otherwise what is visible.
+func (o *Order) Displayed() int64 {
+	if o.Hidden {
+		return 0
+	}
+	return o.Visible()
+}
+
+// replenish refills an iceberg's displayed slice from its hidden reserve.
+func (o *Order) replenish() {
+	o.VisibleQuantity = min(o.DisplayQuantity, o.RemainingQuantity())
+}
+
 // Trade represents a single trade that has been executed.
 type Trade struct {
 	TradeID        string `json:"trade_id"`
+	Symbol         string `json:"symbol"`
 	AggressorOrderID string `json:"aggressor_order_id"` // The ID of the incoming order
 	RestingOrderID string `json:"resting_order_id"`   // The ID of the order that was in the book
+	AggressorSide  Side   `json:"aggressor_side"`     // Side of the incoming order
 	Price          int64  `json:"price"`
 	Quantity       int64  `json:"quantity"`
+	AggressorFee   int64  `json:"aggressor_fee"` // Taker fee; see fees.go
+	RestingFee     int64  `json:"resting_fee"`   // Maker fee, negative for a rebate
+	EventSeq       uint64 `json:"event_seq"`     // Engine-wide event sequence; see OrderEvent
 	Timestamp      int64  `json:"timestamp"`
+	Busted         bool   `json:"busted,omitempty"` // Cancelled after the fact by BustTrade; see bust.go
 }
 
 // ProcessOrderResponse is the result of processing an order
@@ -65,6 +215,43 @@ type ProcessOrderResponse struct {
 	FilledRestingOrders []*Order
 	OrderInBook       bool
 	IsMarketOrder     bool
+	Resubmitted       bool // The ID was already taken: an idempotent retry that executed nothing
+
+	// Stop orders fired by this order's trades, and the trades they produced.
+	TriggeredOrders []*Order
+	TriggeredTrades []Trade
+}
+
+// TotalValue is the sum of price*quantity over the order's own trades
+// (triggered stops excluded). For symbols with QuantityDecimals > 0 it carries
+// the same scale as a quantity.
+func (r ProcessOrderResponse) TotalValue() int64 {
+	var value int64
+	for _, t := range r.Trades {
+		value += t.Price * t.Quantity
+	}
+	return value
+}
+
+// VWAP is the volume-weighted average price of the order's own trades,
+// sum(price*qty)/sum(qty), rounded half up to a whole price unit. It is 0 if
+// nothing traded.
+func (r ProcessOrderResponse) VWAP() int64 {
+	var quantity int64
+	for _, t := range r.Trades {
+		quantity += t.Quantity
+	}
+	if quantity == 0 {
+		return 0
+	}
+	value := r.TotalValue()
+	vwap, rem := value/quantity, value%quantity // Truncated toward zero
+	if 2*rem >= quantity {
+		vwap++
+	} else if 2*rem < -quantity {
+		vwap-- // Negative prices; see AllowNegativePrice
+	}
+	return vwap
 }
 
 // NewOrder creates a new Order with a timestamp.
@@ -78,6 +265,7 @@ func NewOrder(id, symbol string, side Side, orderType OrderType, price, quantity
 		Quantity:  quantity,
 		FilledQuantity: 0,
 		Status:    StatusAccepted, // Default status
+		TimeInForce: GTC,
 		Timestamp: time.Now().UnixNano() / 1_000_000, // Unix Milliseconds
 	}
 }
\ No newline at end of file
@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/google/btree"
+)
+
+// --- Book Invariants ---
+//
+// Every price level in a book's tree is also in its price map, holds at
+// least one order, and queues only open orders of its side and price, with
+// hidden orders behind displayed ones; every queued order is indexed in the
+// order map, and nothing else is. Verify checks this for debugging and
+// tests. SweepBook repairs what a book could be left holding if an order
+// ever stayed queued with nothing left to trade (restored from a snapshot,
+// say): such orders are marked filled and removed, and empty levels are
+// deleted. SetBookSweep makes every change to a book sweep it.
+
+// SetBookSweep controls whether every change to a book is followed by a
+// sweep. Sweeping walks the whole book, so it is meant for debugging and
+// tests rather than production load. Off by default.
+func (me *MatchingEngine) SetBookSweep(enabled bool) {
+	me.sweepBooks.Store(enabled)
+}
+
+// SweepBook removes orders with nothing left to trade and empty price levels
+// from symbol's book and returns how many orders and stray empty levels it
+// removed.
+func (me *MatchingEngine) SweepBook(symbol string) int {
+	book, lock := me.getBookAndLock(symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	removed := book.sweep()
+	me.publishDepth(book)
+	return removed
+}
+
+// Verify checks symbol's book against its invariants and returns the first
+// violation found, or nil.
+func (me *MatchingEngine) Verify(symbol string) error {
+	book, lock := me.getBookAndLock(symbol)
+	lock.RLock()
+	defer lock.RUnlock()
+	return book.verify()
+}
+
+// sweep removes queued orders with nothing left to trade, then any empty
+// levels still in the book, and returns how many of each it removed.
+func (ob *OrderBook) sweep() int {
+	var spent []*Order
+	collect := func(pl *PriceLevel) bool {
+		for e := pl.Orders.Front(); e != nil; e = e.Next() {
+			if order := e.Value.(*Order); order.RemainingQuantity() <= 0 {
+				spent = append(spent, order)
+			}
+		}
+		return true
+	}
+	ob.bids.Ascend(collect)
+	ob.asks.Ascend(collect)
+	for _, order := range spent {
+		order.Status = StatusFilled
+		ob.removeOrder(order.element)
+	}
+	return len(spent) + sweepLevels(ob.bids, ob.bidPriceMap) + sweepLevels(ob.asks, ob.askPriceMap)
+}
+
+// sweepLevels deletes empty levels from a side's tree and price map.
+func sweepLevels(tree *btree.BTreeG[*PriceLevel], priceMap map[int64]*PriceLevel) int {
+	var empty []*PriceLevel
+	tree.Ascend(func(pl *PriceLevel) bool {
+		if pl.Orders.Len() == 0 {
+			empty = append(empty, pl)
+		}
+		return true
+	})
+	for _, pl := range empty {
+		tree.Delete(pl)
+		if priceMap[pl.Price] == pl {
+			delete(priceMap, pl.Price)
+		}
+	}
+	for price, pl := range priceMap {
+		if pl.Orders.Len() == 0 {
+			delete(priceMap, price)
+			empty = append(empty, pl)
+		}
+	}
+	return len(empty)
+}
+
+func (ob *OrderBook) verify() error {
+	queued := 0
+	for _, side := range []struct {
+		side     Side
+		tree     *btree.BTreeG[*PriceLevel]
+		priceMap map[int64]*PriceLevel
+	}{
+		{Buy, ob.bids, ob.bidPriceMap},
+		{Sell, ob.asks, ob.askPriceMap},
+	} {
+		if side.tree.Len() != len(side.priceMap) {
+			return fmt.Errorf("%s %s: %d levels in the tree but %d in the price map", ob.symbol, side.side, side.tree.Len(), len(side.priceMap))
+		}
+		var err error
+		side.tree.Ascend(func(pl *PriceLevel) bool {
+			if side.priceMap[pl.Price] != pl {
+				err = fmt.Errorf("%s %s level %d: not in the price map", ob.symbol, side.side, pl.Price)
+				return false
+			}
+			if pl.Orders.Len() == 0 {
+				err = fmt.Errorf("%s %s level %d: empty", ob.symbol, side.side, pl.Price)
+				return false
+			}
+			var firstHidden *Order
+			for e := pl.Orders.Front(); e != nil; e = e.Next() {
+				order := e.Value.(*Order)
+				queued++
+				switch {
+				case order.element != e || ob.orderMap[order.ID] != e:
+					err = fmt.Errorf("%s %s level %d: order %s is not indexed at its queue position", ob.symbol, side.side, pl.Price, order.ID)
+				case order.Side != side.side || order.Price != pl.Price:
+					err = fmt.Errorf("%s %s level %d: order %s is a %s at %d", ob.symbol, side.side, pl.Price, order.ID, order.Side, order.Price)
+				case order.RemainingQuantity() <= 0:
+					err = fmt.Errorf("%s %s level %d: order %s has nothing left to trade", ob.symbol, side.side, pl.Price, order.ID)
+				case firstHidden != nil && !order.Hidden:
+					err = fmt.Errorf("%s %s level %d: displayed order %s queued behind hidden order %s", ob.symbol, side.side, pl.Price, order.ID, firstHidden.ID)
+				}
+				if err != nil {
+					return false
+				}
+				if order.Hidden && firstHidden == nil {
+					firstHidden = order
+					if pl.hidden != e {
+						err = fmt.Errorf("%s %s level %d: hidden orders do not start at %s", ob.symbol, side.side, pl.Price, order.ID)
+						return false
+					}
+				}
+			}
+			if firstHidden == nil && pl.hidden != nil {
+				err = fmt.Errorf("%s %s level %d: marks a hidden order it does not queue", ob.symbol, side.side, pl.Price)
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if queued != len(ob.orderMap) || queued != ob.resting {
+		return fmt.Errorf("%s: %d orders queued, %d indexed, %d counted resting", ob.symbol, queued, len(ob.orderMap), ob.resting)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// WALOp identifies the mutation recorded by a WAL entry.
+type WALOp string
+
+const (
+	WALSubmit  WALOp = "SUBMIT"
+	WALCancel  WALOp = "CANCEL"
+	WALAmend   WALOp = "AMEND"
+	WALExpire  WALOp = "EXPIRE"
+	WALReplace WALOp = "REPLACE"
+
+	WALAuctionStart WALOp = "AUCTION_START"
+	WALAuctionRun   WALOp = "AUCTION_RUN"
+	WALHalt         WALOp = "HALT"
+	WALResume       WALOp = "RESUME"
+	WALKill         WALOp = "KILL"
+	WALReset        WALOp = "RESET"
+	WALPreOpen      WALOp = "PRE_OPEN" // Session transitions; see session.go
+	WALOpen         WALOp = "OPEN"
+	WALRollSession  WALOp = "ROLL_SESSION"
+	WALReference    WALOp = "REFERENCE" // See reference.go
+	WALBust         WALOp = "BUST"      // See bust.go
+)
+
+// WALEntry is one logged mutation. Submit entries carry the order exactly as
+// it was received; cancel and amend entries reference it by ID; replace
+// entries carry both; auction, halt and reference entries name the symbol;
+// bust entries carry the trade; kill and reset entries apply to the whole engine. EventSeq is the last
+// engine event sequence (see OrderEvent) emitted before the entry was
+// applied.
+type WALEntry struct {
+	Sequence uint64 `json:"seq"`
+	EventSeq uint64 `json:"event_seq"`
+	Op       WALOp  `json:"op"`
+	Order    *Order `json:"order,omitempty"`
+	OrderID  string `json:"order_id,omitempty"`
+	Price    int64  `json:"price,omitempty"`
+	Quantity int64  `json:"quantity,omitempty"`
+	Symbol   string `json:"symbol,omitempty"`
+	Mode     string `json:"mode,omitempty"`
+	Bid      int64  `json:"bid,omitempty"` // Reference entries' quote
+	Ask      int64  `json:"ask,omitempty"`
+	Trade    *Trade `json:"trade,omitempty"`  // Bust entries' trade, as booked
+	Reason   string `json:"reason,omitempty"` // Cancel entries' CancelReason; REQUESTED if empty
+}
+
+// WAL is an append-only log of engine mutations. Append must not return
+// until the entry is as durable as the implementation promises.
+type WAL interface {
+	Append(entry WALEntry) error
+}
+
+// JSONWAL writes one JSON entry per line. If the writer has a Sync method
+// (e.g. *os.File) it is called after every entry.
+type JSONWAL struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONWAL returns a line-delimited JSON WAL writing to w.
+func NewJSONWAL(w io.Writer) *JSONWAL {
+	return &JSONWAL{w: w, enc: json.NewEncoder(w)}
+}
+
+// Append writes the entry as a single line.
+func (l *JSONWAL) Append(entry WALEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(entry); err != nil {
+		return err
+	}
+	if s, ok := l.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// SetWAL makes every subsequent submit, cancel and amend get logged to wal
+// before it is applied. Pass nil to disable logging.
+func (me *MatchingEngine) SetWAL(wal WAL) {
+	me.walMutex.Lock()
+	me.wal = wal
+	me.walMutex.Unlock()
+}
+
+// logWAL stamps the next sequence number and appends the entry. Callers hold
+// the symbol lock, so per-symbol log order always matches apply order.
+func (me *MatchingEngine) logWAL(entry WALEntry) error {
+	me.walMutex.Lock()
+	defer me.walMutex.Unlock()
+	if me.wal == nil {
+		return nil
+	}
+	entry.Sequence = me.walSeq + 1
+	entry.EventSeq = me.events.last()
+	if err := me.wal.Append(entry); err != nil {
+		return &Error{Code: CodeWALFailure, Message: "write-ahead log append failed: " + err.Error(), err: err}
+	}
+	me.walSeq = entry.Sequence
+	return nil
+}
+
+// Recover replays a log written by JSONWAL, in order, to rebuild state.
+// Logging is suspended while replaying, and later entries continue the
+// replayed sequence. Rejections that happened originally happen again and
+// are not treated as errors.
+func (me *MatchingEngine) Recover(wal io.Reader) error {
+	me.walMutex.Lock()
+	saved := me.wal
+	me.wal = nil
+	me.walMutex.Unlock()
+	defer me.SetWAL(saved)
+	me.replaying.Store(true)
+	defer me.replaying.Store(false)
+
+	scanner := bufio.NewScanner(wal)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lastSeq uint64
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry WALEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("invalid WAL entry after seq %d: %w", lastSeq, err)
+		}
+		if entry.Sequence <= lastSeq {
+			return fmt.Errorf("invalid WAL: seq %d after %d", entry.Sequence, lastSeq)
+		}
+		lastSeq = entry.Sequence
+
+		switch entry.Op {
+		case WALSubmit:
+			if entry.Order == nil {
+				return fmt.Errorf("invalid WAL: submit seq %d has no order", entry.Sequence)
+			}
+			_, _ = me.SubmitOrder(entry.Order)
+		case WALCancel:
+			_, _ = me.cancelOrder(entry.OrderID, entry.Reason)
+		case WALAmend:
+			_, _, _ = me.AmendOrder(entry.OrderID, entry.Price, entry.Quantity)
+		case WALReplace:
+			if entry.Order == nil {
+				return fmt.Errorf("invalid WAL: replace seq %d has no order", entry.Sequence)
+			}
+			_, _, _ = me.CancelReplace(entry.OrderID, entry.Order)
+		case WALExpire:
+			me.expireOrder(entry.OrderID)
+		case WALAuctionStart:
+			_ = me.StartAuction(entry.Symbol)
+		case WALAuctionRun:
+			me.RunAuction(entry.Symbol)
+		case WALHalt:
+			_ = me.Halt(entry.Symbol, HaltMode(entry.Mode))
+		case WALResume:
+			_, _ = me.Resume(entry.Symbol)
+		case WALKill:
+			_, _ = me.KillSwitch()
+		case WALReset:
+			_ = me.Reset()
+		case WALPreOpen, WALOpen:
+			me.replaySession(entry.Op, entry.Symbol)
+		case WALRollSession:
+			_, _ = me.RollSession(entry.Symbol)
+		case WALReference:
+			_ = me.SetReference(entry.Symbol, entry.Bid, entry.Ask)
+		case WALBust:
+			if entry.Trade == nil {
+				return fmt.Errorf("invalid WAL: bust seq %d has no trade", entry.Sequence)
+			}
+			// Logged only once the bust was known to apply, so it must apply again
+			if err := me.replayBust(*entry.Trade); err != nil {
+				return fmt.Errorf("invalid WAL: cannot replay bust seq %d: %w", entry.Sequence, err)
+			}
+		default:
+			return fmt.Errorf("invalid WAL: unknown op %q at seq %d", entry.Op, entry.Sequence)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	me.walMutex.Lock()
+	if lastSeq > me.walSeq {
+		me.walSeq = lastSeq
+	}
+	me.walMutex.Unlock()
+	me.seedIDs()
+	return nil
+}
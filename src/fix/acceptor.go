@@ -0,0 +1,310 @@
+// Package fix is a FIX 4.4 order-entry gateway for the matching engine. It
+// accepts NewOrderSingle (D), OrderCancelRequest (F) and
+// OrderCancelReplaceRequest (G) and answers with ExecutionReports (8) and
+// OrderCancelRejects (9), delegating to the same MatchingEngine as the REST
+// and gRPC APIs.
+//
+// The session layer is deliberately small: sequence numbers restart at 1 on
+// every Logon, there is no message store, and a sequence gap ends the
+// session instead of triggering a ResendRequest. Prices are integer engine
+// price units, as in the REST API; quantities may carry the symbol's
+// QuantityDecimals.
+package fix
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "log/slog"
+    "net"
+    "strconv"
+    "sync"
+    "time"
+
+    "order-matching-engine/src/engine"
+)
+
+const (
+    logonTimeout = 10 * time.Second // To receive Logon after connecting
+    writeTimeout = 5 * time.Second
+)
+
+// Acceptor serves FIX sessions. Each client SenderCompID may have one
+// session logged on at a time.
+type Acceptor struct {
+    eng    *engine.MatchingEngine
+    compID string
+    logger *slog.Logger
+
+    mu          sync.Mutex
+    listeners   map[net.Listener]struct{}
+    sessions    map[string]*session    // Logged-on sessions by client SenderCompID
+    orders      map[string]*orderState // Open orders by engine order ID
+    aliases     map[string]string      // Replaced ClOrdIDs to engine order ID; see orderKey
+    unsubscribe func()
+    closed      bool
+}
+
+// NewAcceptor returns an acceptor that answers as compID (its SenderCompID;
+// clients must send it as TargetCompID).
+func NewAcceptor(eng *engine.MatchingEngine, compID string) *Acceptor {
+    return &Acceptor{
+        eng:       eng,
+        compID:    compID,
+        logger:    slog.New(slog.DiscardHandler),
+        listeners: make(map[net.Listener]struct{}),
+        sessions:  make(map[string]*session),
+        orders:    make(map[string]*orderState),
+        aliases:   make(map[string]string),
+    }
+}
+
+// SetLogger sets the logger for session events. The default discards
+// everything.
+func (a *Acceptor) SetLogger(logger *slog.Logger) {
+    a.logger = logger
+}
+
+// Serve accepts connections on l until Close is called, when it returns nil.
+func (a *Acceptor) Serve(l net.Listener) error {
+    a.mu.Lock()
+    if a.closed {
+        a.mu.Unlock()
+        return nil
+    }
+    a.listeners[l] = struct{}{}
+    if a.unsubscribe == nil {
+        a.unsubscribe = a.eng.Subscribe(a.onEvent)
+    }
+    a.mu.Unlock()
+
+    for {
+        conn, err := l.Accept()
+        if err != nil {
+            a.mu.Lock()
+            closed := a.closed
+            delete(a.listeners, l)
+            a.mu.Unlock()
+            if closed {
+                return nil
+            }
+            return err
+        }
+        go a.serveConn(conn)
+    }
+}
+
+// Close stops accepting connections, disconnects every session and stops
+// reporting engine events.
+func (a *Acceptor) Close() error {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.closed {
+        return nil
+    }
+    a.closed = true
+    for l := range a.listeners {
+        l.Close()
+    }
+    for _, s := range a.sessions {
+        s.conn.Close()
+    }
+    if a.unsubscribe != nil {
+        a.unsubscribe()
+    }
+    return nil
+}
+
+// session is one logged-on FIX connection.
+type session struct {
+    a       *Acceptor
+    conn    net.Conn
+    compID  string        // The client's SenderCompID
+    heartBt time.Duration // 0 = no heartbeats
+
+    wmu      sync.Mutex
+    outSeq   int64
+    lastSent time.Time
+}
+
+func (a *Acceptor) serveConn(conn net.Conn) {
+    defer conn.Close()
+    r := bufio.NewReader(conn)
+
+    conn.SetReadDeadline(time.Now().Add(logonTimeout))
+    logon, err := ReadMessage(r)
+    if err != nil {
+        a.logger.Warn("FIX connection closed before logon", "remote", conn.RemoteAddr().String(), "error", err)
+        return
+    }
+    s, err := a.logon(conn, logon)
+    if err != nil {
+        a.logger.Warn("FIX logon refused", "remote", conn.RemoteAddr().String(), "error", err)
+        return
+    }
+    defer a.logout(s)
+    a.logger.Info("FIX session logged on", "comp_id", s.compID, "remote", conn.RemoteAddr().String())
+
+    stop := make(chan struct{})
+    defer close(stop)
+    if s.heartBt > 0 {
+        go s.heartbeats(stop)
+    }
+
+    nextSeq := int64(2) // Logon was 1
+    for {
+        if s.heartBt > 0 {
+            // Heartbeats are due every heartBt; allow one to go missing
+            conn.SetReadDeadline(time.Now().Add(2*s.heartBt + time.Second))
+        } else {
+            conn.SetReadDeadline(time.Time{})
+        }
+        m, err := ReadMessage(r)
+        if errors.Is(err, ErrGarbled) {
+            a.logger.Warn("FIX garbled message ignored", "comp_id", s.compID)
+            continue
+        }
+        if err != nil {
+            if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+                a.logger.Warn("FIX session dropped", "comp_id", s.compID, "error", err)
+            }
+            return
+        }
+        seq, err := strconv.ParseInt(m.Get(tagMsgSeqNum), 10, 64)
+        if err != nil || seq != nextSeq {
+            s.send(NewMessage(msgLogout).Set(tagText, "MsgSeqNum "+m.Get(tagMsgSeqNum)+", expected "+strconv.FormatInt(nextSeq, 10)))
+            return
+        }
+        nextSeq++
+
+        switch m.MsgType() {
+        case msgHeartbeat:
+        case msgTestRequest:
+            s.send(NewMessage(msgHeartbeat).Set(tagTestReqID, m.Get(tagTestReqID)))
+        case msgLogout:
+            s.send(NewMessage(msgLogout))
+            return
+        case msgNewOrderSingle:
+            a.newOrder(s, m)
+        case msgOrderCancelRequest:
+            a.cancelOrder(s, m)
+        case msgOrderCancelReplaceRequest:
+            a.replaceOrder(s, m)
+        case msgLogon:
+            s.sendReject(m, 0, 11, "already logged on") // Invalid MsgType
+        default:
+            s.send(NewMessage(msgBusinessMessageReject).
+                Set(tagRefSeqNum, m.Get(tagMsgSeqNum)).
+                Set(tagRefMsgType, m.MsgType()).
+                Set(tagBusinessRejectReason, "3"). // Unsupported Message Type
+                Set(tagText, "unsupported message type "+m.MsgType()))
+        }
+    }
+}
+
+// logon validates the client's Logon, registers its session and replies.
+func (a *Acceptor) logon(conn net.Conn, m *Message) (*session, error) {
+    if m.MsgType() != msgLogon {
+        return nil, errors.New("first message is not a Logon")
+    }
+    if m.Get(tagMsgSeqNum) != "1" {
+        return nil, errors.New("Logon MsgSeqNum must be 1")
+    }
+    if target := m.Get(tagTargetCompID); target != a.compID {
+        return nil, errors.New("unknown TargetCompID " + strconv.Quote(target))
+    }
+    compID := m.Get(tagSenderCompID)
+    if compID == "" {
+        return nil, errors.New("SenderCompID is required")
+    }
+    heartBt, err := strconv.Atoi(m.Get(tagHeartBtInt))
+    if err != nil || heartBt < 0 {
+        return nil, errors.New("invalid HeartBtInt")
+    }
+    s := &session{a: a, conn: conn, compID: compID, heartBt: time.Duration(heartBt) * time.Second}
+
+    a.mu.Lock()
+    if a.closed {
+        a.mu.Unlock()
+        return nil, errors.New("acceptor closed")
+    }
+    if _, dup := a.sessions[compID]; dup {
+        a.mu.Unlock()
+        s.send(NewMessage(msgLogout).Set(tagText, "already logged on"))
+        return nil, errors.New(compID + " is already logged on")
+    }
+    a.sessions[compID] = s
+    a.mu.Unlock()
+
+    reply := NewMessage(msgLogon).Set(tagEncryptMethod, "0").Set(tagHeartBtInt, strconv.Itoa(heartBt))
+    if m.Get(tagResetSeqNumFlag) == "Y" {
+        reply.Set(tagResetSeqNumFlag, "Y")
+    }
+    s.send(reply)
+    return s, nil
+}
+
+func (a *Acceptor) logout(s *session) {
+    a.mu.Lock()
+    if a.sessions[s.compID] == s {
+        delete(a.sessions, s.compID)
+    }
+    a.mu.Unlock()
+    a.logger.Info("FIX session logged out", "comp_id", s.compID)
+}
+
+// heartbeats sends a Heartbeat whenever nothing else was sent for heartBt.
+func (s *session) heartbeats(stop <-chan struct{}) {
+    ticker := time.NewTicker(s.heartBt / 4)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-stop:
+            return
+        case <-ticker.C:
+            s.wmu.Lock()
+            idle := time.Since(s.lastSent) >= s.heartBt
+            s.wmu.Unlock()
+            if idle {
+                s.send(NewMessage(msgHeartbeat))
+            }
+        }
+    }
+}
+
+// send stamps the standard header on m and writes it. A write error is left
+// for the read loop to notice when the connection closes.
+func (s *session) send(m *Message) {
+    s.wmu.Lock()
+    defer s.wmu.Unlock()
+    s.outSeq++
+    now := time.Now()
+    out := NewMessage(m.MsgType())
+    out.Set(tagSenderCompID, s.a.compID)
+    out.Set(tagTargetCompID, s.compID)
+    out.SetInt(tagMsgSeqNum, s.outSeq)
+    out.Set(tagSendingTime, formatTime(now))
+    for _, f := range m.Fields {
+        if f.Tag != tagMsgType {
+            out.Fields = append(out.Fields, f)
+        }
+    }
+    s.conn.SetWriteDeadline(now.Add(writeTimeout))
+    if _, err := s.conn.Write(out.Bytes()); err != nil {
+        s.a.logger.Warn("FIX write failed", "comp_id", s.compID, "error", err)
+        s.conn.Close()
+        return
+    }
+    s.lastSent = now
+}
+
+// sendReject sends a session-level Reject (3) for m. refTag 0 omits
+// RefTagID.
+func (s *session) sendReject(m *Message, refTag, reason int, text string) {
+    r := NewMessage(msgReject).Set(tagRefSeqNum, m.Get(tagMsgSeqNum)).Set(tagRefMsgType, m.MsgType())
+    if refTag > 0 {
+        r.Set(tagRefTagID, strconv.Itoa(refTag))
+    }
+    r.Set(tagSessionRejectReason, strconv.Itoa(reason)).Set(tagText, text)
+    s.send(r)
+}
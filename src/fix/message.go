@@ -0,0 +1,198 @@
+package fix
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "time"
+)
+
+// soh separates fields on the wire.
+const soh = '\x01'
+
+// beginString is the only protocol version spoken.
+const beginString = "FIX.4.4"
+
+// sendingTimeFormat is the UTCTimestamp format used for SendingTime and
+// TransactTime.
+const sendingTimeFormat = "20060102-15:04:05.000"
+
+// Field is one tag=value pair.
+type Field struct {
+    Tag   int
+    Value string
+}
+
+// Message is a FIX message as an ordered list of fields. BeginString,
+// BodyLength and CheckSum are not stored: ReadMessage checks and strips them
+// and Bytes adds them.
+type Message struct {
+    Fields []Field
+}
+
+// NewMessage returns a message of the given MsgType (tag 35).
+func NewMessage(msgType string) *Message {
+    return &Message{Fields: []Field{{Tag: tagMsgType, Value: msgType}}}
+}
+
+// MsgType returns tag 35.
+func (m *Message) MsgType() string {
+    return m.Get(tagMsgType)
+}
+
+// Get returns the first value of tag, or "" if it is absent.
+func (m *Message) Get(tag int) string {
+    v, _ := m.Lookup(tag)
+    return v
+}
+
+// Lookup returns the first value of tag and whether it was present.
+func (m *Message) Lookup(tag int) (string, bool) {
+    for _, f := range m.Fields {
+        if f.Tag == tag {
+            return f.Value, true
+        }
+    }
+    return "", false
+}
+
+// Set appends tag=value, or replaces the value if tag is already present.
+func (m *Message) Set(tag int, value string) *Message {
+    for i := range m.Fields {
+        if m.Fields[i].Tag == tag {
+            m.Fields[i].Value = value
+            return m
+        }
+    }
+    m.Fields = append(m.Fields, Field{Tag: tag, Value: value})
+    return m
+}
+
+// SetInt is Set for an integer value.
+func (m *Message) SetInt(tag int, value int64) *Message {
+    return m.Set(tag, strconv.FormatInt(value, 10))
+}
+
+// Bytes encodes the message with BeginString, BodyLength and CheckSum.
+// MsgType is always written first in the body, as the standard requires.
+func (m *Message) Bytes() []byte {
+    var body bytes.Buffer
+    writeField(&body, tagMsgType, m.MsgType())
+    for _, f := range m.Fields {
+        if f.Tag != tagMsgType {
+            writeField(&body, f.Tag, f.Value)
+        }
+    }
+    var out bytes.Buffer
+    writeField(&out, tagBeginString, beginString)
+    writeField(&out, tagBodyLength, strconv.Itoa(body.Len()))
+    out.Write(body.Bytes())
+    writeField(&out, tagCheckSum, fmt.Sprintf("%03d", checksum(out.Bytes())))
+    return out.Bytes()
+}
+
+func (m *Message) String() string {
+    return string(bytes.ReplaceAll(m.Bytes(), []byte{soh}, []byte{'|'}))
+}
+
+func writeField(b *bytes.Buffer, tag int, value string) {
+    b.WriteString(strconv.Itoa(tag))
+    b.WriteByte('=')
+    b.WriteString(value)
+    b.WriteByte(soh)
+}
+
+// checksum is the sum of every byte modulo 256.
+func checksum(b []byte) int {
+    var sum int
+    for _, c := range b {
+        sum += int(c)
+    }
+    return sum % 256
+}
+
+// ErrGarbled is returned for a frame whose BodyLength or CheckSum is wrong.
+// The stream is still in sync after it, so the session ignores the message
+// and reads on, as the standard asks.
+var ErrGarbled = errors.New("fix: garbled message")
+
+// maxBodyLength bounds the buffer allocated for one message.
+const maxBodyLength = 64 << 10
+
+// ReadMessage reads one framed message. An error other than ErrGarbled
+// means the stream can no longer be trusted and the connection should close.
+func ReadMessage(r *bufio.Reader) (*Message, error) {
+    begin, err := r.ReadBytes(soh)
+    if err != nil {
+        return nil, err
+    }
+    if string(begin) != "8="+beginString+"\x01" {
+        return nil, fmt.Errorf("fix: expected BeginString %s, got %q", beginString, bytes.TrimSuffix(begin, []byte{soh}))
+    }
+    lengthField, err := r.ReadBytes(soh)
+    if err != nil {
+        return nil, err
+    }
+    tag, value, err := splitField(lengthField)
+    if err != nil || tag != tagBodyLength {
+        return nil, errors.New("fix: expected BodyLength as the second field")
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil || n <= 0 || n > maxBodyLength {
+        return nil, fmt.Errorf("fix: invalid BodyLength %q", value)
+    }
+    body := make([]byte, n)
+    if _, err := io.ReadFull(r, body); err != nil {
+        return nil, err
+    }
+    trailer, err := r.ReadBytes(soh)
+    if err != nil {
+        return nil, err
+    }
+    tag, value, err = splitField(trailer)
+    if err != nil || tag != tagCheckSum {
+        // BodyLength was wrong, so the next frame cannot be found reliably
+        return nil, errors.New("fix: expected CheckSum after the body")
+    }
+
+    sum := checksum(begin) + checksum(lengthField) + checksum(body)
+    if want, err := strconv.Atoi(value); err != nil || want != sum%256 {
+        return nil, ErrGarbled
+    }
+    if body[len(body)-1] != soh {
+        return nil, ErrGarbled
+    }
+    m := &Message{}
+    for _, raw := range bytes.SplitAfter(body[:len(body)-1], []byte{soh}) {
+        tag, value, err := splitField(raw)
+        if err != nil {
+            return nil, ErrGarbled
+        }
+        m.Fields = append(m.Fields, Field{Tag: tag, Value: value})
+    }
+    if len(m.Fields) == 0 || m.Fields[0].Tag != tagMsgType {
+        return nil, ErrGarbled
+    }
+    return m, nil
+}
+
+// splitField parses "tag=value", with or without the trailing SOH.
+func splitField(raw []byte) (int, string, error) {
+    raw = bytes.TrimSuffix(raw, []byte{soh})
+    t, v, ok := bytes.Cut(raw, []byte{'='})
+    if !ok {
+        return 0, "", fmt.Errorf("fix: malformed field %q", raw)
+    }
+    tag, err := strconv.Atoi(string(t))
+    if err != nil || tag <= 0 {
+        return 0, "", fmt.Errorf("fix: malformed tag %q", t)
+    }
+    return tag, string(v), nil
+}
+
+func formatTime(t time.Time) string {
+    return t.UTC().Format(sendingTimeFormat)
+}
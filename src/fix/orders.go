@@ -0,0 +1,585 @@
+package fix
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    "time"
+
+    "order-matching-engine/src/engine"
+)
+
+var (
+    sides = map[string]engine.Side{
+        "1": engine.Buy,
+        "2": engine.Sell,
+    }
+    orderTypes = map[string]engine.OrderType{
+        "1": engine.Market,
+        "2": engine.Limit,
+        "3": engine.Stop,
+        "4": engine.StopLimit,
+        "J": engine.MarketIfTouched,
+        "K": engine.MarketToLimit, // Market with left over as limit
+    }
+    timesInForce = map[string]engine.TimeInForce{
+        "0": engine.Day,
+        "1": engine.GTC,
+        "3": engine.IOC,
+        "4": engine.FOK,
+        "6": engine.GTC, // GTD: GTC with ExpireTime or ExpireDate
+    }
+    sideCodes      = reverse(sides)
+    orderTypeCodes = reverse(orderTypes)
+
+    // ordRejReasons maps engine reason codes to OrdRejReason (103); anything
+    // else is 99 (Other).
+    ordRejReasons = map[engine.ReasonCode]string{
+        engine.CodeUnknownSymbol:    "1", // Unknown symbol
+        engine.CodeSymbolHalted:     "2", // Exchange closed
+        engine.CodeMarketClosed:     "2",
+        engine.CodeBookFull:         "3", // Order exceeds limit
+        engine.CodeDuplicateOrderID: "6", // Duplicate order
+    }
+)
+
+// reverse inverts one of the code tables above.
+func reverse[K, V comparable](m map[K]V) map[V]K {
+    out := make(map[V]K, len(m))
+    for k, v := range m {
+        out[v] = k
+    }
+    return out
+}
+
+// Engine order IDs are the client's SenderCompID and its ClOrdID, so two
+// clients cannot collide. The ID stays fixed for the life of the order; a
+// ClOrdID assigned by a cancel/replace is kept in Acceptor.aliases under
+// the same key.
+func orderKey(compID, clOrdID string) string {
+    return compID + ":" + clOrdID
+}
+
+// orderState is what the gateway tracks about an open order to fill in its
+// ExecutionReports from engine events.
+type orderState struct {
+    owner       string // Client SenderCompID
+    clOrdID     string
+    origClOrdID string
+    pending     *pendingRequest // Cancel or replace awaiting its event
+    aliases     []string        // Keys in Acceptor.aliases
+    reported    bool            // New already sent
+
+    symbol   string
+    account  string
+    side     engine.Side
+    ordType  engine.OrderType
+    limit    bool // Price is a limit price
+    decimals int  // Symbol QuantityDecimals
+    price    int64
+    quantity int64
+    cum      int64
+    value    int64  // Sum of price*quantity over fills, for AvgPx
+    final    string // OrdStatus once rejected, cancelled or expired
+}
+
+type pendingRequest struct {
+    clOrdID, origClOrdID string
+}
+
+func newOrderState(owner, clOrdID string, order *engine.Order, decimals int) *orderState {
+    return &orderState{
+        owner:    owner,
+        clOrdID:  clOrdID,
+        symbol:   order.Symbol,
+        account:  order.AccountID,
+        side:     order.Side,
+        ordType:  order.Type,
+        limit:    order.HasLimitPrice(),
+        decimals: decimals,
+        price:    order.Price,
+        quantity: order.Quantity,
+        cum:      order.FilledQuantity,
+    }
+}
+
+func (st *orderState) status() string {
+    switch {
+    case st.final != "":
+        return st.final
+    case st.cum >= st.quantity:
+        return execFilled
+    case st.cum > 0:
+        return execPartial
+    }
+    return execNew
+}
+
+func (st *orderState) done() bool {
+    return st.final != "" || st.cum >= st.quantity
+}
+
+// report builds an ExecutionReport for the order as it stands.
+func (st *orderState) report(orderID, execID, execType string) *Message {
+    m := NewMessage(msgExecutionReport).Set(tagOrderID, orderID).Set(tagClOrdID, st.clOrdID)
+    if st.origClOrdID != "" {
+        m.Set(tagOrigClOrdID, st.origClOrdID)
+    }
+    m.Set(tagExecID, execID).Set(tagExecType, execType).Set(tagOrdStatus, st.status())
+    if st.account != "" {
+        m.Set(tagAccount, st.account)
+    }
+    m.Set(tagSymbol, st.symbol).Set(tagSide, sideCodes[st.side]).Set(tagOrdType, orderTypeCodes[st.ordType])
+    m.Set(tagOrderQty, engine.FormatQuantity(st.quantity, st.decimals))
+    if st.limit {
+        m.SetInt(tagPrice, st.price)
+    }
+    var leaves int64
+    if st.final == "" {
+        leaves = st.quantity - st.cum
+    }
+    m.Set(tagLeavesQty, engine.FormatQuantity(leaves, st.decimals))
+    m.Set(tagCumQty, engine.FormatQuantity(st.cum, st.decimals))
+    avgPx := "0"
+    if st.cum > 0 {
+        avgPx = strconv.FormatFloat(float64(st.value)/float64(st.cum), 'f', -1, 64)
+    }
+    m.Set(tagAvgPx, avgPx).Set(tagTransactTime, formatTime(time.Now()))
+    return m
+}
+
+// apply updates the state for one engine event and returns the
+// ExecutionReport it calls for, or nil.
+func (st *orderState) apply(orderID string, ev engine.OrderEvent) *Message {
+    execID := strconv.FormatUint(ev.Sequence, 10)
+    switch ev.Type {
+    case engine.EventAccepted:
+        if st.reported {
+            return nil // A conditional order re-entering when it triggers
+        }
+        st.reported = true
+        return st.report(orderID, execID, execNew)
+    case engine.EventTrade:
+        t := ev.Trade
+        st.cum += t.Quantity
+        st.value += t.Price * t.Quantity
+        // Both sides of a trade share its sequence
+        m := st.report(orderID, execID+"-"+sideCodes[st.side], execTrade)
+        return m.Set(tagLastQty, engine.FormatQuantity(t.Quantity, st.decimals)).SetInt(tagLastPx, t.Price)
+    case engine.EventAmended:
+        st.takePending()
+        st.price = ev.Order.Price
+        st.quantity = ev.Order.Quantity
+        return st.report(orderID, execID, execReplaced)
+    case engine.EventCancelled:
+        st.takePending()
+        st.final = execCanceled
+        return st.report(orderID, execID, execCanceled).Set(tagText, ev.Order.CancelReason)
+    case engine.EventExpired:
+        st.takePending()
+        st.final = execExpired
+        return st.report(orderID, execID, execExpired)
+    }
+    return nil
+}
+
+// takePending moves a pending request's ClOrdIDs onto the order, which is
+// how a cancel or replace is acknowledged.
+func (st *orderState) takePending() {
+    if st.pending != nil {
+        st.clOrdID, st.origClOrdID = st.pending.clOrdID, st.pending.origClOrdID
+        st.pending = nil
+    }
+}
+
+// onEvent reports engine events on the gateway's own orders to their owners.
+// Events for orders it does not track are ignored.
+func (a *Acceptor) onEvent(ev engine.OrderEvent) {
+    if ev.Type == engine.EventTrade {
+        a.reportEvent(ev.Trade.RestingOrderID, ev)
+        a.reportEvent(ev.Trade.AggressorOrderID, ev)
+        return
+    }
+    if ev.Order != nil {
+        a.reportEvent(ev.Order.ID, ev)
+    }
+}
+
+func (a *Acceptor) reportEvent(orderID string, ev engine.OrderEvent) {
+    a.mu.Lock()
+    st, ok := a.orders[orderID]
+    if !ok {
+        a.mu.Unlock()
+        return
+    }
+    m := st.apply(orderID, ev)
+    if st.done() {
+        a.forget(orderID, st)
+    }
+    s := a.sessions[st.owner]
+    a.mu.Unlock()
+    if s != nil && m != nil {
+        s.send(m) // Reports for a disconnected owner are dropped
+    }
+}
+
+// forget drops a finished order. The caller holds a.mu.
+func (a *Acceptor) forget(orderID string, st *orderState) {
+    delete(a.orders, orderID)
+    for _, key := range st.aliases {
+        delete(a.aliases, key)
+    }
+}
+
+// inUse reports whether compID already used clOrdID for an order the engine
+// or the gateway knows. The caller holds a.mu.
+func (a *Acceptor) inUse(compID, clOrdID string) bool {
+    key := orderKey(compID, clOrdID)
+    if _, ok := a.aliases[key]; ok {
+        return true
+    }
+    if _, ok := a.orders[key]; ok {
+        return true
+    }
+    _, err := a.eng.GetOrderStatus(key)
+    return err == nil
+}
+
+// fieldError is a bad field in an application message, answered with a
+// session-level Reject.
+type fieldError struct {
+    tag    int
+    reason int // SessionRejectReason
+    text   string
+}
+
+func (e *fieldError) Error() string {
+    return e.text
+}
+
+func missing(tag int) *fieldError {
+    return &fieldError{tag: tag, reason: 1, text: "required tag missing"}
+}
+
+func incorrect(tag int, text string) *fieldError {
+    return &fieldError{tag: tag, reason: 5, text: text}
+}
+
+func required(m *Message, tag int) (string, *fieldError) {
+    v := m.Get(tag)
+    if v == "" {
+        return "", missing(tag)
+    }
+    return v, nil
+}
+
+func parsePrice(m *Message, tag int) (int64, *fieldError) {
+    p, err := strconv.ParseInt(m.Get(tag), 10, 64)
+    if err != nil {
+        return 0, incorrect(tag, "price must be a whole number of price units")
+    }
+    return p, nil
+}
+
+func parseQuantity(m *Message, tag int, decimals int) (int64, *fieldError) {
+    q, err := engine.ParseQuantity(m.Get(tag), decimals)
+    if err != nil || q <= 0 {
+        return 0, incorrect(tag, "quantity must be positive")
+    }
+    return q, nil
+}
+
+// parseNewOrder converts a NewOrderSingle into an engine order.
+func (a *Acceptor) parseNewOrder(compID string, m *Message) (*engine.Order, *fieldError) {
+    clOrdID, ferr := required(m, tagClOrdID)
+    if ferr != nil {
+        return nil, ferr
+    }
+    symbol, ferr := required(m, tagSymbol)
+    if ferr != nil {
+        return nil, ferr
+    }
+    code, ferr := required(m, tagSide)
+    if ferr != nil {
+        return nil, ferr
+    }
+    side, ok := sides[code]
+    if !ok {
+        return nil, incorrect(tagSide, "unsupported Side "+code)
+    }
+    code, ferr = required(m, tagOrdType)
+    if ferr != nil {
+        return nil, ferr
+    }
+    otype, ok := orderTypes[code]
+    if !ok {
+        return nil, incorrect(tagOrdType, "unsupported OrdType "+code)
+    }
+    // FIX defaults to DAY, which needs a session close configured; an
+    // order without TimeInForce rests until cancelled, as in the REST API
+    tif := engine.GTC
+    tifCode, hasTIF := m.Lookup(tagTimeInForce)
+    if hasTIF {
+        if tif, ok = timesInForce[tifCode]; !ok {
+            return nil, incorrect(tagTimeInForce, "unsupported TimeInForce "+tifCode)
+        }
+    }
+    if _, ok := m.Lookup(tagOrderQty); !ok {
+        return nil, missing(tagOrderQty)
+    }
+    decimals := a.eng.GetSymbolConfig(symbol).QuantityDecimals
+    quantity, ferr := parseQuantity(m, tagOrderQty, decimals)
+    if ferr != nil {
+        return nil, ferr
+    }
+
+    order := engine.NewOrder(orderKey(compID, clOrdID), symbol, side, otype, 0, quantity)
+    order.AccountID = m.Get(tagAccount)
+    order.TimeInForce = tif
+    if order.HasLimitPrice() {
+        if _, ok := m.Lookup(tagPrice); !ok {
+            return nil, missing(tagPrice)
+        }
+        if order.Price, ferr = parsePrice(m, tagPrice); ferr != nil {
+            return nil, ferr
+        }
+    }
+    if order.IsConditional() {
+        if _, ok := m.Lookup(tagStopPx); !ok {
+            return nil, missing(tagStopPx)
+        }
+        if order.StopPrice, ferr = parsePrice(m, tagStopPx); ferr != nil {
+            return nil, ferr
+        }
+    }
+    if date, ok := m.Lookup(tagExpireDate); ok && tifCode == "6" {
+        // LocalMktDate: expires at the session close on that date
+        d, err := time.Parse("20060102", date)
+        if err != nil {
+            return nil, incorrect(tagExpireDate, "invalid ExpireDate")
+        }
+        order.ExpireDate = d.Format(time.DateOnly)
+    } else if tifCode == "6" {
+        expire, ok := m.Lookup(tagExpireTime)
+        if !ok {
+            return nil, missing(tagExpireTime)
+        }
+        t, err := time.Parse(sendingTimeFormat, expire)
+        if err != nil {
+            if t, err = time.Parse("20060102-15:04:05", expire); err != nil {
+                return nil, incorrect(tagExpireTime, "invalid ExpireTime")
+            }
+        }
+        order.ExpiresAt = t.UnixMilli()
+    }
+    if _, ok := m.Lookup(tagMaxFloor); ok {
+        display, ferr := parseQuantity(m, tagMaxFloor, decimals)
+        if ferr != nil {
+            return nil, ferr
+        }
+        if display < quantity {
+            order.DisplayQuantity = display
+        }
+    }
+    // ExecInst is a space-separated list; 6 is Participate don't initiate,
+    // G is All or none
+    for _, inst := range strings.Fields(m.Get(tagExecInst)) {
+        switch inst {
+        case "6":
+            order.PostOnly = true
+        case "G":
+            order.AllOrNone = true
+        }
+    }
+    return order, nil
+}
+
+// newOrder handles a NewOrderSingle. Success is reported from the engine's
+// events; a rejection is reported here.
+func (a *Acceptor) newOrder(s *session, m *Message) {
+    order, ferr := a.parseNewOrder(s.compID, m)
+    if ferr != nil {
+        s.sendReject(m, ferr.tag, ferr.reason, ferr.text)
+        return
+    }
+    clOrdID := m.Get(tagClOrdID)
+    st := newOrderState(s.compID, clOrdID, order, a.eng.GetSymbolConfig(order.Symbol).QuantityDecimals)
+
+    a.mu.Lock()
+    if a.inUse(s.compID, clOrdID) {
+        a.mu.Unlock()
+        s.send(st.rejected(order.ID, "6", "duplicate ClOrdID "+clOrdID))
+        return
+    }
+    // Tracked before submitting so that no event can arrive untracked
+    a.orders[order.ID] = st
+    a.mu.Unlock()
+
+    if _, err := a.eng.SubmitOrder(order); err != nil {
+        a.mu.Lock()
+        delete(a.orders, order.ID)
+        a.mu.Unlock()
+        reason, ok := ordRejReasons[engine.Code(err)]
+        if !ok {
+            reason = "99"
+        }
+        s.send(st.rejected(order.ID, reason, err.Error()))
+    }
+}
+
+// rejected is the ExecutionReport for an order the engine refused.
+func (st *orderState) rejected(orderID, reason, text string) *Message {
+    st.final = execRejected
+    return st.report(orderID, "REJ-"+orderID, execRejected).Set(tagOrdRejReason, reason).Set(tagText, text)
+}
+
+// cancelRejection is an OrderCancelReject (9).
+type cancelRejection struct {
+    orderID   string
+    ordStatus string
+    reason    string // CxlRejReason
+    text      string
+}
+
+func (r *cancelRejection) message(m *Message) *Message {
+    responseTo := "1" // Order cancel request
+    if m.MsgType() == msgOrderCancelReplaceRequest {
+        responseTo = "2"
+    }
+    return NewMessage(msgOrderCancelReject).
+        Set(tagOrderID, r.orderID).
+        Set(tagClOrdID, m.Get(tagClOrdID)).
+        Set(tagOrigClOrdID, m.Get(tagOrigClOrdID)).
+        Set(tagOrdStatus, r.ordStatus).
+        Set(tagCxlRejResponseTo, responseTo).
+        Set(tagCxlRejReason, r.reason).
+        Set(tagText, r.text)
+}
+
+// pend marks the order named by OrigClOrdID as awaiting a cancel or replace
+// under a new ClOrdID, and reserves that ClOrdID. Orders the gateway has
+// not tracked since it started, such as those restored from a snapshot, are
+// picked up from the engine here.
+func (a *Acceptor) pend(compID, clOrdID, origClOrdID string) (string, *orderState, *cancelRejection) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    orderID, ok := a.aliases[orderKey(compID, origClOrdID)]
+    if !ok {
+        orderID = orderKey(compID, origClOrdID)
+    }
+    st, ok := a.orders[orderID]
+    if !ok {
+        order, err := a.eng.GetOrderStatus(orderID)
+        if err != nil {
+            return "NONE", nil, &cancelRejection{orderID: "NONE", ordStatus: execRejected, reason: "1", text: "unknown order " + origClOrdID}
+        }
+        switch order.Status {
+        case engine.StatusFilled:
+            return orderID, nil, &cancelRejection{orderID: orderID, ordStatus: execFilled, reason: "0", text: "order is already filled"}
+        case engine.StatusCancelled:
+            return orderID, nil, &cancelRejection{orderID: orderID, ordStatus: execCanceled, reason: "0", text: "order is already cancelled"}
+        }
+        st = newOrderState(compID, origClOrdID, order, a.eng.GetSymbolConfig(order.Symbol).QuantityDecimals)
+        st.reported = true
+        a.orders[orderID] = st
+    }
+    if st.pending != nil {
+        return orderID, nil, &cancelRejection{orderID: orderID, ordStatus: st.status(), reason: "3", text: "a cancel or replace is already pending"}
+    }
+    if a.inUse(compID, clOrdID) {
+        return orderID, nil, &cancelRejection{orderID: orderID, ordStatus: st.status(), reason: "6", text: "duplicate ClOrdID " + clOrdID}
+    }
+    st.pending = &pendingRequest{clOrdID: clOrdID, origClOrdID: origClOrdID}
+    key := orderKey(compID, clOrdID)
+    a.aliases[key] = orderID
+    st.aliases = append(st.aliases, key)
+    return orderID, st, nil
+}
+
+// unpend undoes pend after the engine refused the request.
+func (a *Acceptor) unpend(compID, clOrdID string, st *orderState) string {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if st.pending != nil && st.pending.clOrdID == clOrdID {
+        st.pending = nil
+    }
+    key := orderKey(compID, clOrdID)
+    delete(a.aliases, key)
+    for i, k := range st.aliases {
+        if k == key {
+            st.aliases = append(st.aliases[:i], st.aliases[i+1:]...)
+            break
+        }
+    }
+    return st.status()
+}
+
+// cancelRejectReason maps an engine refusal to CxlRejReason (102).
+func cancelRejectReason(err error) string {
+    switch {
+    case errors.Is(err, engine.ErrOrderNotOpen):
+        return "0" // Too late to cancel
+    case errors.Is(err, engine.ErrOrderNotFound):
+        return "1" // Unknown order
+    }
+    return "99"
+}
+
+// cancelOrder handles an OrderCancelRequest.
+func (a *Acceptor) cancelOrder(s *session, m *Message) {
+    clOrdID, origClOrdID, ferr := cancelIDs(m)
+    if ferr != nil {
+        s.sendReject(m, ferr.tag, ferr.reason, ferr.text)
+        return
+    }
+    orderID, st, rej := a.pend(s.compID, clOrdID, origClOrdID)
+    if rej != nil {
+        s.send(rej.message(m))
+        return
+    }
+    if _, err := a.eng.CancelOrder(orderID); err != nil {
+        status := a.unpend(s.compID, clOrdID, st)
+        s.send((&cancelRejection{orderID: orderID, ordStatus: status, reason: cancelRejectReason(err), text: err.Error()}).message(m))
+    }
+}
+
+// replaceOrder handles an OrderCancelReplaceRequest as an in-place
+// amendment of price and total quantity, keeping time priority where the
+// engine does (see MatchingEngine.AmendOrder). Other fields cannot change.
+func (a *Acceptor) replaceOrder(s *session, m *Message) {
+    clOrdID, origClOrdID, ferr := cancelIDs(m)
+    if ferr == nil {
+        _, ferr = required(m, tagOrderQty)
+    }
+    if ferr != nil {
+        s.sendReject(m, ferr.tag, ferr.reason, ferr.text)
+        return
+    }
+    orderID, st, rej := a.pend(s.compID, clOrdID, origClOrdID)
+    if rej != nil {
+        s.send(rej.message(m))
+        return
+    }
+    quantity, ferr := parseQuantity(m, tagOrderQty, st.decimals)
+    var price int64
+    if _, ok := m.Lookup(tagPrice); ok && ferr == nil {
+        price, ferr = parsePrice(m, tagPrice)
+    }
+    if ferr != nil {
+        a.unpend(s.compID, clOrdID, st)
+        s.sendReject(m, ferr.tag, ferr.reason, ferr.text)
+        return
+    }
+    if _, _, err := a.eng.AmendOrder(orderID, price, quantity); err != nil {
+        status := a.unpend(s.compID, clOrdID, st)
+        s.send((&cancelRejection{orderID: orderID, ordStatus: status, reason: cancelRejectReason(err), text: err.Error()}).message(m))
+    }
+}
+
+func cancelIDs(m *Message) (clOrdID, origClOrdID string, ferr *fieldError) {
+    if clOrdID, ferr = required(m, tagClOrdID); ferr != nil {
+        return
+    }
+    origClOrdID, ferr = required(m, tagOrigClOrdID)
+    return
+}
@@ -0,0 +1,77 @@
+package fix
+
+// Tags used by the gateway, named as in the FIX 4.4 specification.
+const (
+    tagAccount              = 1
+    tagAvgPx                = 6
+    tagBeginString          = 8
+    tagBodyLength           = 9
+    tagCheckSum             = 10
+    tagClOrdID              = 11
+    tagCumQty               = 14
+    tagExecID               = 17
+    tagExecInst             = 18
+    tagLastPx               = 31
+    tagLastQty              = 32
+    tagMsgSeqNum            = 34
+    tagMsgType              = 35
+    tagOrderID              = 37
+    tagOrderQty             = 38
+    tagOrdStatus            = 39
+    tagOrdType              = 40
+    tagOrigClOrdID          = 41
+    tagPrice                = 44
+    tagRefSeqNum            = 45
+    tagSenderCompID         = 49
+    tagSendingTime          = 52
+    tagSide                 = 54
+    tagSymbol               = 55
+    tagTargetCompID         = 56
+    tagText                 = 58
+    tagTimeInForce          = 59
+    tagTransactTime         = 60
+    tagEncryptMethod        = 98
+    tagStopPx               = 99
+    tagCxlRejReason         = 102
+    tagOrdRejReason         = 103
+    tagHeartBtInt           = 108
+    tagMaxFloor             = 111
+    tagTestReqID            = 112
+    tagExpireTime           = 126
+    tagResetSeqNumFlag      = 141
+    tagExecType             = 150
+    tagLeavesQty            = 151
+    tagRefTagID             = 371
+    tagRefMsgType           = 372
+    tagSessionRejectReason  = 373
+    tagBusinessRejectReason = 380
+    tagExpireDate           = 432
+    tagCxlRejResponseTo     = 434
+)
+
+// MsgType values.
+const (
+    msgHeartbeat                 = "0"
+    msgTestRequest               = "1"
+    msgReject                    = "3"
+    msgLogout                    = "5"
+    msgExecutionReport           = "8"
+    msgOrderCancelReject         = "9"
+    msgLogon                     = "A"
+    msgNewOrderSingle            = "D"
+    msgOrderCancelRequest        = "F"
+    msgOrderCancelReplaceRequest = "G"
+    msgBusinessMessageReject     = "j"
+)
+
+// ExecType (150) and OrdStatus (39) values.
+const (
+    execNew      = "0"
+    execPartial  = "1" // OrdStatus only
+    execFilled   = "2" // OrdStatus only
+    execCanceled = "4"
+    execReplaced = "5" // ExecType only
+    execRejected = "8"
+    execExpired  = "C"
+    execTrade    = "F" // ExecType only
+)
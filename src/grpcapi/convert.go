@@ -0,0 +1,147 @@
+package grpcapi
+
+import (
+    "fmt"
+
+    "order-matching-engine/src/engine"
+    pb "order-matching-engine/src/grpcapi/matchingpb"
+)
+
+var (
+    sides = map[pb.Side]engine.Side{
+        pb.Side_SIDE_BUY:  engine.Buy,
+        pb.Side_SIDE_SELL: engine.Sell,
+    }
+    orderTypes = map[pb.OrderType]engine.OrderType{
+        pb.OrderType_ORDER_TYPE_LIMIT:             engine.Limit,
+        pb.OrderType_ORDER_TYPE_MARKET:            engine.Market,
+        pb.OrderType_ORDER_TYPE_STOP:              engine.Stop,
+        pb.OrderType_ORDER_TYPE_STOP_LIMIT:        engine.StopLimit,
+        pb.OrderType_ORDER_TYPE_MARKET_IF_TOUCHED: engine.MarketIfTouched,
+        pb.OrderType_ORDER_TYPE_LIMIT_IF_TOUCHED:  engine.LimitIfTouched,
+    }
+    timesInForce = map[pb.TimeInForce]engine.TimeInForce{
+        pb.TimeInForce_TIME_IN_FORCE_UNSPECIFIED: engine.GTC,
+        pb.TimeInForce_TIME_IN_FORCE_GTC:         engine.GTC,
+        pb.TimeInForce_TIME_IN_FORCE_IOC:         engine.IOC,
+        pb.TimeInForce_TIME_IN_FORCE_FOK:         engine.FOK,
+        pb.TimeInForce_TIME_IN_FORCE_DAY:         engine.Day,
+    }
+    statuses = map[engine.OrderStatus]pb.OrderStatus{
+        engine.StatusAccepted:    pb.OrderStatus_ORDER_STATUS_ACCEPTED,
+        engine.StatusPartialFill: pb.OrderStatus_ORDER_STATUS_PARTIAL_FILL,
+        engine.StatusFilled:      pb.OrderStatus_ORDER_STATUS_FILLED,
+        engine.StatusCancelled:   pb.OrderStatus_ORDER_STATUS_CANCELLED,
+    }
+)
+
+func sideFromPB(s pb.Side) (engine.Side, error) {
+    if side, ok := sides[s]; ok {
+        return side, nil
+    }
+    return "", fmt.Errorf("Invalid order: invalid side %s", s)
+}
+
+func orderTypeFromPB(t pb.OrderType) (engine.OrderType, error) {
+    if otype, ok := orderTypes[t]; ok {
+        return otype, nil
+    }
+    return "", fmt.Errorf("Invalid order: invalid order type %s", t)
+}
+
+func timeInForceFromPB(t pb.TimeInForce) (engine.TimeInForce, error) {
+    if tif, ok := timesInForce[t]; ok {
+        return tif, nil
+    }
+    return "", fmt.Errorf("Invalid order: invalid time in force %s", t)
+}
+
+// reverse inverts one of the enum tables above.
+func reverse[K, V comparable](m map[K]V) map[V]K {
+    out := make(map[V]K, len(m))
+    for k, v := range m {
+        out[v] = k
+    }
+    return out
+}
+
+var (
+    sidesToPB        = reverse(sides)
+    orderTypesToPB   = reverse(orderTypes)
+    timesInForceToPB = map[engine.TimeInForce]pb.TimeInForce{
+        "":         pb.TimeInForce_TIME_IN_FORCE_GTC, // Empty is treated as GTC
+        engine.GTC: pb.TimeInForce_TIME_IN_FORCE_GTC,
+        engine.IOC: pb.TimeInForce_TIME_IN_FORCE_IOC,
+        engine.FOK: pb.TimeInForce_TIME_IN_FORCE_FOK,
+        engine.Day: pb.TimeInForce_TIME_IN_FORCE_DAY,
+    }
+)
+
+func orderToPB(o *engine.Order) *pb.Order {
+    return &pb.Order{
+        Id:              o.ID,
+        Symbol:          o.Symbol,
+        AccountId:       o.AccountID,
+        Side:            sidesToPB[o.Side],
+        Type:            orderTypesToPB[o.Type],
+        Price:           o.Price,
+        Quantity:        o.Quantity,
+        FilledQuantity:  o.FilledQuantity,
+        StopPrice:       o.StopPrice,
+        TimeInForce:     timesInForceToPB[o.TimeInForce],
+        ExpiresAt:       o.ExpiresAt,
+        DisplayQuantity: o.DisplayQuantity,
+        PostOnly:        o.PostOnly,
+        Hidden:          o.Hidden,
+        ProtectionPrice: o.ProtectionPrice,
+        Notional:        o.Notional,
+        SpentNotional:   o.SpentNotional,
+        Status:          statuses[o.Status],
+        CancelReason:    o.CancelReason,
+        Timestamp:       o.Timestamp,
+        Sequence:        o.Sequence,
+    }
+}
+
+func tradeToPB(t engine.Trade) *pb.Trade {
+    return &pb.Trade{
+        TradeId:          t.TradeID,
+        Symbol:           t.Symbol,
+        AggressorOrderId: t.AggressorOrderID,
+        RestingOrderId:   t.RestingOrderID,
+        AggressorSide:    sidesToPB[t.AggressorSide],
+        Price:            t.Price,
+        Quantity:         t.Quantity,
+        AggressorFee:     t.AggressorFee,
+        RestingFee:       t.RestingFee,
+        Timestamp:        t.Timestamp,
+        EventSeq:         t.EventSeq,
+    }
+}
+
+func tradesToPB(trades []engine.Trade) []*pb.Trade {
+    out := make([]*pb.Trade, len(trades))
+    for i, t := range trades {
+        out[i] = tradeToPB(t)
+    }
+    return out
+}
+
+func levelsToPB(levels []engine.AggregatedPriceLevel) []*pb.PriceLevel {
+    out := make([]*pb.PriceLevel, len(levels))
+    for i, l := range levels {
+        out[i] = &pb.PriceLevel{Price: l.Price, Quantity: l.Quantity}
+    }
+    return out
+}
+
+func depthToPB(u engine.DepthUpdate) *pb.BookUpdate {
+    return &pb.BookUpdate{
+        Symbol:   u.Symbol,
+        Sequence: u.Sequence,
+        Bids:     levelsToPB(u.Bids),
+        Asks:     levelsToPB(u.Asks),
+        Checksum: u.Checksum,
+        EventSeq: u.EventSeq,
+    }
+}
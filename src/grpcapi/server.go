@@ -0,0 +1,224 @@
+// Package grpcapi serves the matching engine over gRPC for co-located
+// clients. It mirrors the REST API in package api and delegates to the same
+// MatchingEngine; this package only maps between protobuf messages and
+// engine types.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative -I matchingpb matchingpb/matching.proto
+
+import (
+    "context"
+    "errors"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "order-matching-engine/src/engine"
+    pb "order-matching-engine/src/grpcapi/matchingpb"
+)
+
+// errorDomain is the ErrorInfo domain of engine refusals; the reason is the
+// engine's ReasonCode.
+const errorDomain = "order-matching-engine"
+
+// Server implements the MatchingEngine gRPC service.
+type Server struct {
+    pb.UnimplementedMatchingEngineServer
+    eng *engine.MatchingEngine
+}
+
+// NewServer returns a gRPC server with the MatchingEngine service registered.
+func NewServer(eng *engine.MatchingEngine, opts ...grpc.ServerOption) *grpc.Server {
+    gs := grpc.NewServer(opts...)
+    pb.RegisterMatchingEngineServer(gs, &Server{eng: eng})
+    return gs
+}
+
+func (s *Server) SubmitOrder(ctx context.Context, req *pb.SubmitOrderRequest) (*pb.SubmitOrderResponse, error) {
+    order, err := s.newOrder(req)
+    if err != nil {
+        return nil, invalid(err)
+    }
+    resp, err := s.eng.SubmitOrderContext(ctx, order)
+    if err != nil {
+        return nil, engineError(err)
+    }
+    return &pb.SubmitOrderResponse{
+        Order:   orderToPB(order),
+        Trades:  tradesToPB(resp.Trades),
+        Resting: resp.OrderInBook,
+    }, nil
+}
+
+func (s *Server) CancelOrder(_ context.Context, req *pb.CancelOrderRequest) (*pb.Order, error) {
+    order, err := s.eng.CancelOrder(req.GetOrderId())
+    if err != nil {
+        return nil, engineError(err)
+    }
+    return orderToPB(order), nil
+}
+
+func (s *Server) GetOrder(_ context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+    order, err := s.eng.GetOrderStatus(req.GetOrderId())
+    if err != nil {
+        return nil, engineError(err)
+    }
+    return orderToPB(order), nil
+}
+
+// StreamOrderBook sends a snapshot, then incremental level updates, like
+// /ws/orderbook. A client that falls behind is disconnected with
+// ResourceExhausted and should reconnect for a fresh snapshot.
+func (s *Server) StreamOrderBook(req *pb.StreamOrderBookRequest, stream grpc.ServerStreamingServer[pb.BookUpdate]) error {
+    symbol := req.GetSymbol()
+    if symbol == "" {
+        return status.Error(codes.InvalidArgument, "symbol is required")
+    }
+    if req.GetDepth() < 0 {
+        return status.Error(codes.InvalidArgument, "invalid depth")
+    }
+
+    // Subscribe before taking the snapshot so no update can fall in between.
+    sub := s.eng.SubscribeDepth(symbol)
+    defer sub.Close()
+    snap := s.eng.GetBookSnapshot(symbol, int(req.GetDepth()))
+    seq := snap.Sequence
+    if err := stream.Send(&pb.BookUpdate{
+        Snapshot: true,
+        Symbol:   symbol,
+        Sequence: seq,
+        Bids:     levelsToPB(snap.Bids),
+        Asks:     levelsToPB(snap.Asks),
+        Checksum: snap.Checksum,
+    }); err != nil {
+        return err
+    }
+
+    for {
+        select {
+        case update, ok := <-sub.C:
+            if !ok {
+                return status.Error(codes.ResourceExhausted, "dropped as a slow consumer")
+            }
+            if update.Sequence <= seq {
+                continue // Already reflected in the snapshot
+            }
+            if err := stream.Send(depthToPB(update)); err != nil {
+                return err
+            }
+        case <-stream.Context().Done():
+            return nil
+        }
+    }
+}
+
+// StreamTrades sends every trade in a symbol as it executes, like /ws/trades.
+// Headers are sent once the subscription is in place, so a client that waits
+// for them before trading never misses its own print.
+func (s *Server) StreamTrades(req *pb.StreamTradesRequest, stream grpc.ServerStreamingServer[pb.Trade]) error {
+    symbol := req.GetSymbol()
+    if symbol == "" {
+        return status.Error(codes.InvalidArgument, "symbol is required")
+    }
+    sub := s.eng.SubscribeTrades(symbol)
+    defer sub.Close()
+    if err := stream.SendHeader(nil); err != nil {
+        return err
+    }
+
+    for {
+        select {
+        case trade, ok := <-sub.C:
+            if !ok {
+                return status.Error(codes.ResourceExhausted, "dropped as a slow consumer")
+            }
+            if err := stream.Send(tradeToPB(trade)); err != nil {
+                return err
+            }
+        case <-stream.Context().Done():
+            return nil
+        }
+    }
+}
+
+// newOrder checks the request's shape and builds the engine order; price,
+// quantity and symbol rules are left to the engine, as for REST.
+func (s *Server) newOrder(req *pb.SubmitOrderRequest) (*engine.Order, error) {
+    if req.GetSymbol() == "" {
+        return nil, errors.New("Invalid order: symbol is required")
+    }
+    side, err := sideFromPB(req.GetSide())
+    if err != nil {
+        return nil, err
+    }
+    otype, err := orderTypeFromPB(req.GetType())
+    if err != nil {
+        return nil, err
+    }
+    tif, err := timeInForceFromPB(req.GetTimeInForce())
+    if err != nil {
+        return nil, err
+    }
+    if req.GetQuantity() <= 0 && req.GetNotional() == 0 {
+        return nil, errors.New("Invalid order: quantity must be positive")
+    }
+    if req.GetQuantity() != 0 && req.GetNotional() != 0 {
+        return nil, errors.New("Invalid order: quantity and notional are mutually exclusive")
+    }
+    if req.GetDisplayQuantity() < 0 || req.GetDisplayQuantity() > req.GetQuantity() {
+        return nil, errors.New("Invalid order: display_quantity must be between 0 and quantity")
+    }
+    // A client-supplied ID is an idempotency key (see engine idempotency.go)
+    id := req.GetId()
+    if id == "" {
+        id = s.eng.NewOrderID()
+    }
+    order := engine.NewOrder(id, req.GetSymbol(), side, otype, req.GetPrice(), req.GetQuantity())
+    order.AccountID = req.GetAccountId()
+    order.TimeInForce = tif
+    order.StopPrice = req.GetStopPrice()
+    order.ExpiresAt = req.GetExpiresAt()
+    order.PostOnly = req.GetPostOnly()
+    order.Hidden = req.GetHidden()
+    order.ProtectionPrice = req.GetProtectionPrice()
+    order.Notional = req.GetNotional()
+    if req.GetDisplayQuantity() < req.GetQuantity() {
+        order.DisplayQuantity = req.GetDisplayQuantity()
+    }
+    return order, nil
+}
+
+// invalid is a request-shape error, carrying CodeInvalidOrder like REST.
+func invalid(err error) error {
+    return withReason(codes.InvalidArgument, engine.CodeInvalidOrder, err.Error())
+}
+
+// engineError maps an engine refusal to a gRPC status the way REST maps it
+// to an HTTP status, with the engine's reason code in an ErrorInfo detail.
+func engineError(err error) error {
+    reason := engine.Code(err)
+    if reason == "" {
+        reason = engine.CodeInvalidOrder
+    }
+    code := codes.InvalidArgument
+    switch {
+    case errors.Is(err, engine.ErrOrderNotFound):
+        code = codes.NotFound
+    case errors.Is(err, engine.ErrDuplicateOrderID):
+        code = codes.AlreadyExists
+    case errors.Is(err, engine.ErrSymbolHalted), errors.Is(err, engine.ErrMarketClosed), errors.Is(err, engine.ErrInvalidState), errors.Is(err, engine.ErrOrderNotOpen):
+        code = codes.FailedPrecondition
+    case errors.Is(err, engine.ErrWALFailure):
+        code = codes.Internal
+    }
+    return withReason(code, reason, err.Error())
+}
+
+func withReason(code codes.Code, reason engine.ReasonCode, msg string) error {
+    st := status.New(code, msg)
+    if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(reason), Domain: errorDomain}); err == nil {
+        st = detailed
+    }
+    return st.Err()
+}
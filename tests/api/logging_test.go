@@ -0,0 +1,66 @@
+package api_test
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "log/slog"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    api "order-matching-engine/src/api"
+    "order-matching-engine/src/engine"
+)
+
+func TestRequestIDCorrelatesTrades(t *testing.T) {
+    var buf bytes.Buffer
+    logger := slog.New(slog.NewJSONHandler(&buf, nil))
+    eng := engine.NewMatchingEngine()
+    eng.SetLogger(logger)
+    srv := api.NewServer(eng, api.WithLogger(logger))
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`)))
+    req.Header.Set(api.RequestIDHeader, "req-123")
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    if got := rr.Header().Get(api.RequestIDHeader); got != "req-123" {
+        t.Fatalf("expected request ID to be echoed, got %q", got)
+    }
+
+    seen := map[string]bool{}
+    scanner := bufio.NewScanner(&buf)
+    for scanner.Scan() {
+        var line map[string]interface{}
+        if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+            t.Fatalf("log line is not JSON: %s", scanner.Text())
+        }
+        if line["request_id"] != "req-123" {
+            continue
+        }
+        msg := line["msg"].(string)
+        seen[msg] = true
+        if msg == "trade executed" && line["trade_id"] == "" {
+            t.Fatalf("trade log without trade_id: %v", line)
+        }
+    }
+    for _, msg := range []string{"order submitted", "trade executed", "http request"} {
+        if !seen[msg] {
+            t.Fatalf("expected %q log tagged with the request ID, saw %v", msg, seen)
+        }
+    }
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+    srv := newTestServer()
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+    if rr.Header().Get(api.RequestIDHeader) == "" {
+        t.Fatalf("expected a generated request ID")
+    }
+}
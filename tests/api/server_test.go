@@ -2,10 +2,14 @@ package api_test
 
 import (
     "bytes"
+    "context"
     "encoding/json"
     "net/http"
     "net/http/httptest"
+    "reflect"
+    "strings"
     "testing"
+    "time"
 
     api "order-matching-engine/src/api"
     "order-matching-engine/src/engine"
@@ -123,6 +127,53 @@ func TestCreateOrder_InsufficientLiquidity_Market(t *testing.T) {
     }
 }
 
+func TestCreateOrder_ReportsEveryInvalidField(t *testing.T) {
+    srv := newTestServer()
+
+    post := func(body string) map[string]interface{} {
+        req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(body)))
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        if rr.Code != http.StatusBadRequest {
+            t.Fatalf("expected 400, got %d body=%s", rr.Code, rr.Body.String())
+        }
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return got
+    }
+
+    got := post(`{"side":"HOLD","type":"LIMIT","price":0,"quantity":-5,"time_in_force":"SOMETIMES","peg_reference":"LAST"}`)
+    details, _ := got["details"].([]interface{})
+    fields := map[string]bool{}
+    for _, d := range details {
+        fields[d.(map[string]interface{})["field"].(string)] = true
+    }
+    for _, field := range []string{"symbol", "quantity", "side", "time_in_force", "peg_reference", "price"} {
+        if !fields[field] {
+            t.Fatalf("expected %s in details, got %v", field, got["details"])
+        }
+    }
+    if len(details) != 6 {
+        t.Fatalf("expected 6 details, got %v", got["details"])
+    }
+    if got["code"] != "INVALID_ORDER" {
+        t.Fatalf("expected INVALID_ORDER, got %v", got["code"])
+    }
+    msg, _ := got["error"].(string)
+    if !strings.Contains(msg, "symbol is required") || !strings.Contains(msg, "quantity must be positive") {
+        t.Fatalf("expected every problem in error, got %q", msg)
+    }
+
+    // A single problem keeps the error it always had
+    got = post(`{"symbol":"AAPL","side":"BUY","type":"MARKET","quantity":10,"notional":500}`)
+    if got["error"] != "Invalid order: quantity and notional are mutually exclusive" {
+        t.Fatalf("unexpected error %v", got["error"])
+    }
+    if details, _ := got["details"].([]interface{}); len(details) != 1 || details[0].(map[string]interface{})["field"] != "notional" {
+        t.Fatalf("expected one notional detail, got %v", got["details"])
+    }
+}
+
 func doPost(t *testing.T, srv *api.Server, body []byte, expStatus int) {
     t.Helper()
     req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader(body))
@@ -135,3 +186,1736 @@ func doPost(t *testing.T, srv *api.Server, body []byte, expStatus int) {
 }
 
 
+
+func TestCreateOrder_IOCPartialFill(t *testing.T) {
+    srv := newTestServer()
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":300}`), http.StatusCreated)
+
+    body := []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15050,"quantity":500,"time_in_force":"IOC"}`)
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader(body))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+
+    if rr.Code != http.StatusAccepted {
+        t.Fatalf("expected 202, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["cancelled_quantity"].(float64) != 200 {
+        t.Fatalf("expected cancelled_quantity 200, got %v", got["cancelled_quantity"])
+    }
+    trades, ok := got["trades"].([]interface{})
+    if !ok || len(trades) != 1 {
+        t.Fatalf("expected 1 trade, got %v", got["trades"])
+    }
+}
+
+func TestAmendOrder_Patch(t *testing.T) {
+    srv := newTestServer()
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15040,"quantity":100}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var created map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &created)
+    id := created["order_id"].(string)
+
+    req = httptest.NewRequest(http.MethodPatch, "/api/v1/orders/"+id, bytes.NewReader([]byte(`{"price":15045,"quantity":150}`)))
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["price"].(float64) != 15045 || got["quantity"].(float64) != 150 {
+        t.Fatalf("unexpected amended order %v", got)
+    }
+
+    req = httptest.NewRequest(http.MethodPatch, "/api/v1/orders/missing", bytes.NewReader([]byte(`{"price":15045}`)))
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404, got %d", rr.Code)
+    }
+}
+
+func TestGetTrades(t *testing.T) {
+    srv := newTestServer()
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15050,"quantity":100}`), http.StatusOK)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/trades?symbol=AAPL&limit=10", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    trades, ok := got["trades"].([]interface{})
+    if !ok || len(trades) != 1 {
+        t.Fatalf("expected 1 trade, got %v", got["trades"])
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/trades", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 without symbol, got %d", rr.Code)
+    }
+}
+
+func TestAdminSnapshot_ReturnsBodyWithoutPath(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodPost, "/admin/snapshot", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    restored := engine.NewMatchingEngine()
+    if err := restored.LoadSnapshot(bytes.NewReader(rr.Body.Bytes())); err != nil {
+        t.Fatalf("snapshot body should load: %v", err)
+    }
+    _, asks := restored.GetOrderBookSnapshot("AAPL", 0)
+    if len(asks) != 1 {
+        t.Fatalf("expected restored ask, got %v", asks)
+    }
+}
+
+func TestCreateOrder_FractionalQuantity(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "BTC", QuantityDecimals: 4}); err != nil {
+        t.Fatal(err)
+    }
+    srv := api.NewServer(eng)
+
+    doPost(t, srv, []byte(`{"symbol":"BTC","side":"SELL","type":"LIMIT","price":3000000,"quantity":"0.75"}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"BTC","side":"SELL","type":"LIMIT","price":3000000,"quantity":0.75}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"symbol":"BTC","side":"BUY","type":"LIMIT","price":3000000,"quantity":"1.5"}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["filled_quantity"] != "1.5" {
+        t.Fatalf("expected filled_quantity \"1.5\", got %v", got["filled_quantity"])
+    }
+    trades := got["trades"].([]interface{})
+    if len(trades) != 2 || trades[0].(map[string]interface{})["quantity"] != "0.75" {
+        t.Fatalf("expected two 0.75 trades, got %v", trades)
+    }
+
+    // Too much precision is rejected rather than rounded
+    doPost(t, srv, []byte(`{"symbol":"BTC","side":"BUY","type":"LIMIT","price":3000000,"quantity":"0.00001"}`), http.StatusBadRequest)
+}
+
+func TestDecimalPrices_RoundTrip(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", PriceScale: 2}); err != nil {
+        t.Fatal(err)
+    }
+    srv := api.NewServer(eng)
+
+    // Integer units and the decimal string name the same price
+    doPost(t, srv, []byte(`{"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"s2","symbol":"AAPL","side":"SELL","type":"LIMIT","price":"150.50","quantity":100}`), http.StatusCreated)
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    if len(asks) != 1 || asks[0].Price != 15050 || asks[0].Quantity != 200 {
+        t.Fatalf("expected one level of 200 at 15050, got %v", asks)
+    }
+
+    getPrice := func(target string, accept string) interface{} {
+        t.Helper()
+        req := httptest.NewRequest(http.MethodGet, target, nil)
+        if accept != "" {
+            req.Header.Set("Accept", accept)
+        }
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        if rr.Code != http.StatusOK {
+            t.Fatalf("GET %s expected 200, got %d body=%s", target, rr.Code, rr.Body.String())
+        }
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return got["price"]
+    }
+
+    if p := getPrice("/api/v1/orders/s2", ""); p != float64(15050) {
+        t.Fatalf("expected integer price 15050 by default, got %v", p)
+    }
+    if p := getPrice("/api/v1/orders/s2?format=decimal", ""); p != "150.50" {
+        t.Fatalf("expected \"150.50\" with ?format=decimal, got %v", p)
+    }
+    if p := getPrice("/api/v1/orders/s1", "application/json; format=decimal"); p != "150.50" {
+        t.Fatalf("expected \"150.50\" with the Accept parameter, got %v", p)
+    }
+
+    // A decimal read back is accepted as-is on an amend
+    req := httptest.NewRequest(http.MethodPatch, "/api/v1/orders/s1?format=decimal", bytes.NewReader([]byte(`{"price":"150.55"}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var amended map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &amended)
+    if amended["price"] != "150.55" {
+        t.Fatalf("expected amended price \"150.55\", got %v", amended["price"])
+    }
+    if p := getPrice("/api/v1/orders/s1", ""); p != float64(15055) {
+        t.Fatalf("expected 15055 after the amend, got %v", p)
+    }
+
+    // Trades carry decimal prices too
+    req = httptest.NewRequest(http.MethodPost, "/api/v1/orders?format=decimal", bytes.NewReader([]byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":"150.50","quantity":100}`)))
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var filled map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &filled)
+    trades := filled["trades"].([]interface{})
+    if len(trades) != 1 || trades[0].(map[string]interface{})["price"] != "150.50" {
+        t.Fatalf("expected one trade at \"150.50\", got %v", trades)
+    }
+
+    // More decimals than the symbol's scale are rejected rather than rounded
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":"150.505","quantity":100}`), http.StatusBadRequest)
+}
+
+func TestCreateOrder_TickAndLotValidation(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", TickSize: 5, LotSize: 10}); err != nil {
+        t.Fatal(err)
+    }
+    srv := api.NewServer(eng)
+
+    // Price off-tick
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10002,"quantity":100}`), http.StatusBadRequest)
+    // Quantity off-lot
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":15}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10005,"quantity":20}`), http.StatusCreated)
+}
+
+func TestCreateOrder_PriceBand(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", PriceBandBps: 100, ReferencePrice: 10000}); err != nil {
+        t.Fatal(err)
+    }
+    srv := api.NewServer(eng)
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9899,"quantity":100}`), http.StatusBadRequest)
+}
+
+func TestCreateOrder_MarketProtection(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15000,"quantity":100}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"symbol":"AAPL","side":"BUY","type":"MARKET","quantity":200,"protection_price":10500}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusAccepted {
+        t.Fatalf("expected 202, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["filled_quantity"] != float64(100) || got["cancelled_quantity"] != float64(100) {
+        t.Fatalf("expected 100 filled and 100 cancelled, got %v", got)
+    }
+
+    // Only meaningful for market orders
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"protection_price":10500}`), http.StatusBadRequest)
+}
+
+func TestCancelOrder_ReportsFilledAndRemaining(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15050,"quantity":30}`), http.StatusOK)
+
+    req := httptest.NewRequest(http.MethodDelete, "/api/v1/orders/s1", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["status"] != "CANCELLED" || got["filled_quantity"] != float64(30) || got["remaining_quantity"] != float64(70) {
+        t.Fatalf("expected 30 filled and 70 remaining, got %s", rr.Body.String())
+    }
+}
+
+func TestCancelAll_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","account_id":"X","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","account_id":"Y","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"MSFT","account_id":"X","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodDelete, "/api/v1/orders?symbol=AAPL&account=X", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        IDs   []string `json:"cancelled_order_ids"`
+        Count int      `json:"count"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got.Count != 1 || len(got.IDs) != 1 {
+        t.Fatalf("expected one cancelled order, got %s", rr.Body.String())
+    }
+}
+
+func TestCancelLevel_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    for i := 0; i < 3; i++ {
+        doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":10}`), http.StatusCreated)
+    }
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15100,"quantity":10}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodDelete, "/api/v1/orderbook/AAPL/level?side=SELL&price=15050", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        IDs   []string `json:"cancelled_order_ids"`
+        Count int      `json:"count"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got.Count != 3 || len(got.IDs) != 3 {
+        t.Fatalf("expected three cancelled orders, got %s", rr.Body.String())
+    }
+
+    // The level is gone; cancelling it again is not an error
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/orderbook/AAPL/level?side=SELL&price=15050", nil))
+    if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"count":0`) {
+        t.Fatalf("expected an empty 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/orderbook/AAPL/level?side=UP&price=15100", nil))
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 for a bad side, got %d", rr.Code)
+    }
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/v1/orderbook/AAPL/level?side=SELL&price=15100", nil))
+    if rr.Code != http.StatusMethodNotAllowed {
+        t.Fatalf("expected 405 for PUT, got %d", rr.Code)
+    }
+}
+
+func TestBatch_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    body := `[
+        {"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":100},
+        {"symbol":"AAPL","side":"BUY","type":"MARKET","quantity":500},
+        {"symbol":"AAPL","side":"BUY","type":"LIMIT","price":0,"quantity":10},
+        {"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":100}
+    ]`
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/batch", bytes.NewReader([]byte(body)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        Results []map[string]interface{} `json:"results"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    want := []string{"ACCEPTED", "REJECTED", "REJECTED", "FILLED"}
+    if len(got.Results) != len(want) {
+        t.Fatalf("expected %d results, got %s", len(want), rr.Body.String())
+    }
+    for i, status := range want {
+        if got.Results[i]["status"] != status {
+            t.Fatalf("result %d: expected %s, got %v", i, status, got.Results[i])
+        }
+    }
+}
+
+func TestBBO_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    getBBO := func() map[string]interface{} {
+        req := httptest.NewRequest(http.MethodGet, "/api/v1/bbo?symbol=AAPL", nil)
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        if rr.Code != http.StatusOK {
+            t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+        }
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return got
+    }
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":10}`), http.StatusCreated)
+    got := getBBO()
+    if got["bid"] == nil || got["ask"] != nil || got["mid"] != nil || got["spread"] != nil {
+        t.Fatalf("one-sided book: unexpected %v", got)
+    }
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10001,"quantity":5}`), http.StatusCreated)
+    got = getBBO()
+    if got["spread"] != float64(101) || got["mid"] != 9950.5 {
+        t.Fatalf("two-sided book: unexpected %v", got)
+    }
+    if ask := got["ask"].(map[string]interface{}); ask["price"] != float64(10001) || ask["quantity"] != float64(5) {
+        t.Fatalf("unexpected ask %v", ask)
+    }
+}
+
+func TestBookStats_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":30}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":10}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orderbook/stats?symbol=AAPL", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["imbalance"] != 0.5 || got["total_bid_volume"] != float64(30) || got["spread"] != float64(200) {
+        t.Fatalf("unexpected stats %v", got)
+    }
+}
+
+func TestCreateOrder_Hidden(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10,"hidden":true}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"MARKET","quantity":10,"hidden":true}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10,"display_quantity":5,"hidden":true}`), http.StatusBadRequest)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orderbook?symbol=AAPL", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var book map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &book)
+    if asks, _ := book["asks"].([]interface{}); len(asks) != 0 || book["total_ask_orders"] != float64(0) {
+        t.Fatalf("hidden order leaked into depth: %v", book)
+    }
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusOK)
+}
+
+func TestTicker_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/ticker?symbol=AAPL", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 before any trade, got %d", rr.Code)
+    }
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10100,"quantity":10}`), http.StatusOK)
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/ticker?symbol=AAPL", nil))
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["last_price"] != float64(10000) || got["last_quantity"] != float64(10) {
+        t.Fatalf("expected last trade at the resting price, got %v", got)
+    }
+}
+
+func TestCandles_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusOK)
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/candles?symbol=AAPL&interval=1m&limit=60", nil))
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        Candles []map[string]interface{} `json:"candles"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if len(got.Candles) != 1 || got.Candles[0]["close"] != float64(10000) || got.Candles[0]["volume"] != float64(10) {
+        t.Fatalf("unexpected candles %s", rr.Body.String())
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/candles?symbol=AAPL&interval=2m", nil))
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 for unsupported interval, got %d", rr.Code)
+    }
+}
+
+func TestMetrics_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusOK)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"MARKET","quantity":500}`), http.StatusBadRequest)
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d", rr.Code)
+    }
+    body := rr.Body.String()
+    for _, want := range []string{
+        `ome_trades_executed_total{symbol="AAPL"} 1`,
+        `ome_orders_submitted_total{side="BUY",type="LIMIT"} 1`,
+        `ome_orders_rejected_total{reason="insufficient_liquidity"} 1`,
+        `ome_resting_orders{symbol="AAPL"} 1`,
+        `ome_oldest_order_age_seconds{symbol="AAPL"}`,
+        `ome_order_submit_duration_seconds_count 4`,
+    } {
+        if !strings.Contains(body, want) {
+            t.Fatalf("metrics missing %q", want)
+        }
+    }
+}
+
+func TestRejections_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"MARKET","quantity":500}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":5,"post_only":true}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"id":"bad-1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":0}`), http.StatusBadRequest)
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rejections?limit=2", nil))
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        Rejections []engine.Rejection `json:"rejections"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if len(got.Rejections) != 2 {
+        t.Fatalf("expected 2 rejections, got %v", got.Rejections)
+    }
+    // Newest first, including requests refused before reaching the engine
+    if r := got.Rejections[0]; r.Code != engine.CodeInvalidOrder || r.Order.ID != "bad-1" || r.Symbol != "AAPL" {
+        t.Fatalf("unexpected latest rejection %+v", r)
+    }
+    if r := got.Rejections[1]; r.Code != engine.CodePostOnlyCross || r.Order.Quantity != 5 {
+        t.Fatalf("unexpected rejection %+v", r)
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rejections", nil))
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if len(got.Rejections) != 3 || got.Rejections[2].Code != engine.CodeInsufficientLiquidity {
+        t.Fatalf("expected all 3 rejections, got %+v", got.Rejections)
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/rejections?limit=x", nil))
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 for a bad limit, got %d", rr.Code)
+    }
+}
+
+func TestAuction_AdminEndpoints(t *testing.T) {
+    srv := newTestServer()
+    post := func(path string, exp int) map[string]interface{} {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+        if rr.Code != exp {
+            t.Fatalf("%s: expected %d, got %d body=%s", path, exp, rr.Code, rr.Body.String())
+        }
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return got
+    }
+
+    post("/admin/auction/run?symbol=AAPL", http.StatusConflict)
+    post("/admin/auction/start?symbol=AAPL", http.StatusOK)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10100,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+
+    got := post("/admin/auction/run?symbol=AAPL", http.StatusOK)
+    if got["clearing_price"] != float64(10000) || len(got["trades"].([]interface{})) != 1 || got["phase"] != "CONTINUOUS" {
+        t.Fatalf("unexpected auction result %v", got)
+    }
+}
+
+func TestHalt_AdminEndpoints(t *testing.T) {
+    srv := newTestServer()
+    post := func(path string, exp int) {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+        if rr.Code != exp {
+            t.Fatalf("%s: expected %d, got %d body=%s", path, exp, rr.Code, rr.Body.String())
+        }
+    }
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    post("/admin/halt?symbol=AAPL", http.StatusOK)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusConflict)
+    post("/admin/halt?symbol=AAPL", http.StatusConflict)
+    post("/admin/resume?symbol=AAPL", http.StatusOK)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusOK)
+    post("/admin/halt?symbol=AAPL&mode=sideways", http.StatusBadRequest)
+}
+
+func TestRollSession_AdminEndpoint(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", SessionClose: "23:59"}); err != nil {
+        t.Fatal(err)
+    }
+    eng.SetClock(func() time.Time { return time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC) })
+    srv := api.NewServer(eng)
+    doPost(t, srv, []byte(`{"id":"day","symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"DAY"}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"gtc","symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":10}`), http.StatusCreated)
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/roll-session?symbol=AAPL", nil))
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    expired := got["expired"].([]interface{})
+    if got["count"] != float64(1) || expired[0].(map[string]interface{})["order_id"] != "day" {
+        t.Fatalf("expected only the DAY order expired, got %v", got)
+    }
+    if status, _ := eng.GetOrderStatus("gtc"); status.Status != engine.StatusAccepted {
+        t.Fatalf("expected the GTC order to keep resting, got %v", status.Status)
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/roll-session", nil))
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 without a symbol, got %d", rr.Code)
+    }
+}
+
+func TestKillSwitch_AdminEndpoints(t *testing.T) {
+    srv := newTestServer()
+    post := func(path string, exp int) map[string]interface{} {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+        if rr.Code != exp {
+            t.Fatalf("%s: expected %d, got %d body=%s", path, exp, rr.Code, rr.Body.String())
+        }
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return got
+    }
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"MSFT","side":"BUY","type":"LIMIT","price":20000,"quantity":10}`), http.StatusCreated)
+    if got := post("/admin/killswitch", http.StatusOK); got["count"] != float64(2) {
+        t.Fatalf("expected 2 cancelled, got %v", got)
+    }
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusConflict)
+    post("/admin/reset", http.StatusOK)
+    post("/admin/reset", http.StatusConflict)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+}
+
+func TestCreateOrder_Notional(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":10}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"symbol":"AAPL","side":"BUY","type":"MARKET","notional":150000}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    // 10@10000 + 4@10100 = 140400
+    if got["filled_quantity"] != float64(14) || got["spent_notional"] != float64(140400) || got["leftover_notional"] != float64(9600) {
+        t.Fatalf("unexpected notional fill: %v", got)
+    }
+    // 140400/14 = 10028.57, rounded half up
+    if got["average_price"] != float64(10029) {
+        t.Fatalf("expected average_price 10029, got %v", got["average_price"])
+    }
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"MARKET","quantity":1,"notional":1000}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"notional":1000}`), http.StatusBadRequest)
+}
+
+func TestCreateOrder_AveragePrice(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":20}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10200,"quantity":30}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10200,"quantity":45}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    // 10@10000 + 20@10100 + 15@10200 = 455000 over 45 = 10111.1
+    if got["total_value"] != float64(455000) || got["average_price"] != float64(10111) {
+        t.Fatalf("unexpected average: %v", got)
+    }
+}
+
+func TestCreateOrder_IdempotentRetry(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+
+    post := func(body string) (int, map[string]interface{}) {
+        req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(body))
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return rr.Code, got
+    }
+    buy := `{"id":"client-1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":60}`
+    code1, first := post(buy)
+    code2, retry := post(buy)
+    if code1 != http.StatusOK || code2 != http.StatusOK {
+        t.Fatalf("expected 200 twice, got %d and %d", code1, code2)
+    }
+    if retry["order_id"] != "client-1" || retry["status"] != first["status"] || retry["filled_quantity"] != first["filled_quantity"] {
+        t.Fatalf("retry differs: first=%v retry=%v", first, retry)
+    }
+
+    // Only one fill happened: 40 of the sell is still resting
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/s1", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var s1 map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &s1)
+    if s1["filled_quantity"] != float64(60) {
+        t.Fatalf("expected s1 filled 60 once, got %v", s1["filled_quantity"])
+    }
+
+    doPost(t, srv, []byte(`{"id":"client-1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":70}`), http.StatusConflict)
+}
+
+func TestGetPositions(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","account_id":"mm","side":"SELL","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","account_id":"alice","side":"BUY","type":"LIMIT","price":10000,"quantity":100}`), http.StatusOK)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","account_id":"mm","side":"BUY","type":"LIMIT","price":10200,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","account_id":"alice","side":"SELL","type":"LIMIT","price":10200,"quantity":100}`), http.StatusOK)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/positions?account=alice", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        Positions []map[string]interface{} `json:"positions"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if len(got.Positions) != 1 || got.Positions[0]["net_quantity"] != float64(0) || got.Positions[0]["realized_pnl"] != float64(20000) {
+        t.Fatalf("unexpected positions: %s", rr.Body.String())
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/positions", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 without account, got %d", rr.Code)
+    }
+}
+
+func TestCreateOrder_TradeFees(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", Fees: engine.FeeSchedule{TakerFeeBps: 10, MakerRebateBps: 2}}); err != nil {
+        t.Fatal(err)
+    }
+    srv := api.NewServer(eng)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":100}`))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var got struct {
+        Trades []map[string]interface{} `json:"trades"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if len(got.Trades) != 1 || got.Trades[0]["aggressor_fee"] != float64(1000) || got.Trades[0]["resting_fee"] != float64(-200) {
+        t.Fatalf("unexpected trade fees: %s", rr.Body.String())
+    }
+}
+
+func TestAccountFees_Endpoint(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    fees := engine.FeeSchedule{TakerFeeBps: 10, Tiers: []engine.FeeTier{{MinVolume: 100, TakerFeeBps: 4}}}
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", Fees: fees}); err != nil {
+        t.Fatal(err)
+    }
+    srv := api.NewServer(eng)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":100,"account_id":"maker"}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":100,"account_id":"taker"}`), http.StatusOK)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/taker/fees", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var got struct {
+        AccountID string `json:"account_id"`
+        Fees      []struct {
+            Symbol string         `json:"symbol"`
+            Volume float64        `json:"volume"`
+            Tier   engine.FeeTier `json:"tier"`
+        } `json:"fees"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if rr.Code != http.StatusOK || got.AccountID != "taker" || len(got.Fees) != 1 ||
+        got.Fees[0].Volume != 100 || got.Fees[0].Tier.TakerFeeBps != 4 {
+        t.Fatalf("unexpected account fees: %d %s", rr.Code, rr.Body.String())
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/taker", nil))
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 without /fees, got %d", rr.Code)
+    }
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/accounts/taker/fees", nil))
+    if rr.Code != http.StatusMethodNotAllowed {
+        t.Fatalf("expected 405, got %d", rr.Code)
+    }
+}
+
+func TestReplaceOrder(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"b1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+
+    replace := func(body string) (int, map[string]interface{}) {
+        req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/b1/replace", strings.NewReader(body))
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return rr.Code, got
+    }
+
+    // Invalid replacement: the original stays
+    if code, _ := replace(`{"side":"BUY","type":"LIMIT","price":0,"quantity":100}`); code != http.StatusBadRequest {
+        t.Fatalf("expected 400, got %d", code)
+    }
+
+    code, got := replace(`{"id":"b2","side":"BUY","type":"LIMIT","price":10010,"quantity":50}`)
+    if code != http.StatusCreated {
+        t.Fatalf("expected 201, got %d body=%v", code, got)
+    }
+    cancelled := got["cancelled_order"].(map[string]interface{})
+    created := got["new_order"].(map[string]interface{})
+    if cancelled["order_id"] != "b1" || cancelled["status"] != "CANCELLED" || created["order_id"] != "b2" || created["status"] != "ACCEPTED" {
+        t.Fatalf("unexpected replace result: %v", got)
+    }
+
+    // The original is gone now
+    if code, _ := replace(`{"side":"BUY","type":"LIMIT","price":10010,"quantity":50}`); code != http.StatusBadRequest {
+        t.Fatalf("expected 400 replacing a cancelled order, got %d", code)
+    }
+}
+
+func TestGetOrderBook_MultipleSymbols(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"MSFT","side":"SELL","type":"LIMIT","price":30000,"quantity":50}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orderbook?symbols=AAPL,MSFT,,GOOG&depth=5", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        Books map[string]struct {
+            Bids []map[string]interface{} `json:"bids"`
+            Asks []map[string]interface{} `json:"asks"`
+        } `json:"books"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if len(got.Books) != 3 || len(got.Books["AAPL"].Bids) != 1 || len(got.Books["MSFT"].Asks) != 1 {
+        t.Fatalf("unexpected books: %s", rr.Body.String())
+    }
+    if !strings.Contains(rr.Body.String(), `"GOOG":{"asks":[],"bids":[],"checksum":0}`) {
+        t.Fatalf("expected empty arrays for GOOG, got %s", rr.Body.String())
+    }
+}
+
+func TestGetOrderBook_MaxDepth(t *testing.T) {
+    seed := func(srv *api.Server) {
+        for _, price := range []string{"10000", "9900", "9800"} {
+            doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":`+price+`,"quantity":10}`), http.StatusCreated)
+        }
+    }
+    bids := func(srv *api.Server, path string) (int, int) {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+        var got struct {
+            Bids  []interface{} `json:"bids"`
+            Books map[string]struct {
+                Bids []interface{} `json:"bids"`
+            } `json:"books"`
+        }
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        if got.Books != nil {
+            return rr.Code, len(got.Books["AAPL"].Bids)
+        }
+        return rr.Code, len(got.Bids)
+    }
+
+    // Clamp: too deep, or unlimited, serves the cap
+    srv := api.NewServer(engine.NewMatchingEngine(), api.WithMaxDepth(2, api.DepthClamp))
+    seed(srv)
+    for _, path := range []string{
+        "/api/v1/orderbook/AAPL?depth=10",
+        "/api/v1/orderbook?symbol=AAPL",
+        "/api/v1/orderbook?symbol=AAPL&depth=0",
+        "/api/v1/orderbook?symbols=AAPL,MSFT&depth=10",
+    } {
+        if code, n := bids(srv, path); code != http.StatusOK || n != 2 {
+            t.Fatalf("%s: expected 200 with 2 levels, got %d with %d", path, code, n)
+        }
+    }
+    if code, n := bids(srv, "/api/v1/orderbook/AAPL?depth=1"); code != http.StatusOK || n != 1 {
+        t.Fatalf("expected depth under the cap to be honoured, got %d with %d", code, n)
+    }
+
+    // Reject: too deep is a 400; unlimited still means the cap
+    srv = api.NewServer(engine.NewMatchingEngine(), api.WithMaxDepth(2, api.DepthReject))
+    seed(srv)
+    if code, _ := bids(srv, "/api/v1/orderbook/AAPL?depth=10"); code != http.StatusBadRequest {
+        t.Fatalf("expected 400, got %d", code)
+    }
+    if code, _ := bids(srv, "/api/v1/orderbook?symbols=AAPL&depth=3"); code != http.StatusBadRequest {
+        t.Fatalf("expected 400 for several symbols, got %d", code)
+    }
+    if code, n := bids(srv, "/api/v1/orderbook/AAPL"); code != http.StatusOK || n != 2 {
+        t.Fatalf("expected 200 with 2 levels, got %d with %d", code, n)
+    }
+
+    // The default cap leaves ordinary books whole
+    srv = newTestServer()
+    seed(srv)
+    if code, n := bids(srv, "/api/v1/orderbook/AAPL?depth=1000"); code != http.StatusOK || n != 3 {
+        t.Fatalf("expected the whole book, got %d with %d", code, n)
+    }
+    if _, err := api.ParseDepthPolicy("CLAMP"); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := api.ParseDepthPolicy("truncate"); err == nil {
+        t.Fatal("expected an unknown policy to fail")
+    }
+}
+
+func TestGetOrderBook_Diff(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":100}`), http.StatusCreated)
+
+    type diffResponse struct {
+        Type     string                   `json:"type"`
+        Sequence uint64                   `json:"sequence"`
+        Bids     []map[string]interface{} `json:"bids"`
+        Asks     []map[string]interface{} `json:"asks"`
+    }
+    get := func(since string) diffResponse {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orderbook/diff?symbol=AAPL&since_seq="+since, nil))
+        if rr.Code != http.StatusOK {
+            t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+        }
+        var got diffResponse
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return got
+    }
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orderbook/AAPL", nil))
+    if !strings.Contains(rr.Body.String(), `"sequence":2`) {
+        t.Fatalf("expected the book sequence in the snapshot, got %s", rr.Body.String())
+    }
+    base := get("2")
+    if base.Type != "diff" || base.Sequence != 2 || len(base.Bids) != 0 {
+        t.Fatalf("expected an empty diff at sequence 2, got %+v", base)
+    }
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":25}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":10}`), http.StatusCreated)
+
+    got := get("2")
+    if got.Type != "diff" || got.Sequence != 4 {
+        t.Fatalf("expected a diff to sequence 4, got %+v", got)
+    }
+    if len(got.Bids) != 1 || got.Bids[0]["price"] != float64(10000) || got.Bids[0]["quantity"] != float64(125) {
+        t.Fatalf("expected only the 10000 bid level, got %v", got.Bids)
+    }
+    if len(got.Asks) != 1 || got.Asks[0]["price"] != float64(10100) {
+        t.Fatalf("expected only the new ask level, got %v", got.Asks)
+    }
+
+    // A sequence the book has not reached falls back to a snapshot
+    got = get("99")
+    if got.Type != "snapshot" || got.Sequence != 4 || len(got.Bids) != 2 || len(got.Asks) != 1 {
+        t.Fatalf("expected a full snapshot, got %+v", got)
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orderbook/diff?symbol=AAPL", nil))
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 without since_seq, got %d", rr.Code)
+    }
+}
+
+func TestListOrders(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"s1","symbol":"AAPL","account_id":"alice","side":"SELL","type":"LIMIT","price":10000,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"s2","symbol":"AAPL","account_id":"alice","side":"SELL","type":"LIMIT","price":10100,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"s3","symbol":"AAPL","account_id":"alice","side":"SELL","type":"LIMIT","price":10200,"quantity":100}`), http.StatusCreated)
+    // Fills s1 completely
+    doPost(t, srv, []byte(`{"symbol":"AAPL","account_id":"bob","side":"BUY","type":"LIMIT","price":10000,"quantity":100}`), http.StatusOK)
+
+    list := func(query string) (int, map[string]interface{}) {
+        req := httptest.NewRequest(http.MethodGet, "/api/v1/orders"+query, nil)
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return rr.Code, got
+    }
+
+    code, got := list("?account=alice&symbol=AAPL")
+    orders, _ := got["orders"].([]interface{})
+    if code != http.StatusOK || got["total"] != float64(2) || len(orders) != 2 {
+        t.Fatalf("expected 2 live orders, got %d %v", code, got)
+    }
+    if orders[0].(map[string]interface{})["order_id"] != "s2" {
+        t.Fatalf("expected s2 first, got %v", orders[0])
+    }
+
+    _, got = list("?account=alice&limit=1&offset=1")
+    orders, _ = got["orders"].([]interface{})
+    if len(orders) != 1 || orders[0].(map[string]interface{})["order_id"] != "s3" {
+        t.Fatalf("expected second page to hold s3, got %v", got)
+    }
+    _, got = list("?account=alice&offset=5")
+    if orders, _ = got["orders"].([]interface{}); len(orders) != 0 {
+        t.Fatalf("expected an empty page past the end, got %v", got)
+    }
+    if code, _ := list(""); code != http.StatusBadRequest {
+        t.Fatalf("expected 400 without account, got %d", code)
+    }
+}
+
+func TestCreateOrder_DayTimeInForce(t *testing.T) {
+    srv := newTestServer()
+    // No session close configured for AAPL
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"DAY"}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"GTD"}`), http.StatusBadRequest)
+
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", SessionClose: "23:59"}); err != nil {
+        t.Fatal(err)
+    }
+    eng.SetClock(func() time.Time { return time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC) })
+    srv = api.NewServer(eng)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"day"}`), http.StatusCreated)
+}
+
+func TestErrorCodes_SurfaceThroughHTTP(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "BAND", PriceBandBps: 100, ReferencePrice: 10000}); err != nil {
+        t.Fatal(err)
+    }
+    srv := api.NewServer(eng)
+    doPost(t, srv, []byte(`{"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"f1","symbol":"FILL","side":"SELL","type":"LIMIT","price":10000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"FILL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusOK)
+    if err := eng.Halt("HALT", engine.HaltReject); err != nil {
+        t.Fatal(err)
+    }
+
+    cases := []struct {
+        name   string
+        method string
+        path   string
+        body   string
+        status int
+        code   engine.ReasonCode
+    }{
+        {"invalid", http.MethodPost, "/api/v1/orders", `{"symbol":"AAPL","side":"BUY","type":"LIMIT","quantity":10}`, http.StatusBadRequest, engine.CodeInvalidOrder},
+        {"liquidity", http.MethodPost, "/api/v1/orders", `{"symbol":"AAPL","side":"BUY","type":"MARKET","quantity":50}`, http.StatusBadRequest, engine.CodeInsufficientLiquidity},
+        {"post only", http.MethodPost, "/api/v1/orders", `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":5,"post_only":true}`, http.StatusBadRequest, engine.CodePostOnlyCross},
+        {"band", http.MethodPost, "/api/v1/orders", `{"symbol":"BAND","side":"BUY","type":"LIMIT","price":12000,"quantity":5}`, http.StatusBadRequest, engine.CodeOutsideBand},
+        {"halted", http.MethodPost, "/api/v1/orders", `{"symbol":"HALT","side":"BUY","type":"LIMIT","price":10000,"quantity":5}`, http.StatusConflict, engine.CodeSymbolHalted},
+        {"duplicate", http.MethodPost, "/api/v1/orders", `{"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":10}`, http.StatusConflict, engine.CodeDuplicateOrderID},
+        {"not found", http.MethodDelete, "/api/v1/orders/nope", ``, http.StatusNotFound, engine.CodeOrderNotFound},
+        {"not open", http.MethodDelete, "/api/v1/orders/f1", ``, http.StatusBadRequest, engine.CodeOrderNotOpen},
+        {"state", http.MethodPost, "/admin/resume?symbol=AAPL", ``, http.StatusConflict, engine.CodeInvalidState},
+    }
+    for _, tc := range cases {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        if rr.Code != tc.status || got["code"] != string(tc.code) || got["error"] == "" {
+            t.Fatalf("%s: expected %d %s, got %d body=%s", tc.name, tc.status, tc.code, rr.Code, rr.Body.String())
+        }
+    }
+}
+
+func TestRateLimit_BurstThenRecover(t *testing.T) {
+    srv := api.NewServer(engine.NewMatchingEngine(), api.WithRateLimit(
+        api.RateLimit{Rate: 10, Burst: 3},
+        api.RateLimit{Rate: 100, Burst: 10},
+    ))
+    submit := func(account string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":1}`))
+        req.Header.Set(api.AccountIDHeader, account)
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        return rr
+    }
+
+    for i := 0; i < 3; i++ {
+        if rr := submit("alice"); rr.Code != http.StatusCreated {
+            t.Fatalf("request %d within burst: expected 201, got %d", i, rr.Code)
+        }
+    }
+    rr := submit("alice")
+    if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "1" || !strings.Contains(rr.Body.String(), "RATE_LIMITED") {
+        t.Fatalf("expected 429 with Retry-After, got %d %v body=%s", rr.Code, rr.Header(), rr.Body.String())
+    }
+    // Other accounts have their own bucket, and reads their own limit
+    if rr := submit("bob"); rr.Code != http.StatusCreated {
+        t.Fatalf("expected another account to be unaffected, got %d", rr.Code)
+    }
+    read := httptest.NewRequest(http.MethodGet, "/api/v1/orderbook?symbol=AAPL", nil)
+    read.Header.Set(api.AccountIDHeader, "alice")
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, read)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected reads to use the separate limit, got %d", rr.Code)
+    }
+
+    time.Sleep(150 * time.Millisecond) // Refills one token at 10/s
+    if rr := submit("alice"); rr.Code != http.StatusCreated {
+        t.Fatalf("expected recovery after the window, got %d", rr.Code)
+    }
+}
+
+func TestStats_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":30}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusOK)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d", rr.Code)
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["symbols"] != float64(1) || got["resting_orders"] != float64(1) || got["trades_executed"] != float64(1) || got["volume"] != float64(10) {
+        t.Fatalf("unexpected stats %v", got)
+    }
+    if _, ok := got["uptime_seconds"]; !ok {
+        t.Fatalf("missing uptime_seconds: %v", got)
+    }
+}
+
+func TestGetOrderBook_Group(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10005,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10001,"quantity":10}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orderbook?symbol=AAPL&group=10", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if !strings.Contains(rr.Body.String(), `"bids":[{"price":10000,"quantity":20}]`) {
+        t.Fatalf("expected one 10000 bucket, got %s", rr.Body.String())
+    }
+
+    for _, url := range []string{"/api/v1/orderbook?symbol=AAPL&group=0", "/api/v1/orderbook?symbols=AAPL,MSFT&group=10"} {
+        rr = httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+        if rr.Code != http.StatusBadRequest {
+            t.Fatalf("%s: expected 400, got %d", url, rr.Code)
+        }
+    }
+}
+
+func TestListSymbols(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ListSymbol(engine.SymbolConfig{Symbol: "AAPL", TickSize: 5}); err != nil {
+        t.Fatalf("list: %v", err)
+    }
+    srv := api.NewServer(eng)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/symbols", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var got struct {
+        Symbols []engine.SymbolConfig `json:"symbols"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if rr.Code != http.StatusOK || len(got.Symbols) != 1 || got.Symbols[0].Symbol != "AAPL" || got.Symbols[0].TickSize != 5 {
+        t.Fatalf("unexpected symbols: %d %s", rr.Code, rr.Body.String())
+    }
+}
+
+func TestSymbolSession_Endpoint(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", SessionOpen: "09:30", SessionClose: "16:00"}); err != nil {
+        t.Fatalf("configure: %v", err)
+    }
+    eng.SetClock(func() time.Time { return time.Date(2026, 3, 2, 20, 0, 0, 0, time.UTC) })
+    srv := api.NewServer(eng)
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/symbols/AAPL/session", nil))
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if rr.Code != http.StatusOK || got["state"] != "CLOSED" || got["open"] != "09:30" || got["phase"] != "CONTINUOUS" {
+        t.Fatalf("unexpected session: %d %s", rr.Code, rr.Body.String())
+    }
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`))
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), `"code":"MARKET_CLOSED"`) {
+        t.Fatalf("expected MARKET_CLOSED, got %s", rr.Body.String())
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/symbols/AAPL/other", nil))
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404, got %d", rr.Code)
+    }
+}
+
+func TestQueuePosition(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"q1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"q2","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":200}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"q3","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":300}`), http.StatusCreated)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/q2/queue", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if got["quantity_ahead"].(float64) != 100 || got["level_quantity"].(float64) != 600 {
+        t.Fatalf("unexpected queue position %v", got)
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/orders/nope/queue", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 for unknown order, got %d", rr.Code)
+    }
+}
+
+func TestCreateOrder_GTDDate(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    if err := eng.ConfigureSymbol(engine.SymbolConfig{Symbol: "AAPL", SessionClose: "16:00", SessionTimeZone: "America/New_York"}); err != nil {
+        t.Fatal(err)
+    }
+    eng.SetClock(func() time.Time { return time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC) })
+    srv := api.NewServer(eng)
+
+    doPost(t, srv, []byte(`{"id":"g1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"GTD:2026-03-04"}`), http.StatusCreated)
+    order, err := eng.GetOrderStatus("g1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    // 16:00 New York is 21:00 UTC in early March
+    if want := time.Date(2026, 3, 4, 21, 0, 0, 0, time.UTC).UnixMilli(); order.ExpiresAt != want {
+        t.Fatalf("expected expires_at %d, got %d", want, order.ExpiresAt)
+    }
+
+    for _, body := range []string{
+        `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"GTD:2026-03-01"}`,
+        `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"GTD:2026-3-4"}`,
+        `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"GTD"}`,
+        `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"GTD:2026-03-04","expires_at":4102444800000}`,
+    } {
+        doPost(t, srv, []byte(body), http.StatusBadRequest)
+    }
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10,"time_in_force":"GTD","expires_at":4102444800000}`), http.StatusCreated)
+}
+
+func TestOrderTrades(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"s2","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15100,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"b1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15100,"quantity":150}`), http.StatusOK)
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/b1/trades", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        OrderID string                   `json:"order_id"`
+        Trades  []map[string]interface{} `json:"trades"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+        t.Fatal(err)
+    }
+    if got.OrderID != "b1" || len(got.Trades) != 2 {
+        t.Fatalf("expected two trades for b1, got %s", rr.Body.String())
+    }
+    if got.Trades[0]["resting_order_id"] != "s1" || got.Trades[1]["resting_order_id"] != "s2" {
+        t.Fatalf("unexpected trades %v", got.Trades)
+    }
+
+    // The partially filled resting order
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orders/s2/trades", nil))
+    if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"quantity":50`) {
+        t.Fatalf("expected s2's partial fill, got %d body=%s", rr.Code, rr.Body.String())
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orders/nope/trades", nil))
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404, got %d", rr.Code)
+    }
+}
+
+func TestOrderHistory(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"h1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"h2","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15050,"quantity":40}`), http.StatusOK)
+    req := httptest.NewRequest(http.MethodDelete, "/api/v1/orders/h1", nil)
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("cancel: expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+
+    // The order has left the book; its history remains
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/orders/h1/history", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK {
+        t.Fatalf("expected 200, got %d body=%s", rr.Code, rr.Body.String())
+    }
+    var got struct {
+        History []struct {
+            Seq   uint64                 `json:"seq"`
+            Type  string                 `json:"type"`
+            Order map[string]interface{} `json:"order"`
+            Trade map[string]interface{} `json:"trade"`
+        } `json:"history"`
+        Order map[string]interface{} `json:"order"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+        t.Fatal(err)
+    }
+    if len(got.History) != 3 {
+        t.Fatalf("expected 3 history entries, got %d: %s", len(got.History), rr.Body.String())
+    }
+    for i, want := range []string{"ACCEPTED", "TRADE", "CANCELLED"} {
+        if got.History[i].Type != want {
+            t.Fatalf("entry %d: expected %s, got %s", i, want, got.History[i].Type)
+        }
+        if i > 0 && got.History[i].Seq <= got.History[i-1].Seq {
+            t.Fatalf("entries out of sequence: %+v", got.History)
+        }
+    }
+    trade := got.History[1].Trade
+    if trade["trade_id"] == "" || trade["resting_order_id"] != "h1" || trade["aggressor_order_id"] != "h2" || trade["quantity"].(float64) != 40 {
+        t.Fatalf("unexpected trade entry %v", trade)
+    }
+    if c := got.History[2].Order; c["status"] != "CANCELLED" || c["filled_quantity"].(float64) != 40 {
+        t.Fatalf("unexpected cancel entry %v", c)
+    }
+    if got.Order["status"] != "CANCELLED" {
+        t.Fatalf("unexpected final state %v", got.Order)
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/orders/nope/history", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusNotFound {
+        t.Fatalf("expected 404 for unknown order, got %d", rr.Code)
+    }
+}
+
+func TestLevelOrders_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"s2","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":300,"display_quantity":50}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"s3","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":20,"hidden":true}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"s4","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":40}`), http.StatusCreated)
+
+    get := func(path string) (int, []map[string]interface{}) {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+        var got struct {
+            Orders []map[string]interface{} `json:"orders"`
+        }
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return rr.Code, got.Orders
+    }
+
+    code, orders := get("/api/v1/orderbook/AAPL/level?side=SELL&price=15050")
+    if code != http.StatusOK || len(orders) != 3 {
+        t.Fatalf("expected 200 with 3 orders, got %d %v", code, orders)
+    }
+    for i, want := range []string{"s1", "s2", "s4"} {
+        if orders[i]["order_id"] != want {
+            t.Fatalf("entry %d: expected %s, got %v", i, want, orders)
+        }
+        if _, ok := orders[i]["timestamp"]; !ok {
+            t.Fatalf("expected a timestamp, got %v", orders[i])
+        }
+    }
+    if orders[1]["quantity"].(float64) != 50 {
+        t.Fatalf("expected the iceberg's slice only, got %v", orders[1])
+    }
+
+    _, orders = get("/api/v1/orderbook/AAPL/level?side=SELL&price=15050&include_hidden=true")
+    if len(orders) != 4 || orders[3]["order_id"] != "s3" || orders[1]["quantity"].(float64) != 300 {
+        t.Fatalf("expected hidden orders and full quantities, got %v", orders)
+    }
+
+    code, orders = get("/api/v1/orderbook/AAPL/level?side=BUY&price=15050")
+    if code != http.StatusOK || orders == nil || len(orders) != 0 {
+        t.Fatalf("expected an empty list for a missing level, got %d %v", code, orders)
+    }
+    if code, _ := get("/api/v1/orderbook/AAPL/level?side=SELL"); code != http.StatusBadRequest {
+        t.Fatalf("expected 400 without a price, got %d", code)
+    }
+}
+
+func TestClientMetadata_RoundTrips(t *testing.T) {
+    srv := newTestServer()
+
+    doPost(t, srv, []byte(`{"id":"m1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100,"client_metadata":{"strategy":"twap-3","parent":"p-17"}}`), http.StatusCreated)
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"id":"m2","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15050,"quantity":40,"client_metadata":{"strategy":"sweep"}}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var created map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &created)
+    if rr.Code != http.StatusOK || created["client_metadata"].(map[string]interface{})["strategy"] != "sweep" {
+        t.Fatalf("expected the fill response to echo metadata, got %d %s", rr.Code, rr.Body.String())
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/orders/m1", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    metadata, _ := got["client_metadata"].(map[string]interface{})
+    if rr.Code != http.StatusOK || metadata["strategy"] != "twap-3" || metadata["parent"] != "p-17" || len(metadata) != 2 {
+        t.Fatalf("expected metadata on GET, got %d %s", rr.Code, rr.Body.String())
+    }
+
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/orders/m1/history", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if !strings.Contains(rr.Body.String(), `"client_metadata":{"parent":"p-17","strategy":"twap-3"}`) {
+        t.Fatalf("expected metadata in history, got %s", rr.Body.String())
+    }
+
+    // Oversized metadata is refused
+    big := `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15000,"quantity":1,"client_metadata":{"note":"` + strings.Repeat("x", 2000) + `"}}`
+    doPost(t, srv, []byte(big), http.StatusBadRequest)
+}
+
+func TestCreateOrder_Pegged(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"PEGGED","peg_reference":"bid","quantity":10}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"id":"b1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15000,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"PEGGED","quantity":10}`), http.StatusBadRequest)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"PEGGED","peg_reference":"LAST","quantity":10}`), http.StatusBadRequest)
+
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(`{"id":"p1","symbol":"AAPL","side":"BUY","type":"PEGGED","peg_reference":"bid","peg_offset":-10,"quantity":10}`)))
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var got map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if rr.Code != http.StatusCreated || got["price"].(float64) != 14990 {
+        t.Fatalf("expected 201 at pegged price 14990, got %d %v", rr.Code, got)
+    }
+
+    // The bid moves up; the pegged order follows
+    doPost(t, srv, []byte(`{"id":"b2","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15020,"quantity":100}`), http.StatusCreated)
+    req = httptest.NewRequest(http.MethodGet, "/api/v1/orders/p1", nil)
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var order map[string]interface{}
+    _ = json.Unmarshal(rr.Body.Bytes(), &order)
+    if order["price"].(float64) != 15010 || order["peg_reference"] != "BID" || order["peg_offset"].(float64) != -10 {
+        t.Fatalf("unexpected pegged order %v", order)
+    }
+}
+
+func TestHealth_ReflectsEngineReadiness(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    srv := api.NewServer(eng)
+    probe := func(path string) (int, map[string]interface{}) {
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+        var got map[string]interface{}
+        _ = json.Unmarshal(rr.Body.Bytes(), &got)
+        return rr.Code, got
+    }
+
+    for _, path := range []string{"/api/v1/health", "/api/v1/ready"} {
+        if code, got := probe(path); code != http.StatusOK || got["status"] != "healthy" {
+            t.Fatalf("%s: expected 200 healthy, got %d %v", path, code, got)
+        }
+    }
+    if err := eng.Halt("AAPL", engine.HaltReject); err != nil {
+        t.Fatal(err)
+    }
+    if code, got := probe("/api/v1/ready"); code != http.StatusOK || got["status"] != "degraded" {
+        t.Fatalf("expected 200 degraded with a halted symbol, got %d %v", code, got)
+    }
+
+    if err := srv.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    for _, path := range []string{"/api/v1/health", "/api/v1/ready"} {
+        if code, got := probe(path); code != http.StatusServiceUnavailable || got["status"] != "unavailable" || got["shutting_down"] != true {
+            t.Fatalf("%s: expected 503 unavailable during shutdown, got %d %v", path, code, got)
+        }
+    }
+    if code, _ := probe("/api/v1/live"); code != http.StatusOK {
+        t.Fatalf("expected liveness to pass during shutdown, got %d", code)
+    }
+}
+
+func TestReplay_CSV(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    srv := api.NewServer(eng)
+    // Out of file order: the sells at 1000 and 1001 rest before the buy at
+    // 1002 sweeps them, and s3 is cancelled before anything reaches it
+    csv := "timestamp,action,id,symbol,side,type,price,quantity\n" +
+        "1002,NEW,b1,AAPL,BUY,LIMIT,10100,150\n" +
+        "1000,NEW,s1,AAPL,SELL,LIMIT,10000,100\n" +
+        "1001,,s2,AAPL,SELL,LIMIT,10100,100\n" +
+        "1001,NEW,s3,AAPL,SELL,LIMIT,10050,500\n" +
+        "1001,CANCEL,s3,,,,,\n" +
+        "1003,NEW,bad,AAPL,BUY,LIMIT,,10\n"
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/replay", strings.NewReader(csv))
+    req.Header.Set("Content-Type", "text/csv")
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    var got struct {
+        Events   int `json:"events"`
+        Accepted int `json:"accepted"`
+        Rejected int `json:"rejected"`
+        Trades   int `json:"trades"`
+        Errors   []struct {
+            Line int    `json:"line"`
+            ID   string `json:"id"`
+        } `json:"errors"`
+        Books map[string]struct {
+            Asks []map[string]interface{} `json:"asks"`
+        } `json:"books"`
+    }
+    _ = json.Unmarshal(rr.Body.Bytes(), &got)
+    if rr.Code != http.StatusOK || got.Events != 6 || got.Accepted != 5 || got.Rejected != 1 || got.Trades != 2 {
+        t.Fatalf("unexpected replay result: %d %s", rr.Code, rr.Body.String())
+    }
+    if len(got.Errors) != 1 || got.Errors[0].ID != "bad" || got.Errors[0].Line != 7 {
+        t.Fatalf("expected the unpriced limit order on line 7 to be reported: %s", rr.Body.String())
+    }
+    asks := got.Books["AAPL"].Asks
+    if len(asks) != 1 || asks[0]["price"] != float64(10100) || asks[0]["quantity"] != float64(50) {
+        t.Fatalf("expected 50 left at 10100: %s", rr.Body.String())
+    }
+
+    // The live engine is untouched
+    if bids, asks := eng.GetOrderBookSnapshot("AAPL", 0); len(bids) != 0 || len(asks) != 0 {
+        t.Fatalf("replay leaked into the live book: %v %v", bids, asks)
+    }
+}
+
+func TestReplay_NDJSONPreservesTimestamps(t *testing.T) {
+    srv := newTestServer()
+    ndjson := `{"timestamp":1700000000000,"id":"s1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":100}
+{"timestamp":1700000000500,"id":"b1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":100}
+`
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/replay?preserve_timestamps=true", strings.NewReader(ndjson))
+    req.Header.Set("Content-Type", "application/x-ndjson")
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"accepted":2`) || !strings.Contains(rr.Body.String(), `"trades":0`) {
+        t.Fatalf("unexpected replay result: %d %s", rr.Code, rr.Body.String())
+    }
+
+    req = httptest.NewRequest(http.MethodPost, "/api/v1/replay", strings.NewReader("id,symbol\nx,AAPL\n"))
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusBadRequest {
+        t.Fatalf("expected 400 without a timestamp column, got %d", rr.Code)
+    }
+}
+
+func TestBustTrade_Endpoint(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"ask","symbol":"AAPL","side":"SELL","type":"LIMIT","price":10000,"quantity":20}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"bid","symbol":"AAPL","side":"BUY","type":"LIMIT","price":10000,"quantity":10}`), http.StatusOK)
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/trades?symbol=AAPL", nil))
+    var listed struct {
+        Trades []struct {
+            TradeID string `json:"trade_id"`
+        } `json:"trades"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil || len(listed.Trades) != 1 {
+        t.Fatalf("expected one trade, got %s", rr.Body.String())
+    }
+    path := "/admin/trades/" + listed.Trades[0].TradeID + "/bust"
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+    if rr.Code != http.StatusOK {
+        t.Fatalf("bust: expected 200 got %d body=%s", rr.Code, rr.Body.String())
+    }
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orders/ask", nil))
+    var ask struct {
+        Status         string      `json:"status"`
+        FilledQuantity json.Number `json:"filled_quantity"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &ask); err != nil || ask.Status != "ACCEPTED" || ask.FilledQuantity != "0" {
+        t.Fatalf("expected the ask restored to ACCEPTED with nothing filled, got %s", rr.Body.String())
+    }
+
+    for _, tc := range []struct {
+        method, path string
+        status       int
+    }{
+        {http.MethodPost, path, http.StatusConflict},
+        {http.MethodPost, "/admin/trades/missing/bust", http.StatusNotFound},
+        {http.MethodGet, path, http.StatusMethodNotAllowed},
+        {http.MethodPost, "/admin/trades/" + listed.Trades[0].TradeID, http.StatusNotFound},
+    } {
+        rr = httptest.NewRecorder()
+        srv.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, nil))
+        if rr.Code != tc.status {
+            t.Errorf("%s %s: expected %d got %d body=%s", tc.method, tc.path, tc.status, rr.Code, rr.Body.String())
+        }
+    }
+}
+
+func TestOrdersByTag_ListAndCancel(t *testing.T) {
+    srv := newTestServer()
+    doPost(t, srv, []byte(`{"id":"a1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":10,"client_metadata":{"strategy":"strategyA"}}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"a2","symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":10,"client_metadata":{"strategy":"strategyA"}}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"b1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":9800,"quantity":10,"client_metadata":{"strategy":"strategyB"}}`), http.StatusCreated)
+
+    var listed struct {
+        Orders []struct {
+            OrderID string `json:"order_id"`
+        } `json:"orders"`
+        Total int `json:"total"`
+    }
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orders?tag=strategyA", nil))
+    if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil || rr.Code != http.StatusOK || listed.Total != 2 {
+        t.Fatalf("expected 2 orders tagged strategyA, got %d %s", rr.Code, rr.Body.String())
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/orders?tag=strategy:strategyA", nil))
+    var cancelled struct {
+        IDs   []string `json:"cancelled_order_ids"`
+        Count int      `json:"count"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &cancelled); err != nil || rr.Code != http.StatusOK || cancelled.Count != 2 {
+        t.Fatalf("expected 2 cancelled, got %d %s", rr.Code, rr.Body.String())
+    }
+
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orders?tag=strategy:strategyB", nil))
+    listed.Orders, listed.Total = nil, 0
+    if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil || listed.Total != 1 || listed.Orders[0].OrderID != "b1" {
+        t.Fatalf("expected only b1 left, got %s", rr.Body.String())
+    }
+
+    for _, path := range []string{"/api/v1/orders?tag=", "/api/v1/orders?tag=:x", "/api/v1/orders?tag=a:b&symbol=AAPL"} {
+        for _, method := range []string{http.MethodGet, http.MethodDelete} {
+            rr = httptest.NewRecorder()
+            srv.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+            if rr.Code != http.StatusBadRequest {
+                t.Errorf("%s %s: expected 400 got %d", method, path, rr.Code)
+            }
+        }
+    }
+}
+
+func TestOrderBook_CompactBinaryMatchesJSON(t *testing.T) {
+    srv := newTestServer()
+    for _, body := range []string{
+        `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":10}`,
+        `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9900,"quantity":5}`,
+        `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":9800,"quantity":7}`,
+        `{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":10100,"quantity":3}`,
+    } {
+        doPost(t, srv, []byte(body), http.StatusCreated)
+    }
+
+    rr := httptest.NewRecorder()
+    srv.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/orderbook/AAPL", nil))
+    if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+        t.Fatalf("JSON should stay the default, got %q", ct)
+    }
+    var book struct {
+        Bids     []engine.AggregatedPriceLevel `json:"bids"`
+        Asks     []engine.AggregatedPriceLevel `json:"asks"`
+        Sequence uint64                        `json:"sequence"`
+        Checksum uint32                        `json:"checksum"`
+    }
+    if err := json.Unmarshal(rr.Body.Bytes(), &book); err != nil {
+        t.Fatalf("decoding JSON book: %v", err)
+    }
+
+    req := httptest.NewRequest(http.MethodGet, "/api/v1/orderbook/AAPL", nil)
+    req.Header.Set("Accept", "application/octet-stream")
+    rr = httptest.NewRecorder()
+    srv.ServeHTTP(rr, req)
+    if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/octet-stream" {
+        t.Fatalf("expected a binary book, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+    }
+    compact, err := engine.DecodeCompactBook(rr.Body.Bytes())
+    if err != nil {
+        t.Fatalf("decoding binary book: %v", err)
+    }
+    if compact.Symbol != "AAPL" || compact.Sequence != book.Sequence || compact.Checksum != book.Checksum {
+        t.Errorf("header mismatch: %+v vs JSON sequence %d checksum %d", compact, book.Sequence, book.Checksum)
+    }
+    if !reflect.DeepEqual(compact.Bids, book.Bids) || !reflect.DeepEqual(compact.Asks, book.Asks) {
+        t.Errorf("levels differ: binary %v/%v, JSON %v/%v", compact.Bids, compact.Asks, book.Bids, book.Asks)
+    }
+    if want := 4 + 3 + 8 + 8 + 4 + 2 + len("AAPL") + 4 + 2*16 + 4 + 16; rr.Body.Len() != want {
+        t.Errorf("expected %d bytes, got %d", want, rr.Body.Len())
+    }
+}
@@ -0,0 +1,253 @@
+package api_test
+
+import (
+    "bytes"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gorilla/websocket"
+    api "order-matching-engine/src/api"
+    "order-matching-engine/src/engine"
+)
+
+func TestOrderBookWS_SnapshotThenUpdate(t *testing.T) {
+    srv := newTestServer()
+    ts := httptest.NewServer(srv)
+    defer ts.Close()
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+
+    url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/orderbook?symbol=AAPL&depth=10"
+    conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+    if err != nil {
+        t.Fatalf("dial: %v", err)
+    }
+    defer conn.Close()
+    _ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+    var snap map[string]interface{}
+    if err := conn.ReadJSON(&snap); err != nil {
+        t.Fatalf("read snapshot: %v", err)
+    }
+    if snap["type"] != "snapshot" || len(snap["asks"].([]interface{})) != 1 {
+        t.Fatalf("unexpected snapshot %v", snap)
+    }
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15040,"quantity":50}`), http.StatusCreated)
+
+    var update map[string]interface{}
+    if err := conn.ReadJSON(&update); err != nil {
+        t.Fatalf("read update: %v", err)
+    }
+    if update["type"] != "update" || update["sequence"].(float64) != snap["sequence"].(float64)+1 {
+        t.Fatalf("unexpected update %v after snapshot %v", update, snap)
+    }
+    bids := update["bids"].([]interface{})
+    if len(bids) != 1 || bids[0].(map[string]interface{})["price"].(float64) != 15040 {
+        t.Fatalf("expected bid level 15040, got %v", bids)
+    }
+}
+
+func TestTradesWS_PrintAfterCross(t *testing.T) {
+    srv := newTestServer()
+    ts := httptest.NewServer(srv)
+    defer ts.Close()
+
+    url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/trades?symbol=AAPL"
+    conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+    if err != nil {
+        t.Fatalf("dial: %v", err)
+    }
+    defer conn.Close()
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15050,"quantity":40}`), http.StatusOK)
+
+    _ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+    var got map[string]interface{}
+    if err := conn.ReadJSON(&got); err != nil {
+        t.Fatalf("read trade: %v", err)
+    }
+    if got["price"].(float64) != 15050 || got["quantity"].(float64) != 40 {
+        t.Fatalf("unexpected trade %v", got)
+    }
+    if got["aggressor_side"] != "BUY" || got["trade_id"] == "" {
+        t.Fatalf("expected aggressor side and trade id, got %v", got)
+    }
+}
+
+func TestTradesWS_FillGranularity(t *testing.T) {
+    srv := newTestServer()
+    ts := httptest.NewServer(srv)
+    defer ts.Close()
+
+    dial := func(granularity string) *websocket.Conn {
+        url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/trades?symbol=AAPL&fill_granularity=" + granularity
+        conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+        if err != nil {
+            t.Fatalf("dial %s: %v", granularity, err)
+        }
+        return conn
+    }
+    perTrade := dial("per_trade")
+    defer perTrade.Close()
+    perOrder := dial("per_order")
+    defer perOrder.Close()
+
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15050,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15060,"quantity":100}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15060,"quantity":200}`), http.StatusOK)
+    // A second event marks the end of the first in both streams
+    doPost(t, srv, []byte(`{"symbol":"AAPL","side":"SELL","type":"LIMIT","price":15000,"quantity":10}`), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"last","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15000,"quantity":10}`), http.StatusOK)
+
+    read := func(conn *websocket.Conn) map[string]interface{} {
+        _ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+        var got map[string]interface{}
+        if err := conn.ReadJSON(&got); err != nil {
+            t.Fatalf("read: %v", err)
+        }
+        return got
+    }
+    for i, price := range []float64{15050, 15060, 15000} {
+        if got := read(perTrade); got["price"] != price {
+            t.Fatalf("per_trade message %d: expected price %v, got %v", i, price, got)
+        }
+    }
+    got := read(perOrder)
+    if got["filled_quantity"] != float64(200) || got["average_price"] != float64(15055) || len(got["trade_ids"].([]interface{})) != 2 {
+        t.Fatalf("expected one execution for the two-level fill, got %v", got)
+    }
+    if got := read(perOrder); got["order_id"] != "last" {
+        t.Fatalf("expected the next order's execution, got %v", got)
+    }
+
+    resp, err := http.Get(ts.URL + "/ws/trades?symbol=AAPL&fill_granularity=sometimes")
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusBadRequest {
+        t.Fatalf("expected 400 for an unknown granularity, got %d", resp.StatusCode)
+    }
+}
+
+func TestSessionWS_CancelOnDisconnect(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    srv := api.NewServer(eng)
+    ts := httptest.NewServer(srv)
+    defer ts.Close()
+
+    dial := func(query string) (*websocket.Conn, string) {
+        t.Helper()
+        conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/session"+query, nil)
+        if err != nil {
+            t.Fatalf("dial: %v", err)
+        }
+        _ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+        var hello map[string]interface{}
+        if err := conn.ReadJSON(&hello); err != nil {
+            t.Fatalf("read session: %v", err)
+        }
+        return conn, hello["session_id"].(string)
+    }
+    post := func(session, body string, expStatus int) {
+        t.Helper()
+        req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewReader([]byte(body)))
+        req.Header.Set(api.SessionHeader, session)
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        if rr.Code != expStatus {
+            t.Fatalf("POST expected %d got %d body=%s", expStatus, rr.Code, rr.Body.String())
+        }
+    }
+
+    conn, session := dial("?cancel_on_disconnect=true")
+    keep, keepSession := dial("")
+    defer keep.Close()
+
+    post(session, `{"id":"b1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15000,"quantity":100}`, http.StatusCreated)
+    post(session, `{"id":"a1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15100,"quantity":100}`, http.StatusCreated)
+    post(keepSession, `{"id":"k1","symbol":"AAPL","side":"BUY","type":"LIMIT","price":14900,"quantity":100}`, http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"x1","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15200,"quantity":100}`), http.StatusCreated)
+    post("no-such-session", `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15000,"quantity":100}`, http.StatusBadRequest)
+
+    conn.Close()
+    deadline := time.Now().Add(2 * time.Second)
+    for {
+        // Only the orders outside the closed session are left: k1 and x1
+        snap := eng.GetBookSnapshot("AAPL", 0)
+        if snap.TotalBidOrders == 1 && snap.TotalAskOrders == 1 {
+            if snap.Bids[0].Price != 14900 || snap.Asks[0].Price != 15200 {
+                t.Fatalf("expected k1 and x1 to keep resting, got bids %v asks %v", snap.Bids, snap.Asks)
+            }
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatalf("expected b1 and a1 cancelled after disconnect, book has %d bids %d asks", snap.TotalBidOrders, snap.TotalAskOrders)
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    // The closed session no longer accepts orders
+    post(session, `{"symbol":"AAPL","side":"BUY","type":"LIMIT","price":15000,"quantity":100}`, http.StatusBadRequest)
+}
+
+func TestSessionWS_ForeignOrderIDSurvivesDisconnect(t *testing.T) {
+    eng := engine.NewMatchingEngine()
+    srv := api.NewServer(eng)
+    ts := httptest.NewServer(srv)
+    defer ts.Close()
+
+    conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/session?cancel_on_disconnect=true", nil)
+    if err != nil {
+        t.Fatalf("dial: %v", err)
+    }
+    _ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+    var hello map[string]interface{}
+    if err := conn.ReadJSON(&hello); err != nil {
+        t.Fatalf("read session: %v", err)
+    }
+    session := hello["session_id"].(string)
+    post := func(path, body string, expStatus int) {
+        t.Helper()
+        req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+        req.Header.Set(api.SessionHeader, session)
+        rr := httptest.NewRecorder()
+        srv.ServeHTTP(rr, req)
+        if rr.Code != expStatus {
+            t.Fatalf("POST %s expected %d got %d body=%s", path, expStatus, rr.Code, rr.Body.String())
+        }
+    }
+
+    // Another client's orders, outside the session
+    foreign := `{"id":"theirs","symbol":"AAPL","side":"BUY","type":"LIMIT","price":15000,"quantity":100}`
+    doPost(t, srv, []byte(foreign), http.StatusCreated)
+    doPost(t, srv, []byte(`{"id":"theirs-2","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15100,"quantity":100}`), http.StatusCreated)
+
+    // The session reuses their IDs: a conflict, an idempotent retry, a
+    // rejected batch entry and a replace onto a taken ID
+    post("/api/v1/orders", `{"id":"theirs","symbol":"AAPL","side":"BUY","type":"LIMIT","price":14000,"quantity":5}`, http.StatusConflict)
+    post("/api/v1/orders", foreign, http.StatusCreated)
+    post("/api/v1/orders/batch", `[{"id":"theirs-2","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15100,"quantity":100},{"id":"mine","symbol":"AAPL","side":"SELL","type":"LIMIT","price":15200,"quantity":10}]`, http.StatusOK)
+    post("/api/v1/orders/mine/replace", `{"id":"theirs","side":"SELL","type":"LIMIT","price":15300,"quantity":10}`, http.StatusConflict)
+
+    conn.Close()
+    deadline := time.Now().Add(2 * time.Second)
+    for {
+        if mine, _ := eng.GetOrderStatus("mine"); mine.Status == engine.StatusCancelled {
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatal("expected the session's own order cancelled after disconnect")
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    for _, id := range []string{"theirs", "theirs-2"} {
+        if order, _ := eng.GetOrderStatus(id); order.Status != engine.StatusAccepted {
+            t.Fatalf("expected %s to keep resting, got %s", id, order.Status)
+        }
+    }
+}
@@ -0,0 +1,65 @@
+package engine_test
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestImbalanceAlert_LopsidedFillPostsWebhook(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    alerts := make(chan enginepkg.ImbalanceAlert, 10)
+    sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var alert enginepkg.ImbalanceAlert
+        if err := json.NewDecoder(r.Body).Decode(&alert); err == nil && r.Method == http.MethodPost {
+            alerts <- alert
+        }
+    }))
+    defer sink.Close()
+
+    // A balanced book does not alert
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1001))
+    assert.NoError(eng.SetImbalanceAlerts(enginepkg.ImbalanceAlertConfig{WebhookURL: sink.URL, Threshold: 0.8}))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9800, 20, 1002))
+
+    // Taking out the whole ask side leaves only bids
+    _, _ = eng.SubmitOrder(newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 100, 1003))
+
+    select {
+    case alert := <-alerts:
+        assert.Equal("AAPL", alert.Symbol)
+        assert.Equal(1.0, alert.Imbalance)
+        assert.Equal(0.8, alert.Threshold)
+        assert.Equal(int64(120), alert.TotalBidVolume)
+        assert.Equal(int64(0), alert.TotalAskVolume)
+    case <-time.After(2 * time.Second):
+        t.Fatal("no imbalance alert received")
+    }
+
+    // Debounced: the book is still one-sided, but the symbol is cooling down
+    _, _ = eng.SubmitOrder(newTestOrder("b4", "AAPL", enginepkg.Buy, enginepkg.Limit, 9700, 10, 1004))
+    select {
+    case alert := <-alerts:
+        t.Fatalf("unexpected second alert %+v", alert)
+    case <-time.After(100 * time.Millisecond):
+    }
+}
+
+func TestImbalanceAlert_Validation(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    assert.Error(eng.SetImbalanceAlerts(enginepkg.ImbalanceAlertConfig{WebhookURL: "not a url", Threshold: 0.5}))
+    assert.Error(eng.SetImbalanceAlerts(enginepkg.ImbalanceAlertConfig{WebhookURL: "http://example.com/hook", Threshold: 0}))
+    assert.Error(eng.SetImbalanceAlerts(enginepkg.ImbalanceAlertConfig{WebhookURL: "http://example.com/hook", Threshold: 1.5}))
+    assert.NoError(eng.SetImbalanceAlerts(enginepkg.ImbalanceAlertConfig{WebhookURL: "http://example.com/hook", Threshold: 1}))
+    assert.NoError(eng.SetImbalanceAlerts(enginepkg.ImbalanceAlertConfig{}), "an empty URL turns alerts off")
+}
@@ -0,0 +1,108 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// allocate rests 100, 300, 200 and 300 at one price, in that time order,
+// sends a 450 buy at that price under algorithm, and returns its trades.
+func allocate(t *testing.T, algorithm enginepkg.MatchingAlgorithm) []enginepkg.Trade {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "ES", MatchingAlgorithm: algorithm}))
+
+    for i, qty := range []int64{100, 300, 200, 300} {
+        _, err := eng.SubmitOrder(newTestOrder([]string{"s1", "s2", "s3", "s4"}[i], "ES", enginepkg.Sell, enginepkg.Limit, 10000, qty, int64(1000+i)))
+        assert.NoError(err)
+    }
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "ES", enginepkg.Buy, enginepkg.Limit, 10000, 450, 1004))
+    assert.NoError(err)
+    return resp.Trades
+}
+
+func fills(trades []enginepkg.Trade) ([]string, map[string]int64) {
+    order := []string{}
+    filled := map[string]int64{}
+    for _, tr := range trades {
+        order = append(order, tr.RestingOrderID)
+        filled[tr.RestingOrderID] += tr.Quantity
+    }
+    return order, filled
+}
+
+func TestAllocation_FIFO(t *testing.T) {
+    order, filled := fills(allocate(t, enginepkg.FIFO))
+    assert.Equal(t, []string{"s1", "s2", "s3"}, order)
+    assert.Equal(t, map[string]int64{"s1": 100, "s2": 300, "s3": 50}, filled)
+}
+
+func TestAllocation_ProRata(t *testing.T) {
+    // 450 of 900 is half of every order
+    _, filled := fills(allocate(t, enginepkg.ProRata))
+    assert.Equal(t, map[string]int64{"s1": 50, "s2": 150, "s3": 100, "s4": 150}, filled)
+}
+
+func TestAllocation_SizePriority(t *testing.T) {
+    // The older of the two 300s first, then the other; 100 and 200 wait
+    order, filled := fills(allocate(t, enginepkg.SizePriority))
+    assert.Equal(t, []string{"s2", "s4"}, order)
+    assert.Equal(t, map[string]int64{"s2": 300, "s4": 150}, filled)
+}
+
+func TestAllocation_SizePriorityKeepsHiddenAndAONRules(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "ES", MatchingAlgorithm: enginepkg.SizePriority}))
+
+    hidden := newTestOrder("hidden", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 500, 1000)
+    hidden.Hidden = true
+    aon := newTestOrder("aon", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 400, 1001)
+    aon.AllOrNone = true
+    ice := newTestOrder("ice", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 120, 1002)
+    ice.DisplayQuantity = 50
+    for _, o := range []*enginepkg.Order{
+        hidden,
+        aon,
+        ice,
+        newTestOrder("small", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 80, 1003),
+    } {
+        _, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+    }
+
+    // The AON order is passed over, the iceberg competes with its 50 slice
+    // (behind the 80, and alone once that is gone) and the hidden order
+    // trades last despite its size
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "ES", enginepkg.Buy, enginepkg.Limit, 10000, 230, 1004))
+    assert.NoError(err)
+    order, filled := fills(resp.Trades)
+    assert.Equal([]string{"small", "ice", "ice", "ice", "hidden"}, order)
+    assert.Equal(map[string]int64{"small": 80, "ice": 120, "hidden": 30}, filled)
+}
+
+func TestAllocation_SizePrioritySelfMatchStopsAtTheLargest(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "ES", MatchingAlgorithm: enginepkg.SizePriority, SelfMatchPrefixes: []string{"deskA-"}}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("ext-1", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("deskA-1", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 200, 1001))
+
+    // FIFO would fill this from ext-1, but the desk's own larger order comes
+    // first, so it cannot fill
+    fok := newTestOrder("deskA-2", "ES", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1002)
+    fok.TimeInForce = enginepkg.FOK
+    _, err := eng.SubmitOrder(fok)
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+
+    buy := newTestOrder("deskA-3", "ES", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1003)
+    resp, err := eng.SubmitOrder(buy)
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    assert.Equal(enginepkg.ReasonSelfMatch, buy.CancelReason)
+
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "ES", MatchingAlgorithm: "SIZE_PRIORITY"}))
+}
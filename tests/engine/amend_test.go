@@ -0,0 +1,173 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestAmend_QuantityDownKeepsPriority checks a reduced order stays ahead in the FIFO queue
+func TestAmend_QuantityDownKeepsPriority(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1001))
+
+    amended, resp, err := eng.AmendOrder("sell-1", 0, 100)
+    assert.NoError(err)
+    assert.Equal(0, len(resp.Trades))
+    assert.Equal(int64(100), amended.Quantity)
+
+    buy := newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 100, 1002)
+    resp, _ = eng.SubmitOrder(buy)
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal("sell-1", resp.Trades[0].RestingOrderID, "reduced order keeps its place")
+}
+
+// TestAmend_PriceChangeLosesPriorityAndCanCross checks re-pricing re-queues and matches
+func TestAmend_PriceChangeLosesPriorityAndCanCross(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15040, 200, 1001))
+
+    amended, resp, err := eng.AmendOrder("buy-1", 15050, 0)
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal(int64(200), resp.Trades[0].Quantity)
+    assert.Equal(enginepkg.StatusFilled, amended.Status)
+
+    status, _ := eng.GetOrderStatus("sell-1")
+    assert.Equal(int64(100), status.RemainingQuantity())
+}
+
+// TestAmend_RejectsFinishedOrders checks filled and cancelled orders cannot be amended
+func TestAmend_RejectsFinishedOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15040, 200, 1000))
+    _, _ = eng.CancelOrder("buy-1")
+
+    _, _, err := eng.AmendOrder("buy-1", 15045, 0)
+    assert.Error(err)
+    _, _, err = eng.AmendOrder("missing", 15045, 0)
+    assert.Equal("order not found", err.Error())
+}
+
+// TestPriceLevel_ReduceOrderQuantity checks a reduction stays in place and
+// that increases and reductions to or below the filled quantity are refused
+func TestPriceLevel_ReduceOrderQuantity(t *testing.T) {
+    assert := assert.New(t)
+    level := enginepkg.NewPriceLevel(15050)
+    first := newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1000)
+    second := newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1001)
+    level.AddOrder(first)
+    level.AddOrder(second)
+    first.FilledQuantity = 50
+
+    assert.NoError(level.ReduceOrderQuantity(first, 100))
+    assert.Equal(int64(100), first.Quantity)
+    assert.Equal(first, level.Orders.Front().Value, "reduced order keeps its place")
+
+    assert.ErrorIs(level.ReduceOrderQuantity(first, 200), enginepkg.ErrInvalidOrder, "increases must re-queue")
+    assert.ErrorIs(level.ReduceOrderQuantity(first, 50), enginepkg.ErrInvalidOrder, "nothing would be left to rest")
+    assert.ErrorIs(level.ReduceOrderQuantity(first, 40), enginepkg.ErrInvalidOrder)
+    assert.Equal(int64(100), first.Quantity)
+    assert.Equal(int64(350), level.TotalQuantity())
+}
+
+// TestAmend_QuantityUpLosesPriority checks an increase at the same price
+// moves the order behind the rest of its level
+func TestAmend_QuantityUpLosesPriority(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1001))
+
+    amended, resp, err := eng.AmendOrder("sell-1", 0, 150)
+    assert.NoError(err)
+    assert.True(resp.OrderInBook)
+    assert.Equal(int64(150), amended.Quantity)
+
+    resp, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 100, 1002))
+    if assert.Equal(1, len(resp.Trades)) {
+        assert.Equal("sell-2", resp.Trades[0].RestingOrderID, "increased order went to the back")
+    }
+}
+
+// TestAmend_QuantityToFilledCancels checks reducing to the filled quantity
+// cancels the remainder and reducing below it is rejected
+func TestAmend_QuantityToFilledCancels(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 40, 1001))
+
+    _, _, err := eng.AmendOrder("sell-1", 0, 30)
+    assert.ErrorContains(err, "quantity 30 is below filled quantity 40")
+
+    amended, resp, err := eng.AmendOrder("sell-1", 0, 40)
+    assert.NoError(err)
+    assert.False(resp.OrderInBook)
+    assert.Equal(enginepkg.StatusCancelled, amended.Status)
+    assert.Equal(enginepkg.ReasonRequested, amended.CancelReason)
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Empty(asks)
+}
+
+// TestAmend_OffTickPriceRejected checks an amendment to a price off the
+// symbol's tick is refused and leaves the order resting where it was
+func TestAmend_OffTickPriceRejected(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", TickSize: 5}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("bid-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("bid-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 15000, 50, 1001))
+
+    _, _, err := eng.AmendOrder("bid-1", 15003, 0)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    status, _ := eng.GetOrderStatus("bid-1")
+    assert.Equal(int64(15000), status.Price)
+    assert.Equal(int64(100), status.Quantity)
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15000, Quantity: 150}}, bids)
+
+    // bid-1 kept its place ahead of bid-2
+    resp, err := eng.SubmitOrder(newTestOrder("sell", "AAPL", enginepkg.Sell, enginepkg.Limit, 15000, 100, 1002))
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    assert.Equal("bid-1", resp.Trades[0].RestingOrderID)
+}
+
+// TestAmend_PostOnlyCrossRejected checks a post-only order cannot be amended
+// to a price that would take liquidity
+func TestAmend_PostOnlyCrossRejected(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    bid := newTestOrder("bid-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15000, 100, 1001)
+    bid.PostOnly = true
+    _, err := eng.SubmitOrder(bid)
+    assert.NoError(err)
+
+    _, resp, err := eng.AmendOrder("bid-1", 15050, 0)
+    assert.ErrorIs(err, enginepkg.ErrPostOnlyCross)
+    assert.Empty(resp.Trades)
+    status, _ := eng.GetOrderStatus("bid-1")
+    assert.Equal(int64(15000), status.Price)
+    assert.Equal(enginepkg.StatusAccepted, status.Status)
+
+    // Moving up without crossing is still allowed
+    amended, _, err := eng.AmendOrder("bid-1", 15040, 0)
+    assert.NoError(err)
+    assert.Equal(int64(15040), amended.Price)
+}
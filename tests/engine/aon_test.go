@@ -0,0 +1,168 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func aonOrder(id string, side enginepkg.Side, price, qty int64) *enginepkg.Order {
+    order := newTestOrder(id, "AAPL", side, enginepkg.Limit, price, qty, 0)
+    order.AllOrNone = true
+    return order
+}
+
+func TestAllOrNone_RestingSkippedThenFilled(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(aonOrder("aon", enginepkg.Sell, 10000, 100))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("behind", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 30, 1))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("worse", "AAPL", enginepkg.Sell, enginepkg.Limit, 10010, 30, 2))
+    assert.NoError(err)
+
+    // Too small for the AON order: it is passed over, at its price and worse
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10010, 50, 3))
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal("behind", resp.Trades[0].RestingOrderID)
+    assert.Equal("worse", resp.Trades[1].RestingOrderID)
+    assert.Equal(int64(20), resp.Trades[1].Quantity)
+    aon, _ := eng.GetOrderStatus("aon")
+    assert.Equal(int64(0), aon.FilledQuantity)
+
+    // Large enough: the AON order fills whole, ahead of the rest by time
+    resp, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10010, 105, 4))
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal("aon", resp.Trades[0].RestingOrderID)
+    assert.Equal(int64(100), resp.Trades[0].Quantity)
+    assert.Equal("worse", resp.Trades[1].RestingOrderID)
+    assert.Equal(int64(5), resp.Trades[1].Quantity)
+    aon, _ = eng.GetOrderStatus("aon")
+    assert.Equal(enginepkg.StatusFilled, aon.Status)
+}
+
+func TestAllOrNone_IncomingRestsUntilFullyFillable(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 60, 0))
+    assert.NoError(err)
+
+    // Only 60 of 100 available: nothing trades and the order rests
+    resp, err := eng.SubmitOrder(aonOrder("aon", enginepkg.Buy, 10000, 100))
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    assert.True(resp.OrderInBook)
+    s1, _ := eng.GetOrderStatus("s1")
+    assert.Equal(int64(0), s1.FilledQuantity)
+
+    // A seller of 40 cannot fill the resting AON bid, so it rests too
+    _, err = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 40, 1))
+    assert.NoError(err)
+    aon, _ := eng.GetOrderStatus("aon")
+    assert.Equal(int64(0), aon.FilledQuantity)
+
+    // A seller of 100 takes it whole
+    resp, err = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 2))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal("aon", resp.Trades[0].RestingOrderID)
+    assert.Equal(int64(100), resp.Trades[0].Quantity)
+
+    // An AON order the book can fill completely matches on arrival
+    resp, err = eng.SubmitOrder(aonOrder("aon2", enginepkg.Buy, 10000, 100))
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    aon2, _ := eng.GetOrderStatus("aon2")
+    assert.Equal(enginepkg.StatusFilled, aon2.Status)
+}
+
+func TestAllOrNone_AONAgainstAON(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(aonOrder("big", enginepkg.Sell, 10000, 100))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("small", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1))
+    assert.NoError(err)
+
+    // Resting AON liquidity it cannot take whole does not count
+    resp, err := eng.SubmitOrder(aonOrder("b1", enginepkg.Buy, 10000, 80))
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+
+    resp, err = eng.SubmitOrder(aonOrder("b2", enginepkg.Buy, 10000, 150))
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal("big", resp.Trades[0].RestingOrderID)
+    assert.Equal("small", resp.Trades[1].RestingOrderID)
+}
+
+func TestAllOrNone_ProRataLeavesAONOut(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", MatchingAlgorithm: enginepkg.ProRata}))
+
+    _, err := eng.SubmitOrder(aonOrder("aon", enginepkg.Sell, 10000, 100))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 2))
+    assert.NoError(err)
+
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 50, 3))
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    for _, trade := range resp.Trades {
+        assert.NotEqual("aon", trade.RestingOrderID)
+        assert.Equal(int64(25), trade.Quantity)
+    }
+
+    // Taking the whole level sweeps it in time order, AON first
+    resp, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 250, 4))
+    assert.NoError(err)
+    assert.Equal(3, len(resp.Trades))
+    assert.Equal("aon", resp.Trades[0].RestingOrderID)
+    assert.Equal(int64(100), resp.Trades[0].Quantity)
+}
+
+func TestAllOrNone_Validation(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    market := newTestOrder("m", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 10, 0)
+    market.AllOrNone = true
+    ioc := aonOrder("ioc", enginepkg.Buy, 10000, 10)
+    ioc.TimeInForce = enginepkg.IOC
+    iceberg := aonOrder("ice", enginepkg.Buy, 10000, 100)
+    iceberg.DisplayQuantity = 10
+    for _, order := range []*enginepkg.Order{market, ioc, iceberg} {
+        _, err := eng.SubmitOrder(order)
+        assert.ErrorIs(err, enginepkg.ErrInvalidOrder, order.ID)
+    }
+}
+
+func TestAllOrNone_SitsOutAuction(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.StartAuction("AAPL"))
+
+    _, err := eng.SubmitOrder(aonOrder("aon", enginepkg.Buy, 10100, 100))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 60, 2))
+    assert.NoError(err)
+
+    price, trades := eng.RunAuction("AAPL")
+    assert.Equal(int64(10000), price)
+    assert.Equal(1, len(trades))
+    assert.Equal(int64(40), trades[0].Quantity)
+    aon, _ := eng.GetOrderStatus("aon")
+    assert.Equal(int64(0), aon.FilledQuantity)
+}
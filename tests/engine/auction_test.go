@@ -0,0 +1,106 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestAuction_Uncross(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.StartAuction("AAPL"))
+    assert.Equal(enginepkg.AuctionCollecting, eng.GetTradingPhase("AAPL"))
+
+    orders := []*enginepkg.Order{
+        newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 10, 1000),
+        newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10050, 20, 1001),
+        newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 15, 1002),
+        newTestOrder("b4", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, 1003),
+        newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 9950, 15, 1004),
+        newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1005),
+        newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10050, 20, 1006),
+        newTestOrder("s4", "AAPL", enginepkg.Sell, enginepkg.Limit, 10150, 10, 1007),
+    }
+    for _, o := range orders {
+        resp, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+        assert.Empty(resp.Trades, "no matching while collecting")
+    }
+
+    // Market orders have no place in the call
+    _, err := eng.SubmitOrder(newTestOrder("m1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 5, 1008))
+    assert.Error(err)
+
+    // Executable volume peaks at 30 shares at 10050
+    price, trades := eng.RunAuction("AAPL")
+    assert.Equal(int64(10050), price)
+    var volume int64
+    for _, tr := range trades {
+        assert.Equal(int64(10050), tr.Price)
+        volume += tr.Quantity
+    }
+    assert.Equal(int64(30), volume)
+    assert.Equal(4, len(trades))
+
+    for id, status := range map[string]enginepkg.OrderStatus{
+        "b1": enginepkg.StatusFilled, "b2": enginepkg.StatusFilled, "b3": enginepkg.StatusAccepted,
+        "s1": enginepkg.StatusFilled, "s2": enginepkg.StatusFilled, "s3": enginepkg.StatusPartialFill,
+    } {
+        o, _ := eng.GetOrderStatus(id)
+        assert.Equal(status, o.Status, id)
+    }
+
+    // Book is uncrossed and back to continuous matching
+    bid, ask, _ := eng.GetBBO("AAPL")
+    assert.Equal(int64(10000), bid.Price)
+    assert.Equal(enginepkg.AggregatedPriceLevel{Price: 10050, Quantity: 15}, ask)
+    assert.Equal(enginepkg.Continuous, eng.GetTradingPhase("AAPL"))
+
+    resp, err := eng.SubmitOrder(newTestOrder("b5", "AAPL", enginepkg.Buy, enginepkg.Limit, 10050, 5, 1009))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+}
+
+func TestAuction_TieBreaksOnImbalanceThenPrice(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.StartAuction("AAPL"))
+    assert.Error(eng.StartAuction("AAPL"))
+
+    // 10 shares execute at either 10000 or 10100 with zero imbalance; lower wins
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1001))
+
+    price, trades := eng.RunAuction("AAPL")
+    assert.Equal(int64(10000), price)
+    assert.Equal(1, len(trades))
+
+    // Not collecting: nothing to do
+    price, trades = eng.RunAuction("AAPL")
+    assert.Equal(int64(0), price)
+    assert.Empty(trades)
+}
+
+func TestAuction_WALReplay(t *testing.T) {
+    assert := assert.New(t)
+
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+    assert.NoError(original.StartAuction("AAPL"))
+    _, _ = original.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 10, 1000))
+    _, _ = original.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 4, 1001))
+    original.RunAuction("AAPL")
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    b1, err := recovered.GetOrderStatus("b1")
+    assert.NoError(err)
+    assert.Equal(int64(4), b1.FilledQuantity)
+    last, _ := recovered.GetLastTrade("AAPL")
+    assert.Equal(int64(10000), last.Price)
+    assert.Equal(enginepkg.Continuous, recovered.GetTradingPhase("AAPL"))
+}
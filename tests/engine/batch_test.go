@@ -0,0 +1,32 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestSubmitBatch_MixedResults(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    results := eng.SubmitBatch([]*enginepkg.Order{
+        newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000),
+        newTestOrder("mkt-1", "MSFT", enginepkg.Buy, enginepkg.Market, 0, 50, 1001),
+        newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1002),
+    })
+    assert.Equal(3, len(results))
+
+    assert.NoError(results[0].Err)
+    assert.True(results[0].Response.OrderInBook)
+
+    // Market order with no liquidity is rejected, the rest still run
+    assert.ErrorContains(results[1].Err, "insufficient liquidity")
+
+    // The crossing buy matches the sell submitted earlier in the batch
+    assert.NoError(results[2].Err)
+    assert.Equal(1, len(results[2].Response.Trades))
+    assert.Equal("sell-1", results[2].Response.Trades[0].RestingOrderID)
+    assert.Equal(enginepkg.StatusFilled, results[2].Order.Status)
+}
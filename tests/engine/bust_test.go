@@ -0,0 +1,115 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestBustTrade_RestoresFilledQuantities(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    eng.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+
+    _, _ = eng.SubmitOrder(accountOrder("ask", "mm", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(accountOrder("bid", "alice", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1001))
+    _, _ = eng.SubmitOrder(accountOrder("bid-2", "alice", enginepkg.Buy, enginepkg.Limit, 10000, 20, 1002))
+
+    assert.NoError(eng.BustTrade("trade-1"))
+
+    // The resting ask gets the busted 40 back and keeps resting
+    ask, _ := eng.GetOrderStatus("ask")
+    assert.Equal(int64(20), ask.FilledQuantity)
+    assert.Equal(enginepkg.StatusPartialFill, ask.Status)
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 80}}, asks)
+
+    // The filled bid does not re-enter the book
+    bid, _ := eng.GetOrderStatus("bid")
+    assert.Equal(int64(0), bid.FilledQuantity)
+    assert.Equal(enginepkg.StatusCancelled, bid.Status)
+    assert.Equal(enginepkg.ReasonBusted, bid.CancelReason)
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Empty(bids)
+    assert.NoError(eng.Verify("AAPL"))
+
+    // Positions keep only the trade that stands
+    assert.Equal(int64(20), eng.GetPositions("alice")[0].NetQuantity)
+    assert.Equal(int64(-20), eng.GetPositions("mm")[0].NetQuantity)
+    assert.Equal(int64(0), eng.GetPositions("alice")[0].RealizedPnL)
+
+    trades := eng.GetTradesForOrder("bid")
+    assert.Len(trades, 1)
+    assert.True(trades[0].Busted)
+    history, err := eng.GetOrderHistory("bid")
+    assert.NoError(err)
+    last := history[len(history)-1]
+    assert.Equal(enginepkg.EventBust, last.Type)
+    assert.Equal("trade-1", last.Trade.TradeID)
+
+    assert.ErrorIs(eng.BustTrade("trade-1"), enginepkg.ErrInvalidState)
+    assert.ErrorIs(eng.BustTrade("trade-99"), enginepkg.ErrTradeNotFound)
+}
+
+func TestBustTrade_ReplaysFromWAL(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(newTestOrder("ask", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = original.SubmitOrder(newTestOrder("bid", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1001))
+    assert.NoError(original.BustTrade("trade-1"))
+
+    recovered := setupEngine()
+    recovered.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    ask, _ := recovered.GetOrderStatus("ask")
+    assert.Equal(int64(0), ask.FilledQuantity)
+    assert.Equal(enginepkg.StatusAccepted, ask.Status)
+    assert.True(recovered.GetTradesForOrder("ask")[0].Busted)
+}
+
+func TestBustTrade_ReplaysWithUUIDs(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(newTestOrder("ask", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    resp, _ := original.SubmitOrder(newTestOrder("bid", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1001))
+    assert.NoError(original.BustTrade(resp.Trades[0].TradeID))
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    ask, _ := recovered.GetOrderStatus("ask")
+    assert.Equal(int64(0), ask.FilledQuantity)
+    assert.Equal(enginepkg.StatusAccepted, ask.Status)
+    bid, _ := recovered.GetOrderStatus("bid")
+    assert.Equal(enginepkg.StatusCancelled, bid.Status)
+    assert.Equal(enginepkg.ReasonBusted, bid.CancelReason)
+    trades := recovered.GetTradesForOrder("ask")
+    assert.Len(trades, 1)
+    assert.True(trades[0].Busted)
+    assert.NotEqual(resp.Trades[0].TradeID, trades[0].TradeID)
+}
+
+func TestBustTrade_RecoverFailsOnUnmatchedBust(t *testing.T) {
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(newTestOrder("ask", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    resp, _ := original.SubmitOrder(newTestOrder("bid", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1001))
+    assert.NoError(t, original.BustTrade(resp.Trades[0].TradeID))
+
+    // Without the bid's submit there is no trade for the bust to find
+    lines := bytes.Split(bytes.TrimSpace(log.Bytes()), []byte("\n"))
+    assert.Len(t, lines, 3)
+    damaged := bytes.Join([][]byte{lines[0], lines[2]}, []byte("\n"))
+    err := setupEngine().Recover(bytes.NewReader(damaged))
+    assert.ErrorIs(t, err, enginepkg.ErrTradeNotFound)
+}
@@ -0,0 +1,143 @@
+package engine_test
+
+import (
+    "bytes"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestCancelAll_BySymbol(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    for i := 0; i < 10; i++ {
+        symbol := "AAPL"
+        if i%2 == 1 {
+            symbol = "MSFT"
+        }
+        order := newTestOrder(fmt.Sprintf("o-%d", i), symbol, enginepkg.Buy, enginepkg.Limit, int64(10000+i), 100, int64(1000+i))
+        order.AccountID = "acct-1"
+        _, err := eng.SubmitOrder(order)
+        assert.NoError(err)
+    }
+
+    cancelled, err := eng.CancelAll("AAPL", "")
+    assert.NoError(err)
+    assert.Equal(5, len(cancelled))
+    for _, o := range cancelled {
+        assert.Equal("AAPL", o.Symbol)
+        assert.Equal(enginepkg.StatusCancelled, o.Status)
+    }
+
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Empty(bids)
+    bids, _ = eng.GetOrderBookSnapshot("MSFT", 10)
+    assert.Equal(5, len(bids))
+}
+
+func TestCancelAll_ByAccountSkipsFilled(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    mine := newTestOrder("mine", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000)
+    mine.AccountID = "A"
+    filled := newTestOrder("filled", "AAPL", enginepkg.Sell, enginepkg.Limit, 9000, 50, 1001)
+    filled.AccountID = "A"
+    theirs := newTestOrder("theirs", "MSFT", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1002)
+    theirs.AccountID = "B"
+    stop := newTestOrder("stop", "MSFT", enginepkg.Sell, enginepkg.Stop, 0, 100, 1003)
+    stop.StopPrice = 8000
+    stop.AccountID = "A"
+    for _, o := range []*enginepkg.Order{mine, filled, theirs, stop} {
+        _, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+    }
+    _, _ = eng.SubmitOrder(newTestOrder("taker", "AAPL", enginepkg.Buy, enginepkg.Limit, 9000, 50, 1004))
+
+    cancelled, err := eng.CancelAll("", "A")
+    assert.NoError(err)
+    ids := []string{}
+    for _, o := range cancelled {
+        ids = append(ids, o.ID)
+    }
+    assert.ElementsMatch([]string{"mine", "stop"}, ids)
+
+    o, _ := eng.GetOrderStatus("theirs")
+    assert.Equal(enginepkg.StatusAccepted, o.Status)
+    o, _ = eng.GetOrderStatus("filled")
+    assert.Equal(enginepkg.StatusFilled, o.Status)
+}
+
+func TestCancelLevel_RemovesEveryOrderAtThePrice(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    for i := 1; i <= 3; i++ {
+        _, err := eng.SubmitOrder(newTestOrder(fmt.Sprintf("ask-%d", i), "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, int64(1000+i)))
+        assert.NoError(err)
+    }
+    _, _ = eng.SubmitOrder(newTestOrder("other", "AAPL", enginepkg.Sell, enginepkg.Limit, 15100, 100, 1004))
+    _, _ = eng.SubmitOrder(newTestOrder("bid", "AAPL", enginepkg.Buy, enginepkg.Limit, 15000, 100, 1005))
+
+    cancelled, err := eng.CancelLevel("AAPL", enginepkg.Sell, 15050)
+    assert.NoError(err)
+    ids := make([]string, len(cancelled))
+    for i, o := range cancelled {
+        ids[i] = o.ID
+        assert.Equal(enginepkg.StatusCancelled, o.Status)
+        assert.Equal(enginepkg.ReasonCancelLevel, o.CancelReason)
+    }
+    assert.Equal([]string{"ask-1", "ask-2", "ask-3"}, ids)
+
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15100, Quantity: 100}}, asks)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15000, Quantity: 100}}, bids)
+
+    // A level that is not there (or no longer there) cancels nothing
+    cancelled, err = eng.CancelLevel("AAPL", enginepkg.Sell, 15050)
+    assert.NoError(err)
+    assert.Empty(cancelled)
+    cancelled, err = eng.CancelLevel("AAPL", enginepkg.Buy, 15100)
+    assert.NoError(err)
+    assert.Empty(cancelled)
+}
+
+func TestCancelAll_ReplaysCancelReason(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    _, err := original.CancelAll("AAPL", "")
+    assert.NoError(err)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    order, _ := recovered.GetOrderStatus("b1")
+    assert.Equal(enginepkg.StatusCancelled, order.Status)
+    assert.Equal(enginepkg.ReasonCancelAll, order.CancelReason)
+}
+
+func TestCancelLevel_ReplaysCancelReason(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = original.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, 1001))
+    _, err := original.CancelLevel("AAPL", enginepkg.Buy, 10000)
+    assert.NoError(err)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    order, _ := recovered.GetOrderStatus("b1")
+    assert.Equal(enginepkg.StatusCancelled, order.Status)
+    assert.Equal(enginepkg.ReasonCancelLevel, order.CancelReason)
+    bids, _ := recovered.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 9900, Quantity: 10}}, bids)
+}
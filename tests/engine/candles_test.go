@@ -0,0 +1,52 @@
+package engine_test
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// waitForNextSecond sleeps until just past the next 1s bucket boundary.
+func waitForNextSecond() {
+    now := time.Now().UnixMilli()
+    time.Sleep(time.Duration(1000-now%1000+20) * time.Millisecond)
+}
+
+func TestCandles_TwoBuckets(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    cross := func(id string, price, qty int64) {
+        _, _ = eng.SubmitOrder(newTestOrder("s-"+id, "AAPL", enginepkg.Sell, enginepkg.Limit, price, qty, 0))
+        _, err := eng.SubmitOrder(newTestOrder("b-"+id, "AAPL", enginepkg.Buy, enginepkg.Limit, price, qty, 0))
+        assert.NoError(err)
+    }
+
+    waitForNextSecond()
+    cross("1", 10000, 10)
+    cross("2", 10300, 5)
+    cross("3", 9900, 20)
+    cross("4", 10100, 1)
+
+    waitForNextSecond()
+    cross("5", 10200, 7)
+    cross("6", 10250, 3)
+
+    candles, err := eng.GetCandles("AAPL", "1s", 10)
+    assert.NoError(err)
+    if assert.Equal(2, len(candles)) {
+        first, second := candles[0], candles[1]
+        assert.Equal(int64(1000), second.Start-first.Start)
+        assert.Equal([]int64{10000, 10300, 9900, 10100, 36}, []int64{first.Open, first.High, first.Low, first.Close, first.Volume})
+        assert.Equal(4, first.Trades)
+        assert.Equal([]int64{10200, 10250, 10200, 10250, 10}, []int64{second.Open, second.High, second.Low, second.Close, second.Volume})
+    }
+
+    latest, _ := eng.GetCandles("AAPL", "1s", 1)
+    assert.Equal(candles[1:], latest)
+
+    _, err = eng.GetCandles("AAPL", "7m", 10)
+    assert.Error(err)
+}
@@ -0,0 +1,53 @@
+package engine_test
+
+import (
+    "hash/crc32"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestBookChecksum_ChangesOnlyWithLevels(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    assert.Equal(crc32.ChecksumIEEE(nil), eng.BookChecksum("AAPL"))
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9800, 50, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 70, 1002))
+
+    // Bids and asks interleave level by level, best first
+    expected := crc32.ChecksumIEEE([]byte("9900:100:10100:70:9800:50"))
+    assert.Equal(expected, eng.BookChecksum("AAPL"))
+    assert.Equal(expected, eng.BookChecksum("AAPL"), "stable while nothing changes")
+
+    // Activity elsewhere, or off the displayed book, leaves it alone
+    _, _ = eng.SubmitOrder(newTestOrder("m1", "MSFT", enginepkg.Buy, enginepkg.Limit, 9900, 10, 1003))
+    hidden := newTestOrder("h1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, 1004)
+    hidden.Hidden = true
+    _, _ = eng.SubmitOrder(hidden)
+    assert.Equal(expected, eng.BookChecksum("AAPL"))
+
+    _, _, err := eng.AmendOrder("b2", 0, 40)
+    assert.NoError(err)
+    changed := eng.BookChecksum("AAPL")
+    assert.NotEqual(expected, changed)
+    assert.Equal(crc32.ChecksumIEEE([]byte("9900:100:10100:70:9800:40")), changed)
+}
+
+func TestBookChecksum_CarriedOnDepthUpdates(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    sub := eng.SubscribeDepth("AAPL")
+    defer sub.Close()
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1000))
+
+    update := <-sub.C
+    assert.Equal(eng.BookChecksum("AAPL"), update.Checksum)
+    snap := eng.GetBookSnapshot("AAPL", 1)
+    assert.Equal(update.Checksum, snap.Checksum)
+    assert.Equal(update.Sequence, snap.Sequence)
+}
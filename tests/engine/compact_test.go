@@ -0,0 +1,42 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestCompactBook_RoundTrip(t *testing.T) {
+    assert := assert.New(t)
+
+    book := enginepkg.CompactBook{
+        Symbol:           "BTC-USD",
+        PriceScale:       2,
+        QuantityDecimals: 8,
+        Timestamp:        1_700_000_000_000,
+        Sequence:         42,
+        Checksum:         0xdeadbeef,
+        Bids:             []enginepkg.AggregatedPriceLevel{{Price: 3_000_000, Quantity: 150_000_000}, {Price: 2_999_900, Quantity: 1}},
+        Asks:             []enginepkg.AggregatedPriceLevel{{Price: -5, Quantity: 7}},
+    }
+    data := enginepkg.AppendCompactBook(nil, book)
+    decoded, err := enginepkg.DecodeCompactBook(data)
+    assert.NoError(err)
+    assert.Equal(book, decoded)
+
+    empty, err := enginepkg.DecodeCompactBook(enginepkg.AppendCompactBook(nil, enginepkg.CompactBook{Symbol: "AAPL"}))
+    assert.NoError(err)
+    assert.Empty(empty.Bids)
+    assert.Empty(empty.Asks)
+
+    // Every truncation, and trailing or foreign bytes, is refused
+    for n := range len(data) {
+        _, err := enginepkg.DecodeCompactBook(data[:n])
+        assert.Error(err, "truncated to %d bytes", n)
+    }
+    _, err = enginepkg.DecodeCompactBook(append(data, 0))
+    assert.Error(err)
+    _, err = enginepkg.DecodeCompactBook([]byte(`{"bids":[]}`))
+    assert.Error(err)
+}
@@ -0,0 +1,98 @@
+package engine_test
+
+import (
+    "fmt"
+    "sync"
+    "sync/atomic"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestConcurrentSymbols_ConservesQuantity hammers many symbols from many
+// goroutines, some of them creating the same books at once, while others
+// read. Run with -race. Every submitted share must end up either traded or
+// resting.
+func TestConcurrentSymbols_ConservesQuantity(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    const (
+        workers = 32
+        symbols = 300
+        perWork = 300
+    )
+
+    var bought, sold atomic.Int64
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < perWork; i++ {
+                symbol := fmt.Sprintf("S%03d", (w*7+i)%symbols)
+                side := enginepkg.Buy
+                if (w+i)%2 == 1 {
+                    side = enginepkg.Sell
+                }
+                qty := int64(1 + (w+i)%9)
+                order := newTestOrder(fmt.Sprintf("w%d-%d", w, i), symbol, side, enginepkg.Limit, 10000+int64((w*i)%5)-2, qty, 0)
+                if _, err := eng.SubmitOrder(order); err != nil {
+                    t.Errorf("submit %s: %v", order.ID, err)
+                    return
+                }
+                if side == enginepkg.Buy {
+                    bought.Add(qty)
+                } else {
+                    sold.Add(qty)
+                }
+                if i%10 == 0 {
+                    eng.GetBookSnapshot(symbol, 5)
+                    eng.GlobalStats()
+                }
+            }
+        }(w)
+    }
+    wg.Wait()
+
+    stats := eng.GlobalStats()
+    assert.Equal(symbols, stats.Symbols)
+    var restingBid, restingAsk int64
+    for s := 0; s < symbols; s++ {
+        snap := eng.GetBookSnapshot(fmt.Sprintf("S%03d", s), 0)
+        for _, l := range snap.Bids {
+            restingBid += l.Quantity
+        }
+        for _, l := range snap.Asks {
+            restingAsk += l.Quantity
+        }
+        // Matching keeps every book uncrossed
+        if len(snap.Bids) > 0 && len(snap.Asks) > 0 {
+            assert.Less(snap.Bids[0].Price, snap.Asks[0].Price)
+        }
+    }
+    assert.Equal(bought.Load(), stats.Volume+restingBid)
+    assert.Equal(sold.Load(), stats.Volume+restingAsk)
+}
+
+// BenchmarkSubmitOrder_ManySymbolsParallel submits from every benchmark
+// goroutine across 1,000 symbols, mixing in book reads, to measure lock
+// contention between unrelated symbols.
+func BenchmarkSubmitOrder_ManySymbolsParallel(b *testing.B) {
+    eng := setupEngine()
+    var next atomic.Int64
+    b.RunParallel(func(pb *testing.PB) {
+        for pb.Next() {
+            n := next.Add(1)
+            symbol := fmt.Sprintf("S%03d", n%1000)
+            side := enginepkg.Buy
+            if n%2 == 1 {
+                side = enginepkg.Sell
+            }
+            eng.SubmitOrder(newTestOrder(fmt.Sprintf("o%d", n), symbol, side, enginepkg.Limit, 10000+n%7-3, 10, 0))
+            if n%4 == 0 {
+                eng.GetBookSnapshot(symbol, 5)
+            }
+        }
+    })
+}
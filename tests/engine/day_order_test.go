@@ -0,0 +1,125 @@
+package engine_test
+
+import (
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestDayOrders_ExpireAtSessionClose(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionClose: "16:00"}))
+
+    now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+    eng.SetClock(func() time.Time { return now })
+
+    day := newTestOrder("day", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    day.TimeInForce = enginepkg.Day
+    _, err := eng.SubmitOrder(day)
+    assert.NoError(err)
+    assert.Equal(time.Date(2026, 3, 2, 16, 0, 0, 0, time.UTC).UnixMilli(), day.ExpiresAt)
+    _, err = eng.SubmitOrder(newTestOrder("gtc", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1001))
+    assert.NoError(err)
+
+    // Nothing is due before the close
+    assert.Empty(eng.ExpireOrders(now.Add(59 * time.Minute)))
+
+    now = now.Add(time.Hour)
+    expired := eng.ExpireOrders(now)
+    assert.Equal(1, len(expired))
+    assert.Equal("day", expired[0].ID)
+
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 9900, Quantity: 100}}, bids)
+    status, _ := eng.GetOrderStatus("day")
+    assert.Equal(enginepkg.StatusCancelled, status.Status)
+    assert.Equal(enginepkg.ReasonExpired, status.CancelReason)
+
+    // After the close DAY orders are refused; GTC is still fine
+    late := newTestOrder("late", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1002)
+    late.TimeInForce = enginepkg.Day
+    _, err = eng.SubmitOrder(late)
+    assert.ErrorContains(err, "closed")
+}
+
+func TestDayOrders_NeedSessionClose(t *testing.T) {
+    eng := setupEngine()
+
+    order := newTestOrder("day", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    order.TimeInForce = enginepkg.Day
+    _, err := eng.SubmitOrder(order)
+    assert.ErrorContains(t, err, "session close")
+
+    err = eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionClose: "4pm"})
+    assert.Error(t, err)
+}
+
+func TestGTDDate_ExpiresAtSessionCloseInTimeZone(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionClose: "16:00", SessionTimeZone: "America/New_York"}))
+    ny, err := time.LoadLocation("America/New_York")
+    assert.NoError(err)
+
+    now := time.Date(2026, 3, 2, 10, 0, 0, 0, ny)
+    eng.SetClock(func() time.Time { return now })
+
+    gtd := newTestOrder("gtd", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    gtd.ExpireDate = "2026-03-04"
+    _, err = eng.SubmitOrder(gtd)
+    assert.NoError(err)
+    closeAt := time.Date(2026, 3, 4, 16, 0, 0, 0, ny)
+    assert.Equal(closeAt.UnixMilli(), gtd.ExpiresAt)
+
+    // Midnight UTC on the date, and a millisecond before the close, are too early
+    assert.Empty(eng.ExpireOrders(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)))
+    assert.Empty(eng.ExpireOrders(closeAt.Add(-time.Millisecond)))
+    expired := eng.ExpireOrders(closeAt)
+    assert.Equal(1, len(expired))
+    assert.Equal("gtd", expired[0].ID)
+    status, _ := eng.GetOrderStatus("gtd")
+    assert.Equal(enginepkg.ReasonExpired, status.CancelReason)
+}
+
+func TestGTDDate_WithoutSessionCloseExpiresAtEndOfDate(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+    eng.SetClock(func() time.Time { return now })
+
+    gtd := newTestOrder("gtd", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    gtd.ExpireDate = "2026-03-02"
+    _, err := eng.SubmitOrder(gtd)
+    assert.NoError(err)
+    assert.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC).UnixMilli(), gtd.ExpiresAt)
+}
+
+func TestGTDDate_RejectsPastAndMalformedDates(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionClose: "16:00"}))
+    now := time.Date(2026, 3, 2, 17, 0, 0, 0, time.UTC)
+    eng.SetClock(func() time.Time { return now })
+
+    for i, date := range []string{"2026-03-01", "2026-03-02", "03/04/2026"} {
+        order := newTestOrder(fmt.Sprintf("bad%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+        order.ExpireDate = date
+        _, err := eng.SubmitOrder(order)
+        assert.ErrorIs(err, enginepkg.ErrInvalidOrder, date)
+    }
+
+    day := newTestOrder("day", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    day.TimeInForce = enginepkg.Day
+    day.ExpireDate = "2026-03-04"
+    _, err := eng.SubmitOrder(day)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    ok := newTestOrder("ok", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    ok.ExpireDate = "2026-03-03"
+    _, err = eng.SubmitOrder(ok)
+    assert.NoError(err)
+}
@@ -0,0 +1,87 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestParseAndFormatQuantity(t *testing.T) {
+    cases := []struct {
+        in       string
+        decimals int
+        units    int64
+        out      string
+    }{
+        {"1.5", 2, 150, "1.5"},
+        {"0.75", 2, 75, "0.75"},
+        {"10", 2, 1000, "10"},
+        {".5", 1, 5, "0.5"},
+        {"42", 0, 42, "42"},
+    }
+    for _, c := range cases {
+        units, err := enginepkg.ParseQuantity(c.in, c.decimals)
+        assert.NoError(t, err, c.in)
+        assert.Equal(t, c.units, units, c.in)
+        assert.Equal(t, c.out, enginepkg.FormatQuantity(units, c.decimals), c.in)
+    }
+
+    for _, bad := range []string{"1.234", "abc", "1.", "", "1e3"} {
+        _, err := enginepkg.ParseQuantity(bad, 2)
+        assert.Error(t, err, bad)
+    }
+}
+
+func TestParseAndFormatPrice(t *testing.T) {
+    cases := []struct {
+        in    string
+        scale int
+        units int64
+        out   string
+    }{
+        {"150.50", 2, 15050, "150.50"},
+        {"150.5", 2, 15050, "150.50"},
+        {"150", 2, 15000, "150.00"},
+        {"-0.25", 2, -25, "-0.25"},
+        {"0.00000001", 8, 1, "0.00000001"},
+        {"42", 0, 42, "42"},
+    }
+    for _, c := range cases {
+        units, err := enginepkg.ParsePrice(c.in, c.scale)
+        assert.NoError(t, err, c.in)
+        assert.Equal(t, c.units, units, c.in)
+        assert.Equal(t, c.out, enginepkg.FormatPrice(units, c.scale), c.in)
+    }
+
+    for _, bad := range []string{"150.505", "abc", "", "1e3"} {
+        _, err := enginepkg.ParsePrice(bad, 2)
+        assert.Error(t, err, bad)
+    }
+    _, err := enginepkg.ParsePrice("1", enginepkg.MaxPriceScale+1)
+    assert.Error(t, err)
+}
+
+// TestFractionalQuantities_ExactMatch checks 1.5 fills exactly against 0.75 + 0.75
+func TestFractionalQuantities_ExactMatch(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "BTC", QuantityDecimals: 4}))
+
+    qty := func(s string) int64 {
+        q, err := enginepkg.ParseQuantity(s, eng.GetSymbolConfig("BTC").QuantityDecimals)
+        assert.NoError(err)
+        return q
+    }
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "BTC", enginepkg.Sell, enginepkg.Limit, 3000000, qty("0.75"), 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "BTC", enginepkg.Sell, enginepkg.Limit, 3000000, qty("0.75"), 1001))
+
+    buy := newTestOrder("buy-1", "BTC", enginepkg.Buy, enginepkg.Limit, 3000000, qty("1.5"), 1002)
+    resp, err := eng.SubmitOrder(buy)
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal(enginepkg.StatusFilled, buy.Status)
+    assert.Equal(int64(0), buy.RemainingQuantity())
+    assert.Equal("0.75", enginepkg.FormatQuantity(resp.Trades[1].Quantity, 4))
+}
@@ -0,0 +1,95 @@
+package engine_test
+
+import (
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestSubscribeDepth_IncrementalUpdates checks level changes arrive in sequence order
+func TestSubscribeDepth_IncrementalUpdates(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    sub := eng.SubscribeDepth("AAPL")
+    defer sub.Close()
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "MSFT", enginepkg.Sell, enginepkg.Limit, 30000, 100, 1002))
+
+    first := <-sub.C
+    assert.Equal(uint64(1), first.Sequence)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15050, Quantity: 100}}, first.Asks)
+
+    second := <-sub.C
+    assert.Equal(uint64(2), second.Sequence)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15050, Quantity: 0}}, second.Asks, "filled level is removed")
+    assert.Equal(0, len(second.Bids), "fully matched buy never rested")
+
+    select {
+    case u := <-sub.C:
+        t.Fatalf("unexpected update for other symbol: %+v", u)
+    default:
+    }
+
+    _, _, seq := eng.GetDepthSnapshot("AAPL", 0)
+    assert.Equal(uint64(2), seq)
+}
+
+// TestSubscribeDepth_SlowConsumerDropped checks a full buffer closes the subscription
+func TestSubscribeDepth_SlowConsumerDropped(t *testing.T) {
+    eng := setupEngine()
+
+    sub := eng.SubscribeDepth("AAPL")
+    for i := 0; i < 1000; i++ {
+        _, _ = eng.SubmitOrder(enginepkg.NewOrder(fmt.Sprintf("buy-%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, int64(10000+i), 1))
+    }
+    drained := 0
+    for range sub.C {
+        drained++
+    }
+    if drained >= 1000 {
+        t.Fatalf("expected slow subscriber to be dropped, drained %d", drained)
+    }
+}
+
+func TestGetDepthDiff_OnlyChangedLevels(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9800, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1002))
+    _, _, since := eng.GetDepthSnapshot("AAPL", 0)
+
+    // One level grows, one disappears, one is new; 9800 is untouched
+    _, _ = eng.SubmitOrder(newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 50, 1003))
+    _, _ = eng.CancelOrder("s1")
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 70, 1004))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 30, 1005))
+
+    diff, ok := eng.GetDepthDiff("AAPL", since)
+    assert.True(ok)
+    assert.Equal(since+4, diff.Sequence)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 9900, Quantity: 150}}, diff.Bids)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10100, Quantity: 0}, {Price: 10200, Quantity: 100}}, diff.Asks)
+    assert.Equal(eng.GetBookSnapshot("AAPL", 0).Checksum, diff.Checksum)
+
+    // Up to date: nothing changed
+    diff, ok = eng.GetDepthDiff("AAPL", since+4)
+    assert.True(ok)
+    assert.Empty(diff.Bids)
+    assert.Empty(diff.Asks)
+
+    // Ahead of the book, or older than the retained history: re-sync
+    _, ok = eng.GetDepthDiff("AAPL", since+5)
+    assert.False(ok)
+    for i := 0; i < 2000; i++ {
+        _, _ = eng.SubmitOrder(newTestOrder(fmt.Sprintf("x%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, 9000, 1, int64(2000+i)))
+    }
+    _, ok = eng.GetDepthDiff("AAPL", since)
+    assert.False(ok)
+}
@@ -0,0 +1,65 @@
+package engine_test
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestErrors_MatchSentinelsByCode(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+
+    _, err := eng.SubmitOrder(newTestOrder("m1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 500, 1001))
+    assert.True(errors.Is(err, enginepkg.ErrInsufficientLiquidity))
+    assert.False(errors.Is(err, enginepkg.ErrSymbolHalted))
+    assert.Equal(enginepkg.CodeInsufficientLiquidity, enginepkg.Code(err))
+    assert.Contains(err.Error(), "only 100 shares available") // Message detail is kept
+
+    po := newTestOrder("p1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1002)
+    po.PostOnly = true
+    _, err = eng.SubmitOrder(po)
+    assert.ErrorIs(err, enginepkg.ErrPostOnlyCross)
+
+    _, err = eng.CancelOrder("missing")
+    assert.ErrorIs(err, enginepkg.ErrOrderNotFound)
+
+    assert.NoError(eng.Halt("AAPL", enginepkg.HaltReject))
+    _, err = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, 1003))
+    assert.ErrorIs(err, enginepkg.ErrSymbolHalted)
+    assert.ErrorIs(eng.Halt("AAPL", enginepkg.HaltReject), enginepkg.ErrInvalidState)
+
+    assert.Equal(enginepkg.ReasonCode(""), enginepkg.Code(errors.New("plain")))
+}
+
+func TestErrors_CancelReasonOnEveryCancel(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 10, 1002))
+
+    ioc := newTestOrder("ioc", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 20, 1003)
+    ioc.TimeInForce = enginepkg.IOC
+    _, _ = eng.SubmitOrder(ioc)
+    assert.Equal(enginepkg.ReasonUnfilled, ioc.CancelReason)
+
+    cancelled, err := eng.CancelOrder("s2")
+    assert.NoError(err)
+    assert.Equal(enginepkg.ReasonRequested, cancelled.CancelReason)
+
+    _, _, err = eng.CancelReplace("s3", newTestOrder("s3b", "AAPL", enginepkg.Sell, enginepkg.Limit, 10300, 10, 1004))
+    assert.NoError(err)
+    old, _ := eng.GetOrderStatus("s3")
+    assert.Equal(enginepkg.ReasonReplaced, old.CancelReason)
+
+    all, err := eng.CancelAll("AAPL", "")
+    assert.NoError(err)
+    assert.Equal(1, len(all))
+    assert.Equal(enginepkg.ReasonCancelAll, all[0].CancelReason)
+}
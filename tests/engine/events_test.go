@@ -0,0 +1,257 @@
+package engine_test
+
+import (
+    "bytes"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestEventSequence_MixedWorkload checks accepts, trades, cancels, amends and
+// expiries across symbols share one gapless, increasing sequence
+func TestEventSequence_MixedWorkload(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    var events []enginepkg.OrderEvent
+    eng.SetEventHook(func(ev enginepkg.OrderEvent) { events = append(events, ev) })
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "MSFT", enginepkg.Sell, enginepkg.Limit, 20000, 50, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1002))
+    _, _, err := eng.AmendOrder("s2", 0, 30)
+    assert.NoError(err)
+    ioc := newTestOrder("b2", "MSFT", enginepkg.Buy, enginepkg.Limit, 20000, 40, 1003)
+    ioc.TimeInForce = enginepkg.IOC
+    _, _ = eng.SubmitOrder(ioc)
+    gtd := newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 9000, 10, 1004)
+    gtd.ExpiresAt = time.Now().Add(time.Minute).UnixNano() / 1_000_000
+    _, _ = eng.SubmitOrder(gtd)
+    eng.ExpireOrders(time.Now().Add(time.Hour))
+    _, err = eng.CancelOrder("s1")
+    assert.NoError(err)
+
+    expected := []enginepkg.OrderEventType{
+        enginepkg.EventAccepted,                          // s1
+        enginepkg.EventAccepted,                          // s2
+        enginepkg.EventAccepted, enginepkg.EventTrade,    // b1 fills against s1
+        enginepkg.EventAmended,                           // s2 down to 30
+        enginepkg.EventAccepted, enginepkg.EventTrade,    // b2 takes 30...
+        enginepkg.EventCancelled,                         // ...and the IOC remainder goes
+        enginepkg.EventAccepted, enginepkg.EventExpired,  // b3
+        enginepkg.EventCancelled,                         // s1
+    }
+    if !assert.Len(events, len(expected)) {
+        return
+    }
+    for i, ev := range events {
+        assert.Equal(uint64(i+1), ev.Sequence, "event %d", i)
+        assert.Equal(expected[i], ev.Type, "event %d", i)
+        if ev.Type == enginepkg.EventTrade {
+            assert.Equal(ev.Sequence, ev.Trade.EventSeq)
+        } else {
+            assert.NotNil(ev.Order)
+        }
+    }
+    assert.Equal(enginepkg.ReasonUnfilled, events[7].Order.CancelReason)
+    assert.Equal(int64(30), events[4].Order.Quantity)
+    assert.Equal(uint64(len(expected)), eng.EventSequence())
+
+    // Trade history carries the same sequence as the event
+    trades := eng.GetTrades("AAPL", 0, 0)
+    assert.Equal(1, len(trades))
+    assert.Equal(uint64(4), trades[0].EventSeq)
+
+    // A restored engine continues the sequence
+    var buf bytes.Buffer
+    assert.NoError(eng.Snapshot(&buf))
+    restored := setupEngine()
+    assert.NoError(restored.LoadSnapshot(&buf))
+    var next []enginepkg.OrderEvent
+    restored.SetEventHook(func(ev enginepkg.OrderEvent) { next = append(next, ev) })
+    _, _ = restored.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1005))
+    if assert.Len(next, 1) {
+        assert.Equal(uint64(len(expected)+1), next[0].Sequence)
+    }
+}
+
+// TestEventSequence_ConcurrentSymbols checks the hook sees events in sequence
+// order with no gaps while several symbols trade at once
+func TestEventSequence_ConcurrentSymbols(t *testing.T) {
+    eng := setupEngine()
+
+    var seqs []uint64
+    eng.SetEventHook(func(ev enginepkg.OrderEvent) { seqs = append(seqs, ev.Sequence) })
+
+    var wg sync.WaitGroup
+    for _, symbol := range []string{"AAPL", "MSFT", "GOOG", "AMZN"} {
+        wg.Add(1)
+        go func(symbol string) {
+            defer wg.Done()
+            for i := 0; i < 50; i++ {
+                _, _ = eng.SubmitOrder(newTestOrder(fmt.Sprintf("%s-s%d", symbol, i), symbol, enginepkg.Sell, enginepkg.Limit, 10000, 10, int64(i)))
+                _, _ = eng.SubmitOrder(newTestOrder(fmt.Sprintf("%s-b%d", symbol, i), symbol, enginepkg.Buy, enginepkg.Limit, 10000, 5, int64(i)))
+                _, _ = eng.CancelOrder(fmt.Sprintf("%s-s%d", symbol, i))
+            }
+        }(symbol)
+    }
+    wg.Wait()
+
+    // Per symbol and iteration: 2 accepts, 1 trade, 1 cancel
+    assert.Equal(t, 4*50*4, len(seqs))
+    for i, seq := range seqs {
+        if seq != uint64(i+1) {
+            t.Fatalf("event %d has sequence %d", i, seq)
+        }
+    }
+}
+
+// TestSubscribe_ReceivesTradeAfterCross checks a subscriber is called off the
+// matching path with the events of a cross, in sequence order, and hears
+// nothing after unsubscribing
+func TestSubscribe_ReceivesTradeAfterCross(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    received := make(chan enginepkg.OrderEvent, 16)
+    unsubscribe := eng.Subscribe(func(ev enginepkg.OrderEvent) { received <- ev })
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000))
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1001))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+
+    var got []enginepkg.OrderEvent
+    for len(got) < 3 {
+        select {
+        case ev := <-received:
+            got = append(got, ev)
+        case <-time.After(time.Second):
+            t.Fatalf("timed out after %d events", len(got))
+        }
+    }
+    assert.Equal([]enginepkg.OrderEventType{enginepkg.EventAccepted, enginepkg.EventAccepted, enginepkg.EventTrade},
+        []enginepkg.OrderEventType{got[0].Type, got[1].Type, got[2].Type})
+    assert.Equal(got[0].Sequence+2, got[2].Sequence)
+    assert.Equal(resp.Trades[0].TradeID, got[2].Trade.TradeID)
+    assert.Equal("AAPL", got[2].Symbol)
+
+    unsubscribe()
+    unsubscribe() // Idempotent
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1002))
+    select {
+    case ev := <-received:
+        t.Fatalf("event after unsubscribe: %+v", ev)
+    case <-time.After(50 * time.Millisecond):
+    }
+}
+
+// TestSubscribe_SlowHandlerDoesNotBlockMatching checks a blocked handler
+// neither stalls the engine nor loses the events queued behind it
+func TestSubscribe_SlowHandlerDoesNotBlockMatching(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    release := make(chan struct{})
+    var mu sync.Mutex
+    var seqs []uint64
+    unsubscribe := eng.Subscribe(func(ev enginepkg.OrderEvent) {
+        <-release
+        mu.Lock()
+        seqs = append(seqs, ev.Sequence)
+        mu.Unlock()
+    })
+    defer unsubscribe()
+
+    for i := 0; i < 100; i++ {
+        _, err := eng.SubmitOrder(newTestOrder(fmt.Sprintf("b%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 1, int64(1000+i)))
+        assert.NoError(err)
+    }
+    close(release)
+
+    assert.Eventually(func() bool {
+        mu.Lock()
+        defer mu.Unlock()
+        return len(seqs) == 100
+    }, time.Second, 5*time.Millisecond)
+    for i := range seqs {
+        assert.Equal(uint64(i+1), seqs[i])
+    }
+}
+
+// TestSubscribe_DropsSlowConsumer checks a subscriber too far behind is
+// dropped instead of queueing without bound, while matching carries on
+func TestSubscribe_DropsSlowConsumer(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    release := make(chan struct{})
+    var mu sync.Mutex
+    var seqs []uint64
+    unsubscribe := eng.Subscribe(func(ev enginepkg.OrderEvent) {
+        <-release
+        mu.Lock()
+        seqs = append(seqs, ev.Sequence)
+        mu.Unlock()
+    })
+    defer unsubscribe()
+
+    for i := 0; i < 1000; i++ {
+        _, err := eng.SubmitOrder(newTestOrder(fmt.Sprintf("b%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 1, int64(1000+i)))
+        assert.NoError(err)
+    }
+    close(release)
+
+    time.Sleep(50 * time.Millisecond)
+    mu.Lock()
+    defer mu.Unlock()
+    assert.Less(len(seqs), 1000, "dropped before the backlog was delivered")
+    for i := range seqs {
+        assert.Equal(uint64(i+1), seqs[i], "what was delivered has no gaps")
+    }
+}
+
+// TestEvents_FeedTradesStatsAndMetrics checks the trade feed, GlobalStats
+// and the cancellation metrics follow the event stream
+func TestEvents_FeedTradesStatsAndMetrics(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    sub := eng.SubscribeTrades("AAPL")
+    defer sub.Close()
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 10, 1002))
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 4, 1003))
+    assert.NoError(err)
+
+    select {
+    case trade := <-sub.C:
+        assert.Equal(resp.Trades[0].TradeID, trade.TradeID)
+        assert.Equal(resp.Trades[0].EventSeq, trade.EventSeq)
+    case <-time.After(time.Second):
+        t.Fatal("no trade published")
+    }
+    stats := eng.GlobalStats()
+    assert.Equal(int64(1), stats.TradesExecuted)
+    assert.Equal(int64(4), stats.Volume)
+
+    _, _ = eng.CancelOrder("s2")
+    _, _ = eng.CancelAll("AAPL", "")
+    families, err := eng.Metrics().Gather()
+    assert.NoError(err)
+    cancelled := map[string]float64{}
+    for _, family := range families {
+        if family.GetName() != "ome_orders_cancelled_total" {
+            continue
+        }
+        for _, m := range family.GetMetric() {
+            cancelled[m.GetLabel()[0].GetValue()] = m.GetCounter().GetValue()
+        }
+    }
+    assert.Equal(map[string]float64{"cancel": 1, "cancel_all": 2}, cancelled)
+}
@@ -0,0 +1,55 @@
+package engine_test
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestGTD_ReaperRemovesExpiredOrder checks a short-lived order leaves the book after its deadline
+func TestGTD_ReaperRemovesExpiredOrder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    stop := eng.StartExpiryReaper(5 * time.Millisecond)
+    defer stop()
+
+    gtd := newTestOrder("gtd-buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 15040, 100, 1000)
+    gtd.ExpiresAt = time.Now().Add(30*time.Millisecond).UnixNano() / 1_000_000
+    _, _ = eng.SubmitOrder(gtd)
+    _, _ = eng.SubmitOrder(newTestOrder("gtc-buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 15030, 100, 1001))
+
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal(2, len(bids))
+
+    assert.Eventually(func() bool {
+        bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+        return len(bids) == 1
+    }, time.Second, 5*time.Millisecond)
+
+    status, _ := eng.GetOrderStatus("gtd-buy")
+    assert.Equal(enginepkg.StatusCancelled, status.Status)
+    assert.Equal(enginepkg.ReasonExpired, status.CancelReason)
+
+    status, _ = eng.GetOrderStatus("gtc-buy")
+    assert.Equal(enginepkg.StatusAccepted, status.Status)
+}
+
+// TestExpireOrders_ArmedStop checks dormant stops expire too
+func TestExpireOrders_ArmedStop(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    stop := newTestOrder("stop-sell", "AAPL", enginepkg.Sell, enginepkg.Stop, 0, 100, 1000)
+    stop.StopPrice = 14000
+    stop.ExpiresAt = 5000
+    _, _ = eng.SubmitOrder(stop)
+
+    expired := eng.ExpireOrders(time.UnixMilli(4999))
+    assert.Equal(0, len(expired))
+    expired = eng.ExpireOrders(time.UnixMilli(5000))
+    assert.Equal(1, len(expired))
+    assert.Equal("stop-sell", expired[0].ID)
+}
@@ -0,0 +1,111 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func feeEngine(t *testing.T) *enginepkg.MatchingEngine {
+    eng := setupEngine()
+    err := eng.ConfigureSymbol(enginepkg.SymbolConfig{
+        Symbol: "AAPL",
+        Fees:   enginepkg.FeeSchedule{TakerFeeBps: 10, MakerRebateBps: 2},
+    })
+    assert.NoError(t, err)
+    return eng
+}
+
+func TestFees_TakerFeeAndMakerRebate(t *testing.T) {
+    eng := feeEngine(t)
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(accountOrder("s1", "maker", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    resp, err := eng.SubmitOrder(accountOrder("b1", "taker", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+
+    // Notional 1,000,000: 10 bps taker fee, 2 bps maker rebate
+    assert.Equal(int64(1000), resp.Trades[0].AggressorFee)
+    assert.Equal(int64(-200), resp.Trades[0].RestingFee)
+
+    taker := eng.GetPositions("taker")[0]
+    assert.Equal(int64(1000), taker.Fees)
+    assert.Equal(int64(-1000), taker.RealizedPnL)
+    maker := eng.GetPositions("maker")[0]
+    assert.Equal(int64(-200), maker.Fees)
+    assert.Equal(int64(200), maker.RealizedPnL)
+}
+
+func TestFees_RoundingFavoursExchange(t *testing.T) {
+    eng := feeEngine(t)
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10001, 3, 1000))
+    resp, _ := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 3, 1001))
+
+    // Notional 30003: taker 30.003 rounds up, rebate 6.0006 rounds down
+    assert.Equal(int64(31), resp.Trades[0].AggressorFee)
+    assert.Equal(int64(-6), resp.Trades[0].RestingFee)
+}
+
+func TestFees_NoScheduleChargesNothing(t *testing.T) {
+    eng := setupEngine()
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    resp, _ := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+
+    assert.Equal(t, int64(0), resp.Trades[0].AggressorFee)
+    assert.Equal(t, int64(0), resp.Trades[0].RestingFee)
+}
+
+func TestFees_VolumeTierLowersNextTakerFee(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{
+        Symbol: "AAPL",
+        Fees: enginepkg.FeeSchedule{TakerFeeBps: 10, MakerRebateBps: 2, Tiers: []enginepkg.FeeTier{
+            {MinVolume: 150, TakerFeeBps: 5, MakerRebateBps: 3},
+            {MinVolume: 1000, TakerFeeBps: 0, MakerRebateBps: 0},
+        }},
+    }))
+
+    // 100 traded: still below the first tier, so the base rates apply
+    _, _ = eng.SubmitOrder(accountOrder("s1", "maker", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    resp, _ := eng.SubmitOrder(accountOrder("b1", "taker", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+    assert.Equal(int64(1000), resp.Trades[0].AggressorFee)
+
+    // The trade that crosses 150 is still priced at the base rate...
+    _, _ = eng.SubmitOrder(accountOrder("s2", "maker", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1002))
+    resp, _ = eng.SubmitOrder(accountOrder("b2", "taker", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1003))
+    assert.Equal(int64(1000), resp.Trades[0].AggressorFee)
+
+    // ...and the next one at the tier, for the maker too
+    _, _ = eng.SubmitOrder(accountOrder("s3", "maker", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1004))
+    resp, _ = eng.SubmitOrder(accountOrder("b3", "taker", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1005))
+    assert.Equal(int64(500), resp.Trades[0].AggressorFee)
+    assert.Equal(int64(-300), resp.Trades[0].RestingFee)
+
+    fees := eng.GetAccountFees("taker")
+    assert.Len(fees, 1)
+    assert.Equal(int64(300), fees[0].Volume)
+    assert.Equal(enginepkg.FeeTier{MinVolume: 150, TakerFeeBps: 5, MakerRebateBps: 3}, fees[0].Tier)
+    assert.Empty(eng.GetAccountFees("nobody"))
+}
+
+func TestFees_TiersMustAscend(t *testing.T) {
+    eng := setupEngine()
+    err := eng.ConfigureSymbol(enginepkg.SymbolConfig{
+        Symbol: "AAPL",
+        Fees: enginepkg.FeeSchedule{TakerFeeBps: 10, Tiers: []enginepkg.FeeTier{
+            {MinVolume: 1000, TakerFeeBps: 5},
+            {MinVolume: 500, TakerFeeBps: 2},
+        }},
+    })
+    assert.Error(t, err)
+
+    tiers := enginepkg.FeeSchedule{TakerFeeBps: 10, Tiers: []enginepkg.FeeTier{{MinVolume: 500, TakerFeeBps: 5}}}
+    assert.Equal(t, int64(10), tiers.Tier(499).TakerFeeBps)
+    assert.Equal(t, int64(5), tiers.Tier(500).TakerFeeBps)
+}
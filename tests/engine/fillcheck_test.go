@@ -0,0 +1,95 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestFillCheck_RollbackLeavesBookUntouched(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    eng.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+
+    iceberg := newTestOrder("ice", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 300, 1001)
+    iceberg.DisplayQuantity = 50
+    hidden := newTestOrder("hidden", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 40, 1002)
+    hidden.Hidden = true
+    for _, o := range []*enginepkg.Order{
+        newTestOrder("first", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000),
+        iceberg,
+        hidden,
+        newTestOrder("outer", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1003),
+        newTestOrder("back", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 60, 1004),
+    } {
+        _, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+    }
+    before := eng.GetBookSnapshot("AAPL", 0)
+    ahead, _, _ := eng.GetQueuePosition("back")
+    var events int
+    unsubscribe := eng.Subscribe(func(enginepkg.OrderEvent) { events++ })
+    defer unsubscribe()
+
+    // Both walk the whole book before falling short
+    _, err := eng.SubmitOrder(newTestOrder("mkt", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 1000, 1005))
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+    assert.Contains(err.Error(), "only 600 shares available")
+    fok := newTestOrder("fok", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 550, 1006)
+    fok.TimeInForce = enginepkg.FOK
+    _, err = eng.SubmitOrder(fok)
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+
+    assert.Equal(before, eng.GetBookSnapshot("AAPL", 0))
+    aheadAfter, _, err := eng.GetQueuePosition("back")
+    assert.NoError(err)
+    assert.Equal(ahead, aheadAfter)
+    for _, id := range []string{"first", "ice", "hidden", "outer", "back"} {
+        o, err := eng.GetOrderStatus(id)
+        assert.NoError(err)
+        assert.Equal(int64(0), o.FilledQuantity, id)
+        assert.Equal(enginepkg.StatusAccepted, o.Status, id)
+    }
+    status, _ := eng.GetOrderStatus("ice")
+    assert.Equal(int64(50), status.VisibleQuantity)
+    assert.Empty(eng.GetTrades("AAPL", 0, 0))
+    _, traded := eng.GetLastTrade("AAPL")
+    assert.False(traded)
+    assert.Zero(events, "a rolled back match publishes nothing")
+
+    // The queue is intact: the next order trades in the original time
+    // priority (hidden orders queue behind displayed ones), with the first
+    // trade ID
+    resp, err := eng.SubmitOrder(newTestOrder("mkt-2", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 170, 1007))
+    assert.NoError(err)
+    assert.Len(resp.Trades, 3)
+    assert.Equal("trade-1", resp.Trades[0].TradeID)
+    assert.Equal([]string{"first", "ice", "back"}, []string{resp.Trades[0].RestingOrderID, resp.Trades[1].RestingOrderID, resp.Trades[2].RestingOrderID})
+    assert.Equal(int64(20), resp.Trades[2].Quantity)
+}
+
+func TestFillCheck_ProtectionPriceStillCountsTheWholeSide(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("near", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("far", "AAPL", enginepkg.Sell, enginepkg.Limit, 10500, 100, 1001))
+
+    // Enough liquidity on the side, so the order is accepted; it stops at
+    // its protection price and the rest is cancelled
+    mkt := newTestOrder("mkt", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 150, 1002)
+    mkt.ProtectionPrice = 10200
+    resp, err := eng.SubmitOrder(mkt)
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    assert.Equal(enginepkg.StatusPartialFill, mkt.Status)
+    assert.Equal(enginepkg.ReasonUnfilled, mkt.CancelReason)
+
+    // Not enough anywhere: rolled back, so "far" is untouched
+    short := newTestOrder("short", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 150, 1003)
+    _, err = eng.SubmitOrder(short)
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10500, Quantity: 100}}, asks)
+}
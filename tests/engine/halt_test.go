@@ -0,0 +1,64 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestHalt_RejectMode(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    assert.NoError(eng.Halt("AAPL", enginepkg.HaltReject))
+    assert.Error(eng.Halt("AAPL", enginepkg.HaltReject))
+
+    _, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1001))
+    assert.ErrorContains(err, "trading halted")
+    _, _, err = eng.AmendOrder("s1", 10100, 0)
+    assert.ErrorContains(err, "trading halted")
+
+    // Other symbols keep trading
+    _, err = eng.SubmitOrder(newTestOrder("m1", "MSFT", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1002))
+    assert.NoError(err)
+
+    trades, err := eng.Resume("AAPL")
+    assert.NoError(err)
+    assert.Empty(trades)
+    _, err = eng.Resume("AAPL")
+    assert.Error(err)
+
+    resp, err := eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1003))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+}
+
+func TestHalt_QueueModeSuppressesMatching(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 100, 1001))
+    assert.NoError(eng.Halt("AAPL", enginepkg.HaltQueue))
+
+    // Crossing buy is booked, not matched
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 60, 1002))
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    assert.True(resp.OrderInBook)
+
+    // Cancels still work during a halt
+    _, err = eng.CancelOrder("s2")
+    assert.NoError(err)
+
+    trades, err := eng.Resume("AAPL")
+    assert.NoError(err)
+    assert.Equal(1, len(trades))
+    assert.Equal(int64(60), trades[0].Quantity)
+    assert.Equal(enginepkg.Continuous, eng.GetTradingPhase("AAPL"))
+
+    s1, _ := eng.GetOrderStatus("s1")
+    assert.Equal(int64(60), s1.FilledQuantity)
+}
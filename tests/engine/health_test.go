@@ -0,0 +1,44 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestHealth_States(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    h := eng.Health()
+    assert.Equal(enginepkg.HealthHealthy, h.Status)
+    assert.True(h.Ready)
+
+    assert.NoError(eng.Halt("MSFT", enginepkg.HaltReject))
+    h = eng.Health()
+    assert.Equal(enginepkg.HealthDegraded, h.Status)
+    assert.True(h.Ready)
+    assert.Equal([]string{"MSFT"}, h.HaltedSymbols)
+
+    _, err := eng.KillSwitch()
+    assert.NoError(err)
+    h = eng.Health()
+    assert.Equal(enginepkg.HealthUnavailable, h.Status)
+    assert.False(h.Ready)
+    assert.True(h.Killed)
+
+    // Reset keeps the operator's halt, so the engine stays degraded until it resumes
+    assert.NoError(eng.Reset())
+    h = eng.Health()
+    assert.Equal(enginepkg.HealthDegraded, h.Status)
+    assert.Equal([]string{"MSFT"}, h.HaltedSymbols)
+    _, err = eng.Resume("MSFT")
+    assert.NoError(err)
+    assert.Equal(enginepkg.HealthHealthy, eng.Health().Status)
+
+    eng.Shutdown()
+    h = eng.Health()
+    assert.Equal(enginepkg.HealthUnavailable, h.Status)
+    assert.True(h.ShuttingDown)
+}
@@ -0,0 +1,63 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func hiddenOrder(id string, side enginepkg.Side, price, qty, ts int64) *enginepkg.Order {
+    order := newTestOrder(id, "AAPL", side, enginepkg.Limit, price, qty, ts)
+    order.Hidden = true
+    return order
+}
+
+func TestHidden_OmittedFromDepthButMatches(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(hiddenOrder("h1", enginepkg.Sell, 10000, 100, 1000))
+    assert.NoError(err)
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 50, 1001))
+
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Empty(bids)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10100, Quantity: 50}}, asks)
+    _, ask, _ := eng.GetBBO("AAPL")
+    assert.Equal(int64(10100), ask.Price)
+
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 1002))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal("h1", resp.Trades[0].RestingOrderID)
+    assert.Equal(int64(10000), resp.Trades[0].Price)
+    assert.Equal(int64(40), resp.Trades[0].Quantity)
+}
+
+func TestHidden_DisplayedFirstWithinLevel(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    // The hidden order arrived first but still queues behind displayed ones
+    _, _ = eng.SubmitOrder(hiddenOrder("h1", enginepkg.Sell, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("d1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 30, 1001))
+    _, _ = eng.SubmitOrder(hiddenOrder("h2", enginepkg.Sell, 10000, 100, 1002))
+    _, _ = eng.SubmitOrder(newTestOrder("d2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 20, 1003))
+
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 50}}, asks)
+
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 200, 1004))
+    assert.NoError(err)
+    var got []string
+    for _, trade := range resp.Trades {
+        got = append(got, trade.RestingOrderID)
+    }
+    assert.Equal([]string{"d1", "d2", "h1", "h2"}, got)
+    assert.Equal(int64(50), resp.Trades[3].Quantity)
+
+    // The remaining hidden quantity still shows nothing
+    _, asks = eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Empty(asks)
+}
@@ -0,0 +1,43 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestOrderHistory_FillsAndAmendments(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("r1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 0))
+    assert.NoError(err)
+    _, _, err = eng.AmendOrder("r1", 0, 60)
+    assert.NoError(err)
+    resp, err := eng.SubmitOrder(newTestOrder("t1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 25, 1))
+    assert.NoError(err)
+    resp2, err := eng.SubmitOrder(newTestOrder("t2", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 35, 2))
+    assert.NoError(err)
+
+    // The filled order is out of the book but its history is kept
+    history, err := eng.GetOrderHistory("r1")
+    assert.NoError(err)
+    var types []enginepkg.OrderEventType
+    for _, ev := range history {
+        types = append(types, ev.Type)
+    }
+    assert.Equal([]enginepkg.OrderEventType{enginepkg.EventAccepted, enginepkg.EventAmended, enginepkg.EventTrade, enginepkg.EventTrade}, types)
+    assert.Equal(int64(60), history[1].Order.Quantity)
+    assert.Equal(resp.Trades[0].TradeID, history[2].Trade.TradeID)
+    assert.Equal(resp2.Trades[0].TradeID, history[3].Trade.TradeID)
+
+    // The aggressor's history has its own acceptance and fill
+    history, err = eng.GetOrderHistory("t2")
+    assert.NoError(err)
+    assert.Len(history, 2)
+    assert.Equal(enginepkg.EventTrade, history[1].Type)
+
+    _, err = eng.GetOrderHistory("missing")
+    assert.ErrorIs(err, enginepkg.ErrOrderNotFound)
+}
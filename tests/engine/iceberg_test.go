@@ -0,0 +1,60 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func newIceberg(id string, side enginepkg.Side, price, quantity, display int64, ts int64) *enginepkg.Order {
+    o := newTestOrder(id, "AAPL", side, enginepkg.Limit, price, quantity, ts)
+    o.DisplayQuantity = display
+    return o
+}
+
+// TestIceberg_ReplenishesBehindOtherOrders checks a 10,000 iceberg shows 1,000 and re-queues after each slice
+func TestIceberg_ReplenishesBehindOtherOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newIceberg("ice", enginepkg.Sell, 15050, 10000, 1000, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("plain", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 500, 1001))
+
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15050, Quantity: 1500}}, asks, "only the display slice is visible")
+
+    resp, err := eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 1500, 1002))
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal("ice", resp.Trades[0].RestingOrderID)
+    assert.Equal(int64(1000), resp.Trades[0].Quantity)
+    assert.Equal("plain", resp.Trades[1].RestingOrderID, "replenished iceberg goes behind the plain order")
+    assert.Equal(int64(500), resp.Trades[1].Quantity)
+
+    // Only the iceberg is left: it fills slice by slice within a single sweep
+    resp, _ = eng.SubmitOrder(newTestOrder("buy-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 2500, 1003))
+    assert.Equal(3, len(resp.Trades))
+    assert.Equal(int64(1000), resp.Trades[0].Quantity)
+    assert.Equal(int64(1000), resp.Trades[1].Quantity)
+    assert.Equal(int64(500), resp.Trades[2].Quantity)
+
+    status, _ := eng.GetOrderStatus("ice")
+    assert.Equal(int64(6500), status.RemainingQuantity())
+    _, asks = eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal(int64(500), asks[0].Quantity)
+}
+
+// TestIceberg_ReserveCountsForMarketLiquidity checks hidden reserve can still be taken by a market order
+func TestIceberg_ReserveCountsForMarketLiquidity(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newIceberg("ice", enginepkg.Sell, 15050, 3000, 1000, 1000))
+    resp, err := eng.SubmitOrder(newTestOrder("mkt", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 3000, 1001))
+
+    assert.NoError(err)
+    assert.Equal(3, len(resp.Trades))
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal(0, len(asks))
+}
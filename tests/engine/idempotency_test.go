@@ -0,0 +1,120 @@
+package engine_test
+
+import (
+    "fmt"
+    "io"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestIdempotency_CleanRetryReturnsExistingOrder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 40, 1000))
+    _, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+    assert.NoError(err)
+
+    retry := newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1002)
+    resp, err := eng.SubmitOrder(retry)
+    assert.NoError(err)
+    assert.Empty(resp.Trades, "a retry must not trade again")
+    assert.True(resp.OrderInBook)
+    assert.Equal(enginepkg.StatusPartialFill, retry.Status)
+    assert.Equal(int64(40), retry.FilledQuantity)
+    assert.Equal(int64(1001), retry.Timestamp)
+
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 60}}, bids)
+}
+
+func TestIdempotency_ConflictingReuseIsRejected(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("o1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    assert.NoError(err)
+
+    _, err = eng.SubmitOrder(newTestOrder("o1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 100, 1001))
+    assert.ErrorContains(err, "duplicate order id")
+    _, err = eng.SubmitOrder(newTestOrder("o1", "MSFT", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1002))
+    assert.ErrorContains(err, "duplicate order id")
+
+    stored, _ := eng.GetOrderStatus("o1")
+    assert.Equal(int64(10000), stored.Price)
+}
+
+func TestIdempotency_DuplicateLeavesFirstOrderIntact(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    first := newTestOrder("dup", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000)
+    _, err := eng.SubmitOrder(first)
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("dup", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 50, 1001))
+    assert.ErrorIs(err, enginepkg.ErrDuplicateOrderID)
+
+    stored, _ := eng.GetOrderStatus("dup")
+    assert.Equal(enginepkg.Sell, stored.Side)
+    assert.Equal(enginepkg.StatusAccepted, stored.Status)
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Empty(bids, "the duplicate never reached the book")
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 100}}, asks)
+
+    // The ID still reaches the first order's book entry
+    cancelled, err := eng.CancelOrder("dup")
+    assert.NoError(err)
+    assert.Equal(enginepkg.Sell, cancelled.Side)
+    _, asks = eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Empty(asks)
+}
+
+func TestIdempotency_SameIDOnManyBooksAtOnce(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    // Different books do not share a lock, so only the order store can
+    // keep these apart. A slow WAL holds every submission between its
+    // duplicate check and storing the order.
+    eng.SetWAL(enginepkg.NewJSONWAL(slowWriter{io.Discard}))
+    const books = 16
+    var wg sync.WaitGroup
+    var mu sync.Mutex
+    accepted := []string{}
+    for i := 0; i < books; i++ {
+        wg.Add(1)
+        go func(symbol string) {
+            defer wg.Done()
+            if _, err := eng.SubmitOrder(newTestOrder("same", symbol, enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000)); err == nil {
+                mu.Lock()
+                accepted = append(accepted, symbol)
+                mu.Unlock()
+            } else {
+                assert.ErrorIs(err, enginepkg.ErrDuplicateOrderID)
+            }
+        }(fmt.Sprintf("SYM%d", i))
+    }
+    wg.Wait()
+
+    assert.Len(accepted, 1)
+    stored, err := eng.GetOrderStatus("same")
+    assert.NoError(err)
+    assert.Equal(accepted[0], stored.Symbol)
+    for i := 0; i < books; i++ {
+        symbol := fmt.Sprintf("SYM%d", i)
+        if bids, _ := eng.GetOrderBookSnapshot(symbol, 0); symbol != stored.Symbol {
+            assert.Empty(bids, symbol)
+        }
+    }
+}
+
+type slowWriter struct{ io.Writer }
+
+func (w slowWriter) Write(p []byte) (int, error) {
+    time.Sleep(time.Millisecond)
+    return w.Writer.Write(p)
+}
@@ -0,0 +1,87 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+
+    "github.com/google/uuid"
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestSequentialIDGenerator_TradeIDs(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    eng.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 50, 1001))
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 100, 1002))
+    assert.NoError(err)
+    assert.Len(resp.Trades, 2)
+    assert.Equal("trade-1", resp.Trades[0].TradeID)
+    assert.Equal("trade-2", resp.Trades[1].TradeID)
+
+    // The counter is engine-wide, across symbols
+    _, _ = eng.SubmitOrder(newTestOrder("m1", "MSFT", enginepkg.Sell, enginepkg.Limit, 30000, 10, 1003))
+    resp, _ = eng.SubmitOrder(newTestOrder("m2", "MSFT", enginepkg.Buy, enginepkg.Limit, 30000, 10, 1004))
+    assert.Equal("trade-3", resp.Trades[0].TradeID)
+
+    assert.Equal("order-1", eng.NewOrderID())
+    assert.Equal("order-2", eng.NewOrderID())
+}
+
+func TestIDGenerator_DefaultsToUUID(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1000))
+    resp, _ := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1001))
+    _, err := uuid.Parse(resp.Trades[0].TradeID)
+    assert.NoError(err)
+    _, err = uuid.Parse(eng.NewOrderID())
+    assert.NoError(err)
+
+    // Set then cleared: back to UUIDs
+    eng.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+    eng.SetIDGenerator(nil)
+    _, err = uuid.Parse(eng.NewOrderID())
+    assert.NoError(err)
+}
+
+func TestSequentialIDGenerator_ContinuesAfterRecovery(t *testing.T) {
+    assert := assert.New(t)
+    var log, snap bytes.Buffer
+    original := setupEngine()
+    original.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    for i := 0; i < 3; i++ {
+        sell := newTestOrder(original.NewOrderID(), "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000)
+        _, _ = original.SubmitOrder(sell)
+        buy := newTestOrder(original.NewOrderID(), "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 5, 1001)
+        _, _ = original.SubmitOrder(buy)
+    }
+    assert.NoError(original.Snapshot(&snap))
+
+    // Replayed onto an engine counting from zero
+    recovered := setupEngine()
+    recovered.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    assert.Equal("order-7", recovered.NewOrderID())
+    resp, err := recovered.SubmitOrder(newTestOrder("more", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 1, 1002))
+    assert.NoError(err)
+    assert.Equal("trade-4", resp.Trades[0].TradeID)
+
+    // Restored from a snapshot, with the generator set before or after
+    restored := setupEngine()
+    restored.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+    assert.NoError(restored.LoadSnapshot(bytes.NewReader(snap.Bytes())))
+    resp, err = restored.SubmitOrder(newTestOrder("more", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 1, 1002))
+    assert.NoError(err)
+    assert.Equal("trade-4", resp.Trades[0].TradeID)
+    late := setupEngine()
+    assert.NoError(late.LoadSnapshot(bytes.NewReader(snap.Bytes())))
+    late.SetIDGenerator(&enginepkg.SequentialIDGenerator{})
+    assert.Equal("order-7", late.NewOrderID())
+}
@@ -0,0 +1,73 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestKillSwitch_FlattensAndRefusesOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("m1", "MSFT", enginepkg.Buy, enginepkg.Limit, 20000, 50, 1002))
+    stop := newTestOrder("st1", "MSFT", enginepkg.Sell, enginepkg.Stop, 0, 10, 1003)
+    stop.StopPrice = 19000
+    _, _ = eng.SubmitOrder(stop)
+
+    cancelled, err := eng.KillSwitch()
+    assert.NoError(err)
+    assert.Equal(4, cancelled)
+    assert.True(eng.Killed())
+
+    for _, symbol := range []string{"AAPL", "MSFT"} {
+        bids, asks := eng.GetOrderBookSnapshot(symbol, 10)
+        assert.Empty(bids)
+        assert.Empty(asks)
+        assert.Equal(enginepkg.Halted, eng.GetTradingPhase(symbol))
+    }
+    order, _ := eng.GetOrderStatus("s1")
+    assert.Equal(enginepkg.StatusCancelled, order.Status)
+    assert.Equal(enginepkg.ReasonKillSwitch, order.CancelReason)
+
+    // Known and never-seen symbols alike refuse orders, and Resume cannot bypass the switch
+    _, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1004))
+    assert.ErrorContains(err, "trading halted")
+    _, err = eng.SubmitOrder(newTestOrder("g1", "GOOG", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1005))
+    assert.ErrorContains(err, "trading halted")
+    _, err = eng.Resume("AAPL")
+    assert.Error(err)
+
+    assert.NoError(eng.Reset())
+    assert.Error(eng.Reset())
+    assert.False(eng.Killed())
+    assert.Equal(enginepkg.Continuous, eng.GetTradingPhase("AAPL"))
+    _, err = eng.SubmitOrder(newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1006))
+    assert.NoError(err)
+}
+
+func TestKillSwitch_ResetKeepsOperatorHalts(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("m1", "MSFT", enginepkg.Buy, enginepkg.Limit, 20000, 10, 1001))
+    assert.NoError(eng.Halt("MSFT", enginepkg.HaltQueue))
+
+    _, err := eng.KillSwitch()
+    assert.NoError(err)
+    assert.NoError(eng.Reset())
+
+    // Only the halt the kill switch imposed is lifted
+    assert.Equal(enginepkg.Continuous, eng.GetTradingPhase("AAPL"))
+    assert.Equal(enginepkg.Halted, eng.GetTradingPhase("MSFT"))
+    resp, err := eng.SubmitOrder(newTestOrder("m2", "MSFT", enginepkg.Buy, enginepkg.Limit, 20000, 10, 1002))
+    assert.NoError(err, "the operator's QUEUE halt is back in force")
+    assert.True(resp.OrderInBook)
+    _, err = eng.Resume("MSFT")
+    assert.NoError(err)
+    assert.Equal(enginepkg.Continuous, eng.GetTradingPhase("MSFT"))
+}
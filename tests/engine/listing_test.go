@@ -0,0 +1,115 @@
+package engine_test
+
+import (
+    "bytes"
+    "sync"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestListSymbol_StrictRejectsUnlisted(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    eng.SetStrictSymbols(true)
+
+    assert.NoError(eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "MSFT", TickSize: 5}))
+    assert.NoError(eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}))
+    assert.ErrorIs(eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}), enginepkg.ErrInvalidState, "already listed")
+
+    listed := eng.ListedSymbols()
+    if assert.Equal(2, len(listed)) {
+        assert.Equal("AAPL", listed[0].Symbol)
+        assert.Equal(int64(5), listed[1].TickSize)
+    }
+
+    _, err := eng.SubmitOrder(newTestOrder("g1", "GOOG", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    assert.ErrorIs(err, enginepkg.ErrUnknownSymbol)
+    assert.Equal(0, eng.GlobalStats().Symbols, "no book is created for an unlisted symbol")
+
+    _, err = eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1001))
+    assert.NoError(err)
+}
+
+func TestDelistSymbol_CancelsOpenOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    eng.SetStrictSymbols(true)
+    assert.NoError(eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("a2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1001))
+    _, _ = eng.SubmitOrder(newStopOrder("stop", enginepkg.Buy, enginepkg.Stop, 0, 10200, 10, 1002))
+
+    cancelled, err := eng.DelistSymbol("AAPL")
+    assert.NoError(err)
+    assert.Equal(3, len(cancelled))
+    for _, id := range []string{"a1", "a2", "stop"} {
+        order, _ := eng.GetOrderStatus(id)
+        assert.Equal(enginepkg.StatusCancelled, order.Status)
+        assert.Equal(enginepkg.ReasonDelisted, order.CancelReason)
+    }
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Empty(bids)
+    assert.Empty(asks)
+    assert.Empty(eng.ListedSymbols())
+
+    _, err = eng.SubmitOrder(newTestOrder("a3", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1003))
+    assert.ErrorIs(err, enginepkg.ErrUnknownSymbol)
+    _, err = eng.DelistSymbol("AAPL")
+    assert.ErrorIs(err, enginepkg.ErrUnknownSymbol)
+
+    // Relisting opens the same book again
+    assert.NoError(eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}))
+    _, err = eng.SubmitOrder(newTestOrder("a3", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1004))
+    assert.NoError(err)
+}
+
+func TestListSymbol_ConcurrentListingsOneWins(t *testing.T) {
+    eng := setupEngine()
+
+    var wg sync.WaitGroup
+    errs := make(chan error, 20)
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            errs <- eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"})
+        }()
+    }
+    wg.Wait()
+    close(errs)
+    listed := 0
+    for err := range errs {
+        if err == nil {
+            listed++
+        } else {
+            assert.ErrorIs(t, err, enginepkg.ErrInvalidState)
+        }
+    }
+    assert.Equal(t, 1, listed)
+}
+
+func TestDelistSymbol_ReplaysCancelReason(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+    assert.NoError(original.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}))
+
+    _, _ = original.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = original.SubmitOrder(newTestOrder("a2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1001))
+    _, err := original.CancelOrder("a2")
+    assert.NoError(err)
+    _, err = original.DelistSymbol("AAPL")
+    assert.NoError(err)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    order, _ := recovered.GetOrderStatus("a1")
+    assert.Equal(enginepkg.StatusCancelled, order.Status)
+    assert.Equal(enginepkg.ReasonDelisted, order.CancelReason)
+    order, _ = recovered.GetOrderStatus("a2")
+    assert.Equal(enginepkg.ReasonRequested, order.CancelReason)
+}
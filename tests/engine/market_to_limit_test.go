@@ -0,0 +1,51 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestMarketToLimit_RestsAtLastTradedLevel(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("ask-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1001))
+
+    // Takes the 10000 level only, then rests the rest there as a limit order
+    mtl := newTestOrder("mtl", "AAPL", enginepkg.Buy, enginepkg.MarketToLimit, 0, 150, 1002)
+    resp, err := eng.SubmitOrder(mtl)
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    assert.Equal(int64(10000), resp.Trades[0].Price)
+    assert.Equal(int64(100), resp.Trades[0].Quantity)
+    assert.True(resp.OrderInBook)
+
+    status, err := eng.GetOrderStatus("mtl")
+    assert.NoError(err)
+    assert.Equal(enginepkg.Limit, status.Type)
+    assert.Equal(int64(10000), status.Price)
+    assert.Equal(enginepkg.StatusPartialFill, status.Status)
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 50}}, bids)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10100, Quantity: 100}}, asks)
+    assert.NoError(eng.Verify("AAPL"))
+}
+
+func TestMarketToLimit_RejectsWithoutLiquidity(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("mtl", "AAPL", enginepkg.Buy, enginepkg.MarketToLimit, 0, 100, 1000))
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Empty(bids)
+
+    _, _ = eng.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1001))
+    fok := newTestOrder("fok", "AAPL", enginepkg.Buy, enginepkg.MarketToLimit, 0, 50, 1002)
+    fok.TimeInForce = enginepkg.FOK
+    _, err = eng.SubmitOrder(fok)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+}
@@ -0,0 +1,54 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestMaxOrders_RejectsMakersAtCap(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", MaxOrders: 3}))
+
+    // Fill the book to the cap across both sides
+    _, err := eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("a2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1001))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, 1002))
+    assert.NoError(err)
+
+    // A new maker is refused and never stored
+    _, err = eng.SubmitOrder(newTestOrder("a3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1003))
+    assert.ErrorIs(err, enginepkg.ErrBookFull)
+    assert.Equal(enginepkg.CodeBookFull, enginepkg.Code(err))
+    _, err = eng.GetOrderStatus("a3")
+    assert.Error(err)
+
+    // So is a limit that would only partly fill and rest the remainder
+    _, err = eng.SubmitOrder(newTestOrder("big", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 30, 1004))
+    assert.ErrorIs(err, enginepkg.ErrBookFull)
+
+    // Takers that fill completely still trade
+    resp, err := eng.SubmitOrder(newTestOrder("t1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 5, 1005))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+    assert.False(resp.OrderInBook)
+    resp, err = eng.SubmitOrder(newTestOrder("t2", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 10, 1006))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+
+    // Filling b1 frees a slot for the next maker
+    _, err = eng.SubmitOrder(newTestOrder("a3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1007))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("a4", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1008))
+    assert.ErrorIs(err, enginepkg.ErrBookFull)
+
+    // Cancelling frees one too
+    _, err = eng.CancelOrder("a3")
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("a4", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1009))
+    assert.NoError(err)
+}
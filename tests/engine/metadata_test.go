@@ -0,0 +1,59 @@
+package engine_test
+
+import (
+    "bytes"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestClientMetadata_SurvivesSnapshotsAndGuardsRetries(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    order := newTestOrder("m1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    order.ClientMetadata = map[string]string{"strategy": "twap-3"}
+    _, err := eng.SubmitOrder(order)
+    assert.NoError(err)
+
+    var buf bytes.Buffer
+    assert.NoError(eng.Snapshot(&buf))
+    restored := setupEngine()
+    assert.NoError(restored.LoadSnapshot(&buf))
+    status, err := restored.GetOrderStatus("m1")
+    assert.NoError(err)
+    assert.Equal(map[string]string{"strategy": "twap-3"}, status.ClientMetadata)
+
+    // A retry must carry the same metadata
+    retry := newTestOrder("m1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    retry.ClientMetadata = map[string]string{"strategy": "twap-3"}
+    _, err = eng.SubmitOrder(retry)
+    assert.NoError(err)
+    conflict := newTestOrder("m1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    conflict.ClientMetadata = map[string]string{"strategy": "vwap"}
+    _, err = eng.SubmitOrder(conflict)
+    assert.ErrorIs(err, enginepkg.ErrDuplicateOrderID)
+}
+
+func TestClientMetadata_Bounded(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    tooMany := newTestOrder("many", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    tooMany.ClientMetadata = map[string]string{}
+    for i := 0; i <= enginepkg.MaxMetadataEntries; i++ {
+        tooMany.ClientMetadata[fmt.Sprintf("k%d", i)] = "v"
+    }
+    _, err := eng.SubmitOrder(tooMany)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    emptyKey := newTestOrder("empty", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001)
+    emptyKey.ClientMetadata = map[string]string{"": "v"}
+    _, err = eng.SubmitOrder(emptyKey)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    _, err = eng.GetOrderStatus("many")
+    assert.ErrorIs(err, enginepkg.ErrOrderNotFound)
+}
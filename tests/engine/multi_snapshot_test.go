@@ -0,0 +1,98 @@
+package engine_test
+
+import (
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestGetOrderBookSnapshots_ThreeSymbolsOneEmpty(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("a2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("m1", "MSFT", enginepkg.Sell, enginepkg.Limit, 30000, 50, 1002))
+
+    snaps := eng.GetOrderBookSnapshots([]string{"AAPL", "MSFT", "GOOG", "AAPL"}, 1)
+    assert.Equal(3, len(snaps))
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 100}}, snaps["AAPL"].Bids)
+    assert.Empty(snaps["AAPL"].Asks)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 30000, Quantity: 50}}, snaps["MSFT"].Asks)
+
+    goog, ok := snaps["GOOG"]
+    assert.True(ok)
+    assert.NotNil(goog.Bids)
+    assert.NotNil(goog.Asks)
+    assert.Empty(goog.Bids)
+}
+
+// TestGetBookSnapshot_TotalsIgnoreDepth checks the level and order totals
+// cover the whole book when depth truncates the levels returned
+func TestGetBookSnapshot_TotalsIgnoreDepth(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    // Five bid levels, two orders at the best; one ask level with two orders
+    for i := int64(0); i < 5; i++ {
+        _, _ = eng.SubmitOrder(newTestOrder(fmt.Sprintf("b%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, 10000-i*100, 10, 1000+i))
+    }
+    _, _ = eng.SubmitOrder(newTestOrder("b5", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1005))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10500, 10, 1006))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10500, 10, 1007))
+
+    // A hidden order is neither a displayed level nor a displayed order
+    _, _ = eng.SubmitOrder(hiddenOrder("s3", enginepkg.Sell, 10600, 10, 1008))
+
+    snap := eng.GetBookSnapshot("AAPL", 2)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 20}, {Price: 9900, Quantity: 10}}, snap.Bids)
+    assert.Equal(5, snap.TotalBidLevels)
+    assert.Equal(6, snap.TotalBidOrders)
+    assert.Equal(1, snap.TotalAskLevels)
+    assert.Equal(2, snap.TotalAskOrders)
+}
+
+// TestGetAggregatedSnapshot_GroupsTowardWorsePrices checks bids bucket down
+// and asks bucket up, so grouping never makes the book look crossed
+func TestGetAggregatedSnapshot_GroupsTowardWorsePrices(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    for i, price := range []int64{10005, 10003, 9999, 9990} {
+        _, _ = eng.SubmitOrder(newTestOrder(fmt.Sprintf("b%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, price, 10, 1000+int64(i)))
+    }
+    for i, price := range []int64{10011, 10014, 10019, 10021} {
+        _, _ = eng.SubmitOrder(newTestOrder(fmt.Sprintf("s%d", i), "AAPL", enginepkg.Sell, enginepkg.Limit, price, 10, 1010+int64(i)))
+    }
+
+    snap := eng.GetAggregatedSnapshot("AAPL", 0, 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 20}, {Price: 9990, Quantity: 20}}, snap.Bids)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10020, Quantity: 30}, {Price: 10030, Quantity: 10}}, snap.Asks)
+
+    // Depth counts buckets; totals and checksum still describe the raw book
+    snap = eng.GetAggregatedSnapshot("AAPL", 1, 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 20}}, snap.Bids)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10020, Quantity: 30}}, snap.Asks)
+    assert.Equal(4, snap.TotalBidLevels)
+    assert.Equal(eng.GetBookSnapshot("AAPL", 0).Checksum, snap.Checksum)
+
+    // A group of 1 is the plain snapshot
+    assert.Equal(eng.GetBookSnapshot("AAPL", 2), eng.GetAggregatedSnapshot("AAPL", 2, 1))
+}
+
+// TestGetAggregatedSnapshot_NegativePrices checks buckets floor and ceil
+// across zero rather than truncating toward it
+func TestGetAggregatedSnapshot_NegativePrices(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "SPREAD", AllowNegativePrice: true}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "SPREAD", enginepkg.Buy, enginepkg.Limit, -15, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "SPREAD", enginepkg.Sell, enginepkg.Limit, -5, 10, 1001))
+
+    snap := eng.GetAggregatedSnapshot("SPREAD", 0, 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: -20, Quantity: 10}}, snap.Bids)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 0, Quantity: 10}}, snap.Asks)
+}
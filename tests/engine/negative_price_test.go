@@ -0,0 +1,150 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestNegativePrice_MatchesAcrossZero checks a spread symbol rests and
+// matches negative prices in the right priority and rejects them elsewhere
+func TestNegativePrice_MatchesAcrossZero(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "CLZ5-CLF6", TickSize: 5, AllowNegativePrice: true}))
+
+    // Asks on both sides of zero: the lowest, most negative, is best
+    _, err := eng.SubmitOrder(newTestOrder("s10", "CLZ5-CLF6", enginepkg.Sell, enginepkg.Limit, 10, 10, 1000))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("s-60", "CLZ5-CLF6", enginepkg.Sell, enginepkg.Limit, -60, 10, 1001))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("s0", "CLZ5-CLF6", enginepkg.Sell, enginepkg.Limit, 0, 10, 1002))
+    assert.NoError(err, "zero is an ordinary price on a spread")
+
+    _, asks := eng.GetOrderBookSnapshot("CLZ5-CLF6", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: -60, Quantity: 10}, {Price: 0, Quantity: 10}, {Price: 10, Quantity: 10}}, asks)
+
+    // A buy at -50 crosses only the -60 ask, at the resting price
+    resp, err := eng.SubmitOrder(newTestOrder("b-50", "CLZ5-CLF6", enginepkg.Buy, enginepkg.Limit, -50, 15, 1003))
+    assert.NoError(err)
+    if assert.Equal(1, len(resp.Trades)) {
+        assert.Equal(int64(-60), resp.Trades[0].Price)
+        assert.Equal(int64(10), resp.Trades[0].Quantity)
+    }
+    assert.Equal(int64(-60), resp.VWAP())
+
+    // The remainder bids at -50, below the zero ask
+    bids, asks := eng.GetOrderBookSnapshot("CLZ5-CLF6", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: -50, Quantity: 5}}, bids)
+    assert.Equal(int64(0), asks[0].Price)
+
+    // A more negative bid queues behind it
+    _, err = eng.SubmitOrder(newTestOrder("b-100", "CLZ5-CLF6", enginepkg.Buy, enginepkg.Limit, -100, 5, 1004))
+    assert.NoError(err)
+    bid, _, _ := eng.GetBBO("CLZ5-CLF6")
+    assert.Equal(int64(-50), bid.Price)
+
+    // Amending to a negative price is a real amendment; zero still keeps it
+    amended, _, err := eng.AmendOrder("b-100", -95, 0)
+    assert.NoError(err)
+    assert.Equal(int64(-95), amended.Price)
+    amended, _, err = eng.AmendOrder("b-100", 0, 10)
+    assert.NoError(err)
+    assert.Equal(int64(-95), amended.Price)
+
+    // Market orders ignore price and sweep from the best level
+    resp, err = eng.SubmitOrder(newTestOrder("m1", "CLZ5-CLF6", enginepkg.Sell, enginepkg.Market, 0, 10, 1005))
+    assert.NoError(err)
+    if assert.Equal(2, len(resp.Trades)) {
+        assert.Equal(int64(-50), resp.Trades[0].Price)
+        assert.Equal(int64(-95), resp.Trades[1].Price)
+    }
+
+    // Notional orders cannot convert cash at a non-positive price
+    notional := newTestOrder("n1", "CLZ5-CLF6", enginepkg.Buy, enginepkg.Market, 0, 0, 1006)
+    notional.Notional = 1000
+    _, err = eng.SubmitOrder(notional)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    // Ordinary symbols still require positive prices
+    _, err = eng.SubmitOrder(newTestOrder("neg", "AAPL", enginepkg.Buy, enginepkg.Limit, -50, 10, 1007))
+    assert.ErrorContains(err, "price must be > 0")
+    _, err = eng.SubmitOrder(newTestOrder("zero", "AAPL", enginepkg.Buy, enginepkg.Limit, 0, 10, 1008))
+    assert.ErrorContains(err, "price must be > 0")
+}
+
+// TestNegativePrice_BandAndFees checks bands and fees use |price|
+func TestNegativePrice_BandAndFees(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{
+        Symbol: "SPREAD", AllowNegativePrice: true, PriceBandBps: 1000, ReferencePrice: -200,
+        Fees: enginepkg.FeeSchedule{TakerFeeBps: 100},
+    }))
+
+    // Band is [-220, -180]
+    _, err := eng.SubmitOrder(newTestOrder("out", "SPREAD", enginepkg.Sell, enginepkg.Limit, -230, 10, 1000))
+    assert.ErrorIs(err, enginepkg.ErrOutsideBand)
+    _, err = eng.SubmitOrder(newTestOrder("in", "SPREAD", enginepkg.Sell, enginepkg.Limit, -190, 10, 1001))
+    assert.NoError(err)
+
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "SPREAD", enginepkg.Buy, enginepkg.Limit, -190, 10, 1002))
+    assert.NoError(err)
+    if assert.Equal(1, len(resp.Trades)) {
+        // 1% of |-190| x 10 = 19, charged, not rebated
+        assert.Equal(int64(19), resp.Trades[0].AggressorFee)
+    }
+}
+
+func TestNegativePrice_PartialCloseRealizesPnL(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "SPREAD", AllowNegativePrice: true}))
+    submit := func(id, account string, side enginepkg.Side, price, qty, ts int64) {
+        order := newTestOrder(id, "SPREAD", side, enginepkg.Limit, price, qty, ts)
+        order.AccountID = account
+        _, err := eng.SubmitOrder(order)
+        assert.NoError(err)
+    }
+
+    // Buy 10 at -500, then sell 3 at -400: a gain of 100 on each of 3
+    submit("s1", "mm", enginepkg.Sell, -500, 10, 1000)
+    submit("b1", "alice", enginepkg.Buy, -500, 10, 1001)
+    submit("b2", "mm", enginepkg.Buy, -400, 3, 1002)
+    submit("s2", "alice", enginepkg.Sell, -400, 3, 1003)
+
+    alice := eng.GetPositions("alice")[0]
+    assert.Equal(int64(7), alice.NetQuantity)
+    assert.Equal(int64(-3500), alice.CostBasis)
+    assert.Equal(int64(-500), alice.AveragePrice)
+    assert.Equal(int64(300), alice.RealizedPnL)
+
+    // The market maker's short lost the same
+    mm := eng.GetPositions("mm")[0]
+    assert.Equal(int64(-7), mm.NetQuantity)
+    assert.Equal(int64(-500), mm.AveragePrice)
+    assert.Equal(int64(-300), mm.RealizedPnL)
+}
+
+func TestNegativePrice_BookStatsSpread(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "SPREAD", AllowNegativePrice: true}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "SPREAD", enginepkg.Buy, enginepkg.Limit, -60, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "SPREAD", enginepkg.Sell, enginepkg.Limit, -40, 10, 1001))
+    stats := eng.GetBookStats("SPREAD")
+    assert.Equal(int64(-60), stats.BestBid)
+    assert.Equal(int64(-40), stats.BestAsk)
+    assert.Equal(int64(20), stats.Spread)
+    assert.InDelta(4000.0, stats.SpreadBps, 1e-9) // 20 against a mid of -50
+
+    // A book straddling zero still has a spread; its mid of zero gives no bps
+    _, _ = eng.CancelOrder("s1")
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "SPREAD", enginepkg.Buy, enginepkg.Limit, -10, 10, 1002))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "SPREAD", enginepkg.Sell, enginepkg.Limit, 10, 10, 1003))
+    stats = eng.GetBookStats("SPREAD")
+    assert.Equal(int64(20), stats.Spread)
+    assert.Equal(0.0, stats.SpreadBps)
+}
@@ -0,0 +1,65 @@
+package engine_test
+
+import (
+    "math"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestNextFill_MultiLevelBook(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    // Empty book: nothing to fill either way
+    _, _, ok := eng.NextFill("AAPL", enginepkg.Buy, math.MaxInt64)
+    assert.False(ok)
+
+    for _, o := range []*enginepkg.Order{
+        newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9990, 100, 0),
+        newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9990, 50, 1),
+        newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 9980, 70, 2),
+        newTestOrder("a1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10010, 30, 3),
+        newTestOrder("a2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10020, 40, 4),
+        hiddenOrder("a3", enginepkg.Sell, 10010, 20, 5),
+    } {
+        _, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+    }
+
+    // A buy fills first against the 10010 asks, hidden quantity included
+    price, qty, ok := eng.NextFill("AAPL", enginepkg.Buy, 10015)
+    assert.True(ok)
+    assert.Equal(int64(10010), price)
+    assert.Equal(int64(50), qty)
+    price, qty, ok = eng.NextFill("AAPL", enginepkg.Buy, math.MaxInt64)
+    assert.True(ok)
+    assert.Equal(int64(10010), price)
+    assert.Equal(int64(50), qty)
+
+    // A sell fills first against the 9990 bids
+    price, qty, ok = eng.NextFill("AAPL", enginepkg.Sell, 9985)
+    assert.True(ok)
+    assert.Equal(int64(9990), price)
+    assert.Equal(int64(150), qty)
+
+    // Limits that do not cross
+    _, _, ok = eng.NextFill("AAPL", enginepkg.Buy, 10009)
+    assert.False(ok)
+    _, _, ok = eng.NextFill("AAPL", enginepkg.Sell, 9991)
+    assert.False(ok)
+
+    // Peeking changed nothing
+    snap := eng.GetBookSnapshot("AAPL", 0)
+    assert.Equal(3, snap.TotalBidOrders)
+    assert.Equal(int64(30), snap.Asks[0].Quantity)
+
+    // Once the best ask level is gone the next one is reported
+    _, err := eng.SubmitOrder(newTestOrder("t1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10010, 50, 6))
+    assert.NoError(err)
+    price, qty, ok = eng.NextFill("AAPL", enginepkg.Buy, math.MaxInt64)
+    assert.True(ok)
+    assert.Equal(int64(10020), price)
+    assert.Equal(int64(40), qty)
+}
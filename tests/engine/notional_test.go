@@ -0,0 +1,89 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func notionalOrder(id string, side enginepkg.Side, notional int64) *enginepkg.Order {
+    order := newTestOrder(id, "AAPL", side, enginepkg.Market, 0, 0, 2000)
+    order.Notional = notional
+    return order
+}
+
+func TestNotional_WalksLevelsUntilCashIsSpent(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 10, 1002))
+
+    buy := notionalOrder("b1", enginepkg.Buy, 250000)
+    resp, err := eng.SubmitOrder(buy)
+    assert.NoError(err)
+    assert.Equal(3, len(resp.Trades))
+    // 10@10000 + 10@10100 + 4@10200 = 241800; a fifth share at 10200 is unaffordable
+    assert.Equal(int64(4), resp.Trades[2].Quantity)
+    assert.Equal(int64(24), buy.FilledQuantity)
+    assert.Equal(int64(24), buy.Quantity)
+    assert.Equal(int64(241800), buy.SpentNotional)
+    assert.Equal(int64(8200), buy.LeftoverNotional())
+    assert.Equal(enginepkg.StatusFilled, buy.Status)
+
+    s3, _ := eng.GetOrderStatus("s3")
+    assert.Equal(int64(4), s3.FilledQuantity)
+}
+
+func TestNotional_SellRunsOutOfLiquidity(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, 1001))
+
+    sell := notionalOrder("s1", enginepkg.Sell, 1000000)
+    resp, err := eng.SubmitOrder(sell)
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal(int64(20), sell.FilledQuantity)
+    assert.Equal(int64(199000), sell.SpentNotional)
+    assert.Equal(int64(801000), sell.LeftoverNotional())
+    assert.Equal(enginepkg.StatusPartialFill, sell.Status)
+    assert.False(resp.OrderInBook)
+}
+
+func TestNotional_FractionalQuantity(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "BTC", QuantityDecimals: 4}))
+
+    // 1.0000 BTC at 3,000,000
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "BTC", enginepkg.Sell, enginepkg.Limit, 3000000, 10000, 1000))
+
+    buy := newTestOrder("b1", "BTC", enginepkg.Buy, enginepkg.Market, 0, 0, 1001)
+    buy.Notional = 100000
+    _, err := eng.SubmitOrder(buy)
+    assert.NoError(err)
+    // 0.0333 BTC costs 99900; 0.0334 would cost 100200
+    assert.Equal(int64(333), buy.FilledQuantity)
+    assert.Equal(int64(99900), buy.SpentNotional)
+    assert.Equal(int64(100), buy.LeftoverNotional())
+}
+
+func TestNotional_Validation(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    withQty := notionalOrder("o1", enginepkg.Buy, 1000)
+    withQty.Quantity = 5
+    _, err := eng.SubmitOrder(withQty)
+    assert.ErrorContains(err, "mutually exclusive")
+
+    limit := newTestOrder("o2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 0, 1000)
+    limit.Notional = 1000
+    _, err = eng.SubmitOrder(limit)
+    assert.ErrorContains(err, "requires a MARKET order")
+}
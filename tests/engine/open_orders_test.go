@@ -0,0 +1,41 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestGetOpenOrders_OnlyLiveOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(accountOrder("s1", "alice", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(accountOrder("s2", "alice", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1001))
+    _, _ = eng.SubmitOrder(accountOrder("s3", "alice", enginepkg.Sell, enginepkg.Limit, 10200, 100, 1002))
+    _, _ = eng.SubmitOrder(accountOrder("b1", "bob", enginepkg.Buy, enginepkg.Limit, 9000, 100, 1003))
+    msft := accountOrder("m1", "alice", enginepkg.Buy, enginepkg.Limit, 30000, 10, 1004)
+    msft.Symbol = "MSFT"
+    _, _ = eng.SubmitOrder(msft)
+
+    // Fill s1, part-fill s2, cancel s3
+    _, _ = eng.SubmitOrder(accountOrder("x1", "bob", enginepkg.Buy, enginepkg.Market, 0, 150, 1005))
+    _, _ = eng.CancelOrder("s3")
+
+    open := eng.GetOpenOrders("alice", "")
+    assert.Equal(2, len(open))
+    assert.Equal("s2", open[0].ID)
+    assert.Equal(enginepkg.StatusPartialFill, open[0].Status)
+    assert.Equal("m1", open[1].ID)
+
+    aapl := eng.GetOpenOrders("alice", "AAPL")
+    assert.Equal(1, len(aapl))
+
+    // Copies: changing one does not touch the engine's order
+    aapl[0].Price = 1
+    stored, _ := eng.GetOrderStatus("s2")
+    assert.Equal(int64(10100), stored.Price)
+
+    assert.Empty(eng.GetOpenOrders("carol", ""))
+}
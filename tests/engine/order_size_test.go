@@ -0,0 +1,56 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestOrderSize_MinMaxQuantityBoundaries(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", MinQty: 10, MaxQty: 1000}))
+
+    _, err := eng.SubmitOrder(newTestOrder("dust", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 9, 1000))
+    assert.ErrorContains(err, "quantity 9 is below minimum 10")
+    _, err = eng.SubmitOrder(newTestOrder("huge", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 1001, 1001))
+    assert.ErrorContains(err, "quantity 1001 exceeds maximum 1000")
+    for _, id := range []string{"dust", "huge"} {
+        _, err = eng.GetOrderStatus(id)
+        assert.Error(err, "rejected order %s must not be stored", id)
+    }
+
+    _, err = eng.SubmitOrder(newTestOrder("at-min", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1002))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("at-max", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 1000, 1003))
+    assert.NoError(err)
+
+    // Unconfigured symbols have no bounds
+    _, err = eng.SubmitOrder(newTestOrder("msft", "MSFT", enginepkg.Buy, enginepkg.Limit, 10000, 1, 1004))
+    assert.NoError(err)
+}
+
+func TestOrderSize_MaxNotional(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", MaxNotional: 1_000_000}))
+
+    // 100 x 10000 is exactly at the cap; one more share is over it
+    _, err := eng.SubmitOrder(newTestOrder("at-cap", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("over", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 101, 1001))
+    assert.ErrorContains(err, "exceeds maximum notional 1000000")
+    _, err = eng.GetOrderStatus("over")
+    assert.Error(err)
+
+    // Market orders are valued against the book they would sweep
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1002))
+    _, err = eng.SubmitOrder(newTestOrder("mkt-over", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 101, 1003))
+    assert.ErrorContains(err, "maximum notional")
+    _, err = eng.GetOrderStatus("mkt-over")
+    assert.Error(err)
+    resp, err := eng.SubmitOrder(newTestOrder("mkt-ok", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 100, 1004))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+}
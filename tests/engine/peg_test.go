@@ -0,0 +1,170 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func peggedOrder(id string, side enginepkg.Side, ref enginepkg.PegReference, offset, qty int64) *enginepkg.Order {
+    order := newTestOrder(id, "AAPL", side, enginepkg.Pegged, 0, qty, 0)
+    order.PegReference = ref
+    order.PegOffset = offset
+    return order
+}
+
+func TestPegged_TracksOppositeQuote(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10050, 100, 0))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1))
+    assert.NoError(err)
+
+    // Pegged 20 below the best ask
+    resp, err := eng.SubmitOrder(peggedOrder("p1", enginepkg.Buy, enginepkg.PegAsk, -20, 50))
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    assert.True(resp.OrderInBook)
+    peg, _ := eng.GetOrderStatus("p1")
+    assert.Equal(int64(10030), peg.Price)
+    firstSeq := peg.Sequence
+
+    // The ask moves down: the peg follows and loses its priority
+    _, _, err = eng.AmendOrder("a1", 10040, 0)
+    assert.NoError(err)
+    peg, _ = eng.GetOrderStatus("p1")
+    assert.Equal(int64(10020), peg.Price)
+    assert.Greater(peg.Sequence, firstSeq)
+    snap := eng.GetBookSnapshot("AAPL", 0)
+    assert.Equal(int64(10020), snap.Bids[0].Price)
+    assert.Equal(int64(50), snap.Bids[0].Quantity)
+
+    // A better ask arrives: the peg moves again
+    _, err = eng.SubmitOrder(newTestOrder("a2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10035, 10, 2))
+    assert.NoError(err)
+    peg, _ = eng.GetOrderStatus("p1")
+    assert.Equal(int64(10015), peg.Price)
+
+    // The better ask is cancelled: back to 20 below the remaining one
+    _, err = eng.CancelOrder("a2")
+    assert.NoError(err)
+    peg, _ = eng.GetOrderStatus("p1")
+    assert.Equal(int64(10020), peg.Price)
+
+    // Each move was published as an amendment
+    history, err := eng.GetOrderHistory("p1")
+    assert.NoError(err)
+    amended := 0
+    for _, ev := range history {
+        if ev.Type == enginepkg.EventAmended {
+            amended++
+        }
+    }
+    assert.Equal(3, amended)
+}
+
+func TestPegged_ClampedInsideTheBook(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10050, 100, 0))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1))
+
+    // A peg at or through the ask rests one tick below it instead of taking
+    resp, err := eng.SubmitOrder(peggedOrder("p1", enginepkg.Buy, enginepkg.PegAsk, 10, 50))
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    peg, _ := eng.GetOrderStatus("p1")
+    assert.Equal(int64(10049), peg.Price)
+
+    // A sell pegged to the bid rests one tick above the best bid, which
+    // counts pegged orders: the clamp keeps the book from ever crossing
+    _, err = eng.SubmitOrder(peggedOrder("p2", enginepkg.Sell, enginepkg.PegBid, 0, 50))
+    assert.NoError(err)
+    peg, _ = eng.GetOrderStatus("p2")
+    assert.Equal(int64(10050), peg.Price)
+
+    // Pegged orders still trade as the resting side
+    resp, err = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 50, 2))
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    assert.Equal("p1", resp.Trades[0].RestingOrderID)
+    assert.Equal(int64(10049), resp.Trades[0].Price)
+}
+
+func TestPegged_MidpointInWholeTicks(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", TickSize: 5}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10025, 100, 0))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1))
+
+    // Mid is 10012.5: buys round down to a tick, sells up
+    _, err := eng.SubmitOrder(peggedOrder("pb", enginepkg.Buy, enginepkg.PegMid, 0, 10))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(peggedOrder("ps", enginepkg.Sell, enginepkg.PegMid, 0, 10))
+    assert.NoError(err)
+    buy, _ := eng.GetOrderStatus("pb")
+    sell, _ := eng.GetOrderStatus("ps")
+    assert.Equal(int64(10010), buy.Price)
+    assert.Equal(int64(10015), sell.Price)
+
+    // The mid moves to 10017.5; the pegged orders do not count towards it.
+    // The buy, re-pegged first, is briefly held below the sell's old price
+    // and catches up once the sell moves.
+    _, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10010, 10, 2))
+    assert.NoError(err)
+    buy, _ = eng.GetOrderStatus("pb")
+    sell, _ = eng.GetOrderStatus("ps")
+    assert.Equal(int64(10015), buy.Price)
+    assert.Equal(int64(10020), sell.Price)
+}
+
+func TestPegged_Rejections(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    // Nothing to peg to
+    _, err := eng.SubmitOrder(peggedOrder("p1", enginepkg.Buy, enginepkg.PegBid, 0, 10))
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 0))
+
+    bad := peggedOrder("p2", enginepkg.Buy, "LAST", 0, 10)
+    _, err = eng.SubmitOrder(bad)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    priced := peggedOrder("p3", enginepkg.Buy, enginepkg.PegBid, 0, 10)
+    priced.Price = 10000
+    _, err = eng.SubmitOrder(priced)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    ioc := peggedOrder("p4", enginepkg.Buy, enginepkg.PegBid, 0, 10)
+    ioc.TimeInForce = enginepkg.IOC
+    _, err = eng.SubmitOrder(ioc)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    stray := newTestOrder("l1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9990, 10, 1)
+    stray.PegReference = enginepkg.PegBid
+    _, err = eng.SubmitOrder(stray)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    // A resting peg's price cannot be amended, only its quantity
+    _, err = eng.SubmitOrder(peggedOrder("p5", enginepkg.Buy, enginepkg.PegBid, -10, 10))
+    assert.NoError(err)
+    _, _, err = eng.AmendOrder("p5", 9900, 0)
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+    amended, _, err := eng.AmendOrder("p5", 0, 5)
+    assert.NoError(err)
+    assert.Equal(int64(9990), amended.Price)
+    assert.Equal(int64(5), amended.Quantity)
+
+    // No new pegs while the book is collecting
+    assert.NoError(eng.StartAuction("AAPL"))
+    _, err = eng.SubmitOrder(peggedOrder("p6", enginepkg.Buy, enginepkg.PegBid, 0, 10))
+    assert.ErrorIs(err, enginepkg.ErrPhaseRejected)
+}
@@ -0,0 +1,78 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func accountOrder(id, account string, side enginepkg.Side, oType enginepkg.OrderType, price, quantity, ts int64) *enginepkg.Order {
+    order := newTestOrder(id, "AAPL", side, oType, price, quantity, ts)
+    order.AccountID = account
+    return order
+}
+
+func TestPositions_BuyThenSellAtProfit(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(accountOrder("s1", "mm", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(accountOrder("b1", "alice", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+
+    positions := eng.GetPositions("alice")
+    assert.Equal(1, len(positions))
+    assert.Equal(int64(100), positions[0].NetQuantity)
+    assert.Equal(int64(10000), positions[0].AveragePrice)
+
+    // Sell 60 at 10500 into a resting bid: realized (10500-10000)*60
+    _, _ = eng.SubmitOrder(accountOrder("b2", "mm", enginepkg.Buy, enginepkg.Limit, 10500, 60, 1002))
+    _, _ = eng.SubmitOrder(accountOrder("s2", "alice", enginepkg.Sell, enginepkg.Limit, 10500, 60, 1003))
+
+    alice := eng.GetPositions("alice")[0]
+    assert.Equal("AAPL", alice.Symbol)
+    assert.Equal(int64(40), alice.NetQuantity)
+    assert.Equal(int64(10000), alice.AveragePrice)
+    assert.Equal(int64(30000), alice.RealizedPnL)
+
+    // The market maker is on the other side of both trades
+    mm := eng.GetPositions("mm")[0]
+    assert.Equal(int64(-40), mm.NetQuantity)
+    assert.Equal(int64(-30000), mm.RealizedPnL)
+}
+
+func TestPositions_FlipThroughZero(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(accountOrder("s1", "mm", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1000))
+    _, _ = eng.SubmitOrder(accountOrder("b1", "alice", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1001))
+
+    // Sell 80 at 9800: closes the 50 long at a loss and opens 30 short
+    _, _ = eng.SubmitOrder(accountOrder("b2", "mm", enginepkg.Buy, enginepkg.Limit, 9800, 80, 1002))
+    _, _ = eng.SubmitOrder(accountOrder("s2", "alice", enginepkg.Sell, enginepkg.Market, 0, 80, 1003))
+
+    alice := eng.GetPositions("alice")[0]
+    assert.Equal(int64(-30), alice.NetQuantity)
+    assert.Equal(int64(9800), alice.AveragePrice)
+    assert.Equal(int64(-10000), alice.RealizedPnL)
+
+    // Buy the short back at 9700: +100 per share
+    _, _ = eng.SubmitOrder(accountOrder("s3", "mm", enginepkg.Sell, enginepkg.Limit, 9700, 30, 1004))
+    _, _ = eng.SubmitOrder(accountOrder("b3", "alice", enginepkg.Buy, enginepkg.Market, 0, 30, 1005))
+
+    alice = eng.GetPositions("alice")[0]
+    assert.Equal(int64(0), alice.NetQuantity)
+    assert.Equal(int64(0), alice.AveragePrice)
+    assert.Equal(int64(0), alice.CostBasis)
+    assert.Equal(int64(-7000), alice.RealizedPnL)
+}
+
+func TestPositions_OrdersWithoutAccountAreNotTracked(t *testing.T) {
+    eng := setupEngine()
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1001))
+
+    assert.Empty(t, eng.GetPositions(""))
+}
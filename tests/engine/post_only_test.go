@@ -0,0 +1,47 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func newPostOnly(id string, side enginepkg.Side, price, quantity int64, ts int64) *enginepkg.Order {
+    o := newTestOrder(id, "AAPL", side, enginepkg.Limit, price, quantity, ts)
+    o.PostOnly = true
+    return o
+}
+
+// TestPostOnly_BuyAtAskIsRejected checks a post-only buy at the ask never trades or rests
+func TestPostOnly_BuyAtAskIsRejected(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+
+    resp, err := eng.SubmitOrder(newPostOnly("po-buy", enginepkg.Buy, 15050, 100, 1001))
+    assert.Error(err)
+    assert.Contains(err.Error(), "post-only")
+    assert.Equal(0, len(resp.Trades))
+
+    _, err = eng.GetOrderStatus("po-buy")
+    assert.Error(err, "rejected post-only order must not be stored")
+    status, _ := eng.GetOrderStatus("sell-1")
+    assert.Equal(int64(100), status.RemainingQuantity())
+}
+
+// TestPostOnly_BuyBelowAskRests checks a non-crossing post-only order is added as a maker
+func TestPostOnly_BuyBelowAskRests(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+
+    resp, err := eng.SubmitOrder(newPostOnly("po-buy", enginepkg.Buy, 15049, 100, 1001))
+    assert.NoError(err)
+    assert.True(resp.OrderInBook)
+
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15049, Quantity: 100}}, bids)
+}
@@ -0,0 +1,79 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func bandedEngine(t *testing.T, reference int64) *enginepkg.MatchingEngine {
+    eng := setupEngine()
+    // 5% band: [9500, 10500] around a reference of 10000
+    err := eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", PriceBandBps: 500, ReferencePrice: reference})
+    assert.NoError(t, err)
+    return eng
+}
+
+func TestPriceBand_LimitInsideAndOutside(t *testing.T) {
+    eng := bandedEngine(t, 10000)
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9500, 100, 1000))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10500, 100, 1001))
+    assert.NoError(err)
+
+    _, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9499, 100, 1002))
+    assert.ErrorContains(err, "price band")
+    _, err = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10501, 100, 1003))
+    assert.ErrorContains(err, "price band")
+
+    _, err = eng.GetOrderStatus("b2")
+    assert.Error(err, "rejected order must not be stored")
+}
+
+func TestPriceBand_NoReferenceAcceptsFirstOrder(t *testing.T) {
+    eng := bandedEngine(t, 0)
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 20000, 100, 1000))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 1000, 100, 1001))
+    assert.NoError(err)
+}
+
+func TestPriceBand_MarketStopsAtBandEdge(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10500, 100, 1001))
+    // Rests before the band is configured, so it ends up outside it
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10600, 100, 1002))
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", PriceBandBps: 500, ReferencePrice: 10000}))
+
+    buy := newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 300, 1003)
+    resp, err := eng.SubmitOrder(buy)
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal(int64(200), buy.FilledQuantity)
+    assert.False(resp.OrderInBook)
+
+    s3, _ := eng.GetOrderStatus("s3")
+    assert.Equal(int64(0), s3.FilledQuantity)
+}
+
+func TestPriceBand_ReferenceFollowsLastTrade(t *testing.T) {
+    eng := bandedEngine(t, 10000)
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10400, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10400, 100, 1001))
+
+    // Band is now [9880, 10920] around the 10400 trade
+    _, err := eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10900, 100, 1002))
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9800, 100, 1003))
+    assert.ErrorContains(err, "price band")
+}
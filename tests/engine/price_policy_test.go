@@ -0,0 +1,75 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// crossAt submits a resting sell at 10000 and a buy at buyPrice (0 for a
+// market order) against it, returning the print price under cfg.
+func crossAt(t *testing.T, cfg enginepkg.SymbolConfig, buyPrice int64) int64 {
+    eng := setupEngine()
+    cfg.Symbol = "AAPL"
+    assert.NoError(t, eng.ConfigureSymbol(cfg))
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    buyType := enginepkg.Limit
+    if buyPrice == 0 {
+        buyType = enginepkg.Market
+    }
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, buyType, buyPrice, 100, 1001))
+    assert.NoError(t, err)
+    assert.Equal(t, 1, len(resp.Trades))
+    return resp.Trades[0].Price
+}
+
+func TestPricePolicy_SameCrossPrintsDifferently(t *testing.T) {
+    assert := assert.New(t)
+
+    assert.Equal(int64(10000), crossAt(t, enginepkg.SymbolConfig{}, 10101))
+    assert.Equal(int64(10000), crossAt(t, enginepkg.SymbolConfig{PricePolicy: enginepkg.RestingPrice}, 10101))
+    // Gap of 101: half is 50.5, rounded toward the resting price
+    assert.Equal(int64(10050), crossAt(t, enginepkg.SymbolConfig{PricePolicy: enginepkg.MidPoint}, 10101))
+    // Gap of 300 on a 100 tick: half is 150, rounded to a whole tick
+    assert.Equal(int64(10100), crossAt(t, enginepkg.SymbolConfig{PricePolicy: enginepkg.MidPoint, TickSize: 100}, 10300))
+    // Market orders have no limit to split
+    assert.Equal(int64(10000), crossAt(t, enginepkg.SymbolConfig{PricePolicy: enginepkg.MidPoint}, 0))
+}
+
+func TestPricePolicy_MidPointSellAggressor(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", PricePolicy: enginepkg.MidPoint}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    resp, _ := eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 9900, 100, 1001))
+    assert.Equal(int64(9950), resp.Trades[0].Price)
+
+    last, ok := eng.GetLastTrade("AAPL")
+    assert.True(ok)
+    assert.Equal(int64(9950), last.Price)
+}
+
+func TestPricePolicy_PriceImprovementCapsAtOneTick(t *testing.T) {
+    assert := assert.New(t)
+    cfg := enginepkg.SymbolConfig{PricePolicy: enginepkg.PriceImprovement, TickSize: 10}
+
+    // A buy at 10100 against the ask at 10000 prints one tick inside both
+    price := crossAt(t, cfg, 10100)
+    assert.Equal(int64(10010), price)
+    assert.Greater(price, int64(10000), "better than the resting quote")
+    assert.Less(price, int64(10100))
+    // Half the gap is under a tick: nothing to improve by
+    assert.Equal(int64(10000), crossAt(t, cfg, 10010))
+    assert.Equal(int64(10000), crossAt(t, cfg, 0), "market orders")
+
+    // A sell aggressor improves downwards from the resting bid
+    eng := setupEngine()
+    cfg.Symbol = "AAPL"
+    assert.NoError(eng.ConfigureSymbol(cfg))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    resp, _ := eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 9800, 100, 1001))
+    assert.Equal(int64(9990), resp.Trades[0].Price)
+}
@@ -0,0 +1,83 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestProRata_SingleLevel(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "ES", MatchingAlgorithm: enginepkg.ProRata}))
+
+    // 600 resting: 100, 200 and 300 in time order
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 200, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 300, 1002))
+
+    // 100 split 1:2:3 is 16.67/33.33/50 -> floors 16/33/50, residual 1 to the oldest
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "ES", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1003))
+    assert.NoError(err)
+    filled := map[string]int64{}
+    for _, tr := range resp.Trades {
+        filled[tr.RestingOrderID] += tr.Quantity
+    }
+    assert.Equal(map[string]int64{"s1": 17, "s2": 33, "s3": 50}, filled)
+
+    // An aggressor that takes the whole level fills everyone
+    resp, err = eng.SubmitOrder(newTestOrder("b2", "ES", enginepkg.Buy, enginepkg.Limit, 10000, 600, 1004))
+    assert.NoError(err)
+    assert.Equal(3, len(resp.Trades))
+    assert.Equal(int64(500), resp.Trades[0].Quantity+resp.Trades[1].Quantity+resp.Trades[2].Quantity)
+    for _, id := range []string{"s1", "s2", "s3"} {
+        o, _ := eng.GetOrderStatus(id)
+        assert.Equal(enginepkg.StatusFilled, o.Status)
+    }
+}
+
+func TestProRata_FIFOUnchangedByDefault(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "ES", enginepkg.Sell, enginepkg.Limit, 10000, 200, 1001))
+
+    resp, _ := eng.SubmitOrder(newTestOrder("b1", "ES", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1002))
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal("s1", resp.Trades[0].RestingOrderID)
+
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "ES", MatchingAlgorithm: "RANDOM"}))
+}
+
+func TestProRata_HiddenOrdersTradeAfterDisplayed(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", MatchingAlgorithm: enginepkg.ProRata}))
+
+    _, _ = eng.SubmitOrder(hiddenOrder("h", enginepkg.Sell, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("a", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("b", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1002))
+
+    // Only the displayed 20 is rationed; the hidden order takes no share
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1003))
+    assert.NoError(err)
+    filled := map[string]int64{}
+    for _, tr := range resp.Trades {
+        filled[tr.RestingOrderID] += tr.Quantity
+    }
+    assert.Equal(map[string]int64{"a": 5, "b": 5}, filled)
+
+    // Once the displayed orders are filled, the hidden one takes what is left
+    resp, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 30, 1004))
+    assert.NoError(err)
+    var got []string
+    for _, tr := range resp.Trades {
+        got = append(got, tr.RestingOrderID)
+    }
+    assert.Equal([]string{"a", "b", "h"}, got)
+    assert.Equal(int64(20), resp.Trades[2].Quantity)
+    h, _ := eng.GetOrderStatus("h")
+    assert.Equal(enginepkg.StatusPartialFill, h.Status)
+}
@@ -0,0 +1,46 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestMarketProtection_StopsAtBound(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10050, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 12000, 500, 1002))
+
+    buy := newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 300, 1003)
+    buy.ProtectionPrice = 10100
+    resp, err := eng.SubmitOrder(buy)
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal(int64(200), buy.FilledQuantity)
+    assert.Equal(enginepkg.StatusPartialFill, buy.Status)
+    assert.False(resp.OrderInBook)
+
+    // The expensive level was not touched
+    s3, _ := eng.GetOrderStatus("s3")
+    assert.Equal(int64(0), s3.FilledQuantity)
+}
+
+func TestMarketProtection_Sell(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9000, 100, 1001))
+
+    sell := newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 200, 1002)
+    sell.ProtectionPrice = 9500
+    resp, err := eng.SubmitOrder(sell)
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal(int64(10000), resp.Trades[0].Price)
+    assert.Equal(int64(100), sell.FilledQuantity)
+}
@@ -0,0 +1,90 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestQueuePosition_MiddleOfLevel(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    for i, id := range []string{"first", "middle", "last"} {
+        _, err := eng.SubmitOrder(newTestOrder(id, "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, int64(100*(i+1)), int64(i)))
+        assert.NoError(err)
+    }
+    _, err := eng.SubmitOrder(newTestOrder("other", "AAPL", enginepkg.Buy, enginepkg.Limit, 9990, 50, 3))
+    assert.NoError(err)
+
+    ahead, total, err := eng.GetQueuePosition("middle")
+    assert.NoError(err)
+    assert.Equal(int64(100), ahead)
+    assert.Equal(int64(600), total)
+
+    // A partial fill of the head shrinks what is ahead
+    _, err = eng.SubmitOrder(newTestOrder("sell", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 40, 4))
+    assert.NoError(err)
+    ahead, total, err = eng.GetQueuePosition("middle")
+    assert.NoError(err)
+    assert.Equal(int64(60), ahead)
+    assert.Equal(int64(560), total)
+
+    ahead, _, err = eng.GetQueuePosition("first")
+    assert.NoError(err)
+    assert.Equal(int64(0), ahead)
+
+    _, err = eng.CancelOrder("first")
+    assert.NoError(err)
+    _, _, err = eng.GetQueuePosition("first")
+    assert.ErrorIs(err, enginepkg.ErrOrderNotOpen)
+    ahead, _, err = eng.GetQueuePosition("middle")
+    assert.NoError(err)
+    assert.Equal(int64(0), ahead)
+
+    _, _, err = eng.GetQueuePosition("missing")
+    assert.ErrorIs(err, enginepkg.ErrOrderNotFound)
+}
+
+func TestGetLevelOrders_QueueOrder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    hidden := newTestOrder("hidden", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 70, 1000)
+    hidden.Hidden = true
+    for _, o := range []*enginepkg.Order{
+        hidden,
+        newTestOrder("a", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1001),
+        newTestOrder("other", "AAPL", enginepkg.Sell, enginepkg.Limit, 15060, 10, 1002),
+        newTestOrder("b", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 200, 1003),
+        newTestOrder("c", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1004),
+    } {
+        _, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+    }
+    // Increasing b's quantity sends it to the back
+    _, _, err := eng.AmendOrder("b", 15050, 250)
+    assert.NoError(err)
+
+    ids := func(orders []enginepkg.Order) []string {
+        out := []string{}
+        for _, o := range orders {
+            out = append(out, o.ID)
+        }
+        return out
+    }
+    orders := eng.GetLevelOrders("AAPL", enginepkg.Sell, 15050, false)
+    assert.Equal([]string{"a", "c", "b"}, ids(orders))
+    assert.Equal(int64(1001), orders[0].Timestamp)
+    assert.Equal(int64(250), orders[2].Quantity)
+    assert.Equal([]string{"a", "c", "b", "hidden"}, ids(eng.GetLevelOrders("AAPL", enginepkg.Sell, 15050, true)))
+
+    // Copies: changing one leaves the book alone
+    orders[0].Quantity = 1
+    status, _ := eng.GetOrderStatus("a")
+    assert.Equal(int64(100), status.Quantity)
+
+    assert.Empty(eng.GetLevelOrders("AAPL", enginepkg.Buy, 15050, true))
+    assert.NotNil(eng.GetLevelOrders("AAPL", enginepkg.Sell, 99999, false))
+}
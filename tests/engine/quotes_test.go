@@ -0,0 +1,84 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestGetBBO(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _, ok := eng.GetBBO("AAPL")
+    assert.False(ok)
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9950, 30, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 9950, 20, 1002))
+
+    // One-sided book
+    bid, ask, ok := eng.GetBBO("AAPL")
+    assert.True(ok)
+    assert.Equal(enginepkg.AggregatedPriceLevel{Price: 9950, Quantity: 50}, bid)
+    assert.Equal(enginepkg.AggregatedPriceLevel{}, ask)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10050, 70, 1003))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1004))
+
+    bid, ask, ok = eng.GetBBO("AAPL")
+    assert.True(ok)
+    assert.Equal(int64(9950), bid.Price)
+    assert.Equal(enginepkg.AggregatedPriceLevel{Price: 10050, Quantity: 70}, ask)
+}
+
+func TestGetBookStats(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    empty := eng.GetBookStats("AAPL")
+    assert.Equal(0.0, empty.Imbalance)
+    assert.Equal(0.0, empty.SpreadBps)
+
+    // Lopsided book: 300 bid vs 100 ask
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 200, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 9800, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1002))
+
+    stats := eng.GetBookStats("AAPL")
+    assert.Equal(int64(9900), stats.BestBid)
+    assert.Equal(int64(10100), stats.BestAsk)
+    assert.Equal(int64(200), stats.Spread)
+    assert.InDelta(200.0, stats.SpreadBps, 1e-9)
+    assert.Equal(int64(300), stats.TotalBidVolume)
+    assert.Equal(int64(100), stats.TotalAskVolume)
+    assert.InDelta(0.5, stats.Imbalance, 1e-9)
+
+    // One-sided book: no spread, fully bid-heavy
+    _, _ = eng.CancelOrder("s1")
+    stats = eng.GetBookStats("AAPL")
+    assert.Equal(int64(0), stats.Spread)
+    assert.Equal(0.0, stats.SpreadBps)
+    assert.InDelta(1.0, stats.Imbalance, 1e-9)
+}
+
+func TestGetLastTrade_ExecutionPrice(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, ok := eng.GetLastTrade("AAPL")
+    assert.False(ok)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10020, 50, 1001))
+
+    // Buy priced above both levels; the last print is at the second resting price
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10500, 70, 1002))
+
+    last, ok := eng.GetLastTrade("AAPL")
+    assert.True(ok)
+    assert.Equal(int64(10020), last.Price)
+    assert.Equal(int64(20), last.Quantity)
+    assert.NotZero(last.Timestamp)
+}
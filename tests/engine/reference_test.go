@@ -0,0 +1,104 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestReference_RejectsLimitThroughTheReference(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10020, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("bid-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9980, 100, 1001))
+    assert.NoError(eng.SetReference("AAPL", 9990, 10010))
+
+    // Buying at 10020 would pay more than the 10010 offered elsewhere
+    buy := newTestOrder("buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 10020, 50, 1002)
+    buy.NoTradeThrough = true
+    _, err := eng.SubmitOrder(buy)
+    assert.ErrorIs(err, enginepkg.ErrTradeThrough)
+    assert.Equal(enginepkg.CodeTradeThrough, enginepkg.Code(err))
+    sell := newTestOrder("sell", "AAPL", enginepkg.Sell, enginepkg.Limit, 9980, 50, 1003)
+    sell.NoTradeThrough = true
+    _, err = eng.SubmitOrder(sell)
+    assert.ErrorIs(err, enginepkg.ErrTradeThrough)
+
+    // At or inside the reference, and without the flag, orders are accepted
+    inside := newTestOrder("inside", "AAPL", enginepkg.Buy, enginepkg.Limit, 10010, 50, 1004)
+    inside.NoTradeThrough = true
+    _, err = eng.SubmitOrder(inside)
+    assert.NoError(err)
+    resp, err := eng.SubmitOrder(newTestOrder("unprotected", "AAPL", enginepkg.Buy, enginepkg.Limit, 10020, 50, 1005))
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+
+    assert.Error(eng.SetReference("AAPL", 10010, 9990), "a crossed reference")
+    assert.Error(eng.SetReference("AAPL", -1, 10010))
+    bid, ask := eng.GetReference("AAPL")
+    assert.Equal([2]int64{9990, 10010}, [2]int64{bid, ask})
+}
+
+func TestReference_CapsMarketOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("near", "AAPL", enginepkg.Sell, enginepkg.Limit, 10005, 50, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("far", "AAPL", enginepkg.Sell, enginepkg.Limit, 10020, 100, 1001))
+    assert.NoError(eng.SetReference("AAPL", 0, 10010))
+
+    // Fills up to the reference ask; the rest is cancelled, not traded at 10020
+    mkt := newTestOrder("mkt", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 120, 1002)
+    mkt.NoTradeThrough = true
+    resp, err := eng.SubmitOrder(mkt)
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    assert.Equal(int64(10005), resp.Trades[0].Price)
+    assert.Equal(enginepkg.StatusPartialFill, mkt.Status)
+    assert.Equal(enginepkg.ReasonUnfilled, mkt.CancelReason)
+
+    // An unquoted side protects nothing
+    sell := newTestOrder("sell", "AAPL", enginepkg.Sell, enginepkg.Limit, 9000, 10, 1003)
+    sell.NoTradeThrough = true
+    _, err = eng.SubmitOrder(sell)
+    assert.NoError(err)
+}
+
+func TestReference_ReplaysFromWAL(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10020, 100, 1000))
+    assert.NoError(original.SetReference("AAPL", 9990, 10010))
+    buy := newTestOrder("buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 10020, 50, 1001)
+    buy.NoTradeThrough = true
+    _, err := original.SubmitOrder(buy)
+    assert.ErrorIs(err, enginepkg.ErrTradeThrough)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    bid, ask := recovered.GetReference("AAPL")
+    assert.Equal([2]int64{9990, 10010}, [2]int64{bid, ask})
+    _, asks := recovered.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10020, Quantity: 100}}, asks)
+}
+
+func TestReference_RejectsUnknownSymbol(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    eng := setupEngine()
+    eng.SetWAL(enginepkg.NewJSONWAL(&log))
+    eng.SetStrictSymbols(true)
+    assert.NoError(eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}))
+
+    assert.ErrorIs(eng.SetReference("GOOG", 9990, 10010), enginepkg.ErrUnknownSymbol)
+    assert.Equal(0, eng.GlobalStats().Symbols, "no book is created for an unlisted symbol")
+    assert.Zero(log.Len(), "nothing is logged")
+
+    assert.NoError(eng.SetReference("AAPL", 9990, 10010))
+}
@@ -0,0 +1,63 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestRejections_LoggedNewestFirst(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1000))
+
+    // Invalid, insufficient liquidity, post-only cross and a reused ID
+    _, err := eng.SubmitOrder(newTestOrder("bad", "AAPL", enginepkg.Buy, enginepkg.Limit, -100, 10, 1001))
+    assert.Error(err)
+    _, err = eng.SubmitOrder(newTestOrder("mkt", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 500, 1002))
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+    po := newTestOrder("po", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1003)
+    po.PostOnly = true
+    _, err = eng.SubmitOrder(po)
+    assert.ErrorIs(err, enginepkg.ErrPostOnlyCross)
+    _, err = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 50, 1004))
+    assert.ErrorIs(err, enginepkg.ErrDuplicateOrderID)
+
+    all := eng.GetRejections(0)
+    assert.Len(all, 4)
+    codes := make([]enginepkg.ReasonCode, len(all))
+    for i, r := range all {
+        codes[i] = r.Code
+    }
+    assert.Equal([]enginepkg.ReasonCode{
+        enginepkg.CodeDuplicateOrderID,
+        enginepkg.CodePostOnlyCross,
+        enginepkg.CodeInsufficientLiquidity,
+        enginepkg.CodeInvalidOrder,
+    }, codes)
+
+    latest := all[0]
+    assert.Equal("AAPL", latest.Symbol)
+    assert.Equal("s1", latest.Order.ID)
+    assert.Equal(enginepkg.Sell, latest.Order.Side)
+    assert.Equal(int64(10100), latest.Order.Price)
+    assert.NotEmpty(latest.Message)
+    assert.Positive(latest.Timestamp)
+
+    assert.Len(eng.GetRejections(2), 2)
+    assert.Equal("po", eng.GetRejections(2)[1].Order.ID)
+}
+
+func TestRejections_BoundedLog(t *testing.T) {
+    eng := setupEngine()
+
+    for i := 1; i <= 1100; i++ {
+        _, _ = eng.SubmitOrder(newTestOrder("bad", "AAPL", enginepkg.Buy, enginepkg.Limit, int64(-i), 10, 1000))
+    }
+    all := eng.GetRejections(0)
+    assert.Len(t, all, 1000)
+    assert.Equal(t, int64(-1100), all[0].Order.Price, "newest first")
+    assert.Equal(t, int64(-101), all[999].Order.Price, "oldest kept")
+}
@@ -0,0 +1,79 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestCancelReplace_SwapsOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 30, 1001))
+
+    replacement := newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 80, 1002)
+    cancelled, resp, err := eng.CancelReplace("b1", replacement)
+    assert.NoError(err)
+    assert.Equal("b1", cancelled.ID)
+    assert.Equal(enginepkg.StatusCancelled, cancelled.Status)
+
+    // The replacement crossed and took the resting sell
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal(enginepkg.StatusPartialFill, replacement.Status)
+    assert.True(resp.OrderInBook)
+
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10100, Quantity: 50}}, bids)
+    assert.Empty(asks)
+}
+
+func TestCancelReplace_FailedReplaceLeavesOriginalResting(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", TickSize: 5}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10050, 100, 1002))
+
+    // Off-tick price
+    _, _, err := eng.CancelReplace("b1", newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 10001, 100, 1003))
+    assert.Error(err)
+    // Post-only that would cross
+    postOnly := newTestOrder("b3", "AAPL", enginepkg.Buy, enginepkg.Limit, 10050, 100, 1004)
+    postOnly.PostOnly = true
+    _, _, err = eng.CancelReplace("b1", postOnly)
+    assert.ErrorContains(err, "post-only")
+    // Wrong side
+    _, _, err = eng.CancelReplace("b1", newTestOrder("b3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1005))
+    assert.ErrorContains(err, "side")
+
+    b1, _ := eng.GetOrderStatus("b1")
+    assert.Equal(enginepkg.StatusAccepted, b1.Status)
+    _, err = eng.GetOrderStatus("b3")
+    assert.Error(err)
+
+    // b1 kept its place ahead of b2
+    resp, _ := eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 100, 1006))
+    assert.Equal("b1", resp.Trades[0].RestingOrderID)
+}
+
+func TestCancelReplace_ReplaysFromWAL(t *testing.T) {
+    assert := assert.New(t)
+
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+    _, _ = original.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    _, _, err := original.CancelReplace("b1", newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10010, 60, 1001))
+    assert.NoError(err)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    bids, _ := recovered.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10010, Quantity: 60}}, bids)
+}
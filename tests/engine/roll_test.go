@@ -0,0 +1,109 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestRollSession_ClearsOnlyDayOrders(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionClose: "16:00"}))
+    now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+    eng.SetClock(func() time.Time { return now })
+
+    dayOrder := func(id string, side enginepkg.Side, oType enginepkg.OrderType, price int64) *enginepkg.Order {
+        order := newTestOrder(id, "AAPL", side, oType, price, 100, 1000)
+        order.TimeInForce = enginepkg.Day
+        return order
+    }
+    _, _ = eng.SubmitOrder(newTestOrder("gtc-bid", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1000))
+    _, _ = eng.SubmitOrder(dayOrder("day-bid", enginepkg.Buy, enginepkg.Limit, 9950))
+    _, _ = eng.SubmitOrder(newTestOrder("gtc-ask", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1001))
+    _, _ = eng.SubmitOrder(dayOrder("day-ask", enginepkg.Sell, enginepkg.Limit, 10050))
+    stop := dayOrder("day-stop", enginepkg.Sell, enginepkg.Stop, 0)
+    stop.StopPrice = 9000
+    _, err := eng.SubmitOrder(stop)
+    assert.NoError(err)
+
+    // A trade for the ticker and candles: 40 of day-ask
+    _, _ = eng.SubmitOrder(newTestOrder("taker", "AAPL", enginepkg.Buy, enginepkg.Limit, 10050, 40, 1002))
+    _, traded := eng.GetLastTrade("AAPL")
+    assert.True(traded)
+
+    expired, err := eng.RollSession("AAPL")
+    assert.NoError(err)
+    ids := make([]string, len(expired))
+    for i, order := range expired {
+        ids[i] = order.ID
+        assert.Equal(enginepkg.ReasonExpired, order.CancelReason)
+    }
+    assert.ElementsMatch([]string{"day-bid", "day-ask", "day-stop"}, ids)
+
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 9900, Quantity: 100}}, bids)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10100, Quantity: 100}}, asks)
+    status, _ := eng.GetOrderStatus("day-ask")
+    assert.Equal(enginepkg.StatusCancelled, status.Status)
+    assert.Equal(int64(40), status.FilledQuantity)
+
+    // Per-session state is reset; history is not
+    _, traded = eng.GetLastTrade("AAPL")
+    assert.False(traded)
+    candles, _ := eng.GetCandles("AAPL", "1m", 0)
+    assert.Empty(candles)
+    assert.Len(eng.GetTrades("AAPL", 0, 0), 1)
+
+    // The next trade starts the new session's ticker
+    _, _ = eng.SubmitOrder(newTestOrder("taker-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 10, 1003))
+    last, traded := eng.GetLastTrade("AAPL")
+    assert.True(traded)
+    assert.Equal(int64(10100), last.Price)
+    candles, _ = eng.GetCandles("AAPL", "1m", 0)
+    assert.Len(candles, 1)
+}
+
+func TestRollSession_ReplaysFromWAL(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+    assert.NoError(original.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionClose: "16:00"}))
+    original.SetClock(func() time.Time { return time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC) })
+
+    day := newTestOrder("day", "AAPL", enginepkg.Buy, enginepkg.Limit, 9950, 100, 1000)
+    day.TimeInForce = enginepkg.Day
+    _, _ = original.SubmitOrder(day)
+    _, _ = original.SubmitOrder(newTestOrder("gtc", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1001))
+    _, err := original.RollSession("AAPL")
+    assert.NoError(err)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionClose: "16:00"}))
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    bids, _ := recovered.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 9900, Quantity: 100}}, bids)
+    status, _ := recovered.GetOrderStatus("day")
+    assert.Equal(enginepkg.ReasonExpired, status.CancelReason)
+}
+
+func TestRollSession_RejectsUnknownSymbol(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    eng := setupEngine()
+    eng.SetWAL(enginepkg.NewJSONWAL(&log))
+    eng.SetStrictSymbols(true)
+    assert.NoError(eng.ListSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}))
+
+    _, err := eng.RollSession("GOOG")
+    assert.ErrorIs(err, enginepkg.ErrUnknownSymbol)
+    assert.Equal(0, eng.GlobalStats().Symbols, "no book is created for an unlisted symbol")
+    assert.Zero(log.Len(), "nothing is logged")
+
+    _, err = eng.RollSession("AAPL")
+    assert.NoError(err)
+}
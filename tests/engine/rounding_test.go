@@ -0,0 +1,147 @@
+package engine_test
+
+import (
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+var roundingModes = []enginepkg.RoundingMode{
+    enginepkg.RoundConservative,
+    enginepkg.RoundTruncate,
+    enginepkg.RoundHalfUp,
+    enginepkg.RoundHalfEven,
+}
+
+// roundingEngine returns an engine using mode with cfg configured.
+func roundingEngine(t *testing.T, mode enginepkg.RoundingMode, cfg enginepkg.SymbolConfig) *enginepkg.MatchingEngine {
+    eng := setupEngine()
+    assert.NoError(t, eng.SetRoundingMode(mode))
+    assert.NoError(t, eng.ConfigureSymbol(cfg))
+    return eng
+}
+
+func TestRounding_Fees(t *testing.T) {
+    cases := []struct {
+        name         string
+        price, bps   int64
+        taker, maker [4]int64 // Per mode, in roundingModes order
+    }{
+        {"exact", 10000, 5000, [4]int64{5000, 5000, 5000, 5000}, [4]int64{-5000, -5000, -5000, -5000}},
+        {"half, even below", 10001, 5000, [4]int64{5001, 5000, 5001, 5000}, [4]int64{-5000, -5000, -5001, -5000}},
+        {"half, odd below", 10003, 5000, [4]int64{5002, 5001, 5002, 5002}, [4]int64{-5001, -5001, -5002, -5002}},
+        {"quarter", 10001, 2500, [4]int64{2501, 2500, 2500, 2500}, [4]int64{-2500, -2500, -2500, -2500}},
+        {"three quarters", 10003, 2500, [4]int64{2501, 2500, 2501, 2501}, [4]int64{-2500, -2500, -2501, -2501}},
+        {"just under half", 4999, 1, [4]int64{1, 0, 0, 0}, [4]int64{0, 0, 0, 0}},
+        {"just over half", 5001, 1, [4]int64{1, 0, 1, 1}, [4]int64{0, 0, -1, -1}},
+        {"half beyond 64 bits", 4_000_000_000_000_000_001, 5000, [4]int64{2_000_000_000_000_000_001, 2_000_000_000_000_000_000, 2_000_000_000_000_000_001, 2_000_000_000_000_000_000}, [4]int64{-2_000_000_000_000_000_000, -2_000_000_000_000_000_000, -2_000_000_000_000_000_001, -2_000_000_000_000_000_000}},
+    }
+    for _, tc := range cases {
+        for i, mode := range roundingModes {
+            t.Run(fmt.Sprintf("%s/%s", tc.name, mode), func(t *testing.T) {
+                eng := roundingEngine(t, mode, enginepkg.SymbolConfig{Symbol: "AAPL", Fees: enginepkg.FeeSchedule{TakerFeeBps: tc.bps, MakerRebateBps: tc.bps}})
+                _, err := eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, tc.price, 1, 0))
+                assert.NoError(t, err)
+                resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 1, 1))
+                assert.NoError(t, err)
+                assert.Equal(t, tc.taker[i], resp.Trades[0].AggressorFee)
+                assert.Equal(t, tc.maker[i], resp.Trades[0].RestingFee)
+            })
+        }
+    }
+}
+
+func TestRounding_ProRataShares(t *testing.T) {
+    // Shares of 8 across 5, 5 and 6 are exactly 2.5, 2.5 and 3
+    want := map[enginepkg.RoundingMode][]int64{
+        enginepkg.RoundConservative: {3, 2, 3}, // 2, 2, 3 and the residual to the oldest
+        enginepkg.RoundTruncate:     {3, 2, 3},
+        enginepkg.RoundHalfUp:       {3, 2, 3}, // 3, 3, 3 and the excess back from the newest rounded up
+        enginepkg.RoundHalfEven:     {3, 2, 3}, // 2, 2, 3 and the residual to the oldest
+    }
+    for _, mode := range roundingModes {
+        t.Run(string(mode), func(t *testing.T) {
+            eng := roundingEngine(t, mode, enginepkg.SymbolConfig{Symbol: "AAPL", MatchingAlgorithm: enginepkg.ProRata})
+            for i, qty := range []int64{5, 5, 6} {
+                _, err := eng.SubmitOrder(newTestOrder(fmt.Sprintf("s%d", i), "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, qty, int64(i)))
+                assert.NoError(t, err)
+            }
+            resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 8, 3))
+            assert.NoError(t, err)
+            got := make([]int64, 3)
+            for _, trade := range resp.Trades {
+                var i int
+                fmt.Sscanf(trade.RestingOrderID, "s%d", &i)
+                got[i] += trade.Quantity
+            }
+            assert.Equal(t, want[mode], got)
+        })
+    }
+}
+
+func TestRounding_ProRataIgnoresHiddenOrders(t *testing.T) {
+    // Under every mode the displayed 10 and 10 split 10 evenly; the hidden
+    // 100 at the same price takes nothing
+    for _, mode := range roundingModes {
+        t.Run(string(mode), func(t *testing.T) {
+            eng := roundingEngine(t, mode, enginepkg.SymbolConfig{Symbol: "AAPL", MatchingAlgorithm: enginepkg.ProRata})
+            _, _ = eng.SubmitOrder(hiddenOrder("h", enginepkg.Sell, 10000, 100, 0))
+            _, _ = eng.SubmitOrder(newTestOrder("a", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1))
+            _, _ = eng.SubmitOrder(newTestOrder("b", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 2))
+            resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 3))
+            assert.NoError(t, err)
+            filled := map[string]int64{}
+            for _, trade := range resp.Trades {
+                filled[trade.RestingOrderID] += trade.Quantity
+            }
+            assert.Equal(t, map[string]int64{"a": 5, "b": 5}, filled)
+        })
+    }
+}
+
+func TestRounding_NotionalSpent(t *testing.T) {
+    cases := []struct {
+        name  string
+        price int64
+        spent [4]int64 // Per mode, in roundingModes order
+    }{
+        // A budget of 100.0 buys 0.9 at either price
+        {"90.9", 101, [4]int64{91, 90, 91, 91}},
+        {"94.5", 105, [4]int64{95, 94, 95, 94}},
+        {"90.0", 100, [4]int64{90, 90, 90, 90}},
+    }
+    for _, tc := range cases {
+        for i, mode := range roundingModes {
+            t.Run(fmt.Sprintf("%s/%s", tc.name, mode), func(t *testing.T) {
+                eng := roundingEngine(t, mode, enginepkg.SymbolConfig{Symbol: "BTC", QuantityDecimals: 1})
+                _, err := eng.SubmitOrder(newTestOrder("s1", "BTC", enginepkg.Sell, enginepkg.Limit, tc.price, 9, 0))
+                assert.NoError(t, err)
+                buy := newTestOrder("b1", "BTC", enginepkg.Buy, enginepkg.Market, 0, 0, 1)
+                buy.Notional = 100
+                _, err = eng.SubmitOrder(buy)
+                assert.NoError(t, err)
+                assert.Equal(t, int64(9), buy.FilledQuantity)
+                assert.Equal(t, tc.spent[i], buy.SpentNotional)
+            })
+        }
+    }
+}
+
+func TestRounding_ParseMode(t *testing.T) {
+    for in, want := range map[string]enginepkg.RoundingMode{
+        "":             enginepkg.RoundConservative,
+        "conservative": enginepkg.RoundConservative,
+        "TRUNCATE":     enginepkg.RoundTruncate,
+        "half_up":      enginepkg.RoundHalfUp,
+        "Half_Even":    enginepkg.RoundHalfEven,
+    } {
+        got, err := enginepkg.ParseRoundingMode(in)
+        assert.NoError(t, err, in)
+        assert.Equal(t, want, got, in)
+    }
+    _, err := enginepkg.ParseRoundingMode("HALF_DOWN")
+    assert.Error(t, err)
+    assert.Error(t, setupEngine().SetRoundingMode("CEILING"))
+}
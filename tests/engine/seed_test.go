@@ -0,0 +1,83 @@
+package engine_test
+
+import (
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// seedOrders builds n/2 bids at 9901..9950 and n/2 asks at 10001..10050,
+// ten orders per level, with IDs in slice order.
+func seedOrders(n int) []*enginepkg.Order {
+    orders := make([]*enginepkg.Order, 0, n)
+    for i := 0; i < n/2; i++ {
+        orders = append(orders, newTestOrder(fmt.Sprintf("b%04d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, 9950-int64(i/10), 10, 0))
+        orders = append(orders, newTestOrder(fmt.Sprintf("a%04d", i), "AAPL", enginepkg.Sell, enginepkg.Limit, 10001+int64(i/10), 10, 0))
+    }
+    return orders
+}
+
+func TestSeedBook_LoadsDepthAndPriority(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    assert.NoError(eng.SeedBook("AAPL", seedOrders(1000)))
+
+    snap := eng.GetBookSnapshot("AAPL", 0)
+    assert.Equal(50, snap.TotalBidLevels)
+    assert.Equal(50, snap.TotalAskLevels)
+    assert.Equal(500, snap.TotalBidOrders)
+    assert.Equal(500, snap.TotalAskOrders)
+    assert.Equal(int64(9950), snap.Bids[0].Price)
+    assert.Equal(int64(100), snap.Bids[0].Quantity)
+    assert.Equal(int64(10001), snap.Asks[0].Price)
+    assert.Equal(int64(9901), snap.Bids[49].Price)
+
+    // Sequences follow the slice, so the best bid level fills in seed order
+    resp, err := eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 35, 0))
+    assert.NoError(err)
+    assert.Equal(4, len(resp.Trades))
+    for i, id := range []string{"b0000", "b0001", "b0002", "b0003"} {
+        assert.Equal(id, resp.Trades[i].RestingOrderID)
+    }
+    order, err := eng.GetOrderStatus("b0003")
+    assert.NoError(err)
+    assert.Equal(int64(5), order.FilledQuantity)
+}
+
+func TestSeedBook_RejectsCrossingAndDuplicates(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, err := eng.SubmitOrder(newTestOrder("ask", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 0))
+    assert.NoError(err)
+
+    // Crossing the resting ask
+    err = eng.SeedBook("AAPL", []*enginepkg.Order{
+        newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, 0),
+        newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 0),
+    })
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    // Crossing each other
+    err = eng.SeedBook("AAPL", []*enginepkg.Order{
+        newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9950, 10, 0),
+        newTestOrder("a1", "AAPL", enginepkg.Sell, enginepkg.Limit, 9950, 10, 0),
+    })
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    err = eng.SeedBook("AAPL", []*enginepkg.Order{newTestOrder("ask", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 0)})
+    assert.ErrorIs(err, enginepkg.ErrDuplicateOrderID)
+
+    err = eng.SeedBook("AAPL", []*enginepkg.Order{newTestOrder("m1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 10, 0)})
+    assert.ErrorIs(err, enginepkg.ErrInvalidOrder)
+
+    // Nothing from the failed seeds reached the book
+    snap := eng.GetBookSnapshot("AAPL", 0)
+    assert.Equal(0, snap.TotalBidOrders)
+    assert.Equal(1, snap.TotalAskOrders)
+    _, err = eng.GetOrderStatus("b1")
+    assert.Error(err)
+}
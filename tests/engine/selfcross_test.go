@@ -0,0 +1,54 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestSelfCross_RejectsOrderCrossingOwnRestingOrder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SelfCross: enginepkg.SelfCrossReject}))
+
+    _, _ = eng.SubmitOrder(accountOrder("bid-other", "bob", enginepkg.Buy, enginepkg.Limit, 15100, 10, 1000))
+    _, _ = eng.SubmitOrder(accountOrder("bid-own", "alice", enginepkg.Buy, enginepkg.Limit, 15000, 100, 1001))
+
+    // A sell at 149 would reach alice's own bid at 150 after bob's
+    _, err := eng.SubmitOrder(accountOrder("sell", "alice", enginepkg.Sell, enginepkg.Limit, 14900, 50, 1002))
+    assert.ErrorIs(err, enginepkg.ErrSelfCross)
+    assert.Equal(enginepkg.CodeSelfCross, enginepkg.Code(err))
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Len(bids, 2, "the book is untouched")
+    assert.Empty(asks)
+
+    // Above its own bid, or from another account, the sell is accepted
+    resp, err := eng.SubmitOrder(accountOrder("above", "alice", enginepkg.Sell, enginepkg.Limit, 15100, 5, 1003))
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    _, err = eng.SubmitOrder(accountOrder("other", "carol", enginepkg.Sell, enginepkg.Limit, 14900, 50, 1004))
+    assert.NoError(err)
+
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SelfCross: "IGNORE"}))
+}
+
+func TestSelfCross_RepricesClearOfOwnOrder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", TickSize: 10, SelfCross: enginepkg.SelfCrossReprice}))
+
+    _, _ = eng.SubmitOrder(accountOrder("bid-other", "bob", enginepkg.Buy, enginepkg.Limit, 15100, 10, 1000))
+    _, _ = eng.SubmitOrder(accountOrder("bid-own", "alice", enginepkg.Buy, enginepkg.Limit, 15000, 100, 1001))
+
+    // Trades with bob's better bid, then rests a tick above alice's own
+    sell := accountOrder("sell", "alice", enginepkg.Sell, enginepkg.Limit, 14900, 50, 1002)
+    resp, err := eng.SubmitOrder(sell)
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    assert.Equal(int64(15100), resp.Trades[0].Price)
+    assert.Equal(int64(15010), sell.Price)
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15010, Quantity: 40}}, asks)
+    assert.NoError(eng.Verify("AAPL"))
+}
@@ -0,0 +1,66 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestSelfMatch_SameDeskPrefixDoesNotTrade(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SelfMatchPrefixes: []string{"deskA-", "deskB-"}}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("deskA-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+
+    // Same desk: the aggressor is cancelled, the resting order is untouched
+    same := newTestOrder("deskA-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1001)
+    resp, err := eng.SubmitOrder(same)
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    assert.False(resp.OrderInBook)
+    assert.Equal(enginepkg.StatusCancelled, same.Status)
+    assert.Equal(enginepkg.ReasonSelfMatch, same.CancelReason)
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 10000, Quantity: 100}}, asks)
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Empty(bids, "the crossing remainder must not rest")
+
+    // Another desk matches normally
+    other := newTestOrder("deskB-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 50, 1002)
+    resp, err = eng.SubmitOrder(other)
+    assert.NoError(err)
+    assert.Len(resp.Trades, 1)
+    assert.Equal("deskA-1", resp.Trades[0].RestingOrderID)
+    assert.Equal(enginepkg.StatusFilled, other.Status)
+}
+
+func TestSelfMatch_StopsPartWayThroughTheBook(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SelfMatchPrefixes: []string{"deskA-"}}))
+
+    _, _ = eng.SubmitOrder(newTestOrder("ext-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 30, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("deskA-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 30, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("ext-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 30, 1002))
+
+    // Trades with ext-1, then stops at its own desk's order
+    buy := newTestOrder("deskA-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 80, 1003)
+    resp, _ := eng.SubmitOrder(buy)
+    assert.Len(resp.Trades, 1)
+    assert.Equal("ext-1", resp.Trades[0].RestingOrderID)
+    assert.Equal(enginepkg.StatusPartialFill, buy.Status)
+    assert.Equal(enginepkg.ReasonSelfMatch, buy.CancelReason)
+    assert.Equal(int64(30), buy.FilledQuantity)
+
+    // A market order that could only fill through its own desk is rejected
+    _, err := eng.SubmitOrder(newTestOrder("deskA-3", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 40, 1004))
+    assert.ErrorIs(err, enginepkg.ErrInsufficientLiquidity)
+
+    // Orders outside any desk are unaffected
+    resp, _ = eng.SubmitOrder(newTestOrder("ext-3", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 60, 1005))
+    assert.Len(resp.Trades, 2)
+
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SelfMatchPrefixes: []string{""}}))
+}
@@ -0,0 +1,61 @@
+package engine_test
+
+import (
+    "bytes"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestSequence_StrictlyIncreasing(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    var last int64
+    for i := 0; i < 1000; i++ {
+        // Same timestamp for all: only the sequence can order them
+        order := newTestOrder(fmt.Sprintf("o%d", i), "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 1, 1000)
+        _, err := eng.SubmitOrder(order)
+        assert.NoError(err)
+        assert.Greater(order.Sequence, last)
+        last = order.Sequence
+    }
+
+    // An amendment that loses priority takes a fresh sequence
+    amended, _, err := eng.AmendOrder("o0", 10010, 0)
+    assert.NoError(err)
+    assert.Greater(amended.Sequence, last)
+}
+
+func TestSequence_AuctionAggressorIsLaterArrival(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.StartAuction("AAPL"))
+
+    // Identical timestamps; the sell arrived second
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+
+    _, trades := eng.RunAuction("AAPL")
+    assert.Equal(1, len(trades))
+    assert.Equal("s1", trades[0].AggressorOrderID)
+    assert.Equal(enginepkg.Sell, trades[0].AggressorSide)
+}
+
+func TestSequence_ContinuesAfterSnapshot(t *testing.T) {
+    assert := assert.New(t)
+
+    original := setupEngine()
+    first := newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000)
+    _, _ = original.SubmitOrder(first)
+    var buf bytes.Buffer
+    assert.NoError(original.Snapshot(&buf))
+
+    restored := setupEngine()
+    assert.NoError(restored.LoadSnapshot(&buf))
+    next := newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001)
+    _, _ = restored.SubmitOrder(next)
+    assert.Greater(next.Sequence, first.Sequence)
+}
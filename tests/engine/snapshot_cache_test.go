@@ -0,0 +1,95 @@
+package engine_test
+
+import (
+    "fmt"
+    "sync"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestCachedSnapshot_TracksEveryChange(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.SeedBook("AAPL", seedOrders(200)))
+
+    check := func() {
+        for _, depth := range []int{0, 1, 5, 1000} {
+            assert.Equal(eng.GetBookSnapshot("AAPL", depth), eng.GetCachedSnapshot("AAPL", depth), "depth %d", depth)
+        }
+    }
+    check()
+
+    _, err := eng.SubmitOrder(newTestOrder("take", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 25, 0))
+    assert.NoError(err)
+    check()
+    _, err = eng.CancelOrder("a0000")
+    assert.NoError(err)
+    check()
+    _, _, err = eng.AmendOrder("b0010", 0, 5)
+    assert.NoError(err)
+    check()
+    // Hidden orders change nothing shown, and the cache need not notice
+    hidden := hiddenOrder("h1", enginepkg.Buy, 9960, 50, 0)
+    _, err = eng.SubmitOrder(hidden)
+    assert.NoError(err)
+    check()
+
+    // Callers own the returned levels
+    snap := eng.GetCachedSnapshot("AAPL", 0)
+    snap.Bids[0].Quantity = -1
+    assert.NotEqual(int64(-1), eng.GetCachedSnapshot("AAPL", 0).Bids[0].Quantity)
+}
+
+func TestCachedSnapshot_ConsistentUnderConcurrentMutation(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.SeedBook("AAPL", seedOrders(200)))
+
+    var wg sync.WaitGroup
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        for i := 0; i < 2000; i++ {
+            side := enginepkg.Buy
+            if i%2 == 1 {
+                side = enginepkg.Sell
+            }
+            eng.SubmitOrder(newTestOrder(fmt.Sprintf("m%d", i), "AAPL", side, enginepkg.Limit, 9990+int64(i%21), int64(1+i%5), 0))
+        }
+    }()
+
+    compared := 0
+    for i := 0; i < 2000; i++ {
+        cached := eng.GetCachedSnapshot("AAPL", 10)
+        fresh := eng.GetBookSnapshot("AAPL", 10)
+        // Only snapshots of the same book state are comparable
+        if cached.Sequence == fresh.Sequence {
+            assert.Equal(fresh, cached)
+            compared++
+        }
+        assert.LessOrEqual(cached.Sequence, fresh.Sequence)
+    }
+    wg.Wait()
+    assert.Equal(eng.GetBookSnapshot("AAPL", 0), eng.GetCachedSnapshot("AAPL", 0))
+    assert.Greater(compared, 0)
+}
+
+func BenchmarkGetBookSnapshot(b *testing.B) {
+    eng := setupEngine()
+    eng.SeedBook("AAPL", seedOrders(1000))
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        eng.GetBookSnapshot("AAPL", 20)
+    }
+}
+
+func BenchmarkGetCachedSnapshot(b *testing.B) {
+    eng := setupEngine()
+    eng.SeedBook("AAPL", seedOrders(1000))
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        eng.GetCachedSnapshot("AAPL", 20)
+    }
+}
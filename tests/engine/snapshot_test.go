@@ -0,0 +1,61 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func seedSnapshotBook(eng *enginepkg.MatchingEngine) {
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 200, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-3", "AAPL", enginepkg.Sell, enginepkg.Limit, 15055, 400, 1002))
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15040, 500, 1003))
+    _, _ = eng.SubmitOrder(newTestOrder("buy-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 50, 1004)) // partially fills sell-1
+    _, _ = eng.SubmitOrder(newTestOrder("sell-4", "MSFT", enginepkg.Sell, enginepkg.Limit, 30000, 100, 1005))
+    _, _ = eng.CancelOrder("buy-1")
+}
+
+// TestSnapshot_RoundTripPreservesMatching checks a restored engine matches identically
+func TestSnapshot_RoundTripPreservesMatching(t *testing.T) {
+    assert := assert.New(t)
+
+    original := setupEngine()
+    seedSnapshotBook(original)
+
+    var buf bytes.Buffer
+    assert.NoError(original.Snapshot(&buf))
+
+    restored := setupEngine()
+    assert.NoError(restored.LoadSnapshot(bytes.NewReader(buf.Bytes())))
+
+    for _, symbol := range []string{"AAPL", "MSFT"} {
+        b1, a1 := original.GetOrderBookSnapshot(symbol, 0)
+        b2, a2 := restored.GetOrderBookSnapshot(symbol, 0)
+        assert.Equal(b1, b2)
+        assert.Equal(a1, a2)
+    }
+
+    cancelled, err := restored.GetOrderStatus("buy-1")
+    assert.NoError(err)
+    assert.Equal(enginepkg.StatusCancelled, cancelled.Status)
+
+    // The same sweep must hit the same orders in the same FIFO order
+    resp1, _ := original.SubmitOrder(newTestOrder("sweep", "AAPL", enginepkg.Buy, enginepkg.Limit, 15055, 700, 2000))
+    resp2, _ := restored.SubmitOrder(newTestOrder("sweep", "AAPL", enginepkg.Buy, enginepkg.Limit, 15055, 700, 2000))
+    assert.Equal(len(resp1.Trades), len(resp2.Trades))
+    for i := range resp1.Trades {
+        assert.Equal(resp1.Trades[i].RestingOrderID, resp2.Trades[i].RestingOrderID)
+        assert.Equal(resp1.Trades[i].Price, resp2.Trades[i].Price)
+        assert.Equal(resp1.Trades[i].Quantity, resp2.Trades[i].Quantity)
+    }
+}
+
+// TestLoadSnapshot_RejectsUnknownVersion checks versioning is enforced
+func TestLoadSnapshot_RejectsUnknownVersion(t *testing.T) {
+    eng := setupEngine()
+    err := eng.LoadSnapshot(bytes.NewReader([]byte(`{"version":99}`)))
+    assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package engine_test
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestOldestOrderAge(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    now := time.UnixMilli(1_700_000_000_000)
+    eng.SetClock(func() time.Time { return now })
+
+    assert.Equal(time.Duration(0), eng.OldestOrderAge("AAPL"), "empty book")
+
+    // The oldest order sits at the front of a level behind the best ask
+    _, _ = eng.SubmitOrder(newTestOrder("new", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, now.Add(-time.Minute).UnixMilli()))
+    _, _ = eng.SubmitOrder(newTestOrder("old", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, now.Add(-2*time.Hour).UnixMilli()))
+    _, _ = eng.SubmitOrder(newTestOrder("bid", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, now.Add(-time.Hour).UnixMilli()))
+    assert.Equal(2*time.Hour, eng.OldestOrderAge("AAPL"))
+    assert.Equal(2*time.Hour, eng.GlobalStats().OldestOrderAge)
+
+    _, _ = eng.CancelOrder("old")
+    assert.Equal(time.Hour, eng.OldestOrderAge("AAPL"))
+}
+
+func TestCheckStaleOrders_WarnsOnceWithoutCancelling(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    now := time.UnixMilli(1_700_000_000_000)
+
+    _, _ = eng.SubmitOrder(newTestOrder("stale", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, now.Add(-2*time.Hour).UnixMilli()))
+    hidden := newTestOrder("hidden", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, now.Add(-3*time.Hour).UnixMilli())
+    hidden.Hidden = true
+    _, _ = eng.SubmitOrder(hidden)
+    _, _ = eng.SubmitOrder(newTestOrder("fresh", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 10, now.UnixMilli()))
+
+    assert.Empty(eng.CheckStaleOrders(now), "off by default")
+    assert.Error(eng.SetMaxOrderAge(-time.Second))
+    assert.NoError(eng.SetMaxOrderAge(time.Hour))
+
+    stale := eng.CheckStaleOrders(now)
+    assert.Len(stale, 2)
+    assert.Equal("stale", stale[0].ID)
+    assert.Equal("hidden", stale[1].ID)
+    assert.Empty(eng.CheckStaleOrders(now), "each order is reported once")
+
+    status, _ := eng.GetOrderStatus("stale")
+    assert.Equal(enginepkg.StatusAccepted, status.Status, "stale orders are not cancelled")
+}
@@ -0,0 +1,34 @@
+package engine_test
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestGlobalStats_AggregatesAcrossSymbols(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    empty := eng.GlobalStats()
+    assert.Equal(0, empty.Symbols)
+    assert.Equal(int64(0), empty.TradesExecuted)
+
+    _, _ = eng.SubmitOrder(newTestOrder("a1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 50, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("a2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 50, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("m1", "MSFT", enginepkg.Buy, enginepkg.Limit, 30000, 20, 1002))
+
+    // Two trades in AAPL (80 shares), one in MSFT (5 shares)
+    _, _ = eng.SubmitOrder(newTestOrder("a3", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 80, 1003))
+    _, _ = eng.SubmitOrder(newTestOrder("m2", "MSFT", enginepkg.Sell, enginepkg.Limit, 30000, 5, 1004))
+    _, _ = eng.CancelOrder("m1")
+
+    stats := eng.GlobalStats()
+    assert.Equal(2, stats.Symbols)
+    assert.Equal(1, stats.RestingOrders, "only a2's remainder is left")
+    assert.Equal(int64(3), stats.TradesExecuted)
+    assert.Equal(int64(85), stats.Volume)
+    assert.Greater(stats.Uptime, time.Duration(0))
+}
@@ -0,0 +1,151 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func newStopOrder(id string, side enginepkg.Side, oType enginepkg.OrderType, price, stopPrice, quantity int64, ts int64) *enginepkg.Order {
+    o := newTestOrder(id, "AAPL", side, oType, price, quantity, ts)
+    o.StopPrice = stopPrice
+    return o
+}
+
+// TestStop_MarketOrderCascadesThroughStop checks a large market order fires a resting buy stop
+func TestStop_MarketOrderCascadesThroughStop(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15060, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-3", "AAPL", enginepkg.Sell, enginepkg.Limit, 15070, 200, 1002))
+
+    stop := newStopOrder("stop-buy", enginepkg.Buy, enginepkg.Stop, 0, 15060, 100, 1003)
+    resp, err := eng.SubmitOrder(stop)
+    assert.NoError(err)
+    assert.Equal(0, len(resp.Trades), "stop must stay dormant")
+    assert.Equal(enginepkg.StatusAccepted, stop.Status)
+
+    market := newTestOrder("mkt-buy", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 200, 1004)
+    resp, err = eng.SubmitOrder(market)
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+
+    // Last print at 15060 reaches the stop, which then lifts the 15070 level
+    assert.Equal(1, len(resp.TriggeredOrders))
+    assert.Equal("stop-buy", resp.TriggeredOrders[0].ID)
+    assert.Equal(1, len(resp.TriggeredTrades))
+    assert.Equal(int64(15070), resp.TriggeredTrades[0].Price)
+    assert.Equal(enginepkg.StatusFilled, stop.Status)
+
+    status, _ := eng.GetOrderStatus("sell-3")
+    assert.Equal(int64(100), status.RemainingQuantity())
+}
+
+// TestStopLimit_TriggersAsLimitAndRests checks a sell stop-limit becomes a resting limit
+func TestStopLimit_TriggersAsLimitAndRests(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15000, 100, 1000))
+    stop := newStopOrder("stop-sell", enginepkg.Sell, enginepkg.StopLimit, 14990, 15000, 100, 1001)
+    _, _ = eng.SubmitOrder(stop)
+
+    resp, err := eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15000, 100, 1002))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal(1, len(resp.TriggeredOrders))
+    assert.Equal(enginepkg.Limit, stop.Type)
+
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal(1, len(asks))
+    assert.Equal(int64(14990), asks[0].Price)
+}
+
+// TestStop_CancelArmedStop checks armed stops can be cancelled before they fire
+func TestStop_CancelArmedStop(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    _, _ = eng.SubmitOrder(newStopOrder("stop-buy", enginepkg.Buy, enginepkg.Stop, 0, 15050, 100, 1001))
+
+    _, err := eng.CancelOrder("stop-buy")
+    assert.NoError(err)
+
+    resp, _ := eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 50, 1002))
+    assert.Equal(0, len(resp.TriggeredOrders))
+}
+
+// TestMarketIfTouched_BuyTriggersOnDowntick checks a buy MIT ignores upticks
+// and fires as a market order once the price falls to its trigger
+func TestMarketIfTouched_BuyTriggersOnDowntick(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    mit := newStopOrder("mit-buy", enginepkg.Buy, enginepkg.MarketIfTouched, 0, 14900, 50, 1000)
+    _, err := eng.SubmitOrder(mit)
+    assert.NoError(err)
+
+    // An uptick to 15100 is the wrong direction for a buy MIT
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15100, 10, 1001))
+    resp, _ := eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15100, 10, 1002))
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal(0, len(resp.TriggeredOrders), "buy MIT must not fire on an uptick")
+    assert.Equal(enginepkg.MarketIfTouched, mit.Type)
+
+    // A print at 14900 touches it; it then lifts the 14950 offer
+    _, _ = eng.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 14950, 100, 1003))
+    _, _ = eng.SubmitOrder(newTestOrder("bid-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 14900, 10, 1004))
+    resp, err = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 10, 1005))
+    assert.NoError(err)
+    if assert.Equal(1, len(resp.TriggeredOrders)) {
+        assert.Equal("mit-buy", resp.TriggeredOrders[0].ID)
+    }
+    if assert.Equal(1, len(resp.TriggeredTrades)) {
+        assert.Equal(int64(14950), resp.TriggeredTrades[0].Price)
+        assert.Equal(int64(50), resp.TriggeredTrades[0].Quantity)
+    }
+    assert.Equal(enginepkg.Market, mit.Type)
+    assert.Equal(enginepkg.StatusFilled, mit.Status)
+}
+
+// TestMarketIfTouched_SellTriggersOnUptick checks a sell MIT ignores downticks
+// and fires once the price rises to its trigger, and that a sell
+// limit-if-touched fires alongside it and rests at its limit
+func TestMarketIfTouched_SellTriggersOnUptick(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    mit := newStopOrder("mit-sell", enginepkg.Sell, enginepkg.MarketIfTouched, 0, 15100, 30, 1000)
+    lit := newStopOrder("lit-sell", enginepkg.Sell, enginepkg.LimitIfTouched, 15200, 15050, 20, 1001)
+    _, err := eng.SubmitOrder(mit)
+    assert.NoError(err)
+    _, err = eng.SubmitOrder(lit)
+    assert.NoError(err)
+
+    // A downtick to 14900 is the wrong direction for a sell MIT
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 14900, 10, 1002))
+    resp, _ := eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 14900, 10, 1003))
+    assert.Equal(0, len(resp.TriggeredOrders), "sell MIT must not fire on a downtick")
+
+    // A print at 15100 fires the MIT, whose fill at 15050 then touches the LIT
+    _, _ = eng.SubmitOrder(newTestOrder("bid-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 100, 1004))
+    _, _ = eng.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15100, 10, 1005))
+    resp, err = eng.SubmitOrder(newTestOrder("buy-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 15100, 10, 1006))
+    assert.NoError(err)
+    assert.Equal(2, len(resp.TriggeredOrders))
+    if assert.Equal(1, len(resp.TriggeredTrades)) {
+        assert.Equal("mit-sell", resp.TriggeredTrades[0].AggressorOrderID)
+        assert.Equal(int64(15050), resp.TriggeredTrades[0].Price)
+        assert.Equal(int64(30), resp.TriggeredTrades[0].Quantity)
+    }
+    assert.Equal(enginepkg.StatusFilled, mit.Status)
+
+    // The take-profit limit rests at 15200
+    assert.Equal(enginepkg.Limit, lit.Type)
+    _, asks := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 15200, Quantity: 20}}, asks)
+}
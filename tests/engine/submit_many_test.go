@@ -0,0 +1,112 @@
+package engine_test
+
+import (
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// orderFlow is a deterministic mix of crossing limits, market orders and
+// rejects across the given symbols.
+func orderFlow(n int, symbols ...string) []*enginepkg.Order {
+    orders := make([]*enginepkg.Order, n)
+    for i := range orders {
+        side := enginepkg.Buy
+        if i%2 == 1 {
+            side = enginepkg.Sell
+        }
+        symbol := symbols[i%len(symbols)]
+        id := fmt.Sprintf("o%d", i)
+        switch {
+        case i%17 == 0:
+            orders[i] = newTestOrder(id, symbol, side, enginepkg.Market, 0, int64(5+i%7), 0)
+        case i%29 == 0:
+            orders[i] = newTestOrder(id, symbol, side, enginepkg.Limit, 0, 10, 0) // Rejected: no price
+        default:
+            orders[i] = newTestOrder(id, symbol, side, enginepkg.Limit, 10000+int64((i*7919)%21)-10, int64(10+i%13), 0)
+        }
+    }
+    return orders
+}
+
+func TestSubmitMany_MatchesOneAtATime(t *testing.T) {
+    assert := assert.New(t)
+    one, many := setupEngine(), setupEngine()
+
+    single := make([]enginepkg.BatchResult, 0, 2000)
+    for _, order := range orderFlow(2000, "AAPL", "MSFT") {
+        single = append(single, one.SubmitBatch([]*enginepkg.Order{order})...)
+    }
+    grouped := many.SubmitMany(orderFlow(2000, "AAPL", "MSFT"))
+
+    assert.Equal(len(single), len(grouped))
+    for i := range single {
+        want, got := single[i], grouped[i]
+        assert.Equal(want.Err != nil, got.Err != nil, "order %d", i)
+        if want.Err != nil {
+            assert.Equal(enginepkg.Code(want.Err), enginepkg.Code(got.Err))
+            continue
+        }
+        assert.Equal(want.Order.ID, got.Order.ID)
+        assert.Equal(want.Order.Status, got.Order.Status, "order %d", i)
+        assert.Equal(want.Order.FilledQuantity, got.Order.FilledQuantity, "order %d", i)
+        assert.Equal(want.Response.OrderInBook, got.Response.OrderInBook, "order %d", i)
+        assert.Equal(len(want.Response.Trades), len(got.Response.Trades), "order %d", i)
+        for k := range want.Response.Trades {
+            w, g := want.Response.Trades[k], got.Response.Trades[k]
+            assert.Equal(w.RestingOrderID, g.RestingOrderID)
+            assert.Equal(w.Price, g.Price)
+            assert.Equal(w.Quantity, g.Quantity)
+        }
+    }
+    for _, symbol := range []string{"AAPL", "MSFT"} {
+        want, got := one.GetBookSnapshot(symbol, 0), many.GetBookSnapshot(symbol, 0)
+        assert.Equal(want.Bids, got.Bids)
+        assert.Equal(want.Asks, got.Asks)
+    }
+}
+
+func TestSubmitMany_ResultsInInputOrder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    results := eng.SubmitMany([]*enginepkg.Order{
+        newTestOrder("a-sell", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 0),
+        newTestOrder("m-buy", "MSFT", enginepkg.Buy, enginepkg.Market, 0, 10, 0),
+        newTestOrder("a-buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 40, 0),
+        newTestOrder("bad", "AAPL", enginepkg.Buy, enginepkg.Limit, 0, 40, 0), // No price
+    })
+    assert.Equal(4, len(results))
+    assert.Equal("a-sell", results[0].Order.ID)
+    assert.ErrorIs(results[1].Err, enginepkg.ErrInsufficientLiquidity)
+    assert.Equal("a-buy", results[2].Order.ID)
+    assert.Equal(enginepkg.StatusFilled, results[2].Order.Status)
+    assert.Equal("a-sell", results[2].Response.Trades[0].RestingOrderID)
+    assert.ErrorIs(results[3].Err, enginepkg.ErrInvalidOrder)
+}
+
+const benchOrders = 100_000
+
+func BenchmarkSubmitOrder(b *testing.B) {
+    for i := 0; i < b.N; i++ {
+        b.StopTimer()
+        eng := setupEngine()
+        orders := orderFlow(benchOrders, "AAPL")
+        b.StartTimer()
+        for _, order := range orders {
+            eng.SubmitOrder(order)
+        }
+    }
+}
+
+func BenchmarkSubmitMany(b *testing.B) {
+    for i := 0; i < b.N; i++ {
+        b.StopTimer()
+        eng := setupEngine()
+        orders := orderFlow(benchOrders, "AAPL")
+        b.StartTimer()
+        eng.SubmitMany(orders)
+    }
+}
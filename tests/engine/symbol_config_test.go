@@ -0,0 +1,55 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestSymbolConfig_TickAndLot(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", TickSize: 5, LotSize: 10, MinQty: 10, MaxQty: 1000}))
+
+    _, err := eng.SubmitOrder(newTestOrder("off-tick", "AAPL", enginepkg.Buy, enginepkg.Limit, 10002, 100, 1000))
+    assert.ErrorContains(err, "tick size 5")
+
+    _, err = eng.SubmitOrder(newTestOrder("off-lot", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 105, 1001))
+    assert.ErrorContains(err, "lot size 10")
+
+    _, err = eng.SubmitOrder(newTestOrder("too-big", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 2000, 1002))
+    assert.ErrorContains(err, "exceeds maximum")
+
+    // Rejected orders never reach the store
+    _, err = eng.GetOrderStatus("off-tick")
+    assert.Error(err)
+
+    _, err = eng.SubmitOrder(newTestOrder("ok", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1003))
+    assert.NoError(err)
+
+    // Market orders carry no price, so only the lot applies
+    _, err = eng.SubmitOrder(newTestOrder("mkt", "AAPL", enginepkg.Sell, enginepkg.Market, 0, 50, 1004))
+    assert.NoError(err)
+
+    _, _, err = eng.AmendOrder("ok", 10001, 0)
+    assert.ErrorContains(err, "tick size")
+}
+
+func TestSymbolConfig_Strict(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL"}))
+
+    // Unknown symbols trade under defaults unless strict
+    _, err := eng.SubmitOrder(newTestOrder("a", "MSFT", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1000))
+    assert.NoError(err)
+
+    eng.SetStrictSymbols(true)
+    _, err = eng.SubmitOrder(newTestOrder("b", "GOOG", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+    assert.ErrorContains(err, "unknown symbol")
+    _, err = eng.SubmitOrder(newTestOrder("c", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1002))
+    assert.NoError(err)
+
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "X", MinQty: 10, MaxQty: 5}))
+}
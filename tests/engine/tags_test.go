@@ -0,0 +1,94 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// taggedOrder is a resting limit order carrying client metadata.
+func taggedOrder(id, symbol string, side enginepkg.Side, price int64, metadata map[string]string) *enginepkg.Order {
+    order := newTestOrder(id, symbol, side, enginepkg.Limit, price, 100, 1000)
+    order.ClientMetadata = metadata
+    return order
+}
+
+func TestTags_CancelByTag(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    a := map[string]string{"strategy": "strategyA"}
+    for _, o := range []*enginepkg.Order{
+        taggedOrder("a1", "AAPL", enginepkg.Buy, 9900, a),
+        taggedOrder("a2", "MSFT", enginepkg.Sell, 30100, a),
+        taggedOrder("a3", "AAPL", enginepkg.Sell, 10100, map[string]string{"strategy": "strategyA", "desk": "1"}),
+        taggedOrder("b1", "AAPL", enginepkg.Buy, 9800, map[string]string{"strategy": "strategyB"}),
+        taggedOrder("other-key", "AAPL", enginepkg.Buy, 9700, map[string]string{"parent": "strategyA"}),
+        newTestOrder("untagged", "AAPL", enginepkg.Buy, enginepkg.Limit, 9600, 100, 1000),
+    } {
+        _, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+    }
+    // a1 fills; it is no longer open
+    _, _ = eng.SubmitOrder(newTestOrder("taker", "AAPL", enginepkg.Sell, enginepkg.Limit, 9900, 100, 1001))
+
+    ids := func(orders []*enginepkg.Order) []string {
+        out := []string{}
+        for _, o := range orders {
+            out = append(out, o.ID)
+        }
+        return out
+    }
+    strategyA := enginepkg.MetadataTag{Key: "strategy", Value: "strategyA"}
+    assert.Equal([]string{"a3", "a2"}, ids(eng.GetOrdersByTag(strategyA)), "by symbol, then oldest first")
+    assert.Equal([]string{"a3", "other-key", "a2"}, ids(eng.GetOrdersByTag(enginepkg.MetadataTag{Value: "strategyA"})), "the value under any key")
+
+    cancelled, err := eng.CancelByTag(strategyA)
+    assert.NoError(err)
+    assert.Equal([]string{"a3", "a2"}, ids(cancelled))
+    for _, id := range []string{"a2", "a3"} {
+        status, _ := eng.GetOrderStatus(id)
+        assert.Equal(enginepkg.StatusCancelled, status.Status)
+        assert.Equal(enginepkg.ReasonCancelTag, status.CancelReason)
+    }
+    assert.Empty(eng.GetOrdersByTag(strategyA))
+    assert.Equal([]string{"b1"}, ids(eng.GetOrdersByTag(enginepkg.MetadataTag{Key: "strategy", Value: "strategyB"})))
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Len(bids, 3, "b1, other-key and untagged still rest")
+}
+
+func TestTags_IndexSurvivesSnapshot(t *testing.T) {
+    assert := assert.New(t)
+    original := setupEngine()
+    _, _ = original.SubmitOrder(taggedOrder("a1", "AAPL", enginepkg.Buy, 9900, map[string]string{"strategy": "A"}))
+    var buf bytes.Buffer
+    assert.NoError(original.Snapshot(&buf))
+
+    restored := setupEngine()
+    assert.NoError(restored.LoadSnapshot(&buf))
+    cancelled, err := restored.CancelByTag(enginepkg.MetadataTag{Key: "strategy", Value: "A"})
+    assert.NoError(err)
+    assert.Len(cancelled, 1)
+}
+
+func TestTags_CancelByTagReplaysCancelReason(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(taggedOrder("a1", "AAPL", enginepkg.Buy, 9900, map[string]string{"strategy": "A"}))
+    _, _ = original.SubmitOrder(taggedOrder("b1", "AAPL", enginepkg.Buy, 9800, map[string]string{"strategy": "B"}))
+    _, err := original.CancelByTag(enginepkg.MetadataTag{Key: "strategy", Value: "A"})
+    assert.NoError(err)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+    order, _ := recovered.GetOrderStatus("a1")
+    assert.Equal(enginepkg.StatusCancelled, order.Status)
+    assert.Equal(enginepkg.ReasonCancelTag, order.CancelReason)
+    order, _ = recovered.GetOrderStatus("b1")
+    assert.Equal(enginepkg.StatusAccepted, order.Status)
+}
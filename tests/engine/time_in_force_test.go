@@ -0,0 +1,92 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestIOC_PartialFillCancelsRemainder checks that an IOC order takes what it can and never rests
+func TestIOC_PartialFillCancelsRemainder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1000))
+
+    ioc := newTestOrder("ioc-buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 500, 1001)
+    ioc.TimeInForce = enginepkg.IOC
+    resp, err := eng.SubmitOrder(ioc)
+
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+    assert.Equal(int64(300), ioc.FilledQuantity)
+    assert.Equal(enginepkg.StatusPartialFill, ioc.Status)
+    assert.False(resp.OrderInBook, "IOC remainder must not rest")
+
+    bids, asks := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal(0, len(bids))
+    assert.Equal(0, len(asks))
+}
+
+// TestIOC_NoLiquidityIsCancelled checks that an IOC with nothing to match ends CANCELLED
+func TestIOC_NoLiquidityIsCancelled(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15060, 300, 1000))
+
+    ioc := newTestOrder("ioc-buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 500, 1001)
+    ioc.TimeInForce = enginepkg.IOC
+    resp, err := eng.SubmitOrder(ioc)
+
+    assert.NoError(err)
+    assert.Equal(0, len(resp.Trades))
+    assert.Equal(enginepkg.StatusCancelled, ioc.Status)
+
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 10)
+    assert.Equal(0, len(bids))
+}
+
+// TestFOK_FillsAcrossMultipleLevels checks a FOK that needs two price levels executes in full
+func TestFOK_FillsAcrossMultipleLevels(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15052, 400, 1001))
+
+    fok := newTestOrder("fok-buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 15052, 600, 1002)
+    fok.TimeInForce = enginepkg.FOK
+    resp, err := eng.SubmitOrder(fok)
+
+    assert.NoError(err)
+    assert.Equal(2, len(resp.Trades))
+    assert.Equal(enginepkg.StatusFilled, fok.Status)
+
+    status, _ := eng.GetOrderStatus("sell-2")
+    assert.Equal(int64(100), status.RemainingQuantity())
+}
+
+// TestFOK_BlockedByLimitPrice checks liquidity beyond the limit price does not count
+func TestFOK_BlockedByLimitPrice(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 300, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15055, 400, 1001))
+
+    fok := newTestOrder("fok-buy", "AAPL", enginepkg.Buy, enginepkg.Limit, 15052, 600, 1002)
+    fok.TimeInForce = enginepkg.FOK
+    resp, err := eng.SubmitOrder(fok)
+
+    assert.Error(err)
+    assert.Contains(err.Error(), "insufficient liquidity")
+    assert.Equal(0, len(resp.Trades))
+
+    // Book is untouched and the rejected order is not tracked
+    status, _ := eng.GetOrderStatus("sell-1")
+    assert.Equal(int64(300), status.RemainingQuantity())
+    _, err = eng.GetOrderStatus("fok-buy")
+    assert.Error(err)
+}
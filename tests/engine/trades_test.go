@@ -0,0 +1,117 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestGetTrades_ChronologicalAndPaged checks the trade log survives past the submit response
+func TestGetTrades_ChronologicalAndPaged(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15052, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-3", "MSFT", enginepkg.Sell, enginepkg.Limit, 30000, 100, 1002))
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15052, 200, 1003))
+    _, _ = eng.SubmitOrder(newTestOrder("buy-2", "MSFT", enginepkg.Buy, enginepkg.Limit, 30000, 100, 1004))
+
+    trades := eng.GetTrades("AAPL", 0, 0)
+    assert.Equal(2, len(trades))
+    assert.Equal("sell-1", trades[0].RestingOrderID)
+    assert.Equal("sell-2", trades[1].RestingOrderID)
+    assert.Equal("buy-1", trades[1].AggressorOrderID)
+    assert.Equal("AAPL", trades[0].Symbol)
+
+    first := eng.GetTrades("AAPL", 0, 1)
+    assert.Equal(1, len(first))
+
+    later := eng.GetTrades("AAPL", trades[1].Timestamp, 10)
+    assert.Equal(0, len(later), "since is exclusive")
+}
+
+// TestSubscribeTrades_ReceivesTriggeredPrints checks stop-triggered trades are streamed too
+func TestSubscribeTrades_ReceivesTriggeredPrints(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    sub := eng.SubscribeTrades("AAPL")
+    defer sub.Close()
+
+    _, _ = eng.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15060, 100, 1001))
+    stop := newTestOrder("stop-buy", "AAPL", enginepkg.Buy, enginepkg.Stop, 0, 100, 1002)
+    stop.StopPrice = 15050
+    _, _ = eng.SubmitOrder(stop)
+    _, _ = eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 100, 1003))
+
+    first := <-sub.C
+    second := <-sub.C
+    assert.Equal("buy-1", first.AggressorOrderID)
+    assert.Equal(enginepkg.Buy, first.AggressorSide)
+    assert.Equal("stop-buy", second.AggressorOrderID)
+    assert.Equal(int64(15060), second.Price)
+}
+
+func TestGetTradesForOrder(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("other", "MSFT", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1002))
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 150, 1003))
+
+    // Filled across two resting orders: two trades, as aggressor
+    trades := eng.GetTradesForOrder("b1")
+    assert.Len(trades, 2)
+    assert.Equal("s1", trades[0].RestingOrderID)
+    assert.Equal("s2", trades[1].RestingOrderID)
+
+    // Resting orders see their side of it, fully or partially filled
+    trades = eng.GetTradesForOrder("s1")
+    assert.Len(trades, 1)
+    assert.Equal(int64(100), trades[0].Quantity)
+    trades = eng.GetTradesForOrder("s2")
+    assert.Len(trades, 1)
+    assert.Equal(int64(50), trades[0].Quantity)
+    assert.Equal("b1", trades[0].AggressorOrderID)
+
+    assert.Empty(eng.GetTradesForOrder("other"))
+    assert.Empty(eng.GetTradesForOrder("missing"))
+}
+
+func TestSubscribeExecutions_RollsUpMultiLevelFill(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    trades := eng.SubscribeTrades("AAPL")
+    defer trades.Close()
+    executions := eng.SubscribeExecutions("AAPL")
+    defer executions.Close()
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 100, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 100, 1002))
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10200, 250, 1003))
+    assert.NoError(err)
+    assert.Len(resp.Trades, 3)
+
+    // Per trade: three messages
+    for _, trade := range resp.Trades {
+        assert.Equal(trade, <-trades.C)
+    }
+    assert.Len(trades.C, 0)
+
+    // Per order: one, with the total and the quantity-weighted average
+    exec := <-executions.C
+    assert.Len(executions.C, 0)
+    assert.Equal("b1", exec.OrderID)
+    assert.Equal(enginepkg.Buy, exec.Side)
+    assert.Equal(int64(250), exec.FilledQuantity)
+    assert.Equal(int64((10000*100+10100*100+10200*50)/250), exec.AveragePrice)
+    assert.Equal([]string{resp.Trades[0].TradeID, resp.Trades[1].TradeID, resp.Trades[2].TradeID}, exec.TradeIDs)
+    assert.Equal(resp.Trades[2].EventSeq, exec.EventSeq)
+}
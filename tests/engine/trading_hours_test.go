@@ -0,0 +1,132 @@
+package engine_test
+
+import (
+    "bytes"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func tradingHours(symbol string) enginepkg.SymbolConfig {
+    return enginepkg.SymbolConfig{Symbol: symbol, SessionPreOpen: "09:00", SessionOpen: "09:30", SessionClose: "16:00", SessionTimeZone: "America/New_York"}
+}
+
+func TestTradingHours_PreOpenOpenClosed(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(tradingHours("AAPL")))
+
+    ny, _ := time.LoadLocation("America/New_York")
+    now := time.Date(2026, 3, 2, 8, 0, 0, 0, ny)
+    eng.SetClock(func() time.Time { return now })
+
+    // Closed before the pre-open
+    assert.Equal(enginepkg.SessionClosed, eng.GetSessionStatus("AAPL").State)
+    _, err := eng.SubmitOrder(newTestOrder("early", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 10, 1000))
+    assert.ErrorIs(err, enginepkg.ErrMarketClosed)
+
+    // Pre-open collects crossing orders without matching
+    now = time.Date(2026, 3, 2, 9, 10, 0, 0, ny)
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 10, 1001))
+    assert.NoError(err)
+    assert.True(resp.OrderInBook)
+    resp, err = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1002))
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    assert.Equal(enginepkg.PreOpen, eng.GetTradingPhase("AAPL"))
+    _, err = eng.SubmitOrder(newTestOrder("m1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 10, 1003))
+    assert.ErrorIs(err, enginepkg.ErrPhaseRejected)
+
+    // The open uncrosses the book and resumes continuous matching
+    now = time.Date(2026, 3, 2, 9, 30, 0, 0, ny)
+    status := eng.GetSessionStatus("AAPL")
+    assert.Equal(enginepkg.SessionOpen, status.State)
+    assert.Equal(enginepkg.PreOpen, status.Phase, "phase waits for the next sweep")
+    eng.SyncSessions(now)
+    assert.Equal(enginepkg.Continuous, eng.GetTradingPhase("AAPL"))
+    for _, id := range []string{"b1", "s1"} {
+        order, _ := eng.GetOrderStatus(id)
+        assert.Equal(enginepkg.StatusFilled, order.Status)
+    }
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1004))
+    resp, err = eng.SubmitOrder(newTestOrder("b2", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 10, 1005))
+    assert.NoError(err)
+    assert.Equal(1, len(resp.Trades))
+
+    // Closed again at the close; cancels still work
+    _, _ = eng.SubmitOrder(newTestOrder("rest", "AAPL", enginepkg.Buy, enginepkg.Limit, 9000, 10, 1006))
+    now = time.Date(2026, 3, 2, 16, 0, 0, 0, ny)
+    _, err = eng.SubmitOrder(newTestOrder("late", "AAPL", enginepkg.Buy, enginepkg.Limit, 9000, 10, 1007))
+    assert.ErrorIs(err, enginepkg.ErrMarketClosed)
+    _, err = eng.CancelOrder("rest")
+    assert.NoError(err)
+
+    // Symbols without hours are always open
+    assert.Equal(enginepkg.SessionOpen, eng.GetSessionStatus("MSFT").State)
+    _, err = eng.SubmitOrder(newTestOrder("msft", "MSFT", enginepkg.Buy, enginepkg.Limit, 9000, 10, 1008))
+    assert.NoError(err)
+}
+
+func TestTradingHours_FirstOrderAfterOpenUncrosses(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.NoError(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "AAPL", SessionPreOpen: "09:00", SessionOpen: "09:30", SessionClose: "16:00"}))
+
+    now := time.Date(2026, 3, 2, 9, 15, 0, 0, time.UTC)
+    eng.SetClock(func() time.Time { return now })
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 5, 1001))
+
+    // Without a sweep, the first order after the open triggers the uncross
+    // and then trades continuously against what is left
+    now = time.Date(2026, 3, 2, 9, 31, 0, 0, time.UTC)
+    resp, err := eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 5, 1002))
+    assert.NoError(err)
+    if assert.Equal(1, len(resp.Trades)) {
+        assert.Equal("b1", resp.Trades[0].RestingOrderID)
+    }
+    order, _ := eng.GetOrderStatus("b1")
+    assert.Equal(enginepkg.StatusFilled, order.Status)
+}
+
+func TestTradingHours_ReplayIgnoresClock(t *testing.T) {
+    assert := assert.New(t)
+    var log bytes.Buffer
+
+    eng := setupEngine()
+    eng.SetWAL(enginepkg.NewJSONWAL(&log))
+    assert.NoError(eng.ConfigureSymbol(tradingHours("AAPL")))
+    ny, _ := time.LoadLocation("America/New_York")
+    now := time.Date(2026, 3, 2, 9, 10, 0, 0, ny)
+    eng.SetClock(func() time.Time { return now })
+    _, _ = eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Limit, 10100, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1001))
+    now = time.Date(2026, 3, 2, 9, 30, 0, 0, ny)
+    eng.SyncSessions(now)
+
+    // Replayed at night, when the market is closed, the log still rebuilds
+    // the pre-open and the opening uncross
+    replayed := setupEngine()
+    assert.NoError(replayed.ConfigureSymbol(tradingHours("AAPL")))
+    replayed.SetClock(func() time.Time { return time.Date(2026, 3, 2, 22, 0, 0, 0, ny) })
+    assert.NoError(replayed.Recover(&log))
+    for _, id := range []string{"b1", "s1"} {
+        order, err := replayed.GetOrderStatus(id)
+        if assert.NoError(err) {
+            assert.Equal(enginepkg.StatusFilled, order.Status)
+        }
+    }
+    assert.Equal(enginepkg.Continuous, replayed.GetTradingPhase("AAPL"))
+}
+
+func TestTradingHours_ConfigValidation(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "A", SessionOpen: "09:30"}), "open needs a close")
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "A", SessionOpen: "16:00", SessionClose: "09:30"}))
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "A", SessionPreOpen: "10:00", SessionOpen: "09:30", SessionClose: "16:00"}))
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "A", SessionPreOpen: "09:00", SessionClose: "16:00"}))
+    assert.Error(eng.ConfigureSymbol(enginepkg.SymbolConfig{Symbol: "A", SessionOpen: "9.30", SessionClose: "16:00"}))
+}
@@ -0,0 +1,102 @@
+package engine_test
+
+import (
+    "bytes"
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// phantomSnapshot snapshots a book with bids at 9900 and 10000 after
+// marking the 10000 bid fully filled, so restoring it queues an order with
+// nothing left to trade at a level of its own.
+func phantomSnapshot(t *testing.T) []byte {
+    eng := setupEngine()
+    _, _ = eng.SubmitOrder(newTestOrder("bid-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("phantom", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 100, 1001))
+    var buf bytes.Buffer
+    assert.NoError(t, eng.Snapshot(&buf))
+
+    var snap map[string]interface{}
+    assert.NoError(t, json.Unmarshal(buf.Bytes(), &snap))
+    for _, o := range snap["orders"].([]interface{}) {
+        if order := o.(map[string]interface{}); order["id"] == "phantom" {
+            order["filled_quantity"] = order["quantity"]
+        }
+    }
+    out, err := json.Marshal(snap)
+    assert.NoError(t, err)
+    return out
+}
+
+func TestVerify_SweepRemovesPhantomLevels(t *testing.T) {
+    assert := assert.New(t)
+
+    eng := setupEngine()
+    assert.NoError(eng.LoadSnapshot(bytes.NewReader(phantomSnapshot(t))))
+
+    // Depth already hides the empty level, but the book still holds it
+    bids, _ := eng.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal([]enginepkg.AggregatedPriceLevel{{Price: 9900, Quantity: 100}}, bids)
+    err := eng.Verify("AAPL")
+    assert.Error(err)
+    assert.Contains(err.Error(), "phantom has nothing left to trade")
+
+    assert.Equal(1, eng.SweepBook("AAPL"))
+    assert.NoError(eng.Verify("AAPL"))
+    status, _ := eng.GetOrderStatus("phantom")
+    assert.Equal(enginepkg.StatusFilled, status.Status)
+    assert.Equal(0, eng.SweepBook("AAPL"), "nothing left to sweep")
+
+    // The level is really gone: a sell at 10000 rests instead of matching it
+    resp, err := eng.SubmitOrder(newTestOrder("sell", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1002))
+    assert.NoError(err)
+    assert.Empty(resp.Trades)
+    assert.True(resp.OrderInBook)
+    assert.NoError(eng.Verify("AAPL"))
+}
+
+func TestVerify_BookSweepRunsOnEveryChange(t *testing.T) {
+    assert := assert.New(t)
+
+    eng := setupEngine()
+    eng.SetBookSweep(true)
+    assert.NoError(eng.LoadSnapshot(bytes.NewReader(phantomSnapshot(t))))
+    assert.Error(eng.Verify("AAPL"))
+
+    _, err := eng.SubmitOrder(newTestOrder("ask-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1002))
+    assert.NoError(err)
+    assert.NoError(eng.Verify("AAPL"))
+}
+
+func TestVerify_HoldsThroughNormalTrading(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    iceberg := newTestOrder("ice", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 300, 1000)
+    iceberg.DisplayQuantity = 50
+    hidden := newTestOrder("hidden", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 40, 1001)
+    hidden.Hidden = true
+    aon := newTestOrder("aon", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 500, 1002)
+    aon.AllOrNone = true
+    for _, o := range []*enginepkg.Order{
+        iceberg,
+        hidden,
+        aon,
+        newTestOrder("plain", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 60, 1003),
+        newTestOrder("bid", "AAPL", enginepkg.Buy, enginepkg.Limit, 9900, 100, 1004),
+    } {
+        _, err := eng.SubmitOrder(o)
+        assert.NoError(err)
+    }
+    assert.NoError(eng.Verify("AAPL"))
+
+    _, _ = eng.SubmitOrder(newTestOrder("take", "AAPL", enginepkg.Buy, enginepkg.Limit, 10000, 400, 1005))
+    assert.NoError(eng.Verify("AAPL"))
+    _, _, _ = eng.AmendOrder("bid", 9950, 100)
+    _, _ = eng.CancelOrder("aon")
+    assert.NoError(eng.Verify("AAPL"))
+    assert.Equal(0, eng.SweepBook("AAPL"))
+}
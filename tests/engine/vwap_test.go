@@ -0,0 +1,39 @@
+package engine_test
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+func TestVWAP_ThreeLevelFill(t *testing.T) {
+    eng := setupEngine()
+    assert := assert.New(t)
+
+    _, _ = eng.SubmitOrder(newTestOrder("s1", "AAPL", enginepkg.Sell, enginepkg.Limit, 10000, 10, 1000))
+    _, _ = eng.SubmitOrder(newTestOrder("s2", "AAPL", enginepkg.Sell, enginepkg.Limit, 10100, 10, 1001))
+    _, _ = eng.SubmitOrder(newTestOrder("s3", "AAPL", enginepkg.Sell, enginepkg.Limit, 10200, 10, 1002))
+
+    resp, err := eng.SubmitOrder(newTestOrder("b1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 25, 1003))
+    assert.NoError(err)
+    assert.Equal(3, len(resp.Trades))
+    // 10@10000 + 10@10100 + 5@10200 = 252000 over 25 = 10080 exactly
+    assert.Equal(int64(252000), resp.TotalValue())
+    assert.Equal(int64(10080), resp.VWAP())
+}
+
+func TestVWAP_RoundsHalfUp(t *testing.T) {
+    assert := assert.New(t)
+
+    resp := enginepkg.ProcessOrderResponse{Trades: []enginepkg.Trade{
+        {Price: 100, Quantity: 1},
+        {Price: 101, Quantity: 1},
+    }}
+    assert.Equal(int64(101), resp.VWAP()) // 100.5 rounds up
+
+    resp.Trades = append(resp.Trades, enginepkg.Trade{Price: 100, Quantity: 1})
+    assert.Equal(int64(100), resp.VWAP()) // 100.33 rounds down
+
+    assert.Equal(int64(0), enginepkg.ProcessOrderResponse{}.VWAP())
+}
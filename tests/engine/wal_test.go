@@ -0,0 +1,68 @@
+package engine_test
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    enginepkg "order-matching-engine/src/engine"
+)
+
+// TestWAL_ReplayReproducesBook checks recovering from the log rebuilds the same book
+func TestWAL_ReplayReproducesBook(t *testing.T) {
+    assert := assert.New(t)
+
+    var log bytes.Buffer
+    original := setupEngine()
+    original.SetWAL(enginepkg.NewJSONWAL(&log))
+
+    _, _ = original.SubmitOrder(newTestOrder("sell-1", "AAPL", enginepkg.Sell, enginepkg.Limit, 15050, 200, 1000))
+    _, _ = original.SubmitOrder(newTestOrder("sell-2", "AAPL", enginepkg.Sell, enginepkg.Limit, 15052, 300, 1001))
+    _, _ = original.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15040, 400, 1002))
+    _, _ = original.SubmitOrder(newTestOrder("buy-2", "AAPL", enginepkg.Buy, enginepkg.Limit, 15050, 150, 1003))
+    _, _ = original.SubmitOrder(newTestOrder("mkt-1", "AAPL", enginepkg.Buy, enginepkg.Market, 0, 5000, 1004)) // rejected
+    _, _, _ = original.AmendOrder("buy-1", 15045, 0)
+    _, _ = original.CancelOrder("sell-2")
+
+    // Line-delimited JSON with gapless sequence numbers
+    scanner := bufio.NewScanner(bytes.NewReader(log.Bytes()))
+    var seq uint64
+    for scanner.Scan() {
+        var entry enginepkg.WALEntry
+        assert.NoError(json.Unmarshal(scanner.Bytes(), &entry))
+        seq++
+        assert.Equal(seq, entry.Sequence)
+    }
+    assert.Equal(uint64(7), seq)
+
+    recovered := setupEngine()
+    assert.NoError(recovered.Recover(bytes.NewReader(log.Bytes())))
+
+    b1, a1 := original.GetOrderBookSnapshot("AAPL", 0)
+    b2, a2 := recovered.GetOrderBookSnapshot("AAPL", 0)
+    assert.Equal(b1, b2)
+    assert.Equal(a1, a2)
+
+    status, _ := recovered.GetOrderStatus("sell-1")
+    assert.Equal(int64(150), status.FilledQuantity)
+    status, _ = recovered.GetOrderStatus("sell-2")
+    assert.Equal(enginepkg.StatusCancelled, status.Status)
+}
+
+type failingWAL struct{}
+
+func (failingWAL) Append(enginepkg.WALEntry) error { return errors.New("disk full") }
+
+// TestWAL_AppendFailureBlocksMutation checks nothing is applied if it cannot be logged
+func TestWAL_AppendFailureBlocksMutation(t *testing.T) {
+    eng := setupEngine()
+    eng.SetWAL(failingWAL{})
+
+    _, err := eng.SubmitOrder(newTestOrder("buy-1", "AAPL", enginepkg.Buy, enginepkg.Limit, 15040, 100, 1000))
+    assert.Error(t, err)
+    _, err = eng.GetOrderStatus("buy-1")
+    assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package fix_test
+
+import (
+    "bufio"
+    "fmt"
+    "net"
+    "strings"
+    "testing"
+    "time"
+
+    "order-matching-engine/src/engine"
+    "order-matching-engine/src/fix"
+)
+
+// testClient is a FIX initiator driving an acceptor over TCP.
+type testClient struct {
+    t    *testing.T
+    conn net.Conn
+    r    *bufio.Reader
+    seq  int
+}
+
+// newTestClient serves a fresh engine and logs on as CLIENT.
+func newTestClient(t *testing.T) (*testClient, *engine.MatchingEngine) {
+    eng := engine.NewMatchingEngine()
+    acc := fix.NewAcceptor(eng, "OME")
+    lis, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatalf("listen: %v", err)
+    }
+    go func() { _ = acc.Serve(lis) }()
+    t.Cleanup(func() { acc.Close() })
+
+    conn, err := net.Dial("tcp", lis.Addr().String())
+    if err != nil {
+        t.Fatalf("dial: %v", err)
+    }
+    t.Cleanup(func() { conn.Close() })
+    c := &testClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+    c.send("35=A|98=0|108=0")
+    if logon := c.read(); logon.MsgType() != "A" {
+        t.Fatalf("expected Logon reply, got %s", logon)
+    }
+    return c, eng
+}
+
+// send frames body, a |-separated list of fields after the standard header.
+func (c *testClient) send(body string) {
+    c.seq++
+    msgType, rest, _ := strings.Cut(body, "|")
+    full := fmt.Sprintf("%s|49=CLIENT|56=OME|34=%d|52=20260101-00:00:00.000|", msgType, c.seq)
+    if rest != "" {
+        full += rest + "|"
+    }
+    if _, err := c.conn.Write(frame(full)); err != nil {
+        c.t.Fatalf("write: %v", err)
+    }
+}
+
+// frame adds BeginString, BodyLength and CheckSum to a |-separated body.
+func frame(body string) []byte {
+    body = strings.ReplaceAll(body, "|", "\x01")
+    head := fmt.Sprintf("8=FIX.4.4\x019=%d\x01", len(body))
+    sum := 0
+    for _, b := range []byte(head + body) {
+        sum += int(b)
+    }
+    return []byte(fmt.Sprintf("%s%s10=%03d\x01", head, body, sum%256))
+}
+
+func (c *testClient) read() *fix.Message {
+    c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+    m, err := fix.ReadMessage(c.r)
+    if err != nil {
+        c.t.Fatalf("read: %v", err)
+    }
+    return m
+}
+
+// expect reads the next message and checks the given tag values.
+func (c *testClient) expect(want map[int]string) *fix.Message {
+    c.t.Helper()
+    m := c.read()
+    for tag, v := range want {
+        if got := m.Get(tag); got != v {
+            c.t.Fatalf("tag %d: expected %q, got %q in %s", tag, v, got, m)
+        }
+    }
+    return m
+}
+
+func TestFIX_NewOrderSingleIsAcknowledged(t *testing.T) {
+    c, eng := newTestClient(t)
+
+    c.send("35=D|11=o1|1=acct|55=AAPL|54=1|38=100|40=2|44=15000|59=1|60=20260101-00:00:00")
+    c.expect(map[int]string{35: "8", 150: "0", 39: "0", 37: "CLIENT:o1", 11: "o1", 1: "acct", 55: "AAPL", 54: "1", 38: "100", 44: "15000", 151: "100", 14: "0"})
+
+    order, err := eng.GetOrderStatus("CLIENT:o1")
+    if err != nil || order.AccountID != "acct" || order.Price != 15000 || order.TimeInForce != engine.GTC {
+        t.Fatalf("expected the order resting in the engine, got %+v (err %v)", order, err)
+    }
+}
+
+func TestFIX_FillsReportBothSides(t *testing.T) {
+    c, _ := newTestClient(t)
+
+    c.send("35=D|11=s1|55=AAPL|54=2|38=100|40=2|44=15000")
+    c.expect(map[int]string{150: "0", 11: "s1"})
+    c.send("35=D|11=b1|55=AAPL|54=1|38=60|40=1|59=3")
+    c.expect(map[int]string{150: "0", 11: "b1"})
+    c.expect(map[int]string{150: "F", 11: "s1", 39: "1", 32: "60", 31: "15000", 14: "60", 151: "40", 6: "15000"})
+    c.expect(map[int]string{150: "F", 11: "b1", 39: "2", 32: "60", 31: "15000", 14: "60", 151: "0"})
+}
+
+func TestFIX_CancelAndReplace(t *testing.T) {
+    c, _ := newTestClient(t)
+
+    c.send("35=D|11=o1|55=AAPL|54=1|38=100|40=2|44=15000")
+    c.expect(map[int]string{150: "0"})
+
+    c.send("35=G|11=o2|41=o1|55=AAPL|54=1|38=80|40=2|44=15100")
+    c.expect(map[int]string{150: "5", 39: "0", 37: "CLIENT:o1", 11: "o2", 41: "o1", 38: "80", 44: "15100", 151: "80"})
+
+    // The replaced order is now known by its new ClOrdID
+    c.send("35=F|11=o3|41=o2|55=AAPL|54=1")
+    c.expect(map[int]string{150: "4", 39: "4", 37: "CLIENT:o1", 11: "o3", 41: "o2", 151: "0", 58: engine.ReasonRequested})
+
+    c.send("35=F|11=o4|41=o3|55=AAPL|54=1")
+    c.expect(map[int]string{35: "9", 11: "o4", 41: "o3", 434: "1", 102: "1"})
+}
+
+func TestFIX_Rejects(t *testing.T) {
+    c, _ := newTestClient(t)
+
+    // The engine refuses a limit order priced at zero
+    c.send("35=D|11=o1|55=AAPL|54=1|38=100|40=2|44=0")
+    c.expect(map[int]string{35: "8", 150: "8", 39: "8", 11: "o1", 151: "0"})
+
+    c.send("35=D|11=o2|55=AAPL|54=1|38=100|40=2|44=15000")
+    c.expect(map[int]string{150: "0"})
+    c.send("35=D|11=o2|55=AAPL|54=1|38=100|40=2|44=15000")
+    c.expect(map[int]string{150: "8", 103: "6"})
+
+    // A malformed field is a session-level Reject
+    c.send("35=D|11=o3|55=AAPL|54=7|38=100|40=2|44=15000")
+    c.expect(map[int]string{35: "3", 371: "54", 373: "5"})
+    c.send("35=D|11=o4|55=AAPL|54=1|40=2|44=15000")
+    c.expect(map[int]string{35: "3", 371: "38", 373: "1"})
+
+    c.send("35=R|131=q1")
+    c.expect(map[int]string{35: "j", 372: "R", 380: "3"})
+}
+
+func TestFIX_TestRequestAndLogout(t *testing.T) {
+    c, _ := newTestClient(t)
+
+    c.send("35=1|112=ping")
+    c.expect(map[int]string{35: "0", 112: "ping"})
+    c.send("35=5")
+    c.expect(map[int]string{35: "5"})
+}
+
+func TestFIX_GarbledMessageIsIgnored(t *testing.T) {
+    c, _ := newTestClient(t)
+
+    bad := frame(fmt.Sprintf("35=1|49=CLIENT|56=OME|34=%d|52=20260101-00:00:00.000|112=x|", c.seq+1))
+    bad[len(bad)-2]++ // Corrupt the checksum
+    c.conn.Write(bad)
+
+    // Not processed, so the same sequence number is still expected next
+    c.send("35=1|112=ok")
+    c.expect(map[int]string{35: "0", 112: "ok"})
+}
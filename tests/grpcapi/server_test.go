@@ -0,0 +1,122 @@
+package grpcapi_test
+
+import (
+    "context"
+    "net"
+    "testing"
+    "time"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/grpc/status"
+    "google.golang.org/grpc/test/bufconn"
+    "order-matching-engine/src/engine"
+    "order-matching-engine/src/grpcapi"
+    pb "order-matching-engine/src/grpcapi/matchingpb"
+)
+
+// newTestClient serves a fresh engine over an in-process connection.
+func newTestClient(t *testing.T) pb.MatchingEngineClient {
+    lis := bufconn.Listen(1 << 20)
+    srv := grpcapi.NewServer(engine.NewMatchingEngine())
+    go func() { _ = srv.Serve(lis) }()
+    t.Cleanup(srv.Stop)
+
+    conn, err := grpc.NewClient("passthrough:///bufnet",
+        grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+        grpc.WithTransportCredentials(insecure.NewCredentials()),
+    )
+    if err != nil {
+        t.Fatalf("dial: %v", err)
+    }
+    t.Cleanup(func() { conn.Close() })
+    return pb.NewMatchingEngineClient(conn)
+}
+
+func TestGRPC_SubmitAndMatch(t *testing.T) {
+    client := newTestClient(t)
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+
+    trades, err := client.StreamTrades(ctx, &pb.StreamTradesRequest{Symbol: "AAPL"})
+    if err != nil {
+        t.Fatalf("stream trades: %v", err)
+    }
+    if _, err := trades.Header(); err != nil { // Subscribed
+        t.Fatalf("stream trades header: %v", err)
+    }
+    book, err := client.StreamOrderBook(ctx, &pb.StreamOrderBookRequest{Symbol: "AAPL"})
+    if err != nil {
+        t.Fatalf("stream order book: %v", err)
+    }
+    snap, err := book.Recv()
+    if err != nil || !snap.Snapshot || len(snap.Bids)+len(snap.Asks) != 0 {
+        t.Fatalf("expected an empty snapshot, got %v (err %v)", snap, err)
+    }
+
+    sell, err := client.SubmitOrder(ctx, &pb.SubmitOrderRequest{
+        Id: "s1", Symbol: "AAPL", Side: pb.Side_SIDE_SELL, Type: pb.OrderType_ORDER_TYPE_LIMIT, Price: 15000, Quantity: 100,
+    })
+    if err != nil || !sell.Resting || sell.Order.Status != pb.OrderStatus_ORDER_STATUS_ACCEPTED {
+        t.Fatalf("expected a resting sell, got %v (err %v)", sell, err)
+    }
+    update, err := book.Recv()
+    if err != nil || update.Snapshot || len(update.Asks) != 1 || update.Asks[0].Quantity != 100 {
+        t.Fatalf("expected an ask update, got %v (err %v)", update, err)
+    }
+
+    buy, err := client.SubmitOrder(ctx, &pb.SubmitOrderRequest{
+        Id: "b1", Symbol: "AAPL", Side: pb.Side_SIDE_BUY, Type: pb.OrderType_ORDER_TYPE_LIMIT, Price: 15000, Quantity: 40,
+    })
+    if err != nil {
+        t.Fatalf("submit buy: %v", err)
+    }
+    if buy.Order.Status != pb.OrderStatus_ORDER_STATUS_FILLED || len(buy.Trades) != 1 || buy.Trades[0].Quantity != 40 || buy.Trades[0].RestingOrderId != "s1" {
+        t.Fatalf("expected a 40 lot fill against s1, got %v", buy)
+    }
+    trade, err := trades.Recv()
+    if err != nil || trade.TradeId != buy.Trades[0].TradeId || trade.EventSeq == 0 {
+        t.Fatalf("expected the trade on the stream, got %v (err %v)", trade, err)
+    }
+
+    order, err := client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: "s1"})
+    if err != nil || order.Status != pb.OrderStatus_ORDER_STATUS_PARTIAL_FILL || order.FilledQuantity != 40 {
+        t.Fatalf("expected s1 partially filled, got %v (err %v)", order, err)
+    }
+    cancelled, err := client.CancelOrder(ctx, &pb.CancelOrderRequest{OrderId: "s1"})
+    if err != nil || cancelled.Status != pb.OrderStatus_ORDER_STATUS_CANCELLED || cancelled.CancelReason != engine.ReasonRequested {
+        t.Fatalf("expected s1 cancelled, got %v (err %v)", cancelled, err)
+    }
+}
+
+func TestGRPC_ErrorsCarryReasonCodes(t *testing.T) {
+    client := newTestClient(t)
+    ctx := context.Background()
+
+    _, err := client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: "missing"})
+    if status.Code(err) != codes.NotFound || reason(err) != string(engine.CodeOrderNotFound) {
+        t.Fatalf("expected NotFound/ORDER_NOT_FOUND, got %v", err)
+    }
+
+    _, err = client.SubmitOrder(ctx, &pb.SubmitOrderRequest{Symbol: "AAPL", Side: pb.Side_SIDE_BUY, Type: pb.OrderType_ORDER_TYPE_MARKET, Quantity: 10})
+    if status.Code(err) != codes.InvalidArgument || reason(err) != string(engine.CodeInsufficientLiquidity) {
+        t.Fatalf("expected InvalidArgument/INSUFFICIENT_LIQUIDITY, got %v", err)
+    }
+
+    _, err = client.SubmitOrder(ctx, &pb.SubmitOrderRequest{Symbol: "AAPL", Type: pb.OrderType_ORDER_TYPE_LIMIT, Price: 100, Quantity: 10})
+    if status.Code(err) != codes.InvalidArgument || reason(err) != string(engine.CodeInvalidOrder) {
+        t.Fatalf("expected InvalidArgument/INVALID_ORDER for a missing side, got %v", err)
+    }
+}
+
+// reason returns the ErrorInfo reason attached to a gRPC error.
+func reason(err error) string {
+    for _, detail := range status.Convert(err).Details() {
+        if info, ok := detail.(*errdetails.ErrorInfo); ok {
+            return info.Reason
+        }
+    }
+    return ""
+}